@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// readmeVarPattern matches environment variables mentioned in a README. Matches directly followed by an underscore or
+// asterisk are ignored since they are prefixes, such as ECO_CNF_RAN_*, rather than variables.
+var readmeVarPattern = regexp.MustCompile(`ECO_[A-Z][A-Z0-9]*(?:_[A-Z0-9]+)*`)
+
+// ProblemKind is the kind of mismatch between the code and a README.
+type ProblemKind string
+
+const (
+	// ProblemUndocumented means a variable read by the code is not mentioned in the README of its suite.
+	ProblemUndocumented ProblemKind = "UNDOCUMENTED"
+	// ProblemStale means a variable mentioned in a README is not read by the code of its suite nor is it global.
+	ProblemStale ProblemKind = "STALE"
+	// ProblemMissingReadme means a suite reads variables but has no README.
+	ProblemMissingReadme ProblemKind = "MISSING"
+	// ProblemUnresolved means the code reads a variable whose name is not a constant, so it cannot be documented.
+	ProblemUnresolved ProblemKind = "UNRESOLVED"
+)
+
+// Problem is a single mismatch between the code and a README.
+type Problem struct {
+	Kind   ProblemKind
+	Var    string
+	Readme string
+	// Location is the file and line of the read for unresolved problems, relative to the repo root.
+	Location string
+}
+
+// String returns the problem in the same format as the check-envvar-docs.sh script it replaces.
+func (problem Problem) String() string {
+	switch problem.Kind {
+	case ProblemUndocumented:
+		return fmt.Sprintf("%s: %s not in %s", problem.Kind, problem.Var, problem.Readme)
+	case ProblemStale:
+		return fmt.Sprintf("%s: %s in %s but not in code", problem.Kind, problem.Var, problem.Readme)
+	case ProblemUnresolved:
+		return fmt.Sprintf("%s: %s at %s does not read a constant", problem.Kind, problem.Var, problem.Location)
+	default:
+		return fmt.Sprintf("%s: %s does not exist", problem.Kind, problem.Readme)
+	}
+}
+
+// CheckReadmes compares the variables read by each suite with those mentioned in the README of that suite. Global
+// variables, which are those in globalVars, may be mentioned in any README without being stale. Problems are sorted by
+// README and then variable.
+func CheckReadmes(root string, envVars []EnvVar, globalVars []string) ([]Problem, error) {
+	klog.V(100).Infof("Checking READMEs for %d environment variables", len(envVars))
+
+	suiteVars := make(map[string][]string)
+
+	for _, envVar := range envVars {
+		suiteVars[envVar.Suite] = append(suiteVars[envVar.Suite], envVar.Name)
+	}
+
+	var problems []Problem
+
+	for suite, codeVars := range suiteVars {
+		readme := filepath.ToSlash(filepath.Join(suite, readmeFileName))
+
+		docVars, err := readmeVars(filepath.Join(root, readme))
+		if errors.Is(err, os.ErrNotExist) {
+			problems = append(problems, Problem{Kind: ProblemMissingReadme, Readme: readme})
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, codeVar := range codeVars {
+			if !slices.Contains(docVars, codeVar) {
+				problems = append(problems, Problem{Kind: ProblemUndocumented, Var: codeVar, Readme: readme})
+			}
+		}
+
+		for _, docVar := range docVars {
+			if !slices.Contains(codeVars, docVar) && !slices.Contains(globalVars, docVar) {
+				problems = append(problems, Problem{Kind: ProblemStale, Var: docVar, Readme: readme})
+			}
+		}
+	}
+
+	slices.SortFunc(problems, func(problemA, problemB Problem) int {
+		if n := strings.Compare(problemA.Readme, problemB.Readme); n != 0 {
+			return n
+		}
+
+		return strings.Compare(problemA.Var, problemB.Var)
+	})
+
+	return slices.Compact(problems), nil
+}
+
+// GlobalVars returns the variables that apply to every suite: those read by the test runner script and those in the
+// general config, which is in the tests/internal suite.
+func GlobalVars(root string, envVars []EnvVar) ([]string, error) {
+	globalVars, err := readmeVars(filepath.Join(root, testRunnerScript))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, envVar := range envVars {
+		if envVar.Suite == testsDir {
+			globalVars = append(globalVars, envVar.Name)
+		}
+	}
+
+	slices.Sort(globalVars)
+
+	return slices.Compact(globalVars), nil
+}
+
+// readmeVars returns the sorted, unique variables mentioned in the file at path.
+func readmeVars(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var docVars []string
+
+	for _, match := range readmeVarPattern.FindAllIndex(content, -1) {
+		end := match[1]
+		if end < len(content) && (content[end] == '_' || content[end] == '*') {
+			continue
+		}
+
+		docVars = append(docVars, string(content[match[0]:end]))
+	}
+
+	slices.Sort(docVars)
+
+	return slices.Compact(docVars), nil
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"cmp"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// envVarPattern matches the names of the environment variables that are documented. Variables without the ECO_ prefix,
+// such as KUBECONFIG, are not owned by this repo.
+var envVarPattern = regexp.MustCompile(`^ECO_[A-Z0-9_]+$`)
+
+// EnvVar is a single environment variable read by the test code.
+type EnvVar struct {
+	// Name is the name of the environment variable.
+	Name string `json:"name"`
+	// Type is the Go type of the field the variable is read into. Variables read directly with os.Getenv or
+	// os.LookupEnv are always strings.
+	Type string `json:"type"`
+	// Default is the value of the field in the default.yaml next to the struct, if any.
+	Default string `json:"default,omitempty"`
+	// Description is the doc comment or line comment of the field, if any.
+	Description string `json:"description,omitempty"`
+	// Suite is the directory of the suite that owns the variable, relative to the repo root. Its README is expected
+	// to document the variable.
+	Suite string `json:"suite"`
+	// Source is the struct and field the variable is read into, or the function reading it directly.
+	Source string `json:"source"`
+	// Location is the file and line where the variable is declared, relative to the repo root.
+	Location string `json:"location"`
+	// yamlKey is the yaml key of the field used to look up the default.
+	yamlKey string
+}
+
+// CollectEnvVars parses all non-test Go files under the tests directory of the repo at root and returns every ECO_*
+// environment variable found in envconfig struct tags and in calls to os.Getenv and os.LookupEnv. The argument to these
+// calls may be any constant expression, including constants declared in other packages of the module. Calls whose
+// argument is not a constant are returned as problems since the variable they read cannot be documented. Variables are
+// sorted by suite and then name. A variable declared in multiple places within the same suite is only returned once,
+// preferring the declaration in a struct tag.
+func CollectEnvVars(root string) ([]EnvVar, []Problem, error) {
+	klog.V(100).Infof("Collecting environment variables from %s", root)
+
+	fileSet := token.NewFileSet()
+	defaultsCache := make(map[string]*Defaults)
+
+	loader, err := NewSourceLoader(fileSet, root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		envVars  []EnvVar
+		problems []Problem
+	)
+
+	err = filepath.WalkDir(filepath.Join(root, testsDir), func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !dirEntry.IsDir() {
+			return nil
+		}
+
+		sourcePackage, err := loader.LoadDir(path)
+		if err != nil {
+			return err
+		}
+
+		var dirVars []EnvVar
+
+		for _, file := range sourcePackage.Files {
+			fileVars, fileProblems := collectFileEnvVars(fileSet, sourcePackage.Info, file)
+			dirVars = append(dirVars, fileVars...)
+			problems = append(problems, fileProblems...)
+		}
+
+		if len(dirVars) == 0 {
+			return nil
+		}
+
+		defaults, err := loadDefaultsCached(defaultsCache, path)
+		if err != nil {
+			return err
+		}
+
+		suite, err := findSuite(root, fileSet.File(sourcePackage.Files[0].Pos()).Name())
+		if err != nil {
+			return err
+		}
+
+		for _, envVar := range dirVars {
+			envVar.Suite = suite
+			envVar.Default = defaults.Lookup(envVar.yamlKey)
+			envVars = append(envVars, envVar)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range envVars {
+		location, err := relativeLocation(root, envVars[i].Location)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		envVars[i].Location = location
+	}
+
+	for i := range problems {
+		location, err := relativeLocation(root, problems[i].Location)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		problems[i].Location = location
+	}
+
+	slices.SortStableFunc(envVars, compareEnvVars)
+
+	return slices.CompactFunc(envVars, func(envVarA, envVarB EnvVar) bool {
+		return envVarA.Suite == envVarB.Suite && envVarA.Name == envVarB.Name
+	}), problems, nil
+}
+
+// collectFileEnvVars returns the environment variables declared in a single file, using info to evaluate the arguments
+// of direct reads, along with a problem for every direct read whose argument is not a constant. Locations are absolute
+// and the suite and default are left unset.
+func collectFileEnvVars(fileSet *token.FileSet, info *types.Info, file *ast.File) ([]EnvVar, []Problem) {
+	var (
+		envVars  []EnvVar
+		problems []Problem
+	)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.TypeSpec:
+			structType, ok := node.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+
+			envVars = append(envVars, collectStructEnvVars(fileSet, node.Name.Name, structType)...)
+
+			return false
+		case *ast.FuncDecl:
+			funcVars, funcProblems := collectCallEnvVars(fileSet, info, node)
+			envVars = append(envVars, funcVars...)
+			problems = append(problems, funcProblems...)
+
+			return false
+		}
+
+		return true
+	})
+
+	return envVars, problems
+}
+
+// collectStructEnvVars returns the environment variables from the envconfig tags of the fields of a struct type.
+// Fields whose type is an anonymous struct, or a pointer, slice, or map of one, are included recursively with the field
+// name appended to structName.
+func collectStructEnvVars(fileSet *token.FileSet, structName string, structType *ast.StructType) []EnvVar {
+	var envVars []EnvVar
+
+	for _, field := range structType.Fields.List {
+		if nestedType := anonymousStruct(field.Type); nestedType != nil && len(field.Names) > 0 {
+			envVars = append(envVars, collectStructEnvVars(fileSet, structName+"."+field.Names[0].Name, nestedType)...)
+		}
+
+		if field.Tag == nil {
+			continue
+		}
+
+		tagValue, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+
+		tag := reflect.StructTag(tagValue)
+
+		name, _, _ := strings.Cut(tag.Get("envconfig"), ",")
+		if !envVarPattern.MatchString(name) {
+			continue
+		}
+
+		yamlKey, _, _ := strings.Cut(tag.Get("yaml"), ",")
+		fieldName := types.ExprString(field.Type)
+
+		if len(field.Names) > 0 {
+			fieldName = field.Names[0].Name
+		}
+
+		envVars = append(envVars, EnvVar{
+			Name:        name,
+			Type:        types.ExprString(field.Type),
+			Description: fieldDescription(field),
+			Source:      structName + "." + fieldName,
+			Location:    fileSet.Position(field.Pos()).String(),
+			yamlKey:     yamlKey,
+		})
+	}
+
+	return envVars
+}
+
+// anonymousStruct returns the anonymous struct type of expr, looking through pointers, slices, and maps. It returns nil
+// if there is no anonymous struct type.
+func anonymousStruct(expr ast.Expr) *ast.StructType {
+	switch expr := expr.(type) {
+	case *ast.StructType:
+		return expr
+	case *ast.StarExpr:
+		return anonymousStruct(expr.X)
+	case *ast.ArrayType:
+		return anonymousStruct(expr.Elt)
+	case *ast.MapType:
+		return anonymousStruct(expr.Value)
+	default:
+		return nil
+	}
+}
+
+// collectCallEnvVars returns the environment variables read directly with os.Getenv or os.LookupEnv in a function. The
+// argument of each call is evaluated using info, so it may be a literal, a named constant, or any other constant
+// expression. Calls whose argument does not evaluate to a constant string are returned as problems.
+func collectCallEnvVars(fileSet *token.FileSet, info *types.Info, funcDecl *ast.FuncDecl) ([]EnvVar, []Problem) {
+	var (
+		envVars  []EnvVar
+		problems []Problem
+	)
+
+	ast.Inspect(funcDecl, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || types.ExprString(selector.X) != "os" ||
+			(selector.Sel.Name != "Getenv" && selector.Sel.Name != "LookupEnv") {
+			return true
+		}
+
+		value := info.Types[call.Args[0]].Value
+		if value == nil || value.Kind() != constant.String {
+			problems = append(problems, Problem{
+				Kind:     ProblemUnresolved,
+				Var:      types.ExprString(call),
+				Location: fileSet.Position(call.Pos()).String(),
+			})
+
+			return true
+		}
+
+		name := constant.StringVal(value)
+		if !envVarPattern.MatchString(name) {
+			return true
+		}
+
+		envVars = append(envVars, EnvVar{
+			Name:     name,
+			Type:     "string",
+			Source:   funcDecl.Name.Name,
+			Location: fileSet.Position(call.Pos()).String(),
+		})
+
+		return true
+	})
+
+	return envVars, problems
+}
+
+// fieldDescription returns the doc comment of the field, or its line comment if there is no doc comment, joined into a
+// single line.
+func fieldDescription(field *ast.Field) string {
+	comment := field.Doc
+	if comment == nil {
+		comment = field.Comment
+	}
+
+	if comment == nil {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(comment.Text()), " ")
+}
+
+// findSuite returns the directory of the suite owning the file at path, relative to root. For files in an internal
+// directory, this is the directory containing the last internal element. Otherwise, it is the nearest directory with a
+// README.md, up to the tests directory.
+func findSuite(root, path string) (string, error) {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+
+	relPath = filepath.ToSlash(relPath)
+
+	if index := strings.LastIndex(relPath, "/internal/"); index >= 0 {
+		return relPath[:index], nil
+	}
+
+	dir := filepath.Dir(relPath)
+
+	for dir != testsDir && dir != "." {
+		_, err := os.Stat(filepath.Join(root, dir, readmeFileName))
+		if err == nil {
+			return dir, nil
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return testsDir, nil
+}
+
+// relativeLocation converts an absolute or relative file:line:column location into a file:line location relative to
+// root.
+func relativeLocation(root, location string) (string, error) {
+	fileName, rest, _ := strings.Cut(location, ":")
+	line, _, _ := strings.Cut(rest, ":")
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	absFileName, err := filepath.Abs(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	relFileName, err := filepath.Rel(absRoot, absFileName)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(relFileName) + ":" + line, nil
+}
+
+// compareEnvVars orders variables by suite and then name. Variables declared in struct tags are ordered before those
+// read directly, since they have more information to document.
+func compareEnvVars(envVarA, envVarB EnvVar) int {
+	if n := cmp.Compare(envVarA.Suite, envVarB.Suite); n != 0 {
+		return n
+	}
+
+	if n := cmp.Compare(envVarA.Name, envVarB.Name); n != 0 {
+		return n
+	}
+
+	return cmp.Compare(envVarA.fromCall(), envVarB.fromCall())
+}
+
+// fromCall returns 1 if the variable is read directly with os.Getenv or os.LookupEnv rather than declared in a struct
+// tag, and 0 otherwise.
+func (envVar EnvVar) fromCall() int {
+	if strings.Contains(envVar.Source, ".") {
+		return 0
+	}
+
+	return 1
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+// defaultsFileName is the name of the file containing the default values for a config struct. It is expected to be in
+// the same directory as the struct.
+const defaultsFileName = "default.yaml"
+
+// Defaults contains the parsed default.yaml for a single config package.
+type Defaults struct {
+	root *yaml.Node
+}
+
+// LoadDefaults parses the default.yaml in dir. If there is no such file, the returned Defaults is empty and every
+// lookup returns an empty string.
+func LoadDefaults(dir string) (*Defaults, error) {
+	content, err := os.ReadFile(filepath.Join(dir, defaultsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Defaults{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(100).Infof("Loading defaults from %s", dir)
+
+	document := &yaml.Node{}
+
+	err = yaml.Unmarshal(content, document)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 {
+		return &Defaults{}, nil
+	}
+
+	return &Defaults{root: document.Content[0]}, nil
+}
+
+// Lookup returns the default for the provided yaml key. Keys at the top level of the file are preferred, otherwise a
+// key that appears exactly once at any depth is used, since config structs may be nested. Scalars are returned as is
+// while sequences and mappings are returned as flow-style yaml. It returns an empty string if the key is empty or not
+// found.
+func (defaults *Defaults) Lookup(key string) string {
+	if defaults.root == nil || key == "" {
+		return ""
+	}
+
+	if value := mappingValue(defaults.root, key); value != nil {
+		return formatNode(value)
+	}
+
+	var matches []*yaml.Node
+
+	findKey(defaults.root, key, &matches)
+
+	if len(matches) != 1 {
+		return ""
+	}
+
+	return formatNode(matches[0])
+}
+
+// loadDefaultsCached returns the defaults for dir from cache, loading and adding them if they are not present.
+func loadDefaultsCached(cache map[string]*Defaults, dir string) (*Defaults, error) {
+	if defaults, ok := cache[dir]; ok {
+		return defaults, nil
+	}
+
+	defaults, err := LoadDefaults(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cache[dir] = defaults
+
+	return defaults, nil
+}
+
+// mappingValue returns the value for key in node if node is a mapping, and nil otherwise.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// findKey appends the values for key in all mappings under node, excluding node itself, to matches.
+func findKey(node *yaml.Node, key string, matches *[]*yaml.Node) {
+	for _, child := range node.Content {
+		if value := mappingValue(child, key); value != nil {
+			*matches = append(*matches, value)
+		}
+
+		findKey(child, key, matches)
+	}
+}
+
+// formatNode returns the value of a scalar node or the flow-style yaml of any other node.
+func formatNode(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+
+	flowNode := *node
+	flowNode.Style = yaml.FlowStyle
+
+	content, err := yaml.Marshal(&flowNode)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
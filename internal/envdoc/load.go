@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// goModFileName is the name of the file declaring the module path, relative to the repo root.
+const goModFileName = "go.mod"
+
+// SourcePackage is a single package parsed and type checked from source.
+type SourcePackage struct {
+	// Files are the parsed non-test Go files of the package.
+	Files []*ast.File
+	// Info holds the types and constant values of the expressions in Files. It is partial since type checking errors
+	// are ignored.
+	Info *types.Info
+	// types is the type checked package, or nil if it is still being checked.
+	types *types.Package
+}
+
+// SourceLoader parses and type checks the packages of the module at its root from source so constant expressions,
+// such as the names of environment variables, can be evaluated. Only packages within the module are loaded and all
+// type checking errors, including those from imports outside the module, are ignored. This is enough to resolve
+// constants declared anywhere in the module without needing to load its dependencies.
+type SourceLoader struct {
+	fileSet    *token.FileSet
+	root       string
+	modulePath string
+	packages   map[string]*SourcePackage
+}
+
+// NewSourceLoader returns a SourceLoader for the module at root, using fileSet for positions. If root has no go.mod,
+// imports of other packages are never resolved but constants within a single package still are.
+func NewSourceLoader(fileSet *token.FileSet, root string) (*SourceLoader, error) {
+	modulePath, err := readModulePath(filepath.Join(root, goModFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SourceLoader{
+		fileSet:    fileSet,
+		root:       filepath.Clean(root),
+		modulePath: modulePath,
+		packages:   make(map[string]*SourcePackage),
+	}, nil
+}
+
+// LoadDir returns the package in dir, which must be within the root, parsing and type checking it the first time it is
+// requested. The returned package has no files if dir does not contain any non-test Go files.
+func (loader *SourceLoader) LoadDir(dir string) (*SourcePackage, error) {
+	dir = filepath.Clean(dir)
+
+	if sourcePackage, ok := loader.packages[dir]; ok {
+		return sourcePackage, nil
+	}
+
+	klog.V(100).Infof("Loading package from %s", dir)
+
+	sourcePackage := &SourcePackage{Info: &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(loader.fileSet, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		sourcePackage.Files = append(sourcePackage.Files, file)
+	}
+
+	loader.packages[dir] = sourcePackage
+
+	if len(sourcePackage.Files) == 0 {
+		return sourcePackage, nil
+	}
+
+	config := types.Config{Importer: loader, Error: func(error) {}}
+	sourcePackage.types, _ = config.Check(loader.importPath(dir), loader.fileSet, sourcePackage.Files, sourcePackage.Info)
+
+	return sourcePackage, nil
+}
+
+// Import implements the types.Importer interface for packages within the module.
+func (loader *SourceLoader) Import(path string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	relPath, ok := strings.CutPrefix(path, loader.modulePath+"/")
+	if loader.modulePath == "" || !ok {
+		return nil, fmt.Errorf("package %s is not in the module", path)
+	}
+
+	sourcePackage, err := loader.LoadDir(filepath.Join(loader.root, filepath.FromSlash(relPath)))
+	if err != nil {
+		return nil, err
+	}
+
+	if sourcePackage.types == nil {
+		return nil, fmt.Errorf("package %s has no Go files or is part of an import cycle", path)
+	}
+
+	return sourcePackage.types, nil
+}
+
+// importPath returns the import path of the package in dir. Without a module path, the path relative to the root is
+// used instead.
+func (loader *SourceLoader) importPath(dir string) string {
+	relPath, err := filepath.Rel(loader.root, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+
+	if loader.modulePath == "" {
+		return filepath.ToSlash(relPath)
+	}
+
+	return loader.modulePath + "/" + filepath.ToSlash(relPath)
+}
+
+// readModulePath returns the path from the module directive of the go.mod at path. It returns an empty string if the
+// file does not exist.
+func readModulePath(path string) (string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+
+	return "", scanner.Err()
+}
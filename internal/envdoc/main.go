@@ -0,0 +1,182 @@
+/*
+Envdoc is a tool to generate a reference of every ECO_* environment variable read by the tests and to verify that the
+README of each suite documents them. Variables are found by parsing the envconfig tags of config structs and calls to
+os.Getenv and os.LookupEnv under tests/. The packages of the module are type checked from source so that calls using a
+named constant, even one from another package, are found too. For each variable, the reference includes its Go type,
+the default from the default.yaml next to the struct, the suite that owns it, and the doc comment of the field.
+
+A variable is owned by the suite containing the internal directory it is declared in, or otherwise by the nearest
+directory with a README.md. Each README must mention every variable owned by its suite and must not mention variables
+that are neither owned by its suite nor global. Global variables are those read by scripts/test-runner.sh and those
+declared under tests/internal.
+
+Upon successful generation of the reference the exit code is 0. If any README does not match the code, or a call reads a
+variable whose name is not a constant, the reference is still written, the problems are printed to stderr, and the exit
+code is 1. If any other error occurs it will be logged to stderr and the exit code will be 1.
+
+Usage:
+
+	envdoc [flags]
+
+The flags are:
+
+	-c, -check
+		Only check the READMEs without writing the reference
+
+	-f, -format string
+		Format of the reference. One of markdown or json. Uses markdown if left blank
+
+	-h, -help
+		Print this help message
+
+	-o, -output string
+		File to write the reference to. Uses stdout if left blank
+
+	-r, -root string
+		Path to the root of the repo. Uses the current directory if left blank
+
+	-v int
+		Log level verbosity for klog. Use 100 for logging all messages or leave blank for none
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// testsDir is the directory containing all the test suites, relative to the repo root.
+	testsDir = "tests"
+	// testRunnerScript is the script that runs the tests, relative to the repo root. Variables it reads are global.
+	testRunnerScript = "scripts/test-runner.sh"
+	// readmeFileName is the name of the README of each suite.
+	readmeFileName = "README.md"
+)
+
+var (
+	help   bool
+	check  bool
+	format string
+	output string
+	root   string
+)
+
+//nolint:gochecknoinits // This is a main package so init is fine.
+func init() {
+	const (
+		helpUsage   = "Print this help message"
+		checkUsage  = "Only check the READMEs without writing the reference"
+		formatUsage = "Format of the reference. One of markdown or json. Uses markdown if left blank"
+		outputUsage = "File to write the reference to. Uses stdout if left blank"
+		rootUsage   = "Path to the root of the repo. Uses the current directory if left blank"
+
+		defaultHelp   = false
+		defaultCheck  = false
+		defaultFormat = "markdown"
+		defaultOutput = ""
+		defaultRoot   = "."
+
+		shorthand = " (shorthand)"
+	)
+
+	klog.InitFlags(nil)
+
+	_ = flag.Set("logtostderr", "true")
+
+	flag.BoolVar(&help, "help", defaultHelp, helpUsage)
+	flag.BoolVar(&help, "h", defaultHelp, helpUsage+shorthand)
+
+	flag.BoolVar(&check, "check", defaultCheck, checkUsage)
+	flag.BoolVar(&check, "c", defaultCheck, checkUsage+shorthand)
+
+	flag.StringVar(&format, "format", defaultFormat, formatUsage)
+	flag.StringVar(&format, "f", defaultFormat, formatUsage+shorthand)
+
+	flag.StringVar(&output, "output", defaultOutput, outputUsage)
+	flag.StringVar(&output, "o", defaultOutput, outputUsage+shorthand)
+
+	flag.StringVar(&root, "root", defaultRoot, rootUsage)
+	flag.StringVar(&root, "r", defaultRoot, rootUsage+shorthand)
+}
+
+func main() {
+	flag.Parse()
+
+	if help {
+		flag.Usage()
+
+		return
+	}
+
+	outputFormat, err := ParseOutputFormat(format)
+	if err != nil {
+		klog.Errorf("Invalid format=\"%s\": %v", format, err)
+
+		os.Exit(1)
+	}
+
+	envVars, problems, err := CollectEnvVars(root)
+	if err != nil {
+		klog.Errorf("Failed to collect environment variables from root=\"%s\": %v", root, err)
+
+		os.Exit(1)
+	}
+
+	if !check {
+		err := writeReference(envVars, outputFormat, output)
+		if err != nil {
+			klog.Errorf("Failed to write reference to output=\"%s\": %v", output, err)
+
+			os.Exit(1)
+		}
+	}
+
+	globalVars, err := GlobalVars(root, envVars)
+	if err != nil {
+		klog.Errorf("Failed to get global environment variables: %v", err)
+
+		os.Exit(1)
+	}
+
+	readmeProblems, err := CheckReadmes(root, envVars, globalVars)
+	if err != nil {
+		klog.Errorf("Failed to check READMEs: %v", err)
+
+		os.Exit(1)
+	}
+
+	problems = append(problems, readmeProblems...)
+
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "OK: all %d ECO_* environment variables are documented.\n", len(envVars))
+}
+
+// writeReference writes the reference to the file at output, or stdout if output is empty.
+func writeReference(envVars []EnvVar, format OutputFormat, output string) error {
+	var writer io.Writer = os.Stdout
+
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		writer = file
+	}
+
+	return WriteReference(writer, envVars, format)
+}
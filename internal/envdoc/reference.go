@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OutputFormat is the format used when writing the reference.
+type OutputFormat string
+
+const (
+	// FormatMarkdown is a heading per suite followed by a table of its variables.
+	FormatMarkdown OutputFormat = "markdown"
+	// FormatJSON is an array of all variables.
+	FormatJSON OutputFormat = "json"
+)
+
+// ParseOutputFormat returns the OutputFormat matching format, or an error if it is not one of the supported formats.
+// The empty string is treated as FormatMarkdown.
+func ParseOutputFormat(format string) (OutputFormat, error) {
+	switch OutputFormat(format) {
+	case "", FormatMarkdown:
+		return FormatMarkdown, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, must be one of %s or %s", format, FormatMarkdown, FormatJSON)
+	}
+}
+
+// WriteReference writes the reference for envVars to writer in the provided format. The variables should already be
+// sorted by suite, as returned by CollectEnvVars.
+func WriteReference(writer io.Writer, envVars []EnvVar, format OutputFormat) error {
+	switch format {
+	case FormatMarkdown:
+		return writeMarkdown(writer, envVars)
+	case FormatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(envVars)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeMarkdown writes the reference as a heading for each suite followed by a table of its variables.
+func writeMarkdown(writer io.Writer, envVars []EnvVar) error {
+	builder := &strings.Builder{}
+
+	builder.WriteString("# Environment variable reference\n\n")
+	builder.WriteString("<!-- Generated by go run ./internal/envdoc. DO NOT EDIT. -->\n")
+
+	suite := ""
+
+	for i, envVar := range envVars {
+		if i == 0 || envVar.Suite != suite {
+			suite = envVar.Suite
+
+			fmt.Fprintf(builder, "\n## %s\n\n", suite)
+			builder.WriteString("| Variable | Type | Default | Description | Source |\n")
+			builder.WriteString("| --- | --- | --- | --- | --- |\n")
+		}
+
+		fmt.Fprintf(builder, "| `%s` | `%s` | %s | %s | [%s](%s) |\n",
+			envVar.Name,
+			envVar.Type,
+			formatDefault(envVar.Default),
+			escapeCell(envVar.Description),
+			escapeCell(envVar.Source),
+			locationLink(envVar.Location))
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+
+	return err
+}
+
+// formatDefault returns the default as inline code, or an empty cell if there is no default.
+func formatDefault(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return "`" + strings.ReplaceAll(value, "|", `\|`) + "`"
+}
+
+// escapeCell escapes the characters in text that would break a markdown table cell.
+func escapeCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// locationLink converts a file:line location relative to the repo root into a relative link with a line anchor. The
+// reference is expected to be written at the repo root.
+func locationLink(location string) string {
+	fileName, line, found := strings.Cut(location, ":")
+	if !found {
+		return fileName
+	}
+
+	return fmt.Sprintf("%s#L%s", fileName, line)
+}
@@ -10,19 +10,30 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/onsi/ginkgo/v2/types"
 	"k8s.io/klog/v2"
 )
 
 const (
-	cacheDir = "eco-gotests"
+	cacheDir   = "eco-gotests"
+	historyDir = "history"
+	suitesDir  = "suites"
+	// suiteExpiry is how long a cached suite report is kept after it was last used.
+	suiteExpiry = 14 * 24 * time.Hour
 )
 
 var (
 	errCacheMiss = fmt.Errorf("cache miss")
 )
 
+// LocalKey is the key used for the tree of a local repo with uncommitted changes. Such trees are never cached and do
+// not match any revision on the remote.
+var LocalKey = CacheKey{Branch: "local", Revision: "local"}
+
 // IsMiss returns true if the given error is a cache miss error and false otherwise.
 func IsMiss(err error) bool {
 	return errors.Is(err, errCacheMiss)
@@ -35,22 +46,40 @@ type CacheKey struct {
 	Revision string
 }
 
+// ShortRevision returns the first 7 characters of the revision, or the entire revision if it is shorter. Local trees
+// use "local" as their revision, which is shorter than a normal abbreviated hash.
+func (key CacheKey) ShortRevision() string {
+	if len(key.Revision) < 7 {
+		return key.Revision
+	}
+
+	return key.Revision[:7]
+}
+
 // Cache represents the format of the cache file. It will be saved as JSON according to the XDG base directory
 // specification.
+//
+// In addition to whole trees, the reports of individual suites are cached by the hash of their sources in the suites
+// subdirectory, so that creating a tree only dry runs the suites that changed. It is safe to call GetOrCreate
+// concurrently.
 type Cache struct {
 	Trees     map[CacheKey]*SuiteTree
 	directory string
 	ctx       context.Context
+	pool      *WorkerPool
+	mutex     sync.Mutex
 }
 
 // NewCacheContext creates a new cache instance. It will attempt to load the cache from the cache file. If the file does
-// not exist, a new cache will be created but not saved until Save is called.
-func NewCacheContext(ctx context.Context) (*Cache, error) {
+// not exist, a new cache will be created but not saved until Save is called. Dry runs are limited by pool, which may be
+// shared with other operations.
+func NewCacheContext(ctx context.Context, pool *WorkerPool) (*Cache, error) {
 	klog.V(100).Info("Instantiating new Cache and attempting to load")
 
 	cache := &Cache{
 		Trees: make(map[CacheKey]*SuiteTree),
 		ctx:   ctx,
+		pool:  pool,
 	}
 
 	err := cache.Load()
@@ -119,7 +148,9 @@ func (cache *Cache) Load() error {
 			continue
 		}
 
-		tree, err := loadCacheFile(filepath.Join(cachePath, dirEntry.Name()))
+		tree := &SuiteTree{}
+
+		err := loadCacheFile(filepath.Join(cachePath, dirEntry.Name()), tree)
 		if err != nil {
 			return err
 		}
@@ -155,6 +186,11 @@ func (cache *Cache) Save() error {
 		return err
 	}
 
+	err = cache.deleteExpiredSuites()
+	if err != nil {
+		return err
+	}
+
 	for key, tree := range cache.Trees {
 		err := saveCacheFile(filepath.Join(cachePath, generateCacheFileName(key)), tree)
 		if err != nil {
@@ -215,6 +251,9 @@ func (cache *Cache) Get(repoPath string) (*SuiteTree, error) {
 		return nil, err
 	}
 
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
 	tree, ok := cache.Trees[key]
 	if !ok {
 		return nil, errCacheMiss
@@ -224,8 +263,9 @@ func (cache *Cache) Get(repoPath string) (*SuiteTree, error) {
 }
 
 // GetOrCreate returns the suite tree for the given repo path from the cache. It first calls Get and if there is a cache
-// miss, it calls the given create function and adds the result to the cache. Note that if the repo has local changes,
-// the create function will always be called, but the result will not be added to the cache.
+// miss, it creates the tree from the reports of every suite in the repo and adds the result to the cache. Note that if
+// the repo has local changes, the tree will always be created, but the result will not be added to the cache. Suites
+// whose sources are unchanged are still loaded from the suite cache.
 func (cache *Cache) GetOrCreate(repoPath string) (*SuiteTree, error) {
 	klog.V(100).Infof("Getting or creating cache for repo %s", repoPath)
 
@@ -238,27 +278,29 @@ func (cache *Cache) GetOrCreate(repoPath string) (*SuiteTree, error) {
 		return nil, err
 	}
 
-	klog.V(100).Infof("Cache miss for repo %s, dry running on repo", repoPath)
+	klog.V(100).Infof("Cache miss for repo %s, dry running changed suites", repoPath)
 
-	reportPath, err := DryRun(cache.ctx, repoPath)
+	reports, err := cache.getSuiteReports(repoPath)
 	if err != nil {
-		klog.V(100).Infof("Failed to run eco-gotests dry-run: %v", err)
+		klog.V(100).Infof("Failed to get suite reports: %v", err)
 
 		return nil, err
 	}
 
-	tree, err = NewFromFile(reportPath)
+	tree = NewFromReports(reports)
+
+	err = tree.ApplyOwners(repoPath)
 	if err != nil {
-		klog.V(100).Infof("Failed to create SuiteTree from report.json: %v", err)
+		klog.V(100).Infof("Failed to apply owners to SuiteTree: %v", err)
 
 		return nil, err
 	}
 
-	_ = os.Remove(reportPath)
-
 	key, err := cache.GetKeyFromPath(repoPath)
 	if err == nil {
+		cache.mutex.Lock()
 		cache.Trees[key] = tree
+		cache.mutex.Unlock()
 	} else {
 		klog.V(100).Infof("Failed to get cache key for repo %s, created tree not saved", repoPath)
 	}
@@ -333,6 +375,157 @@ func (cache *Cache) GetKeyFromPath(repoPath string) (CacheKey, error) {
 	return CacheKey{Branch: branch, Revision: revision}, nil
 }
 
+// LoadHistory loads the run history for branch from the history subdirectory of the cache directory. Unlike the
+// cached trees, the history does not depend on the source code sum or revision, so it is kept until the cache is
+// cleaned. If there is no history for branch, an empty History is returned.
+func LoadHistory(branch string) (*History, error) {
+	klog.V(100).Infof("Loading history for branch %s", branch)
+
+	cache := &Cache{ctx: context.TODO()}
+
+	cachePath, err := cache.getDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	history := NewHistory(branch)
+
+	err = loadCacheFile(filepath.Join(cachePath, historyDir, generateHistoryFileName(branch)), history)
+	if errors.Is(err, os.ErrNotExist) {
+		klog.V(100).Infof("No history found for branch %s", branch)
+
+		return history, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// SaveHistory saves the history to the history subdirectory of the cache directory, replacing any existing history for
+// the same branch.
+func SaveHistory(history *History) error {
+	klog.V(100).Infof("Saving history with %d runs for branch %s", len(history.Runs), history.Branch)
+
+	cache := &Cache{ctx: context.TODO()}
+
+	cachePath, err := cache.getDirectory()
+	if err != nil {
+		return err
+	}
+
+	historyPath := filepath.Join(cachePath, historyDir)
+
+	err = os.MkdirAll(historyPath, 0755)
+	if err != nil {
+		return err
+	}
+
+	return saveCacheFile(filepath.Join(historyPath, generateHistoryFileName(history.Branch)), history)
+}
+
+// getSuiteReports returns the reports for every suite in the repo at repoPath. Suites found in the suite cache are
+// loaded, while the rest are dry run concurrently, limited by the pool, and then saved to the suite cache.
+func (cache *Cache) getSuiteReports(repoPath string) ([]types.Report, error) {
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	suites, err := ListSuiteSources(cache.ctx, absRepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		reportsMutex sync.Mutex
+		reports      []types.Report
+		tasks        []func(ctx context.Context) error
+	)
+
+	for _, suite := range suites {
+		tasks = append(tasks, func(ctx context.Context) error {
+			suiteReports, err := cache.getOrDryRunSuite(ctx, absRepoPath, suite)
+			if err != nil {
+				return fmt.Errorf("failed to get report for suite %s: %w", suite.Path, err)
+			}
+
+			reportsMutex.Lock()
+			reports = append(reports, suiteReports...)
+			reportsMutex.Unlock()
+
+			return nil
+		})
+	}
+
+	err = RunAll(cache.ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// getOrDryRunSuite returns the reports for a single suite from the suite cache, or dry runs it on a miss. Cached
+// reports store the suite path relative to the repo so they can be reused across clones.
+func (cache *Cache) getOrDryRunSuite(
+	ctx context.Context, absRepoPath string, suite SuiteSource) ([]types.Report, error) {
+	cachePath, err := cache.getDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	suiteFile := filepath.Join(cachePath, suitesDir, suite.Hash+".json.zstd")
+
+	var reports []types.Report
+
+	err = loadCacheFile(suiteFile, &reports)
+	if err == nil {
+		klog.V(100).Infof("Suite %s loaded from suite cache", suite.Path)
+
+		now := time.Now()
+		_ = os.Chtimes(suiteFile, now, now)
+
+		return relocateReports(reports, absRepoPath, true)
+	}
+
+	klog.V(100).Infof("Suite %s not in suite cache, dry running: %v", suite.Path, err)
+
+	err = cache.pool.Do(ctx, func(ctx context.Context) error {
+		reportPath, err := DryRunSuite(ctx, absRepoPath, suite.Path)
+		if err != nil {
+			return err
+		}
+
+		defer os.Remove(reportPath)
+
+		reports, err = LoadReports(reportPath)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relativeReports, err := relocateReports(slices.Clone(reports), absRepoPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(suiteFile), 0755)
+	if err == nil {
+		err = saveCacheFile(suiteFile, relativeReports)
+	}
+
+	if err != nil {
+		klog.V(100).Infof("Failed to save suite %s to suite cache: %v", suite.Path, err)
+	}
+
+	return reports, nil
+}
+
 // getDirectory returns the stored directory for this cache if it exists, otherwise it uses a subdirectory of the
 // OS-specific user cache directory. If the user cache directory does not exist, it returns an error.
 func (cache *Cache) getDirectory() (string, error) {
@@ -387,9 +580,72 @@ func (cache *Cache) deleteExpiredFiles() error {
 	return nil
 }
 
-// saveCacheFile saves the tree at the path provided by cacheFileName, truncating if the file already exists.
-func saveCacheFile(cacheFileName string, tree *SuiteTree) error {
-	klog.V(100).Infof("Saving cached tree to %s", cacheFileName)
+// deleteExpiredSuites deletes all the regular files in the suites subdirectory of the cache directory that have not
+// been used within suiteExpiry. It returns early on any error deleting files.
+func (cache *Cache) deleteExpiredSuites() error {
+	cachePath, err := cache.getDirectory()
+	if err != nil {
+		return err
+	}
+
+	suitesPath := filepath.Join(cachePath, suitesDir)
+
+	klog.V(100).Infof("Deleting expired suite cache files from %s", suitesPath)
+
+	suiteDirEntries, err := os.ReadDir(suitesPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, dirEntry := range suiteDirEntries {
+		if !dirEntry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil || time.Since(info.ModTime()) < suiteExpiry {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(suitesPath, dirEntry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relocateReports converts the suite paths of reports between absolute paths in the repo at absRepoPath and paths
+// relative to it. If toAbsolute is true, relative paths are joined to absRepoPath, otherwise absolute paths are made
+// relative. Reports are modified in place and returned.
+func relocateReports(reports []types.Report, absRepoPath string, toAbsolute bool) ([]types.Report, error) {
+	for i := range reports {
+		if toAbsolute {
+			reports[i].SuitePath = filepath.Join(absRepoPath, reports[i].SuitePath)
+
+			continue
+		}
+
+		suitePath, err := filepath.Rel(absRepoPath, reports[i].SuitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		reports[i].SuitePath = suitePath
+	}
+
+	return reports, nil
+}
+
+// saveCacheFile saves the value at the path provided by cacheFileName as zstd-compressed JSON, truncating if the file
+// already exists.
+func saveCacheFile(cacheFileName string, value any) error {
+	klog.V(100).Infof("Saving cached value to %s", cacheFileName)
 
 	file, err := os.Create(cacheFileName)
 	if err != nil {
@@ -405,7 +661,7 @@ func saveCacheFile(cacheFileName string, tree *SuiteTree) error {
 
 	defer compressor.Close()
 
-	err = json.NewEncoder(compressor).Encode(tree)
+	err = json.NewEncoder(compressor).Encode(value)
 	if err != nil {
 		return err
 	}
@@ -413,30 +669,25 @@ func saveCacheFile(cacheFileName string, tree *SuiteTree) error {
 	return nil
 }
 
-// loadCacheFile attempts to load a SuiteTree from cacheFileName.
-func loadCacheFile(cacheFileName string) (*SuiteTree, error) {
-	klog.V(100).Infof("Loading cached tree from %s", cacheFileName)
+// loadCacheFile attempts to load the zstd-compressed JSON from cacheFileName into value.
+func loadCacheFile(cacheFileName string, value any) error {
+	klog.V(100).Infof("Loading cached value from %s", cacheFileName)
 
 	file, err := os.Open(cacheFileName)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	defer file.Close()
 
 	decompressor, err := zstd.NewReader(file)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	tree := &SuiteTree{}
+	defer decompressor.Close()
 
-	err = json.NewDecoder(decompressor).Decode(tree)
-	if err != nil {
-		return nil, err
-	}
-
-	return tree, nil
+	return json.NewDecoder(decompressor).Decode(value)
 }
 
 // generateCacheFileName takes the parameters and generates the corresponding cache file name. It guarantees that the
@@ -445,6 +696,12 @@ func generateCacheFileName(key CacheKey) string {
 	return fmt.Sprintf("%s %s %s.json.zstd", key.Branch, key.Revision, sourceCodeSum)
 }
 
+// generateHistoryFileName returns the name of the history file for branch. Since branches may contain slashes, they are
+// replaced to keep the history directory flat.
+func generateHistoryFileName(branch string) string {
+	return strings.ReplaceAll(branch, "/", "_") + ".json.zstd"
+}
+
 // parseCacheFileName takes the cacheFileName and extracts the branch, revision, and sum. It is guaranteed to be the
 // inverse of generateCacheFileName. For an invalid cacheFileName, all returns will be empty.
 func parseCacheFileName(cacheFileName string) (key CacheKey, sum string) {
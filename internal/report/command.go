@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"iter"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"k8s.io/klog/v2"
@@ -36,22 +40,75 @@ func CloneRepo(ctx context.Context, localPath, repo, branch string) (string, err
 	return clonedPath, nil
 }
 
-// DryRun runs the eco-gotests tests in dry-run mode and returns the path to the JSON report file.
-func DryRun(ctx context.Context, clonedPath string) (string, error) {
-	klog.V(100).Infof("Running eco-gotests dry-run in %s", clonedPath)
+// GoPackage is the subset of the fields printed by go list that are needed to determine which suites changed.
+type GoPackage struct {
+	ImportPath string
+	Dir        string
+	Deps       []string
+}
+
+// ListTestPackages lists all the packages under ./tests in the repo at repoPath along with their transitive
+// dependencies. Since test packages are included, there will be a test main package, with an import path ending in
+// .test, for every package with tests.
+func ListTestPackages(ctx context.Context, repoPath string) ([]GoPackage, error) {
+	klog.V(100).Infof("Listing test packages in %s", repoPath)
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-test", "-deps", "-e", "-json=ImportPath,Dir,Deps", "./tests/...")
+	cmd.Dir = repoPath
+
+	stdout, err := execCommandWithStdout(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []GoPackage
+
+	decoder := json.NewDecoder(strings.NewReader(stdout))
+
+	for {
+		var goPackage GoPackage
+
+		err := decoder.Decode(&goPackage)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		packages = append(packages, goPackage)
+	}
+
+	return packages, nil
+}
+
+// DryRunSuite runs the single suite at suitePath, relative to repoPath, in dry-run mode and returns the path to the
+// JSON report file. The report is written to a temporary file that the caller should remove.
+func DryRunSuite(ctx context.Context, repoPath, suitePath string) (string, error) {
+	klog.V(100).Infof("Running eco-gotests dry-run for suite %s in %s", suitePath, repoPath)
 
-	cmd := exec.CommandContext(ctx, "ginkgo", "--json-report=report.json", "-dry-run", "-v", "-r", "./tests")
-	cmd.Dir = clonedPath
+	reportFile, err := os.CreateTemp("", "report-*.json")
+	if err != nil {
+		return "", err
+	}
+
+	reportPath := reportFile.Name()
+	_ = reportFile.Close()
+
+	cmd := exec.CommandContext(
+		ctx, "ginkgo", "--json-report="+reportPath, "-dry-run", "-v", "./"+filepath.ToSlash(suitePath))
+	cmd.Dir = repoPath
 	cmd.Env = append(cmd.Env, os.Environ()...)
 	cmd.Env = append(cmd.Env, "ECO_DRY_RUN=true")
 
-	err := execCommand(cmd)
+	err = execCommand(cmd)
 	if err != nil {
+		_ = os.Remove(reportPath)
+
 		return "", err
 	}
 
-	reportPath := path.Join(clonedPath, "report.json")
-
 	return reportPath, nil
 }
 
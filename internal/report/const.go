@@ -3,4 +3,8 @@ package main
 const (
 	// RemoteURL is the URL of the remote repository. It should always point to the upstream eco-gotests repository.
 	RemoteURL = "https://github.com/rh-ecosystem-edge/eco-gotests.git"
+
+	// ReportXMLIDPrefix is the prefix of labels added by reportxml.ID that contain the ID itself. It matches the default
+	// IDTag in eco-goinfra reportxml.
+	ReportXMLIDPrefix = "test_id:"
 )
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// TreeDiff contains the differences in specs between a base tree and a head tree. Specs are identified by the path of
+// their suite relative to the root of the tree and their full text.
+type TreeDiff struct {
+	Base CacheKey
+	Head CacheKey
+	// Added contains specs only present in the head tree.
+	Added []SpecInfo
+	// Removed contains specs only present in the base tree.
+	Removed []SpecInfo
+	// Moved contains specs with the same text that are in a different suite in the head tree.
+	Moved []SpecChange
+	// Renamed contains specs with different text but the same reportxml IDs in the head tree.
+	Renamed []SpecChange
+	// Relabeled contains specs present in both trees whose labels changed.
+	Relabeled []SpecChange
+	// ReIdentified contains specs present in both trees whose reportxml IDs changed.
+	ReIdentified []SpecChange
+}
+
+// SpecChange pairs the base and head versions of a spec that changed.
+type SpecChange struct {
+	Base SpecInfo
+	Head SpecInfo
+}
+
+// diffKey identifies a spec for the purpose of diffing.
+type diffKey struct {
+	Suite string
+	Text  string
+}
+
+// NewTreeDiff compares the base and head trees and returns the differences. Both trees should have been trimmed
+// already, so that the suite paths are relative to the same directory.
+func NewTreeDiff(baseKey CacheKey, base *SuiteTree, headKey CacheKey, head *SuiteTree) *TreeDiff {
+	klog.V(100).Infof("Diffing tree for branch %s against branch %s", headKey.Branch, baseKey.Branch)
+
+	diff := &TreeDiff{Base: baseKey, Head: headKey}
+	baseSpecs := collectDiffSpecs(base)
+	headSpecs := collectDiffSpecs(head)
+
+	var removed, added []SpecInfo
+
+	for key, baseSpec := range baseSpecs {
+		headSpec, ok := headSpecs[key]
+		if !ok {
+			removed = append(removed, baseSpec)
+
+			continue
+		}
+
+		if !slices.Equal(baseSpec.Labels, headSpec.Labels) {
+			diff.Relabeled = append(diff.Relabeled, SpecChange{Base: baseSpec, Head: headSpec})
+		}
+
+		if !slices.Equal(baseSpec.IDs, headSpec.IDs) {
+			diff.ReIdentified = append(diff.ReIdentified, SpecChange{Base: baseSpec, Head: headSpec})
+		}
+	}
+
+	for key, headSpec := range headSpecs {
+		if _, ok := baseSpecs[key]; !ok {
+			added = append(added, headSpec)
+		}
+	}
+
+	diff.Moved, removed, added = pairSpecs(removed, added, func(spec SpecInfo) string {
+		return spec.Text
+	})
+	diff.Renamed, removed, added = pairSpecs(removed, added, func(spec SpecInfo) string {
+		return strings.Join(spec.IDs, ",")
+	})
+	diff.Removed = removed
+	diff.Added = added
+
+	diff.sort()
+
+	return diff
+}
+
+// IsEmpty returns true if there are no differences between the trees.
+func (diff *TreeDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Moved) == 0 && len(diff.Renamed) == 0 &&
+		len(diff.Relabeled) == 0 && len(diff.ReIdentified) == 0
+}
+
+// String returns a human-readable representation of the diff. Added specs are prefixed with a +, removed specs with a
+// -, and changed specs with a ~.
+func (diff *TreeDiff) String() string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "Diff %s (%s) -> %s (%s)\n",
+		diff.Base.Branch, diff.Base.ShortRevision(), diff.Head.Branch, diff.Head.ShortRevision())
+
+	if diff.IsEmpty() {
+		builder.WriteString("No differences\n")
+
+		return builder.String()
+	}
+
+	writeSection := func(title string, count int, writeEntries func()) {
+		if count == 0 {
+			return
+		}
+
+		fmt.Fprintf(builder, "%s (%d):\n", title, count)
+		writeEntries()
+	}
+
+	writeSection("Added", len(diff.Added), func() {
+		for _, spec := range diff.Added {
+			fmt.Fprintf(builder, "+ %s: %s\n", spec.Suite, spec.Text)
+		}
+	})
+	writeSection("Removed", len(diff.Removed), func() {
+		for _, spec := range diff.Removed {
+			fmt.Fprintf(builder, "- %s: %s\n", spec.Suite, spec.Text)
+		}
+	})
+	writeSection("Moved", len(diff.Moved), func() {
+		for _, change := range diff.Moved {
+			fmt.Fprintf(builder, "~ %s: %s -> %s\n", change.Head.Text, change.Base.Suite, change.Head.Suite)
+		}
+	})
+	writeSection("Renamed", len(diff.Renamed), func() {
+		for _, change := range diff.Renamed {
+			fmt.Fprintf(builder, "~ %s: %s -> %s\n", change.Head.Suite, change.Base.Text, change.Head.Text)
+		}
+	})
+	writeSection("Relabeled", len(diff.Relabeled), func() {
+		for _, change := range diff.Relabeled {
+			fmt.Fprintf(builder, "~ %s: %s: %v -> %v\n",
+				change.Head.Suite, change.Head.Text, change.Base.Labels, change.Head.Labels)
+		}
+	})
+	writeSection("Changed IDs", len(diff.ReIdentified), func() {
+		for _, change := range diff.ReIdentified {
+			fmt.Fprintf(builder, "~ %s: %s: %v -> %v\n",
+				change.Head.Suite, change.Head.Text, change.Base.IDs, change.Head.IDs)
+		}
+	})
+
+	return builder.String()
+}
+
+// sort sorts all the lists in the diff by suite and then text so the output is stable.
+func (diff *TreeDiff) sort() {
+	compareSpecs := func(specA, specB SpecInfo) int {
+		if n := strings.Compare(specA.Suite, specB.Suite); n != 0 {
+			return n
+		}
+
+		return strings.Compare(specA.Text, specB.Text)
+	}
+	compareChanges := func(changeA, changeB SpecChange) int {
+		return compareSpecs(changeA.Head, changeB.Head)
+	}
+
+	slices.SortFunc(diff.Added, compareSpecs)
+	slices.SortFunc(diff.Removed, compareSpecs)
+	slices.SortFunc(diff.Moved, compareChanges)
+	slices.SortFunc(diff.Renamed, compareChanges)
+	slices.SortFunc(diff.Relabeled, compareChanges)
+	slices.SortFunc(diff.ReIdentified, compareChanges)
+}
+
+// collectDiffSpecs returns a map of all the specs in the tree, keyed by their suite path relative to the root of the
+// tree and their full text.
+func collectDiffSpecs(tree *SuiteTree) map[diffKey]SpecInfo {
+	specs := make(map[diffKey]SpecInfo)
+
+	for suite, leaf := range tree.Leaves() {
+		spec := NewSpecInfo(tree, suite, leaf)
+
+		// reportxml.ID adds both the plain ID and the prefixed ID as labels. Since ID changes are tracked separately,
+		// these labels are excluded to avoid reporting the same change twice.
+		spec.Labels = slices.DeleteFunc(spec.Labels, func(label string) bool {
+			return strings.HasPrefix(label, ReportXMLIDPrefix) || slices.Contains(spec.IDs, label)
+		})
+
+		specs[diffKey{Suite: spec.Suite, Text: spec.Text}] = spec
+	}
+
+	return specs
+}
+
+// pairSpecs matches removed and added specs that share the same nonempty key, as returned by keyFunc. Only keys that
+// are unique among both the removed and added specs are paired. It returns the pairs along with the removed and added
+// specs that remain unpaired.
+func pairSpecs(
+	removed, added []SpecInfo, keyFunc func(SpecInfo) string,
+) (pairs []SpecChange, unpairedRemoved, unpairedAdded []SpecInfo) {
+	countKeys := func(specs []SpecInfo) map[string]int {
+		counts := make(map[string]int)
+
+		for _, spec := range specs {
+			counts[keyFunc(spec)]++
+		}
+
+		return counts
+	}
+
+	removedCounts := countKeys(removed)
+	addedCounts := countKeys(added)
+	addedByKey := make(map[string]SpecInfo)
+
+	for _, spec := range added {
+		key := keyFunc(spec)
+		if key != "" && removedCounts[key] == 1 && addedCounts[key] == 1 {
+			addedByKey[key] = spec
+
+			continue
+		}
+
+		unpairedAdded = append(unpairedAdded, spec)
+	}
+
+	for _, spec := range removed {
+		headSpec, ok := addedByKey[keyFunc(spec)]
+		if !ok {
+			unpairedRemoved = append(unpairedRemoved, spec)
+
+			continue
+		}
+
+		pairs = append(pairs, SpecChange{Base: spec, Head: headSpec})
+	}
+
+	return pairs, unpairedRemoved, unpairedAdded
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTreeDiff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     []types.Report
+		head     []types.Report
+		expected *TreeDiff
+	}{
+		{
+			name:     "unchanged",
+			base:     []types.Report{newTestReport("a", newTestSpec("spec", "label"))},
+			head:     []types.Report{newTestReport("a", newTestSpec("spec", "label"))},
+			expected: &TreeDiff{},
+		},
+		{
+			name: "added-and-removed",
+			base: []types.Report{newTestReport("a", newTestSpec("kept"), newTestSpec("removed"))},
+			head: []types.Report{newTestReport("a", newTestSpec("kept"), newTestSpec("added"))},
+			expected: &TreeDiff{
+				Added:   []SpecInfo{newTestSpecInfo("a", "added")},
+				Removed: []SpecInfo{newTestSpecInfo("a", "removed")},
+			},
+		},
+		{
+			name: "moved",
+			base: []types.Report{newTestReport("a", newTestSpec("spec")), newTestReport("b")},
+			head: []types.Report{newTestReport("a"), newTestReport("b", newTestSpec("spec"))},
+			expected: &TreeDiff{
+				Moved: []SpecChange{{Base: newTestSpecInfo("a", "spec"), Head: newTestSpecInfo("b", "spec")}},
+			},
+		},
+		{
+			name: "moved-ambiguous",
+			base: []types.Report{
+				newTestReport("a", newTestSpec("spec")), newTestReport("b", newTestSpec("spec")), newTestReport("c"),
+			},
+			head: []types.Report{newTestReport("a"), newTestReport("b"), newTestReport("c", newTestSpec("spec"))},
+			expected: &TreeDiff{
+				Added:   []SpecInfo{newTestSpecInfo("c", "spec")},
+				Removed: []SpecInfo{newTestSpecInfo("a", "spec"), newTestSpecInfo("b", "spec")},
+			},
+		},
+		{
+			name: "renamed",
+			base: []types.Report{newTestReport("a", newTestSpec("old", "12345", "test_id:12345"))},
+			head: []types.Report{newTestReport("a", newTestSpec("new", "12345", "test_id:12345"))},
+			expected: &TreeDiff{
+				Renamed: []SpecChange{{
+					Base: newTestSpecInfo("a", "old", "12345", "test_id:12345"),
+					Head: newTestSpecInfo("a", "new", "12345", "test_id:12345"),
+				}},
+			},
+		},
+		{
+			name: "relabeled",
+			base: []types.Report{newTestReport("a", newTestSpec("spec", "old"))},
+			head: []types.Report{newTestReport("a", newTestSpec("spec", "new"))},
+			expected: &TreeDiff{
+				Relabeled: []SpecChange{{
+					Base: newTestSpecInfo("a", "spec", "old"),
+					Head: newTestSpecInfo("a", "spec", "new"),
+				}},
+			},
+		},
+		{
+			name: "reidentified",
+			base: []types.Report{newTestReport("a", newTestSpec("spec", "12345", "test_id:12345"))},
+			head: []types.Report{newTestReport("a", newTestSpec("spec", "54321", "test_id:54321"))},
+			expected: &TreeDiff{
+				ReIdentified: []SpecChange{{
+					Base: newTestSpecInfo("a", "spec", "12345", "test_id:12345"),
+					Head: newTestSpecInfo("a", "spec", "54321", "test_id:54321"),
+				}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			baseKey := CacheKey{Branch: "main", Revision: "0123456789"}
+			headKey := CacheKey{Branch: "feature", Revision: "9876543210"}
+			base := NewFromReports(testCase.base)
+			head := NewFromReports(testCase.head)
+
+			testCase.expected.Base = baseKey
+			testCase.expected.Head = headKey
+
+			diff := NewTreeDiff(baseKey, base, headKey, head)
+			assert.Equal(t, testCase.expected, diff)
+			assert.Equal(t, testCase.name == "unchanged", diff.IsEmpty())
+		})
+	}
+}
+
+func TestPairSpecs(t *testing.T) {
+	byText := func(spec SpecInfo) string {
+		return spec.Text
+	}
+
+	testCases := []struct {
+		name            string
+		removed         []SpecInfo
+		added           []SpecInfo
+		expectedPairs   []SpecChange
+		expectedRemoved []SpecInfo
+		expectedAdded   []SpecInfo
+	}{
+		{
+			name:          "unique-key",
+			removed:       []SpecInfo{{Suite: "a", Text: "spec"}},
+			added:         []SpecInfo{{Suite: "b", Text: "spec"}},
+			expectedPairs: []SpecChange{{Base: SpecInfo{Suite: "a", Text: "spec"}, Head: SpecInfo{Suite: "b", Text: "spec"}}},
+		},
+		{
+			name:            "duplicate-added-key",
+			removed:         []SpecInfo{{Suite: "a", Text: "spec"}},
+			added:           []SpecInfo{{Suite: "b", Text: "spec"}, {Suite: "c", Text: "spec"}},
+			expectedRemoved: []SpecInfo{{Suite: "a", Text: "spec"}},
+			expectedAdded:   []SpecInfo{{Suite: "b", Text: "spec"}, {Suite: "c", Text: "spec"}},
+		},
+		{
+			name:            "duplicate-removed-key",
+			removed:         []SpecInfo{{Suite: "a", Text: "spec"}, {Suite: "b", Text: "spec"}},
+			added:           []SpecInfo{{Suite: "c", Text: "spec"}},
+			expectedRemoved: []SpecInfo{{Suite: "a", Text: "spec"}, {Suite: "b", Text: "spec"}},
+			expectedAdded:   []SpecInfo{{Suite: "c", Text: "spec"}},
+		},
+		{
+			name:            "empty-key",
+			removed:         []SpecInfo{{Suite: "a"}},
+			added:           []SpecInfo{{Suite: "b"}},
+			expectedRemoved: []SpecInfo{{Suite: "a"}},
+			expectedAdded:   []SpecInfo{{Suite: "b"}},
+		},
+		{
+			name:            "different-keys",
+			removed:         []SpecInfo{{Suite: "a", Text: "old"}},
+			added:           []SpecInfo{{Suite: "a", Text: "new"}},
+			expectedRemoved: []SpecInfo{{Suite: "a", Text: "old"}},
+			expectedAdded:   []SpecInfo{{Suite: "a", Text: "new"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pairs, removed, added := pairSpecs(testCase.removed, testCase.added, byText)
+			assert.Equal(t, testCase.expectedPairs, pairs)
+			assert.Equal(t, testCase.expectedRemoved, removed)
+			assert.Equal(t, testCase.expectedAdded, added)
+		})
+	}
+}
+
+// newTestReport returns a report for the suite at /repo/tests/<suite> containing the provided specs.
+func newTestReport(suite string, specs ...types.SpecReport) types.Report {
+	return types.Report{
+		SuitePath:        "/repo/tests/" + suite,
+		SuiteDescription: suite,
+		PreRunStats:      types.PreRunStats{TotalSpecs: len(specs)},
+		SpecReports:      specs,
+	}
+}
+
+// newTestSpec returns an It spec report with the provided text and labels.
+func newTestSpec(text string, labels ...string) types.SpecReport {
+	return types.SpecReport{
+		LeafNodeType:     types.NodeTypeIt,
+		LeafNodeText:     text,
+		LeafNodeLabels:   labels,
+		LeafNodeLocation: types.CodeLocation{FileName: "/repo/tests/spec_test.go", LineNumber: 1},
+	}
+}
+
+// newTestSpecInfo returns the SpecInfo that collectDiffSpecs produces for a spec created by newTestSpec in the provided
+// suite. Labels must be passed in sorted order and labels added by reportxml.ID are excluded, as they are in the diff.
+func newTestSpecInfo(suite, text string, labels ...string) SpecInfo {
+	var ids []string
+
+	specLabels := []string{}
+
+	for _, label := range labels {
+		if id, found := strings.CutPrefix(label, ReportXMLIDPrefix); found {
+			ids = append(ids, id)
+
+			continue
+		}
+
+		specLabels = append(specLabels, label)
+	}
+
+	// IDs are also added as plain labels, so they are removed as well.
+	specLabels = slices.DeleteFunc(specLabels, func(label string) bool { return slices.Contains(ids, label) })
+
+	return SpecInfo{
+		Suite:    "repo/tests/" + suite,
+		Text:     text,
+		Location: CleanPath("/repo/tests/spec_test.go") + ":1",
+		Labels:   specLabels,
+		IDs:      ids,
+	}
+}
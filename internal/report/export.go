@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// OutputFormat is the format used when printing trees.
+type OutputFormat string
+
+const (
+	// FormatText is the indented text format produced by [SuiteTree.String].
+	FormatText OutputFormat = "text"
+	// FormatJSON is the full tree for every branch, including spec metadata, as a JSON array.
+	FormatJSON OutputFormat = "json"
+	// FormatCSV is one row per spec for every branch, including a header row.
+	FormatCSV OutputFormat = "csv"
+	// FormatMarkdown is a nested list for every branch, suitable for pull request comments.
+	FormatMarkdown OutputFormat = "markdown"
+)
+
+// csvHeader is the header row for the CSV format. Each spec row must have the same number of fields.
+var csvHeader = []string{"branch", "revision", "suite", "spec", "labels", "ids", "file", "line", "state"}
+
+// ExportedBranch is the JSON representation of the tree for a single branch.
+type ExportedBranch struct {
+	Branch   string        `json:"branch"`
+	Revision string        `json:"revision"`
+	Tree     *ExportedNode `json:"tree"`
+}
+
+// ExportedNode is the JSON representation of a single node in a SuiteTree. Unlike SuiteTree, it does not include the
+// entire spec report, only the metadata relevant for planning and dashboards.
+type ExportedNode struct {
+	Name        string          `json:"name"`
+	Path        string          `json:"path"`
+	Description string          `json:"description,omitempty"`
+	Specs       int             `json:"specs"`
+	Results     *ResultSummary  `json:"results,omitempty"`
+	Children    []*ExportedNode `json:"children,omitempty"`
+	Spec        *ExportedSpec   `json:"spec,omitempty"`
+}
+
+// ExportedSpec is the JSON representation of the spec metadata for a leaf node.
+type ExportedSpec struct {
+	Text                 string      `json:"text"`
+	Labels               []string    `json:"labels"`
+	IDs                  []string    `json:"ids"`
+	Location             string      `json:"location"`
+	IsSerial             bool        `json:"isSerial"`
+	IsInOrderedContainer bool        `json:"isInOrderedContainer"`
+	Result               *SpecResult `json:"result,omitempty"`
+}
+
+// ParseOutputFormat returns the OutputFormat matching format, or an error if it is not one of the supported formats.
+// The empty string is treated as FormatText.
+func ParseOutputFormat(format string) (OutputFormat, error) {
+	switch OutputFormat(format) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON, FormatCSV, FormatMarkdown:
+		return OutputFormat(format), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, must be one of %s, %s, %s, or %s",
+			format, FormatText, FormatJSON, FormatCSV, FormatMarkdown)
+	}
+}
+
+// WriteTreeMap writes all the trees in treeMap to writer in the provided format. Branches are written in
+// lexicographical order so the output is stable.
+func WriteTreeMap(writer io.Writer, treeMap map[CacheKey]*SuiteTree, format OutputFormat) error {
+	klog.V(100).Infof("Writing %d trees in format %s", len(treeMap), format)
+
+	keys := slices.SortedFunc(maps.Keys(treeMap), func(keyA, keyB CacheKey) int {
+		return strings.Compare(keyA.Branch, keyB.Branch)
+	})
+
+	switch format {
+	case FormatText:
+		return writeText(writer, treeMap, keys)
+	case FormatJSON:
+		return writeJSON(writer, treeMap, keys)
+	case FormatCSV:
+		return writeCSV(writer, treeMap, keys)
+	case FormatMarkdown:
+		return writeMarkdown(writer, treeMap, keys)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// NewExportedNode converts the tree into its JSON representation. Paths are relative to the root of the tree.
+func NewExportedNode(tree *SuiteTree) *ExportedNode {
+	return newExportedNode(tree, nil, tree)
+}
+
+// newExportedNode is the recursive helper for NewExportedNode. The parent is only used for leaf nodes to determine the
+// suite of the spec.
+func newExportedNode(root, parent, tree *SuiteTree) *ExportedNode {
+	node := &ExportedNode{
+		Name:        tree.Name,
+		Path:        strings.TrimPrefix(strings.TrimPrefix(tree.Path, root.Path), "/"),
+		Description: tree.Description,
+		Specs:       tree.Specs,
+		Results:     tree.Results,
+	}
+
+	if tree.SpecReport != nil && parent != nil {
+		info := NewSpecInfo(root, parent, tree)
+		node.Spec = &ExportedSpec{
+			Text:                 info.Text,
+			Labels:               info.Labels,
+			IDs:                  append([]string{}, info.IDs...),
+			Location:             info.Location,
+			IsSerial:             tree.SpecReport.IsSerial,
+			IsInOrderedContainer: tree.SpecReport.IsInOrderedContainer,
+			Result:               tree.Result,
+		}
+	}
+
+	for _, child := range tree.Children {
+		node.Children = append(node.Children, newExportedNode(root, tree, child))
+	}
+
+	return node
+}
+
+// writeText writes the trees in the same format that has always been printed to stdout.
+func writeText(writer io.Writer, treeMap map[CacheKey]*SuiteTree, keys []CacheKey) error {
+	for _, key := range keys {
+		_, err := fmt.Fprintf(writer, "---\nBranch %s (%s)\n%s", key.Branch, key.ShortRevision(), treeMap[key])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeJSON writes the trees as an array of ExportedBranch.
+func writeJSON(writer io.Writer, treeMap map[CacheKey]*SuiteTree, keys []CacheKey) error {
+	branches := make([]ExportedBranch, 0, len(keys))
+
+	for _, key := range keys {
+		branches = append(branches, ExportedBranch{
+			Branch:   key.Branch,
+			Revision: key.Revision,
+			Tree:     NewExportedNode(treeMap[key]),
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(branches)
+}
+
+// writeCSV writes one row per spec for all the trees, preceded by csvHeader. Labels and IDs are joined with semicolons.
+func writeCSV(writer io.Writer, treeMap map[CacheKey]*SuiteTree, keys []CacheKey) error {
+	csvWriter := csv.NewWriter(writer)
+
+	err := csvWriter.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		tree := treeMap[key]
+
+		for suite, leaf := range tree.Leaves() {
+			info := NewSpecInfo(tree, suite, leaf)
+
+			state := ""
+			if leaf.Result != nil {
+				state = string(leaf.Result.State)
+			}
+
+			err := csvWriter.Write([]string{
+				key.Branch,
+				key.Revision,
+				info.Suite,
+				info.Text,
+				strings.Join(info.Labels, ";"),
+				strings.Join(info.IDs, ";"),
+				CleanPath(leaf.SpecReport.LeafNodeLocation.FileName),
+				strconv.Itoa(leaf.SpecReport.LeafNodeLocation.LineNumber),
+				state,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// writeMarkdown writes a heading for each branch followed by the tree as a nested list. Spec labels are included as
+// inline code.
+func writeMarkdown(writer io.Writer, treeMap map[CacheKey]*SuiteTree, keys []CacheKey) error {
+	builder := &strings.Builder{}
+
+	for _, key := range keys {
+		fmt.Fprintf(builder, "## Branch `%s` (%s)\n\n", key.Branch, key.ShortRevision())
+		writeMarkdownNode(builder, treeMap[key], 0)
+		builder.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+
+	return err
+}
+
+// writeMarkdownNode is a helper function to recursively write a node as a nested markdown list item. The level
+// parameter controls the indentation and starts at 0.
+func writeMarkdownNode(builder *strings.Builder, tree *SuiteTree, level int) {
+	builder.WriteString(strings.Repeat("  ", level))
+	builder.WriteString("- ")
+
+	if tree.SpecReport != nil {
+		builder.WriteString(escapeMarkdown(tree.Name))
+
+		for _, label := range tree.SpecReport.Labels() {
+			fmt.Fprintf(builder, " `%s`", label)
+		}
+
+		if tree.Result != nil {
+			fmt.Fprintf(builder, " (%s)", tree.Result.State)
+		}
+	} else {
+		fmt.Fprintf(builder, "**%s** %d", escapeMarkdown(tree.Name), tree.Specs)
+
+		if tree.Results != nil {
+			fmt.Fprintf(builder, " (%s)", tree.Results)
+		}
+	}
+
+	builder.WriteByte('\n')
+
+	for _, child := range tree.Children {
+		writeMarkdownNode(builder, child, level+1)
+	}
+}
+
+// escapeMarkdown escapes the characters in text that would otherwise be interpreted as markdown formatting.
+func escapeMarkdown(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`)
+
+	return replacer.Replace(text)
+}
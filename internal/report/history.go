@@ -0,0 +1,312 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultHistoryLength is the default number of runs kept in the history of each branch.
+	DefaultHistoryLength = 20
+	// TopSpecs is the number of specs included in the lists of the flakiest and slowest specs of each suite.
+	TopSpecs = 5
+)
+
+// History is the record of past run results for a single branch. It is saved in the cache directory so trends can be
+// computed across multiple invocations of this program.
+type History struct {
+	Branch string
+	// Runs is the list of recorded runs, ordered from oldest to newest.
+	Runs []HistoryRun
+	// Specs maps from the key of each spec, as returned by historyKey, to its outcomes. Outcomes are ordered from
+	// oldest to newest and only include runs in which the spec had a result.
+	Specs map[string][]SpecOutcome
+}
+
+// HistoryRun identifies a single recorded run.
+type HistoryRun struct {
+	// ID is the ID of the ResultSet the run was recorded from.
+	ID string
+	// Recorded is when the run was added to the history.
+	Recorded time.Time
+}
+
+// SpecOutcome is the result of a single spec in a single run.
+type SpecOutcome struct {
+	RunID   string
+	State   ResultState
+	RunTime time.Duration
+}
+
+// SpecTrend summarizes the history of a single spec. It is attached to leaf nodes of the SuiteTree.
+type SpecTrend struct {
+	// Outcomes are the states of the most recent runs, ordered from oldest to newest.
+	Outcomes []ResultState
+	// FlakeRate is the fraction of runs that were flaky, between 0 and 1. A run is flaky if the spec flaked within it
+	// or if it passed after failing in the previous run or failed after passing in the previous run.
+	FlakeRate float64
+	// MeanRunTime is the mean run time over all the runs in which the spec ran.
+	MeanRunTime time.Duration
+	// RunTimeTrend is the relative change in mean run time between the older and newer halves of the runs. For example,
+	// 0.5 means the newer runs took 50% longer on average. It is zero if there are not enough runs.
+	RunTimeTrend float64
+}
+
+// NewHistory returns an empty history for branch.
+func NewHistory(branch string) *History {
+	return &History{
+		Branch: branch,
+		Specs:  make(map[string][]SpecOutcome),
+	}
+}
+
+// Record adds the results applied to tree as a new run with the provided ID. If a run with the same ID has already been
+// recorded, the history is unchanged and false is returned. Once recorded, only the most recent maxRuns runs are kept.
+func (history *History) Record(tree *SuiteTree, runID string, maxRuns int) bool {
+	klog.V(100).Infof("Recording run %s in history for branch %s", runID, history.Branch)
+
+	if slices.ContainsFunc(history.Runs, func(run HistoryRun) bool { return run.ID == runID }) {
+		klog.V(100).Infof("Run %s already recorded for branch %s", runID, history.Branch)
+
+		return false
+	}
+
+	if history.Specs == nil {
+		history.Specs = make(map[string][]SpecOutcome)
+	}
+
+	history.Runs = append(history.Runs, HistoryRun{ID: runID, Recorded: time.Now()})
+
+	for suite, leaf := range tree.Leaves() {
+		if leaf.Result == nil {
+			continue
+		}
+
+		key := historyKey(tree, suite, leaf)
+		history.Specs[key] = append(history.Specs[key], SpecOutcome{
+			RunID:   runID,
+			State:   leaf.Result.State,
+			RunTime: leaf.Result.RunTime,
+		})
+	}
+
+	history.prune(maxRuns)
+
+	return true
+}
+
+// ApplyHistory sets the trend for every leaf of tree that has at least one outcome in history. Only the most recent
+// maxRuns runs are considered.
+func (tree *SuiteTree) ApplyHistory(history *History, maxRuns int) {
+	klog.V(100).Infof("Applying history for branch %s to tree with path %s", history.Branch, tree.Path)
+
+	for suite, leaf := range tree.Leaves() {
+		outcomes := history.Specs[historyKey(tree, suite, leaf)]
+		if len(outcomes) > maxRuns {
+			outcomes = outcomes[len(outcomes)-maxRuns:]
+		}
+
+		leaf.Trend = newSpecTrend(outcomes)
+	}
+}
+
+// TopFlaky returns up to n leaves under this node with the highest nonzero flake rate, in descending order.
+func (tree *SuiteTree) TopFlaky(n int) []*SuiteTree {
+	return tree.topLeaves(n, func(trend *SpecTrend) float64 { return trend.FlakeRate })
+}
+
+// TopSlowest returns up to n leaves under this node with the highest nonzero mean run time, in descending order.
+func (tree *SuiteTree) TopSlowest(n int) []*SuiteTree {
+	return tree.topLeaves(n, func(trend *SpecTrend) float64 { return float64(trend.MeanRunTime) })
+}
+
+// IsSuite returns true if this node is the root of a Ginkgo suite. Only suite nodes have descriptions.
+func (tree *SuiteTree) IsSuite() bool {
+	return tree.Description != ""
+}
+
+// TopSpecs returns a human-readable list of the flakiest and slowest specs for every suite under this node, up to n of
+// each per suite. Suites without any history are skipped.
+func (tree *SuiteTree) TopSpecs(n int) string {
+	builder := &strings.Builder{}
+	tree.writeTopSpecs(builder, n)
+
+	return builder.String()
+}
+
+// String returns the outcomes as one character per run followed by the flake rate and mean run time.
+func (trend *SpecTrend) String() string {
+	return fmt.Sprintf("%s %.1f%% flaky, %s mean",
+		formatOutcomes(trend.Outcomes), trend.FlakeRate*100, trend.MeanRunTime.Round(time.Millisecond))
+}
+
+// topLeaves returns up to n leaves under this node with trends, sorted descending by the value returned by metric.
+// Leaves with a metric of zero are excluded.
+func (tree *SuiteTree) topLeaves(n int, metric func(*SpecTrend) float64) []*SuiteTree {
+	var leaves []*SuiteTree
+
+	for _, leaf := range tree.Leaves() {
+		if leaf.Trend != nil && metric(leaf.Trend) > 0 {
+			leaves = append(leaves, leaf)
+		}
+	}
+
+	slices.SortStableFunc(leaves, func(leafA, leafB *SuiteTree) int {
+		return cmp.Compare(metric(leafB.Trend), metric(leafA.Trend))
+	})
+
+	if len(leaves) > n {
+		leaves = leaves[:n]
+	}
+
+	return leaves
+}
+
+// writeTopSpecs is the recursive helper for TopSpecs.
+func (tree *SuiteTree) writeTopSpecs(builder *strings.Builder, n int) {
+	if tree.IsSuite() {
+		flaky := tree.TopFlaky(n)
+		slowest := tree.TopSlowest(n)
+
+		if len(flaky) > 0 || len(slowest) > 0 {
+			fmt.Fprintf(builder, "%s (%s)\n", tree.Description, tree.Name)
+		}
+
+		for _, leaf := range flaky {
+			fmt.Fprintf(builder, "  flaky   %5.1f%% %s %s\n",
+				leaf.Trend.FlakeRate*100, formatOutcomes(leaf.Trend.Outcomes), leaf.SpecReport.FullText())
+		}
+
+		for _, leaf := range slowest {
+			fmt.Fprintf(builder, "  slowest %s %+.0f%% %s\n",
+				leaf.Trend.MeanRunTime.Round(time.Second), leaf.Trend.RunTimeTrend*100, leaf.SpecReport.FullText())
+		}
+	}
+
+	for _, child := range tree.Children {
+		child.writeTopSpecs(builder, n)
+	}
+}
+
+// prune removes all but the most recent maxRuns runs, along with any outcomes from the removed runs. Specs left without
+// any outcomes are removed entirely.
+func (history *History) prune(maxRuns int) {
+	if len(history.Runs) <= maxRuns {
+		return
+	}
+
+	history.Runs = slices.Clone(history.Runs[len(history.Runs)-maxRuns:])
+	keptRuns := make(map[string]bool)
+
+	for _, run := range history.Runs {
+		keptRuns[run.ID] = true
+	}
+
+	for key, outcomes := range history.Specs {
+		outcomes = slices.DeleteFunc(outcomes, func(outcome SpecOutcome) bool {
+			return !keptRuns[outcome.RunID]
+		})
+
+		if len(outcomes) == 0 {
+			delete(history.Specs, key)
+
+			continue
+		}
+
+		history.Specs[key] = outcomes
+	}
+}
+
+// newSpecTrend computes the trend from outcomes, which should be ordered from oldest to newest. It returns nil if there
+// are no outcomes.
+func newSpecTrend(outcomes []SpecOutcome) *SpecTrend {
+	if len(outcomes) == 0 {
+		return nil
+	}
+
+	trend := &SpecTrend{}
+
+	var (
+		ran      []SpecOutcome
+		flaky    int
+		previous ResultState
+	)
+
+	for _, outcome := range outcomes {
+		trend.Outcomes = append(trend.Outcomes, outcome.State)
+
+		if outcome.State == ResultSkipped || outcome.State == ResultPending {
+			continue
+		}
+
+		ran = append(ran, outcome)
+
+		if outcome.State == ResultFlaked || (previous != "" && previous != outcome.State) {
+			flaky++
+		}
+
+		previous = outcome.State
+	}
+
+	if len(ran) == 0 {
+		return trend
+	}
+
+	trend.FlakeRate = float64(flaky) / float64(len(ran))
+	trend.MeanRunTime = meanRunTime(ran)
+
+	if len(ran) >= 2 {
+		olderMean := meanRunTime(ran[:len(ran)/2])
+		newerMean := meanRunTime(ran[len(ran)/2:])
+
+		if olderMean > 0 {
+			trend.RunTimeTrend = float64(newerMean-olderMean) / float64(olderMean)
+		}
+	}
+
+	return trend
+}
+
+// meanRunTime returns the mean run time of outcomes, which must not be empty.
+func meanRunTime(outcomes []SpecOutcome) time.Duration {
+	var total time.Duration
+
+	for _, outcome := range outcomes {
+		total += outcome.RunTime
+	}
+
+	return total / time.Duration(len(outcomes))
+}
+
+// formatOutcomes returns one character per outcome: P for passed, F for failed, ~ for flaked, and - otherwise.
+func formatOutcomes(outcomes []ResultState) string {
+	builder := &strings.Builder{}
+
+	for _, outcome := range outcomes {
+		switch outcome {
+		case ResultPassed:
+			builder.WriteByte('P')
+		case ResultFailed:
+			builder.WriteByte('F')
+		case ResultFlaked:
+			builder.WriteByte('~')
+		default:
+			builder.WriteByte('-')
+		}
+	}
+
+	return builder.String()
+}
+
+// historyKey returns the key identifying the leaf in the history. It uses the suite path relative to the root of the
+// tree and the full text of the spec so that it is stable across clones and machines.
+func historyKey(root, suite, leaf *SuiteTree) string {
+	info := NewSpecInfo(root, suite, leaf)
+
+	return info.Suite + "\n" + info.Text
+}
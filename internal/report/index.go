@@ -0,0 +1,155 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog/v2"
+)
+
+// TreeIndex contains lookup tables for the specs in a single SuiteTree. It allows finding specs by label or reportxml
+// ID rather than by their position in the tree.
+type TreeIndex struct {
+	// Labels contains every label used by at least one spec, sorted by label.
+	Labels []IndexEntry
+	// IDs contains every reportxml ID used by at least one spec, sorted by ID.
+	IDs []IndexEntry
+	// DuplicateIDs contains the subset of IDs that are used by more than one spec.
+	DuplicateIDs []IndexEntry
+	// MissingIDs contains every spec that does not have a reportxml ID.
+	MissingIDs []SpecInfo
+}
+
+// IndexEntry is a single key in the index, either a label or an ID, and all the specs that have it.
+type IndexEntry struct {
+	Key   string
+	Specs []SpecInfo
+}
+
+// NewTreeIndex builds the index for all the specs in tree.
+func NewTreeIndex(tree *SuiteTree) *TreeIndex {
+	klog.V(100).Infof("Building index for tree with path %s", tree.Path)
+
+	index := &TreeIndex{}
+	labels := make(map[string][]SpecInfo)
+	ids := make(map[string][]SpecInfo)
+
+	for suite, leaf := range tree.Leaves() {
+		spec := NewSpecInfo(tree, suite, leaf)
+
+		for _, label := range spec.Labels {
+			// The prefixed ID labels are already covered by the IDs, so there is no need to include them as labels.
+			if strings.HasPrefix(label, ReportXMLIDPrefix) {
+				continue
+			}
+
+			labels[label] = append(labels[label], spec)
+		}
+
+		for _, id := range spec.IDs {
+			ids[id] = append(ids[id], spec)
+		}
+
+		if len(spec.IDs) == 0 {
+			index.MissingIDs = append(index.MissingIDs, spec)
+		}
+	}
+
+	index.Labels = newIndexEntries(labels)
+	index.IDs = newIndexEntries(ids)
+
+	for _, entry := range index.IDs {
+		if len(entry.Specs) > 1 {
+			index.DuplicateIDs = append(index.DuplicateIDs, entry)
+		}
+	}
+
+	slices.SortFunc(index.MissingIDs, compareSpecInfos)
+
+	return index
+}
+
+// String returns a human-readable representation of the index. It lists the number of specs per label, followed by any
+// duplicate IDs and the number of specs missing an ID.
+func (index *TreeIndex) String() string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "Labels (%d):\n", len(index.Labels))
+
+	for _, entry := range index.Labels {
+		fmt.Fprintf(builder, "  %s %d\n", entry.Key, len(entry.Specs))
+	}
+
+	fmt.Fprintf(builder, "IDs: %d\n", len(index.IDs))
+	fmt.Fprintf(builder, "Duplicate IDs (%d):\n", len(index.DuplicateIDs))
+
+	for _, entry := range index.DuplicateIDs {
+		for _, spec := range entry.Specs {
+			fmt.Fprintf(builder, "  %s %s: %s (%s)\n", entry.Key, spec.Suite, spec.Text, spec.Location)
+		}
+	}
+
+	fmt.Fprintf(builder, "Specs without ID: %d\n", len(index.MissingIDs))
+
+	return builder.String()
+}
+
+// Filter returns a copy of the tree containing only the specs that match the label filter. Suites and containers left
+// without any specs are removed and spec counts are recomputed. If no specs match, the returned tree has no children.
+// The receiver is not modified, although leaf nodes are shared between the original and the copy.
+func (tree *SuiteTree) Filter(filter types.LabelFilter) *SuiteTree {
+	klog.V(100).Infof("Filtering tree with path %s", tree.Path)
+
+	if tree.SpecReport != nil {
+		if filter(tree.SpecReport.Labels()) {
+			return tree
+		}
+
+		return nil
+	}
+
+	filtered := *tree
+	filtered.Specs = 0
+	filtered.Children = nil
+
+	for _, child := range tree.Children {
+		filteredChild := child.Filter(filter)
+		if filteredChild == nil || filteredChild.Specs == 0 {
+			continue
+		}
+
+		filtered.Specs += filteredChild.Specs
+		filtered.Children = append(filtered.Children, filteredChild)
+	}
+
+	return &filtered
+}
+
+// newIndexEntries converts the map of keys to specs into a slice of entries sorted by key. The specs in each entry are
+// sorted as well.
+func newIndexEntries(specsByKey map[string][]SpecInfo) []IndexEntry {
+	entries := make([]IndexEntry, 0, len(specsByKey))
+
+	for key, specs := range specsByKey {
+		slices.SortFunc(specs, compareSpecInfos)
+		entries = append(entries, IndexEntry{Key: key, Specs: specs})
+	}
+
+	slices.SortFunc(entries, func(entryA, entryB IndexEntry) int {
+		return cmp.Compare(entryA.Key, entryB.Key)
+	})
+
+	return entries
+}
+
+// compareSpecInfos orders specs by suite and then text.
+func compareSpecInfos(specA, specB SpecInfo) int {
+	if n := strings.Compare(specA.Suite, specB.Suite); n != 0 {
+		return n
+	}
+
+	return strings.Compare(specA.Text, specB.Text)
+}
@@ -12,9 +12,23 @@ Usage:
 
 The flags are:
 
+	-f, -format string
+		Format for printing trees to stdout. One of text, json, csv, or markdown. Uses text if left blank
+
 	-h, -help
 		Print this help message
 
+	-history int
+		Number of runs to keep in the history of each branch. Results are recorded in the history when -r is provided
+		and trends are shown for every spec. Use 0 to disable history
+
+	-i, -index
+		Print the label and reportxml ID index for each tree instead of the tree itself
+
+	-j, -jobs int
+		Maximum number of clones and suite dry runs to run at once, across all branches. Uses the number of CPUs if
+		left blank
+
 	-a, -action-url string
 		URL to the action generating this report. Only necessary with -o. Uses "/" if left blank
 
@@ -24,9 +38,28 @@ The flags are:
 	-c, -clean
 		Delete the test suite cache and exit without running
 
+	-d, -diff string
+		Two space-separated branches to compare, base first then head. The diff is printed instead of the trees and
+		included in the static site if -o is provided. Cannot be used with -b, -f, -i, -s
+
+	-l, -label-filter string
+		Ginkgo label filter expression, using the same syntax as --label-filter. Trees are pruned to only the matching
+		specs before printing or templating
+
 	-o, -output string
 		Directory to output static site to. Will not be generated if left blank
 
+	-r, -results string
+		Space-separated list of globs matching Ginkgo JSON, JUnit, or reportxml result files. Results are overlaid onto
+		all trees if provided
+
+	-serve string
+		Address to serve the report on, such as localhost:8080, instead of printing the trees. The report includes a
+		search page and is rebuilt whenever files under tests/ change when serving the local repo
+
+	-s, -stats
+		Print the flakiest and slowest specs of each suite based on the history instead of the tree
+
 	-v int
 		Log level verbosity for klog. Use 100 for logging all messages or leave blank for none
 */
@@ -40,20 +73,33 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2/types"
 	"k8s.io/klog/v2"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
-	help      bool
-	actionURL string
-	branch    string
-	clean     bool
-	output    string
+	help        bool
+	actionURL   string
+	branch      string
+	clean       bool
+	diff        string
+	format      string
+	historyLen  int
+	index       bool
+	jobs        int
+	labelFilter string
+	output      string
+	results     string
+	serve       string
+	stats       bool
 )
 
 //nolint:gochecknoinits // This is a main package so init is fine.
@@ -63,13 +109,32 @@ func init() {
 		actionURLUsage = "URL to the action generating this report. Only necessary with -o. Uses \"/\" if left blank"
 		branchUsage    = "Space-separated list of globs to match branches. Leave blank to use the local directory"
 		cleanUsage     = "Delete the test suite cache and exit without running"
+		diffUsage      = "Two space-separated branches to compare, base first then head. Cannot be used with -b, -f, -i, -s"
+		formatUsage    = "Format for printing trees to stdout. One of text, json, csv, or markdown. Uses text if left blank"
+		historyUsage   = "Number of runs to keep in the history of each branch. Use 0 to disable history"
+		indexUsage     = "Print the label and reportxml ID index for each tree instead of the tree itself"
+		jobsUsage      = "Maximum number of clones and suite dry runs to run at once, across all branches"
+		filterUsage    = "Ginkgo label filter expression used to prune the trees before printing or templating"
 		outputUsage    = "Directory to output static site to. Will not be generated if left blank"
+		resultsUsage   = "Space-separated list of globs matching Ginkgo JSON, JUnit, or reportxml result files. " +
+			"Results are overlaid onto all trees if provided"
+		serveUsage = "Address to serve the report on, such as localhost:8080, instead of printing the trees"
+		statsUsage = "Print the flakiest and slowest specs of each suite based on the history instead of the tree"
 
 		defaultHelp      = false
 		defaultActionURL = "/"
 		defaultBranch    = ""
 		defaultClean     = false
+		defaultDiff      = ""
+		defaultFormat    = "text"
+		defaultHistory   = DefaultHistoryLength
+		defaultIndex     = false
+		defaultJobs      = 0
+		defaultFilter    = ""
 		defaultOutput    = ""
+		defaultResults   = ""
+		defaultServe     = ""
+		defaultStats     = false
 
 		shorthand = " (shorthand)"
 	)
@@ -92,8 +157,33 @@ func init() {
 	flag.BoolVar(&clean, "clean", defaultClean, cleanUsage)
 	flag.BoolVar(&clean, "c", defaultClean, cleanUsage+shorthand)
 
+	flag.StringVar(&diff, "diff", defaultDiff, diffUsage)
+	flag.StringVar(&diff, "d", defaultDiff, diffUsage+shorthand)
+
+	flag.StringVar(&format, "format", defaultFormat, formatUsage)
+	flag.StringVar(&format, "f", defaultFormat, formatUsage+shorthand)
+
+	flag.IntVar(&historyLen, "history", defaultHistory, historyUsage)
+
+	flag.BoolVar(&index, "index", defaultIndex, indexUsage)
+	flag.BoolVar(&index, "i", defaultIndex, indexUsage+shorthand)
+
+	flag.IntVar(&jobs, "jobs", defaultJobs, jobsUsage)
+	flag.IntVar(&jobs, "j", defaultJobs, jobsUsage+shorthand)
+
+	flag.StringVar(&labelFilter, "label-filter", defaultFilter, filterUsage)
+	flag.StringVar(&labelFilter, "l", defaultFilter, filterUsage+shorthand)
+
 	flag.StringVar(&output, "output", defaultOutput, outputUsage)
 	flag.StringVar(&output, "o", defaultOutput, outputUsage+shorthand)
+
+	flag.StringVar(&results, "results", defaultResults, resultsUsage)
+	flag.StringVar(&results, "r", defaultResults, resultsUsage+shorthand)
+
+	flag.StringVar(&serve, "serve", defaultServe, serveUsage)
+
+	flag.BoolVar(&stats, "stats", defaultStats, statsUsage)
+	flag.BoolVar(&stats, "s", defaultStats, statsUsage+shorthand)
 }
 
 func main() {
@@ -116,17 +206,70 @@ func main() {
 		return
 	}
 
-	treeMap, err := getTrees(branch)
+	if diff != "" && branch != "" {
+		klog.Errorf("Cannot use both diff=\"%s\" and branch=\"%s\"", diff, branch)
+
+		os.Exit(1)
+	}
+
+	if conflicting := setFlags("format", "f", "index", "i", "stats", "s"); diff != "" && len(conflicting) > 0 {
+		klog.Errorf("Cannot use diff=\"%s\" with -%s since the diff is printed instead of the trees",
+			diff, strings.Join(conflicting, ", -"))
+
+		os.Exit(1)
+	}
+
+	treeBranches := branch
+	if diff != "" {
+		treeBranches = diff
+	}
+
+	if serve != "" {
+		err := serveTrees(treeBranches, serve)
+		if err != nil {
+			klog.Errorf("Failed to serve suite trees on %s: %v", serve, err)
+
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	treeMap, err := buildTreeMap(treeBranches)
 	if err != nil {
-		klog.Errorf("Failed to get suite trees when branch=\"%s\": %v", branch, err)
+		klog.Errorf("Failed to build suite trees when branch=\"%s\": %v", treeBranches, err)
 
 		os.Exit(1)
 	}
 
-	printTreeMap(treeMap)
+	var diffs []*TreeDiff
+
+	if diff != "" {
+		treeDiff, err := diffTreeMap(treeMap, diff)
+		if err != nil {
+			klog.Errorf("Failed to diff suite trees when diff=\"%s\": %v", diff, err)
+
+			os.Exit(1)
+		}
+
+		fmt.Print(treeDiff)
+
+		diffs = append(diffs, treeDiff)
+	} else if index {
+		printIndexMap(treeMap)
+	} else if stats {
+		printStatsMap(treeMap)
+	} else {
+		err := printTreeMap(treeMap, format)
+		if err != nil {
+			klog.Errorf("Failed to print suite trees when format=\"%s\": %v", format, err)
+
+			os.Exit(1)
+		}
+	}
 
 	if output != "" {
-		err := templateTreeMap(treeMap, output)
+		err := templateTreeMap(treeMap, diffs, output)
 		if err != nil {
 			klog.Errorf("Failed to template tree map and save to %s: %v", output, err)
 
@@ -135,11 +278,30 @@ func main() {
 	}
 }
 
+// setFlags returns the names of the provided flags that were set on the command line, sorted lexicographically.
+func setFlags(names ...string) []string {
+	var set []string
+
+	flag.Visit(func(setFlag *flag.Flag) {
+		if slices.Contains(names, setFlag.Name) {
+			set = append(set, setFlag.Name)
+		}
+	})
+
+	return set
+}
+
 func getTrees(branch string) (map[CacheKey]*SuiteTree, error) {
 	ctx, cancel := signal.NotifyContext(context.TODO(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	cache, err := NewCacheContext(ctx)
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	pool := NewWorkerPool(jobs)
+
+	cache, err := NewCacheContext(ctx, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +310,7 @@ func getTrees(branch string) (map[CacheKey]*SuiteTree, error) {
 
 	if branch != "" {
 		patterns := strings.Fields(branch)
-		treeMap, err = getFromCacheOrClone(ctx, cache, patterns)
+		treeMap, err = getFromCacheOrClone(ctx, cache, pool, patterns)
 	} else {
 		treeMap, err = getLocalTreeMap(cache, ".")
 	}
@@ -170,15 +332,176 @@ func getTrees(branch string) (map[CacheKey]*SuiteTree, error) {
 	return treeMap, nil
 }
 
-func printTreeMap(treeMap map[CacheKey]*SuiteTree) {
+// buildTreeMap gets the trees for the space-separated branch patterns, or the local repo if branch is empty, then
+// filters them, applies results, and updates the history according to the flags.
+func buildTreeMap(branch string) (map[CacheKey]*SuiteTree, error) {
+	treeMap, err := getTrees(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if labelFilter != "" {
+		err := filterTreeMap(treeMap, labelFilter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter suite trees when label-filter=\"%s\": %w", labelFilter, err)
+		}
+	}
+
+	runID := ""
+
+	if results != "" {
+		runID, err = applyResults(treeMap, results)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply results when results=\"%s\": %w", results, err)
+		}
+	}
+
+	if historyLen > 0 {
+		err := updateHistory(treeMap, runID, historyLen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update history: %w", err)
+		}
+	}
+
+	return treeMap, nil
+}
+
+// serveTrees builds the trees for branch and serves them on addr until the program is interrupted. When serving the
+// local repo, the trees are rebuilt whenever files under tests/ change.
+func serveTrees(branch, addr string) error {
+	ctx, cancel := signal.NotifyContext(context.TODO(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	server, err := NewServer(func() (map[CacheKey]*SuiteTree, error) {
+		return buildTreeMap(branch)
+	})
+	if err != nil {
+		return err
+	}
+
+	if branch == "" {
+		go server.Watch(ctx, "tests", WatchInterval)
+	}
+
+	return server.ListenAndServe(ctx, addr)
+}
+
+// filterTreeMap parses the Ginkgo label filter expression and replaces every tree in treeMap with a copy containing
+// only the specs matching the filter.
+func filterTreeMap(treeMap map[CacheKey]*SuiteTree, labelFilter string) error {
+	filter, err := types.ParseLabelFilter(labelFilter)
+	if err != nil {
+		return err
+	}
+
+	for key, tree := range treeMap {
+		treeMap[key] = tree.Filter(filter)
+	}
+
+	return nil
+}
+
+// applyResults loads the result files matching the space-separated globs in results and overlays them onto every tree
+// in treeMap. The trees are modified in place. It returns the ID of the loaded results.
+func applyResults(treeMap map[CacheKey]*SuiteTree, results string) (string, error) {
+	resultSet, err := LoadResults(strings.Fields(results))
+	if err != nil {
+		return "", err
+	}
+
+	if resultSet.Len() == 0 {
+		return "", fmt.Errorf("no results found in files matching %s", results)
+	}
+
+	for key, tree := range treeMap {
+		matched := tree.ApplyResults(resultSet)
+
+		klog.V(100).Infof("Matched %d of %d results to specs on branch %s", matched, resultSet.Len(), key.Branch)
+	}
+
+	return resultSet.ID, nil
+}
+
+// updateHistory loads the history for every branch in treeMap, records the applied results if runID is not empty, and
+// applies the resulting trends to the trees. Histories are only saved if a run was recorded.
+func updateHistory(treeMap map[CacheKey]*SuiteTree, runID string, maxRuns int) error {
+	for key, tree := range treeMap {
+		branchHistory, err := LoadHistory(key.Branch)
+		if err != nil {
+			return err
+		}
+
+		if runID != "" && branchHistory.Record(tree, runID, maxRuns) {
+			err := SaveHistory(branchHistory)
+			if err != nil {
+				return err
+			}
+		}
+
+		tree.ApplyHistory(branchHistory, maxRuns)
+	}
+
+	return nil
+}
+
+// diffTreeMap finds the trees for the two space-separated branches in diffBranches and returns the diff between them.
+// The first branch is used as the base and the second as the head.
+func diffTreeMap(treeMap map[CacheKey]*SuiteTree, diffBranches string) (*TreeDiff, error) {
+	branches := strings.Fields(diffBranches)
+	if len(branches) != 2 {
+		return nil, fmt.Errorf("expected exactly two branches to diff but got %d", len(branches))
+	}
+
+	findTree := func(branch string) (CacheKey, *SuiteTree, error) {
+		for key, tree := range treeMap {
+			if key.Branch == branch {
+				return key, tree, nil
+			}
+		}
+
+		return CacheKey{}, nil, fmt.Errorf("branch %s not found on remote", branch)
+	}
+
+	baseKey, baseTree, err := findTree(branches[0])
+	if err != nil {
+		return nil, err
+	}
+
+	headKey, headTree, err := findTree(branches[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTreeDiff(baseKey, baseTree, headKey, headTree), nil
+}
+
+// printTreeMap prints all the trees in treeMap to stdout using the provided format.
+func printTreeMap(treeMap map[CacheKey]*SuiteTree, format string) error {
+	outputFormat, err := ParseOutputFormat(format)
+	if err != nil {
+		return err
+	}
+
+	return WriteTreeMap(os.Stdout, treeMap, outputFormat)
+}
+
+func printIndexMap(treeMap map[CacheKey]*SuiteTree) {
 	for key, tree := range treeMap {
 		fmt.Println("---")
-		fmt.Printf("Branch %s (%s)\n", key.Branch, key.Revision[:7])
-		fmt.Print(tree)
+		fmt.Printf("Branch %s (%s)\n", key.Branch, key.ShortRevision())
+		fmt.Print(NewTreeIndex(tree))
 	}
 }
 
-func templateTreeMap(treeMap map[CacheKey]*SuiteTree, output string) error {
+func printStatsMap(treeMap map[CacheKey]*SuiteTree) {
+	for key, tree := range treeMap {
+		fmt.Println("---")
+		fmt.Printf("Branch %s (%s)\n", key.Branch, key.ShortRevision())
+		fmt.Print(tree.TopSpecs(TopSpecs))
+	}
+}
+
+func templateTreeMap(treeMap map[CacheKey]*SuiteTree, diffs []*TreeDiff, output string) error {
 	err := os.MkdirAll(output, 0755)
 	if err != nil {
 		return err
@@ -187,33 +510,28 @@ func templateTreeMap(treeMap map[CacheKey]*SuiteTree, output string) error {
 	var branchReports []BranchReportConfig
 
 	for key, tree := range treeMap {
-		config := TreeTemplateConfig{
-			Tree:       tree,
-			Generated:  time.Now(),
-			Branch:     key.Branch,
-			ActionURL:  template.URL(actionURL),
-			RepoURL:    RemoteURL,
-			TimeFormat: time.RFC3339,
+		branchReport, err := templateBranch(key, tree, output)
+		if err != nil {
+			return err
 		}
-		outputFileName := fmt.Sprintf("report_%s.html", key.Branch)
-		outputFilePath := filepath.Join(output, outputFileName)
 
-		err := TemplateTree(config, outputFilePath)
+		branchReports = append(branchReports, branchReport)
+	}
+
+	var diffReports []DiffReportConfig
+
+	for _, treeDiff := range diffs {
+		diffReport, err := templateDiff(treeDiff, output)
 		if err != nil {
 			return err
 		}
 
-		branchReport := BranchReportConfig{
-			Name:          key.Branch,
-			ReportFile:    outputFileName,
-			Revision:      key.Revision,
-			ShortRevision: key.Revision[:7],
-		}
-		branchReports = append(branchReports, branchReport)
+		diffReports = append(diffReports, diffReport)
 	}
 
 	config := ReportTemplateConfig{
 		BranchReports: branchReports,
+		DiffReports:   diffReports,
 		Generated:     time.Now(),
 		ActionURL:     template.URL(actionURL),
 		RepoURL:       RemoteURL,
@@ -229,6 +547,87 @@ func templateTreeMap(treeMap map[CacheKey]*SuiteTree, output string) error {
 	return nil
 }
 
+// templateBranch templates the tree and index pages for a single branch into the output directory and returns the
+// config to include them in the main report.
+func templateBranch(key CacheKey, tree *SuiteTree, output string) (BranchReportConfig, error) {
+	indexFileName := fmt.Sprintf("index_%s.html", key.Branch)
+
+	// Trees with uncommitted changes do not match any revision on the remote, so they are templated without links.
+	revision := key.Revision
+	if key == LocalKey {
+		revision = ""
+	}
+
+	config := TreeTemplateConfig{
+		Tree:       tree,
+		Generated:  time.Now(),
+		Branch:     key.Branch,
+		Revision:   revision,
+		IndexFile:  indexFileName,
+		ActionURL:  template.URL(actionURL),
+		RepoURL:    RemoteURL,
+		TimeFormat: time.RFC3339,
+	}
+	outputFileName := fmt.Sprintf("report_%s.html", key.Branch)
+	outputFilePath := filepath.Join(output, outputFileName)
+
+	err := TemplateTree(config, outputFilePath)
+	if err != nil {
+		return BranchReportConfig{}, err
+	}
+
+	indexConfig := IndexTemplateConfig{
+		Index:      NewTreeIndex(tree),
+		Generated:  config.Generated,
+		Branch:     key.Branch,
+		ReportFile: outputFileName,
+		ActionURL:  config.ActionURL,
+		RepoURL:    RemoteURL,
+		TimeFormat: time.RFC3339,
+	}
+
+	err = TemplateIndex(indexConfig, filepath.Join(output, indexFileName))
+	if err != nil {
+		return BranchReportConfig{}, err
+	}
+
+	branchReport := BranchReportConfig{
+		Name:          key.Branch,
+		ReportFile:    outputFileName,
+		IndexFile:     indexFileName,
+		Revision:      key.Revision,
+		ShortRevision: key.ShortRevision(),
+	}
+
+	return branchReport, nil
+}
+
+// templateDiff templates the page for a single diff into the output directory and returns the config to include it in
+// the main report.
+func templateDiff(treeDiff *TreeDiff, output string) (DiffReportConfig, error) {
+	config := DiffTemplateConfig{
+		Diff:       treeDiff,
+		Generated:  time.Now(),
+		ActionURL:  template.URL(actionURL),
+		RepoURL:    RemoteURL,
+		TimeFormat: time.RFC3339,
+	}
+	outputFileName := fmt.Sprintf("diff_%s_%s.html", treeDiff.Base.Branch, treeDiff.Head.Branch)
+	outputFilePath := filepath.Join(output, outputFileName)
+
+	err := TemplateDiff(config, outputFilePath)
+	if err != nil {
+		return DiffReportConfig{}, err
+	}
+
+	diffReport := DiffReportConfig{
+		Name:       fmt.Sprintf("%s...%s", treeDiff.Base.Branch, treeDiff.Head.Branch),
+		ReportFile: outputFileName,
+	}
+
+	return diffReport, nil
+}
+
 func getLocalTreeMap(cache *Cache, repoPath string) (map[CacheKey]*SuiteTree, error) {
 	tree, err := cache.GetOrCreate(repoPath)
 	if err != nil {
@@ -239,7 +638,7 @@ func getLocalTreeMap(cache *Cache, repoPath string) (map[CacheKey]*SuiteTree, er
 
 	key, err := cache.GetKeyFromPath(repoPath)
 	if IsMiss(err) {
-		treeMap := map[CacheKey]*SuiteTree{{Branch: "local", Revision: "local"}: tree}
+		treeMap := map[CacheKey]*SuiteTree{LocalKey: tree}
 
 		return treeMap, nil
 	}
@@ -253,7 +652,10 @@ func getLocalTreeMap(cache *Cache, repoPath string) (map[CacheKey]*SuiteTree, er
 	return treeMap, nil
 }
 
-func getFromCacheOrClone(ctx context.Context, cache *Cache, patterns []string) (map[CacheKey]*SuiteTree, error) {
+// getFromCacheOrClone returns the trees for all branches matching patterns. Branches missing from the cache are cloned
+// into their own temporary directories and processed concurrently, with clones limited by pool.
+func getFromCacheOrClone(
+	ctx context.Context, cache *Cache, pool *WorkerPool, patterns []string) (map[CacheKey]*SuiteTree, error) {
 	treeMap, err := cache.GetRemotePatterns(patterns)
 	if err != nil {
 		return nil, err
@@ -264,25 +666,65 @@ func getFromCacheOrClone(ctx context.Context, cache *Cache, patterns []string) (
 		return nil, fmt.Errorf("unable to access temp dir to clone repo: %w", err)
 	}
 
+	var (
+		treeMapMutex sync.Mutex
+		tasks        []func(ctx context.Context) error
+	)
+
 	for key, tree := range treeMap {
 		if tree != nil {
 			continue
 		}
 
-		repoPath, err := CloneRepo(ctx, tempDir, RemoteURL, key.Branch)
-		if err != nil {
-			return nil, err
-		}
+		tasks = append(tasks, func(ctx context.Context) error {
+			tree, err := cloneAndCreate(ctx, cache, pool, tempDir, key.Branch)
+			if err != nil {
+				return err
+			}
 
-		tree, err := cache.GetOrCreate(repoPath)
-		if err != nil {
-			klog.Errorf("Failed to get or create SuiteTree from cache: %v", err)
+			treeMapMutex.Lock()
+			treeMap[key] = tree
+			treeMapMutex.Unlock()
 
-			return nil, err
-		}
+			return nil
+		})
+	}
 
-		treeMap[key] = tree
+	err = RunAll(ctx, tasks)
+	if err != nil {
+		return nil, err
 	}
 
 	return treeMap, nil
 }
+
+// cloneAndCreate clones branch into a new directory under tempDir and gets or creates its tree from the cache. The
+// clone is removed once the tree has been created.
+func cloneAndCreate(ctx context.Context, cache *Cache, pool *WorkerPool, tempDir, branch string) (*SuiteTree, error) {
+	cloneDir, err := os.MkdirTemp(tempDir, "report-")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(cloneDir)
+
+	var repoPath string
+
+	err = pool.Do(ctx, func(ctx context.Context) error {
+		repoPath, err = CloneRepo(ctx, cloneDir, RemoteURL, branch)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := cache.GetOrCreate(repoPath)
+	if err != nil {
+		klog.Errorf("Failed to get or create SuiteTree from cache: %v", err)
+
+		return nil, err
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+// ownersFileName is the name of the files listing the approvers and reviewers for a directory and its subdirectories.
+const ownersFileName = "OWNERS"
+
+// Owners contains the approvers and reviewers for a suite, resolved from the nearest OWNERS file.
+type Owners struct {
+	// File is the path of the OWNERS file relative to the root of the repo.
+	File      string   `yaml:"-"`
+	Approvers []string `yaml:"approvers"`
+	Reviewers []string `yaml:"reviewers"`
+}
+
+// ApplyOwners sets the owners of every suite node in the tree, using the nearest OWNERS file in the suite directory or
+// any parent directory up to repoPath. Suites without any OWNERS file up to repoPath are left without owners.
+func (tree *SuiteTree) ApplyOwners(repoPath string) error {
+	klog.V(100).Infof("Applying owners from repo %s to tree with path %s", repoPath, tree.Path)
+
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return err
+	}
+
+	return tree.applyOwners(absRepoPath, make(map[string]*Owners))
+}
+
+// applyOwners is the recursive helper for ApplyOwners. The loaded map caches owners by directory so each OWNERS file is
+// only read once.
+func (tree *SuiteTree) applyOwners(absRepoPath string, loaded map[string]*Owners) error {
+	if tree.IsSuite() {
+		owners, err := findOwners(absRepoPath, tree.Path, loaded)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		tree.Owners = owners
+	}
+
+	for _, child := range tree.Children {
+		err := child.applyOwners(absRepoPath, loaded)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findOwners returns the owners from the nearest OWNERS file in dir or its parents, stopping at absRepoPath. It returns
+// an error wrapping os.ErrNotExist if there is no such file or dir is not in the repo. Directories without owners are
+// stored in loaded as nil.
+func findOwners(absRepoPath, dir string, loaded map[string]*Owners) (*Owners, error) {
+	if owners, ok := loaded[dir]; ok {
+		if owners == nil {
+			return nil, fmt.Errorf("no %s file for %s: %w", ownersFileName, dir, os.ErrNotExist)
+		}
+
+		return owners, nil
+	}
+
+	relDir, err := filepath.Rel(absRepoPath, dir)
+	if err != nil || relDir == ".." || strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("directory %s is not in repo %s: %w", dir, absRepoPath, os.ErrNotExist)
+	}
+
+	owners, err := loadOwners(absRepoPath, filepath.Join(dir, ownersFileName))
+	if errors.Is(err, os.ErrNotExist) && dir != absRepoPath {
+		owners, err = findOwners(absRepoPath, filepath.Dir(dir), loaded)
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	loaded[dir] = owners
+
+	return owners, err
+}
+
+// loadOwners parses the OWNERS file at ownersPath. If the file does not exist, the returned error wraps os.ErrNotExist.
+func loadOwners(absRepoPath, ownersPath string) (*Owners, error) {
+	content, err := os.ReadFile(ownersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(100).Infof("Loading owners from %s", ownersPath)
+
+	owners := &Owners{}
+
+	err = yaml.Unmarshal(content, owners)
+	if err != nil {
+		return nil, err
+	}
+
+	owners.File, err = filepath.Rel(absRepoPath, ownersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// WorkerPool bounds the number of expensive operations, such as clones and dry runs, that run at the same time. The
+// same pool is shared across all branches and suites so the bound applies to the entire program.
+type WorkerPool struct {
+	slots chan struct{}
+}
+
+// NewWorkerPool creates a new WorkerPool that allows at most workers operations at once. Values less than one are
+// treated as one.
+func NewWorkerPool(workers int) *WorkerPool {
+	return &WorkerPool{slots: make(chan struct{}, max(workers, 1))}
+}
+
+// Do waits for a free slot in the pool and then runs task, releasing the slot once it returns. If ctx is cancelled
+// before a slot is available, the task is not run and the context error is returned.
+func (pool *WorkerPool) Do(ctx context.Context, task func(ctx context.Context) error) error {
+	select {
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	defer func() {
+		<-pool.slots
+	}()
+
+	return task(ctx)
+}
+
+// RunAll runs all the tasks concurrently and waits for them to finish. Tasks are not bounded by a pool, so any
+// expensive work they do should go through [WorkerPool.Do]. The context passed to the tasks is cancelled as soon as any
+// task returns an error, and the first error is returned.
+func RunAll(ctx context.Context, tasks []func(ctx context.Context) error) error {
+	klog.V(100).Infof("Running %d tasks concurrently", len(tasks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		waitGroup sync.WaitGroup
+		errOnce   sync.Once
+		firstErr  error
+	)
+
+	for _, task := range tasks {
+		waitGroup.Go(func() {
+			err := task(ctx)
+			if err == nil {
+				return
+			}
+
+			errOnce.Do(func() {
+				firstErr = err
+
+				cancel()
+			})
+		})
+	}
+
+	waitGroup.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog/v2"
+)
+
+// ResultState is the simplified outcome of a spec run. Ginkgo has many more states, but for the purposes of the report
+// they are collapsed into these few.
+type ResultState string
+
+const (
+	// ResultPassed means the spec passed on its first attempt.
+	ResultPassed ResultState = "passed"
+	// ResultFailed means the spec failed, timed out, panicked, was interrupted, or was aborted.
+	ResultFailed ResultState = "failed"
+	// ResultSkipped means the spec was skipped, either explicitly or by a filter.
+	ResultSkipped ResultState = "skipped"
+	// ResultFlaked means the spec eventually passed but only after failing at least once.
+	ResultFlaked ResultState = "flaked"
+	// ResultPending means the spec is marked as pending.
+	ResultPending ResultState = "pending"
+)
+
+// SpecResult is the result of running a single spec. It is attached to leaf nodes of the SuiteTree.
+type SpecResult struct {
+	// State is the outcome of the spec.
+	State ResultState
+	// RunTime is how long the spec took to run. It is zero when the source of the result does not include timing.
+	RunTime time.Duration
+	// FailureMessage is the message of the failure or skip, if any.
+	FailureMessage string
+	// Attempts is the number of times the spec was run, summed over all result files it appeared in.
+	Attempts int
+}
+
+// ResultSummary is the rollup of all the SpecResults under a node of the SuiteTree.
+type ResultSummary struct {
+	Passed  int
+	Failed  int
+	Skipped int
+	Flaked  int
+	Pending int
+	// RunTime is the sum of the run times of all the specs under the node.
+	RunTime time.Duration
+}
+
+// ResultSet holds spec results indexed by the suite description and spec text. It is created from result files by
+// LoadResults and applied to a tree using [SuiteTree.ApplyResults].
+type ResultSet struct {
+	// ID is the SHA-256 sum of the contents of all the loaded result files. It identifies the run these results came
+	// from so the same results are not recorded in the history twice.
+	ID string
+
+	results map[resultKey]*SpecResult
+	// byText indexes results by only the spec text. A nil value means the text is ambiguous across suites.
+	byText map[string]*SpecResult
+}
+
+// junitTestSuites is the root element of a Ginkgo JUnit report. Only the fields needed for results are included.
+type junitTestSuites struct {
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a single suite in a Ginkgo JUnit report and the root element of a reportxml testrun file.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single spec in either a Ginkgo JUnit report or a reportxml testrun file. The reportxml format does
+// not include status or time, so they will be left empty.
+type junitTestCase struct {
+	Name    string  `xml:"name,attr"`
+	Status  string  `xml:"status,attr"`
+	Time    float64 `xml:"time,attr"`
+	Skipped *struct {
+		Message string `xml:"message,attr"`
+	} `xml:"skipped"`
+	Error *struct {
+		Message string `xml:"message,attr"`
+	} `xml:"error"`
+	Failure *struct {
+		Message     string `xml:"message,attr"`
+		Description string `xml:",chardata"`
+	} `xml:"failure"`
+}
+
+// resultKey identifies a spec across the different result file formats. Text is the spec text in any of the formats
+// produced by specTextVariants.
+type resultKey struct {
+	Suite string
+	Text  string
+}
+
+// LoadResults reads all result files matching the provided glob patterns and merges them into a single ResultSet.
+// Ginkgo JSON reports are expected to end in .json whereas Ginkgo JUnit reports and reportxml testrun files are
+// expected to end in .xml. Patterns that match no files are ignored.
+func LoadResults(patterns []string) (*ResultSet, error) {
+	klog.V(100).Infof("Loading results from files matching %v", patterns)
+
+	resultSet := &ResultSet{
+		results: make(map[resultKey]*SpecResult),
+		byText:  make(map[string]*SpecResult),
+	}
+
+	summer := sha256.New()
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			err := resultSet.loadFile(match, summer)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load results from %s: %w", match, err)
+			}
+		}
+	}
+
+	resultSet.ID = fmt.Sprintf("%x", summer.Sum(nil))
+
+	klog.V(100).Infof("Loaded %d results", len(resultSet.results))
+
+	return resultSet, nil
+}
+
+// Len returns the number of spec results in the set.
+func (resultSet *ResultSet) Len() int {
+	return len(resultSet.results)
+}
+
+// Lookup returns the result for the spec in the suite with the provided description. Results recorded under any of the
+// names the spec may have in a result file are merged together. If there is no exact match, it falls back to a match on
+// the spec text only, provided it is unambiguous. It returns nil if no result is found.
+func (resultSet *ResultSet) Lookup(suiteDescription string, spec *types.SpecReport) *SpecResult {
+	var exact, byText []*SpecResult
+
+	for _, text := range specTextVariants(spec) {
+		if result, ok := resultSet.results[resultKey{Suite: suiteDescription, Text: text}]; ok {
+			exact = append(exact, result)
+		}
+
+		if result := resultSet.byText[text]; result != nil {
+			byText = append(byText, result)
+		}
+	}
+
+	if len(exact) == 0 {
+		exact = byText
+	}
+
+	if len(exact) == 0 {
+		return nil
+	}
+
+	merged := *exact[0]
+	for _, result := range exact[1:] {
+		merged.merge(result)
+	}
+
+	return &merged
+}
+
+// ApplyResults annotates every leaf of the tree with its result from resultSet and recomputes the result summary of
+// every node. Leaves without a result have their Result cleared. It returns the number of leaves that were matched.
+func (tree *SuiteTree) ApplyResults(resultSet *ResultSet) int {
+	klog.V(100).Infof("Applying %d results to tree with path %s", resultSet.Len(), tree.Path)
+
+	return tree.applyResults(resultSet, tree.Description)
+}
+
+// applyResults is the recursive helper for ApplyResults. The suiteDescription is that of the nearest ancestor with a
+// description, since only suite nodes have descriptions.
+func (tree *SuiteTree) applyResults(resultSet *ResultSet, suiteDescription string) int {
+	if tree.Description != "" {
+		suiteDescription = tree.Description
+	}
+
+	if tree.SpecReport != nil {
+		tree.Result = resultSet.Lookup(suiteDescription, tree.SpecReport)
+		tree.Results = nil
+
+		if tree.Result == nil {
+			return 0
+		}
+
+		tree.Results = &ResultSummary{}
+		tree.Results.add(tree.Result)
+
+		return 1
+	}
+
+	matched := 0
+	tree.Results = nil
+
+	for _, child := range tree.Children {
+		matched += child.applyResults(resultSet, suiteDescription)
+
+		if child.Results == nil {
+			continue
+		}
+
+		if tree.Results == nil {
+			tree.Results = &ResultSummary{}
+		}
+
+		tree.Results.merge(child.Results)
+	}
+
+	return matched
+}
+
+// Total returns the total number of results included in the summary.
+func (summary *ResultSummary) Total() int {
+	return summary.Passed + summary.Failed + summary.Skipped + summary.Flaked + summary.Pending
+}
+
+// String returns a short, human-readable representation of the summary, omitting any states with a count of zero.
+func (summary *ResultSummary) String() string {
+	var parts []string
+
+	for _, count := range []struct {
+		state ResultState
+		n     int
+	}{
+		{ResultPassed, summary.Passed},
+		{ResultFailed, summary.Failed},
+		{ResultFlaked, summary.Flaked},
+		{ResultSkipped, summary.Skipped},
+		{ResultPending, summary.Pending},
+	} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.state))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// add includes a single result in the summary.
+func (summary *ResultSummary) add(result *SpecResult) {
+	switch result.State {
+	case ResultPassed:
+		summary.Passed++
+	case ResultFailed:
+		summary.Failed++
+	case ResultSkipped:
+		summary.Skipped++
+	case ResultFlaked:
+		summary.Flaked++
+	case ResultPending:
+		summary.Pending++
+	}
+
+	summary.RunTime += result.RunTime
+}
+
+// merge adds all the counts from other into the summary.
+func (summary *ResultSummary) merge(other *ResultSummary) {
+	summary.Passed += other.Passed
+	summary.Failed += other.Failed
+	summary.Skipped += other.Skipped
+	summary.Flaked += other.Flaked
+	summary.Pending += other.Pending
+	summary.RunTime += other.RunTime
+}
+
+// loadFile loads a single result file into the set based on its extension. The contents of the file are also written
+// to summer so the set can be identified.
+func (resultSet *ResultSet) loadFile(path string, summer io.Writer) error {
+	klog.V(100).Infof("Loading results from %s", path)
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = summer.Write(contents)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(path) {
+	case ".json":
+		return resultSet.loadGinkgoJSON(contents)
+	case ".xml":
+		return resultSet.loadXML(contents)
+	default:
+		return fmt.Errorf("unknown result file extension %q", filepath.Ext(path))
+	}
+}
+
+// loadGinkgoJSON loads results from a Ginkgo JSON report, which is the same format used for the dry run.
+func (resultSet *ResultSet) loadGinkgoJSON(contents []byte) error {
+	var reports []types.Report
+
+	err := json.Unmarshal(contents, &reports)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		for _, spec := range report.SpecReports.WithLeafNodeType(types.NodeTypeIt) {
+			result := &SpecResult{
+				State:          resultStateFromGinkgo(spec.State, spec.NumAttempts),
+				RunTime:        spec.RunTime,
+				FailureMessage: spec.Failure.Message,
+				Attempts:       max(spec.NumAttempts, 1),
+			}
+
+			resultSet.add(report.SuiteDescription, spec.FullText(), result)
+		}
+	}
+
+	return nil
+}
+
+// loadXML loads results from either a Ginkgo JUnit report, which has a testsuites root element, or a reportxml testrun
+// file, which has a testsuite root element.
+func (resultSet *ResultSet) loadXML(contents []byte) error {
+	var root struct {
+		XMLName xml.Name
+	}
+
+	err := xml.Unmarshal(contents, &root)
+	if err != nil {
+		return err
+	}
+
+	switch root.XMLName.Local {
+	case "testsuites":
+		var suites junitTestSuites
+
+		err := xml.Unmarshal(contents, &suites)
+		if err != nil {
+			return err
+		}
+
+		for _, suite := range suites.TestSuites {
+			resultSet.addJUnitSuite(suite)
+		}
+	case "testsuite":
+		var suite junitTestSuite
+
+		err := xml.Unmarshal(contents, &suite)
+		if err != nil {
+			return err
+		}
+
+		resultSet.addJUnitSuite(suite)
+	default:
+		return fmt.Errorf("unknown xml root element %q", root.XMLName.Local)
+	}
+
+	return nil
+}
+
+// addJUnitSuite adds all the test cases from a JUnit test suite. Since the reportxml format is a subset of the JUnit
+// format, this works for both. Test cases without a status are assumed to have passed unless they contain a failure or
+// skip element.
+func (resultSet *ResultSet) addJUnitSuite(suite junitTestSuite) {
+	for _, testCase := range suite.TestCases {
+		result := &SpecResult{
+			State:    ResultPassed,
+			RunTime:  time.Duration(testCase.Time * float64(time.Second)),
+			Attempts: 1,
+		}
+
+		switch {
+		case testCase.Failure != nil:
+			result.State = ResultFailed
+			result.FailureMessage = testCase.Failure.Message
+
+			if result.FailureMessage == "" {
+				result.FailureMessage = strings.TrimSpace(testCase.Failure.Description)
+			}
+		case testCase.Error != nil:
+			result.State = ResultFailed
+			result.FailureMessage = testCase.Error.Message
+		case testCase.Skipped != nil && (testCase.Status == "pending" || testCase.Skipped.Message == "pending"):
+			result.State = ResultPending
+		case testCase.Skipped != nil:
+			result.State = ResultSkipped
+			result.FailureMessage = testCase.Skipped.Message
+		}
+
+		resultSet.add(suite.Name, testCase.Name, result)
+	}
+}
+
+// add includes result in the set, merging it with any existing result for the same spec. A spec that both passed and
+// failed across result files is considered flaked.
+func (resultSet *ResultSet) add(suiteDescription, text string, result *SpecResult) {
+	key := resultKey{Suite: suiteDescription, Text: text}
+
+	existing, ok := resultSet.results[key]
+	if ok {
+		existing.merge(result)
+
+		return
+	}
+
+	resultSet.results[key] = result
+
+	if _, ok := resultSet.byText[text]; ok {
+		resultSet.byText[text] = nil
+	} else {
+		resultSet.byText[text] = result
+	}
+}
+
+// merge combines other into result. Skipped and pending results are overridden by any result from an actual run.
+func (result *SpecResult) merge(other *SpecResult) {
+	result.Attempts += other.Attempts
+	result.RunTime = max(result.RunTime, other.RunTime)
+
+	ran := func(state ResultState) bool {
+		return state != ResultSkipped && state != ResultPending
+	}
+
+	switch {
+	case !ran(other.State):
+		return
+	case !ran(result.State):
+		result.State = other.State
+		result.FailureMessage = other.FailureMessage
+	case result.State != other.State:
+		if result.State == ResultPassed {
+			result.FailureMessage = other.FailureMessage
+		}
+
+		result.State = ResultFlaked
+	}
+}
+
+// resultStateFromGinkgo collapses the Ginkgo spec state into a ResultState. Passing specs with more than one attempt
+// are considered flaked.
+func resultStateFromGinkgo(state types.SpecState, attempts int) ResultState {
+	switch {
+	case state.Is(types.SpecStatePassed) && attempts > 1:
+		return ResultFlaked
+	case state.Is(types.SpecStatePassed):
+		return ResultPassed
+	case state.Is(types.SpecStatePending):
+		return ResultPending
+	case state.Is(types.SpecStateSkipped):
+		return ResultSkipped
+	default:
+		return ResultFailed
+	}
+}
+
+// specTextVariants returns all the ways the provided spec may be named in a result file. Ginkgo JSON and reportxml use
+// the full text whereas Ginkgo JUnit prefixes the node type and appends labels.
+func specTextVariants(spec *types.SpecReport) []string {
+	fullText := spec.FullText()
+	junitText := fmt.Sprintf("[%s] %s", spec.LeafNodeType, fullText)
+	variants := []string{fullText, junitText}
+
+	if labels := spec.Labels(); len(labels) > 0 {
+		variants = append(variants, junitText+" ["+strings.Join(labels, ", ")+"]")
+	}
+
+	return variants
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSuiteDescription = "Test Suite"
+	testJUnitReport      = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5">
+  <testsuite name="Test Suite" tests="5">
+    <testcase name="[It] container passes [label]" status="passed" time="1.5"></testcase>
+    <testcase name="[It] container fails" status="failed" time="2">
+      <failure message="expected true" type="failed"></failure>
+    </testcase>
+    <testcase name="[It] container describes failure" status="failed" time="0">
+      <failure type="failed">
+        assertion failed
+      </failure>
+    </testcase>
+    <testcase name="[It] container errors" status="panicked" time="0">
+      <error message="panic" type="panicked"></error>
+    </testcase>
+    <testcase name="[It] container skips" status="skipped" time="0">
+      <skipped message="skipped - not supported"></skipped>
+    </testcase>
+    <testcase name="[It] container is pending" status="pending" time="0">
+      <skipped message="pending"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>`
+	testReportXMLRun = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="Test Suite" tests="2">
+  <testcase name="container passes"></testcase>
+  <testcase name="container fails">
+    <failure message="expected true"></failure>
+  </testcase>
+</testsuite>`
+)
+
+func TestLoadXML(t *testing.T) {
+	testCases := []struct {
+		name            string
+		contents        string
+		expectedResults map[resultKey]SpecResult
+		expectedError   bool
+	}{
+		{
+			name:     "junit-report",
+			contents: testJUnitReport,
+			expectedResults: map[resultKey]SpecResult{
+				{Suite: testSuiteDescription, Text: "[It] container passes [label]"}: {
+					State: ResultPassed, RunTime: 1500 * time.Millisecond, Attempts: 1,
+				},
+				{Suite: testSuiteDescription, Text: "[It] container fails"}: {
+					State: ResultFailed, RunTime: 2 * time.Second, FailureMessage: "expected true", Attempts: 1,
+				},
+				{Suite: testSuiteDescription, Text: "[It] container describes failure"}: {
+					State: ResultFailed, FailureMessage: "assertion failed", Attempts: 1,
+				},
+				{Suite: testSuiteDescription, Text: "[It] container errors"}: {
+					State: ResultFailed, FailureMessage: "panic", Attempts: 1,
+				},
+				{Suite: testSuiteDescription, Text: "[It] container skips"}: {
+					State: ResultSkipped, FailureMessage: "skipped - not supported", Attempts: 1,
+				},
+				{Suite: testSuiteDescription, Text: "[It] container is pending"}: {
+					State: ResultPending, Attempts: 1,
+				},
+			},
+		},
+		{
+			name:     "reportxml-testrun",
+			contents: testReportXMLRun,
+			expectedResults: map[resultKey]SpecResult{
+				{Suite: testSuiteDescription, Text: "container passes"}: {State: ResultPassed, Attempts: 1},
+				{Suite: testSuiteDescription, Text: "container fails"}: {
+					State: ResultFailed, FailureMessage: "expected true", Attempts: 1,
+				},
+			},
+		},
+		{
+			name:          "unknown-root",
+			contents:      `<results></results>`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid-xml",
+			contents:      `<testsuites>`,
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resultSet := newTestResultSet()
+
+			err := resultSet.loadXML([]byte(testCase.contents))
+			if testCase.expectedError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedResults, dereferenceResults(resultSet))
+		})
+	}
+}
+
+func TestLoadGinkgoJSON(t *testing.T) {
+	reports := []types.Report{{
+		SuiteDescription: testSuiteDescription,
+		SpecReports: types.SpecReports{
+			{
+				LeafNodeType: types.NodeTypeBeforeSuite,
+				State:        types.SpecStateFailed,
+			},
+			newTestSpecReport("passes", types.SpecStatePassed, 1),
+			newTestSpecReport("flakes", types.SpecStatePassed, 3),
+			newTestSpecReport("times out", types.SpecStateTimedout, 1),
+			newTestSpecReport("skips", types.SpecStateSkipped, 0),
+			newTestSpecReport("is pending", types.SpecStatePending, 0),
+		},
+	}}
+
+	contents, err := json.Marshal(reports)
+	assert.NoError(t, err)
+
+	resultSet := newTestResultSet()
+	err = resultSet.loadGinkgoJSON(contents)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[resultKey]SpecResult{
+		{Suite: testSuiteDescription, Text: "container passes"}: {
+			State: ResultPassed, RunTime: time.Second, Attempts: 1,
+		},
+		{Suite: testSuiteDescription, Text: "container flakes"}: {
+			State: ResultFlaked, RunTime: time.Second, Attempts: 3,
+		},
+		{Suite: testSuiteDescription, Text: "container times out"}: {
+			State: ResultFailed, RunTime: time.Second, FailureMessage: "times out failed", Attempts: 1,
+		},
+		{Suite: testSuiteDescription, Text: "container skips"}: {
+			State: ResultSkipped, RunTime: time.Second, FailureMessage: "skips failed", Attempts: 1,
+		},
+		{Suite: testSuiteDescription, Text: "container is pending"}: {
+			State: ResultPending, RunTime: time.Second, FailureMessage: "is pending failed", Attempts: 1,
+		},
+	}, dereferenceResults(resultSet))
+
+	err = resultSet.loadGinkgoJSON([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestSpecResultMerge(t *testing.T) {
+	testCases := []struct {
+		name     string
+		result   SpecResult
+		other    SpecResult
+		expected SpecResult
+	}{
+		{
+			name:     "passed-then-passed",
+			result:   SpecResult{State: ResultPassed, RunTime: time.Second, Attempts: 1},
+			other:    SpecResult{State: ResultPassed, RunTime: 2 * time.Second, Attempts: 1},
+			expected: SpecResult{State: ResultPassed, RunTime: 2 * time.Second, Attempts: 2},
+		},
+		{
+			name:   "passed-then-failed",
+			result: SpecResult{State: ResultPassed, RunTime: 2 * time.Second, Attempts: 1},
+			other:  SpecResult{State: ResultFailed, RunTime: time.Second, FailureMessage: "failed", Attempts: 1},
+			expected: SpecResult{
+				State: ResultFlaked, RunTime: 2 * time.Second, FailureMessage: "failed", Attempts: 2,
+			},
+		},
+		{
+			name:     "failed-then-passed",
+			result:   SpecResult{State: ResultFailed, FailureMessage: "failed", Attempts: 1},
+			other:    SpecResult{State: ResultPassed, Attempts: 1},
+			expected: SpecResult{State: ResultFlaked, FailureMessage: "failed", Attempts: 2},
+		},
+		{
+			name:     "skipped-then-passed",
+			result:   SpecResult{State: ResultSkipped, FailureMessage: "skipped", Attempts: 1},
+			other:    SpecResult{State: ResultPassed, RunTime: time.Second, Attempts: 1},
+			expected: SpecResult{State: ResultPassed, RunTime: time.Second, Attempts: 2},
+		},
+		{
+			name:     "failed-then-pending",
+			result:   SpecResult{State: ResultFailed, FailureMessage: "failed", Attempts: 1},
+			other:    SpecResult{State: ResultPending, Attempts: 1},
+			expected: SpecResult{State: ResultFailed, FailureMessage: "failed", Attempts: 2},
+		},
+		{
+			name:     "pending-then-skipped",
+			result:   SpecResult{State: ResultPending, Attempts: 1},
+			other:    SpecResult{State: ResultSkipped, FailureMessage: "skipped", Attempts: 1},
+			expected: SpecResult{State: ResultPending, Attempts: 2},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.result.merge(&testCase.other)
+			assert.Equal(t, testCase.expected, testCase.result)
+		})
+	}
+}
+
+func TestResultSetLookup(t *testing.T) {
+	spec := &types.SpecReport{
+		ContainerHierarchyTexts:  []string{"container"},
+		ContainerHierarchyLabels: [][]string{{"outer"}},
+		LeafNodeType:             types.NodeTypeIt,
+		LeafNodeText:             "passes",
+		LeafNodeLabels:           []string{"inner"},
+	}
+
+	testCases := []struct {
+		name     string
+		suite    string
+		add      func(resultSet *ResultSet)
+		expected *SpecResult
+	}{
+		{
+			name:  "full-text",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add(testSuiteDescription, "container passes", &SpecResult{State: ResultPassed, Attempts: 1})
+			},
+			expected: &SpecResult{State: ResultPassed, Attempts: 1},
+		},
+		{
+			name:  "junit-text",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add(testSuiteDescription, "[It] container passes", &SpecResult{State: ResultPassed, Attempts: 1})
+			},
+			expected: &SpecResult{State: ResultPassed, Attempts: 1},
+		},
+		{
+			name:  "junit-text-with-labels",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add(testSuiteDescription, "[It] container passes [outer, inner]",
+					&SpecResult{State: ResultPassed, Attempts: 1})
+			},
+			expected: &SpecResult{State: ResultPassed, Attempts: 1},
+		},
+		{
+			name:  "variants-merged",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add(testSuiteDescription, "container passes", &SpecResult{State: ResultPassed, Attempts: 1})
+				resultSet.add(testSuiteDescription, "[It] container passes [outer, inner]",
+					&SpecResult{State: ResultFailed, FailureMessage: "failed", Attempts: 1})
+			},
+			expected: &SpecResult{State: ResultFlaked, FailureMessage: "failed", Attempts: 2},
+		},
+		{
+			name:  "unambiguous-text-other-suite",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add("Other Suite", "container passes", &SpecResult{State: ResultPassed, Attempts: 1})
+			},
+			expected: &SpecResult{State: ResultPassed, Attempts: 1},
+		},
+		{
+			name:  "ambiguous-text-other-suites",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add("Other Suite", "container passes", &SpecResult{State: ResultPassed, Attempts: 1})
+				resultSet.add("Another Suite", "container passes", &SpecResult{State: ResultFailed, Attempts: 1})
+			},
+			expected: nil,
+		},
+		{
+			name:  "exact-preferred-over-text",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add(testSuiteDescription, "container passes", &SpecResult{State: ResultPassed, Attempts: 1})
+				resultSet.add("Other Suite", "container passes", &SpecResult{State: ResultFailed, Attempts: 1})
+			},
+			expected: &SpecResult{State: ResultPassed, Attempts: 1},
+		},
+		{
+			name:  "different-text",
+			suite: testSuiteDescription,
+			add: func(resultSet *ResultSet) {
+				resultSet.add(testSuiteDescription, "container fails", &SpecResult{State: ResultFailed, Attempts: 1})
+			},
+			expected: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resultSet := newTestResultSet()
+			testCase.add(resultSet)
+
+			assert.Equal(t, testCase.expected, resultSet.Lookup(testCase.suite, spec))
+		})
+	}
+}
+
+func TestLoadResults(t *testing.T) {
+	dir := t.TempDir()
+	reports := []types.Report{{
+		SuiteDescription: testSuiteDescription,
+		SpecReports:      types.SpecReports{newTestSpecReport("passes", types.SpecStatePassed, 1)},
+	}}
+
+	contents, err := json.Marshal(reports)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "report.json"), contents, 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "junit.xml"), []byte(testJUnitReport), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "report.txt"), []byte("text"), 0o600))
+
+	testCases := []struct {
+		name          string
+		patterns      []string
+		expectedLen   int
+		expectedError bool
+	}{
+		{
+			name:        "json-and-xml",
+			patterns:    []string{filepath.Join(dir, "*.json"), filepath.Join(dir, "*.xml")},
+			expectedLen: 7,
+		},
+		{
+			name:        "no-matches",
+			patterns:    []string{filepath.Join(dir, "*.yaml")},
+			expectedLen: 0,
+		},
+		{
+			name:          "unknown-extension",
+			patterns:      []string{filepath.Join(dir, "*.txt")},
+			expectedError: true,
+		},
+		{
+			name:          "bad-pattern",
+			patterns:      []string{"["},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resultSet, err := LoadResults(testCase.patterns)
+			if testCase.expectedError {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedLen, resultSet.Len())
+			assert.Len(t, resultSet.ID, 64)
+		})
+	}
+
+	first, err := LoadResults([]string{filepath.Join(dir, "*.json")})
+	assert.NoError(t, err)
+
+	second, err := LoadResults([]string{filepath.Join(dir, "*.json")})
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+
+	combined, err := LoadResults([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "*.xml")})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, combined.ID)
+}
+
+func TestApplyResults(t *testing.T) {
+	labeledSpec := newTestSpecReport("passes", types.SpecStatePassed, 1)
+	labeledSpec.LeafNodeLabels = []string{"label"}
+
+	tree := NewFromReports([]types.Report{{
+		SuitePath:        "/repo/tests/suite",
+		SuiteDescription: testSuiteDescription,
+		PreRunStats:      types.PreRunStats{TotalSpecs: 3},
+		SpecReports: types.SpecReports{
+			labeledSpec,
+			newTestSpecReport("fails", types.SpecStatePassed, 1),
+			newTestSpecReport("has no result", types.SpecStatePassed, 1),
+		},
+	}})
+
+	resultSet := newTestResultSet()
+	assert.NoError(t, resultSet.loadXML([]byte(testJUnitReport)))
+
+	matched := tree.ApplyResults(resultSet)
+	assert.Equal(t, 2, matched)
+	assert.Equal(t, &ResultSummary{Passed: 1, Failed: 1, RunTime: 3500 * time.Millisecond}, tree.Results)
+	assert.Equal(t, "1 passed, 1 failed", tree.Results.String())
+	assert.Equal(t, 2, tree.Results.Total())
+
+	for _, leaf := range tree.Leaves() {
+		if leaf.SpecReport.LeafNodeText == "has no result" {
+			assert.Nil(t, leaf.Result)
+			assert.Nil(t, leaf.Results)
+		}
+	}
+}
+
+// newTestResultSet returns an empty ResultSet ready for results to be added.
+func newTestResultSet() *ResultSet {
+	return &ResultSet{
+		results: make(map[resultKey]*SpecResult),
+		byText:  make(map[string]*SpecResult),
+	}
+}
+
+// newTestSpecReport returns an It spec report in a single container with the provided leaf text, state, and number of
+// attempts. Each spec runs for one second and, unless it passed, has a failure message derived from its text.
+func newTestSpecReport(text string, state types.SpecState, attempts int) types.SpecReport {
+	spec := types.SpecReport{
+		ContainerHierarchyTexts: []string{"container"},
+		LeafNodeType:            types.NodeTypeIt,
+		LeafNodeText:            text,
+		State:                   state,
+		NumAttempts:             attempts,
+		RunTime:                 time.Second,
+	}
+
+	if !state.Is(types.SpecStatePassed) {
+		spec.Failure.Message = text + " failed"
+	}
+
+	return spec
+}
+
+// dereferenceResults returns a copy of the results in resultSet with the pointers dereferenced so they can be compared
+// by value.
+func dereferenceResults(resultSet *ResultSet) map[resultKey]SpecResult {
+	results := make(map[resultKey]SpecResult, len(resultSet.results))
+
+	for key, result := range resultSet.results {
+		results[key] = *result
+	}
+
+	return results
+}
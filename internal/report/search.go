@@ -0,0 +1,98 @@
+package main
+
+import (
+	"html/template"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// MaxSearchResults is the maximum number of results returned by a single search.
+const MaxSearchResults = 500
+
+// SearchResult is a single spec matching a search.
+type SearchResult struct {
+	Branch string
+	Spec   SpecInfo
+	// SourceURL is the link to the spec at the revision of the branch. It is empty if no link can be created.
+	SourceURL template.URL
+}
+
+// SearchTrees returns the specs in all the trees whose text, labels, or reportxml IDs contain every whitespace-separated
+// term in query, ignoring case. Results are sorted by branch, then suite, then text, and at most maxResults are
+// returned. If there were more matches, truncated is true. An empty query matches nothing.
+func SearchTrees(
+	treeMap map[CacheKey]*SuiteTree, query string, maxResults int) (results []SearchResult, truncated bool) {
+	klog.V(100).Infof("Searching %d trees for %q", len(treeMap), query)
+
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, false
+	}
+
+	keys := slices.SortedFunc(maps.Keys(treeMap), func(keyA, keyB CacheKey) int {
+		return strings.Compare(keyA.Branch, keyB.Branch)
+	})
+
+	for _, key := range keys {
+		tree := treeMap[key]
+		linker := SourceLinker{RepoURL: RemoteURL, Revision: key.Revision}
+
+		if key == LocalKey {
+			linker.Revision = ""
+		}
+
+		var branchResults []SearchResult
+
+		for suite, leaf := range tree.Leaves() {
+			spec := NewSpecInfo(tree, suite, leaf)
+			if !matchesTerms(spec, terms) {
+				continue
+			}
+
+			location := leaf.SpecReport.LeafNodeLocation
+			branchResults = append(branchResults, SearchResult{
+				Branch:    key.Branch,
+				Spec:      spec,
+				SourceURL: linker.FileURL(location.FileName, location.LineNumber),
+			})
+		}
+
+		slices.SortFunc(branchResults, func(resultA, resultB SearchResult) int {
+			return compareSpecInfos(resultA.Spec, resultB.Spec)
+		})
+
+		results = append(results, branchResults...)
+	}
+
+	if len(results) > maxResults {
+		return results[:maxResults], true
+	}
+
+	return results, false
+}
+
+// matchesTerms returns true if every term is contained in the text, a label, or an ID of spec. Terms must already be
+// lowercase.
+func matchesTerms(spec SpecInfo, terms []string) bool {
+	fields := make([]string, 0, 1+len(spec.Labels)+len(spec.IDs))
+	fields = append(fields, strings.ToLower(spec.Text))
+
+	for _, label := range spec.Labels {
+		fields = append(fields, strings.ToLower(label))
+	}
+
+	for _, id := range spec.IDs {
+		fields = append(fields, strings.ToLower(id))
+	}
+
+	for _, term := range terms {
+		if !slices.ContainsFunc(fields, func(field string) bool { return strings.Contains(field, term) }) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// WatchInterval is how often the watched directory is checked for changes when serving.
+	WatchInterval = 2 * time.Second
+	// serverTimeout is the timeout for reading request headers and for shutting down the server.
+	serverTimeout = 10 * time.Second
+)
+
+// Server serves the report for a set of trees over HTTP. Pages are templated on every request from the most recently
+// built trees, so rebuilding the trees updates the report without restarting the server.
+type Server struct {
+	build     func() (map[CacheKey]*SuiteTree, error)
+	mutex     sync.RWMutex
+	treeMap   map[CacheKey]*SuiteTree
+	generated time.Time
+}
+
+// NewServer creates a new Server that uses build to build the trees. The trees are built once before returning.
+func NewServer(build func() (map[CacheKey]*SuiteTree, error)) (*Server, error) {
+	server := &Server{build: build}
+
+	err := server.Rebuild()
+	if err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// Rebuild builds the trees again and replaces the served trees. If building fails, the previous trees continue to be
+// served and the error is returned.
+func (server *Server) Rebuild() error {
+	klog.V(100).Info("Rebuilding served trees")
+
+	treeMap, err := server.build()
+	if err != nil {
+		return err
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.treeMap = treeMap
+	server.generated = time.Now()
+
+	return nil
+}
+
+// Watch polls dir every interval and rebuilds the trees whenever any file under it is added, removed, or modified. It
+// blocks until ctx is cancelled. Rebuild errors are logged rather than stopping the watch.
+func (server *Server) Watch(ctx context.Context, dir string, interval time.Duration) {
+	klog.V(100).Infof("Watching %s for changes every %s", dir, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastSnapshot, err := snapshotDir(dir)
+	if err != nil {
+		klog.Errorf("Failed to snapshot %s for watching: %v", dir, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		snapshot, err := snapshotDir(dir)
+		if err != nil {
+			klog.Errorf("Failed to snapshot %s for watching: %v", dir, err)
+
+			continue
+		}
+
+		if snapshot == lastSnapshot {
+			continue
+		}
+
+		lastSnapshot = snapshot
+
+		klog.Infof("Files under %s changed, rebuilding", dir)
+
+		err = server.Rebuild()
+		if err != nil {
+			klog.Errorf("Failed to rebuild trees: %v", err)
+		}
+	}
+}
+
+// ListenAndServe serves the report on addr until ctx is cancelled, at which point the server is shut down.
+func (server *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: serverTimeout,
+	}
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	klog.Infof("Serving report on http://%s", addr)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverTimeout)
+	defer cancel()
+
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	err = <-errChan
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Handler returns the handler for all the pages of the report. The main page is at /, the tree and index of each branch
+// are at /tree/<branch> and /index/<branch>, and search is at /search?q=<query>.
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", server.handleReport)
+	mux.HandleFunc("GET /tree/{branch...}", server.handleTree)
+	mux.HandleFunc("GET /index/{branch...}", server.handleIndex)
+	mux.HandleFunc("GET /search", server.handleSearch)
+
+	return mux
+}
+
+// handleReport serves the main page linking to the tree and index of every branch.
+func (server *Server) handleReport(writer http.ResponseWriter, _ *http.Request) {
+	treeMap, generated := server.getTrees()
+
+	var branchReports []BranchReportConfig
+
+	for key := range treeMap {
+		branchReports = append(branchReports, BranchReportConfig{
+			Name:          key.Branch,
+			ReportFile:    treeURL(key.Branch),
+			IndexFile:     indexURL(key.Branch),
+			Revision:      key.Revision,
+			ShortRevision: key.ShortRevision(),
+		})
+	}
+
+	config := ReportTemplateConfig{
+		BranchReports: branchReports,
+		SearchURL:     "/search",
+		Generated:     generated,
+		ActionURL:     "/",
+		RepoURL:       RemoteURL,
+		TimeFormat:    time.RFC3339,
+	}
+
+	writeResponse(writer, ExecuteReport(writer, config))
+}
+
+// handleTree serves the tree for the branch in the request path.
+func (server *Server) handleTree(writer http.ResponseWriter, request *http.Request) {
+	key, tree, generated, ok := server.findTree(request.PathValue("branch"))
+	if !ok {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	config := TreeTemplateConfig{
+		Tree:       tree,
+		Generated:  generated,
+		Branch:     key.Branch,
+		Revision:   key.Revision,
+		IndexFile:  indexURL(key.Branch),
+		ActionURL:  "/",
+		RepoURL:    RemoteURL,
+		TimeFormat: time.RFC3339,
+	}
+
+	if key == LocalKey {
+		config.Revision = ""
+	}
+
+	writeResponse(writer, ExecuteTree(writer, config))
+}
+
+// handleIndex serves the label and ID index for the branch in the request path.
+func (server *Server) handleIndex(writer http.ResponseWriter, request *http.Request) {
+	key, tree, generated, ok := server.findTree(request.PathValue("branch"))
+	if !ok {
+		http.NotFound(writer, request)
+
+		return
+	}
+
+	config := IndexTemplateConfig{
+		Index:      NewTreeIndex(tree),
+		Generated:  generated,
+		Branch:     key.Branch,
+		ReportFile: treeURL(key.Branch),
+		ActionURL:  "/",
+		RepoURL:    RemoteURL,
+		TimeFormat: time.RFC3339,
+	}
+
+	writeResponse(writer, ExecuteIndex(writer, config))
+}
+
+// handleSearch serves the results of searching all trees for the q query parameter.
+func (server *Server) handleSearch(writer http.ResponseWriter, request *http.Request) {
+	treeMap, generated := server.getTrees()
+	query := request.URL.Query().Get("q")
+	results, truncated := SearchTrees(treeMap, query, MaxSearchResults)
+
+	config := SearchTemplateConfig{
+		Query:      query,
+		Results:    results,
+		Truncated:  truncated,
+		ReportURL:  "/",
+		Generated:  generated,
+		RepoURL:    RemoteURL,
+		TimeFormat: time.RFC3339,
+	}
+
+	writeResponse(writer, ExecuteSearch(writer, config))
+}
+
+// getTrees returns the current trees and when they were built.
+func (server *Server) getTrees() (map[CacheKey]*SuiteTree, time.Time) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	return server.treeMap, server.generated
+}
+
+// findTree returns the current tree for branch, along with its key and when it was built. If there is no such branch,
+// ok is false.
+func (server *Server) findTree(branch string) (key CacheKey, tree *SuiteTree, generated time.Time, ok bool) {
+	treeMap, generated := server.getTrees()
+
+	for treeKey, branchTree := range treeMap {
+		if treeKey.Branch == branch {
+			return treeKey, branchTree, generated, true
+		}
+	}
+
+	return CacheKey{}, nil, generated, false
+}
+
+// writeResponse logs err if it is not nil. Since the template may have already written part of the response, the
+// status code cannot be changed, so an error message is appended instead.
+func writeResponse(writer http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+
+	klog.Errorf("Failed to template response: %v", err)
+
+	_, _ = fmt.Fprintf(writer, "\n%s\n", template.HTMLEscapeString(err.Error()))
+}
+
+// treeURL returns the URL of the tree page for branch.
+func treeURL(branch string) string {
+	return "/tree/" + escapeBranch(branch)
+}
+
+// indexURL returns the URL of the index page for branch.
+func indexURL(branch string) string {
+	return "/index/" + escapeBranch(branch)
+}
+
+// escapeBranch escapes each element of branch for use in a URL path while keeping the slashes between them.
+func escapeBranch(branch string) string {
+	elements := strings.Split(branch, "/")
+
+	for i, element := range elements {
+		elements[i] = url.PathEscape(element)
+	}
+
+	return strings.Join(elements, "/")
+}
+
+// snapshotDir returns a sum of the path, size, and modification time of every regular file under dir. The sum changes
+// whenever a file is added, removed, or modified.
+func snapshotDir(dir string) (string, error) {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !dirEntry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return err
+		}
+
+		files[path] = fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	summer := sha256.New()
+
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		_, _ = fmt.Fprintf(summer, "%s %s\n", path, files[path])
+	}
+
+	return fmt.Sprintf("%x", summer.Sum(nil)), nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// moduleFiles are the files at the root of the repo that affect every package. Missing files are skipped.
+var moduleFiles = []string{"go.mod", "go.sum", "vendor/modules.txt"}
+
+// SuiteSource identifies a single suite and the state of all the source code it depends on.
+type SuiteSource struct {
+	// Path is the directory of the suite relative to the root of the repo.
+	Path string
+	// Hash is the sha256 of the module files and every file in the suite directory and the directories of its
+	// transitive imports from the same repo. If it is unchanged, then so is the dry run report for the suite.
+	Hash string
+}
+
+// ListSuiteSources returns every package with tests under ./tests in the repo at repoPath, sorted by path. Packages
+// from vendor are covered by the module files rather than by hashing their directories.
+func ListSuiteSources(ctx context.Context, repoPath string) ([]SuiteSource, error) {
+	klog.V(100).Infof("Listing suite sources in %s", repoPath)
+
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := ListTestPackages(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	packageDirs := make(map[string]string)
+
+	for _, goPackage := range packages {
+		packageDirs[goPackage.ImportPath] = goPackage.Dir
+	}
+
+	moduleSummer := sha256.New()
+
+	for _, moduleFile := range moduleFiles {
+		err := hashFile(moduleSummer, filepath.Join(absRepoPath, moduleFile))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	moduleSum := moduleSummer.Sum(nil)
+	dirSums := make(map[string][]byte)
+
+	var suites []SuiteSource
+
+	for _, goPackage := range packages {
+		if !strings.HasSuffix(goPackage.ImportPath, ".test") {
+			continue
+		}
+
+		localDirs := []string{goPackage.Dir}
+
+		for _, dep := range goPackage.Deps {
+			dir := packageDirs[dep]
+			if isLocalDir(absRepoPath, dir) {
+				localDirs = append(localDirs, dir)
+			}
+		}
+
+		slices.Sort(localDirs)
+		localDirs = slices.Compact(localDirs)
+
+		summer := sha256.New()
+		summer.Write(moduleSum)
+
+		for _, dir := range localDirs {
+			dirSum, ok := dirSums[dir]
+			if !ok {
+				dirSum, err = hashDir(dir)
+				if err != nil {
+					return nil, err
+				}
+
+				dirSums[dir] = dirSum
+			}
+
+			relDir, err := filepath.Rel(absRepoPath, dir)
+			if err != nil {
+				return nil, err
+			}
+
+			_, _ = io.WriteString(summer, filepath.ToSlash(relDir)+"\n")
+			summer.Write(dirSum)
+		}
+
+		suitePath, err := filepath.Rel(absRepoPath, goPackage.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		suites = append(suites, SuiteSource{Path: suitePath, Hash: hex.EncodeToString(summer.Sum(nil))})
+	}
+
+	slices.SortFunc(suites, func(suiteA, suiteB SuiteSource) int {
+		return strings.Compare(suiteA.Path, suiteB.Path)
+	})
+
+	klog.V(100).Infof("Found %d suites in %s", len(suites), repoPath)
+
+	return suites, nil
+}
+
+// isLocalDir returns true if dir is inside the repo at absRepoPath but not in its vendor directory.
+func isLocalDir(absRepoPath, dir string) bool {
+	relDir, err := filepath.Rel(absRepoPath, dir)
+	if err != nil || relDir == ".." || strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	return relDir != "vendor" && !strings.HasPrefix(relDir, "vendor"+string(filepath.Separator))
+}
+
+// hashDir returns the sha256 of the names and contents of all the regular files directly in dir. Subdirectories are
+// not included since they are separate packages.
+func hashDir(dir string) ([]byte, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	summer := sha256.New()
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() {
+			continue
+		}
+
+		_, _ = io.WriteString(summer, dirEntry.Name()+"\n")
+
+		err := hashFile(summer, filepath.Join(dir, dirEntry.Name()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summer.Sum(nil), nil
+}
+
+// hashFile writes the contents of the file at path to summer.
+func hashFile(summer hash.Hash, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(summer, file)
+
+	return err
+}
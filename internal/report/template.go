@@ -2,7 +2,9 @@ package main
 
 import (
 	_ "embed"
+	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 	"slices"
@@ -16,14 +18,32 @@ var (
 
 	//go:embed report_template.html
 	reportTemplateFile string
+
+	//go:embed diff_template.html
+	diffTemplateFile string
+
+	//go:embed index_template.html
+	indexTemplateFile string
+
+	//go:embed search_template.html
+	searchTemplateFile string
 )
 
 var (
 	funcMap = template.FuncMap{
-		"cleanPath": cleanPath,
+		"cleanPath": CleanPath,
+		"dict":      dict,
+		"dirURL":    SourceLinker{}.DirURL,
+		"fileURL":   SourceLinker{}.FileURL,
+		"join":      strings.Join,
+		"percent":   percent,
+		"topSpecs":  func() int { return TopSpecs },
 	}
 	treeTemplate   = template.Must(template.New("tree_template.html").Funcs(funcMap).Parse(treeTemplateFile))
 	reportTemplate = template.Must(template.New("report_template.html").Parse(reportTemplateFile))
+	diffTemplate   = template.Must(template.New("diff_template.html").Funcs(funcMap).Parse(diffTemplateFile))
+	indexTemplate  = template.Must(template.New("index_template.html").Parse(indexTemplateFile))
+	searchTemplate = template.Must(template.New("search_template.html").Parse(searchTemplateFile))
 )
 
 // TreeTemplateConfig contains the data necessary to template a single SuiteTree into an html report.
@@ -31,19 +51,85 @@ type TreeTemplateConfig struct {
 	Tree       *SuiteTree
 	Generated  time.Time
 	Branch     string
+	Revision   string
+	IndexFile  string
 	ActionURL  template.URL
 	RepoURL    template.URL
 	TimeFormat string
 }
 
-// TemplateTree uses config to generate a SuiteTree report and save it at outputFileName.
+// TemplateTree uses config to generate a SuiteTree report and save it at outputFileName. Source links point to files at
+// config.Revision in config.RepoURL and are omitted if the revision is empty.
 func TemplateTree(config TreeTemplateConfig, outputFileName string) error {
-	return executeTemplateAndSave(treeTemplate, config, outputFileName)
+	tmpl, err := linkedTreeTemplate(config)
+	if err != nil {
+		return err
+	}
+
+	return executeTemplateAndSave(tmpl, config, outputFileName)
+}
+
+// ExecuteTree is the same as TemplateTree but writes the report to writer instead of a file.
+func ExecuteTree(writer io.Writer, config TreeTemplateConfig) error {
+	tmpl, err := linkedTreeTemplate(config)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(writer, config)
+}
+
+// SourceLinker creates links to files and directories in the repo at a single revision. The zero value creates no
+// links, which lets templates be parsed before the revision is known.
+type SourceLinker struct {
+	RepoURL  string
+	Revision string
+}
+
+// FileURL returns the URL of line in the file at path, or of the whole file if line is not positive. The path may be
+// relative to the root of the repo or absolute so long as it contains the eco-gotests directory, as described in
+// [CleanPath]. It returns an empty URL if no link can be created.
+func (linker SourceLinker) FileURL(path string, line int) template.URL {
+	base := linker.sourceURL("blob", path)
+	if base == "" || line <= 0 {
+		return base
+	}
+
+	return template.URL(fmt.Sprintf("%s#L%d", base, line))
+}
+
+// DirURL returns the URL of the directory at path, following the same rules as [SourceLinker.FileURL].
+func (linker SourceLinker) DirURL(path string) template.URL {
+	return linker.sourceURL("tree", path)
 }
 
-// ReportTemplateConfig contains the data necessary to generate a report linking to multiple templated SuiteTrees.
+// sourceURL returns the URL of path under the provided kind, either blob for files or tree for directories.
+func (linker SourceLinker) sourceURL(kind, path string) template.URL {
+	if linker.RepoURL == "" || linker.Revision == "" {
+		return ""
+	}
+
+	relativePath, found := strings.CutPrefix(CleanPath(path), "eco-gotests")
+	if !found {
+		if filepath.IsAbs(path) {
+			return ""
+		}
+
+		relativePath = path
+	}
+
+	relativePath = strings.TrimPrefix(filepath.ToSlash(relativePath), "/")
+	repoURL := strings.TrimSuffix(linker.RepoURL, ".git")
+
+	return template.URL(fmt.Sprintf("%s/%s/%s/%s", repoURL, kind, linker.Revision, relativePath))
+}
+
+// ReportTemplateConfig contains the data necessary to generate a report linking to multiple templated SuiteTrees. The
+// search form is only included if SearchURL is not empty.
 type ReportTemplateConfig struct {
 	BranchReports []BranchReportConfig
+	DiffReports   []DiffReportConfig
+	SearchURL     string
 	Generated     time.Time
 	ActionURL     template.URL
 	RepoURL       template.URL
@@ -54,20 +140,103 @@ type ReportTemplateConfig struct {
 type BranchReportConfig struct {
 	Name          string
 	ReportFile    string
+	IndexFile     string
 	Revision      string
 	ShortRevision string
 }
 
+// IndexTemplateConfig contains the data necessary to template the TreeIndex for a single branch into an html report.
+type IndexTemplateConfig struct {
+	Index      *TreeIndex
+	Generated  time.Time
+	Branch     string
+	ReportFile string
+	ActionURL  template.URL
+	RepoURL    template.URL
+	TimeFormat string
+}
+
+// TemplateIndex uses config to generate a TreeIndex report and save it at outputFileName.
+func TemplateIndex(config IndexTemplateConfig, outputFileName string) error {
+	return executeTemplateAndSave(indexTemplate, config, outputFileName)
+}
+
+// ExecuteIndex is the same as TemplateIndex but writes the report to writer instead of a file.
+func ExecuteIndex(writer io.Writer, config IndexTemplateConfig) error {
+	return indexTemplate.Execute(writer, config)
+}
+
+// SearchTemplateConfig contains the data necessary to template the results of a search across all trees.
+type SearchTemplateConfig struct {
+	Query      string
+	Results    []SearchResult
+	Truncated  bool
+	ReportURL  string
+	Generated  time.Time
+	RepoURL    template.URL
+	TimeFormat string
+}
+
+// ExecuteSearch uses config to generate the search page and writes it to writer.
+func ExecuteSearch(writer io.Writer, config SearchTemplateConfig) error {
+	return searchTemplate.Execute(writer, config)
+}
+
+// DiffReportConfig contains the data necessary to include a single templated TreeDiff in the main report.
+type DiffReportConfig struct {
+	Name       string
+	ReportFile string
+}
+
+// DiffTemplateConfig contains the data necessary to template a single TreeDiff into an html report.
+type DiffTemplateConfig struct {
+	Diff       *TreeDiff
+	Generated  time.Time
+	ActionURL  template.URL
+	RepoURL    template.URL
+	TimeFormat string
+}
+
+// TemplateDiff uses config to generate a TreeDiff report and save it at outputFileName.
+func TemplateDiff(config DiffTemplateConfig, outputFileName string) error {
+	return executeTemplateAndSave(diffTemplate, config, outputFileName)
+}
+
 // TemplateReport uses config to generate a report linking to multiple SuiteTree reports and save it at outputFileName.
 // Branch reports will be sorted lexicographically ascending by branch name.
 func TemplateReport(config ReportTemplateConfig, outputFileName string) error {
-	slices.SortFunc(config.BranchReports, func(a, b BranchReportConfig) int {
-		return strings.Compare(a.Name, b.Name)
-	})
+	sortBranchReports(config.BranchReports)
 
 	return executeTemplateAndSave(reportTemplate, config, outputFileName)
 }
 
+// ExecuteReport is the same as TemplateReport but writes the report to writer instead of a file.
+func ExecuteReport(writer io.Writer, config ReportTemplateConfig) error {
+	sortBranchReports(config.BranchReports)
+
+	return reportTemplate.Execute(writer, config)
+}
+
+// linkedTreeTemplate returns a copy of the tree template whose source link functions use the repo URL and revision from
+// config.
+func linkedTreeTemplate(config TreeTemplateConfig) (*template.Template, error) {
+	tmpl, err := treeTemplate.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	linker := SourceLinker{RepoURL: string(config.RepoURL), Revision: config.Revision}
+
+	return tmpl.Funcs(template.FuncMap{"dirURL": linker.DirURL, "fileURL": linker.FileURL}), nil
+}
+
+// sortBranchReports sorts the branch reports lexicographically ascending by branch name.
+func sortBranchReports(branchReports []BranchReportConfig) {
+	slices.SortFunc(branchReports, func(a, b BranchReportConfig) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
 // executeTemplateAndSave creates a file at outputFileName before executing tmpl with data provided by config. If
 // outputFileName already exists, then it is truncated.
 func executeTemplateAndSave(tmpl *template.Template, config any, outputFileName string) error {
@@ -86,9 +255,9 @@ func executeTemplateAndSave(tmpl *template.Template, config any, outputFileName
 	return nil
 }
 
-// cleanPath cleans the provided path of anything preceding the eco-gotests directory. This is useful to template paths
+// CleanPath cleans the provided path of anything preceding the eco-gotests directory. This is useful to template paths
 // to be relative to the repo root rather than / on the machine that generated the report.
-func cleanPath(path string) string {
+func CleanPath(path string) string {
 	pathElements := strings.Split(path, string(os.PathSeparator))
 	for i, element := range pathElements {
 		if element == "eco-gotests" {
@@ -98,3 +267,29 @@ func cleanPath(path string) string {
 
 	return path
 }
+
+// dict creates a map from alternating keys and values. It allows passing multiple values to a nested template. Keys
+// must be strings and there must be an even number of arguments.
+func dict(keysAndValues ...any) (map[string]any, error) {
+	if len(keysAndValues)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments, got %d", len(keysAndValues))
+	}
+
+	result := make(map[string]any, len(keysAndValues)/2)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", keysAndValues[i])
+		}
+
+		result[key] = keysAndValues[i+1]
+	}
+
+	return result, nil
+}
+
+// percent formats a fraction between 0 and 1 as a percentage with one decimal place.
+func percent(fraction float64) string {
+	return fmt.Sprintf("%.1f%%", fraction*100)
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"cmp"
 	"encoding/json"
+	"fmt"
 	"io"
+	"iter"
 	"os"
 	"path"
 	"slices"
@@ -29,6 +31,49 @@ type SuiteTree struct {
 	// SpecReport should only be set when Children is empty, meaning this is a leaf node representing a single spec.
 	// It should only have It specs.
 	SpecReport *types.SpecReport
+	// Result is the result of running the spec. It is only set on leaf nodes by [SuiteTree.ApplyResults].
+	Result *SpecResult `json:",omitempty"`
+	// Results is the rollup of all results for specs under this node. It is nil if no results have been applied.
+	Results *ResultSummary `json:",omitempty"`
+	// Trend is the summary of past results of the spec. It is only set on leaf nodes by [SuiteTree.ApplyHistory].
+	Trend *SpecTrend `json:",omitempty"`
+	// Owners are the approvers and reviewers from the nearest OWNERS file. It is only set on suite nodes by
+	// [SuiteTree.ApplyOwners].
+	Owners *Owners `json:",omitempty"`
+}
+
+// SpecInfo is a flattened view of a single spec in a SuiteTree, containing the information needed to list specs outside
+// of the tree.
+type SpecInfo struct {
+	// Suite is the path of the suite containing this spec relative to the root of the tree.
+	Suite string
+	// Text is the full text of the spec, including the text of all its containers.
+	Text string
+	// Location is the file and line of the spec, relative to the root of the repo.
+	Location string
+	// Labels are all the labels of the spec, including those inherited from containers, sorted lexicographically.
+	Labels []string
+	// IDs are the reportxml IDs of the spec, sorted lexicographically.
+	IDs []string
+}
+
+// NewSpecInfo creates a SpecInfo for the leaf node, which is a child of the suite node, in the tree with the provided
+// root.
+func NewSpecInfo(root, suite, leaf *SuiteTree) SpecInfo {
+	labels := slices.Clone(leaf.SpecReport.Labels())
+	slices.Sort(labels)
+
+	ids := leaf.ReportXMLIDs()
+	slices.Sort(ids)
+
+	return SpecInfo{
+		Suite: strings.TrimPrefix(strings.TrimPrefix(suite.Path, root.Path), "/"),
+		Text:  leaf.SpecReport.FullText(),
+		Location: fmt.Sprintf("%s:%d",
+			CleanPath(leaf.SpecReport.LeafNodeLocation.FileName), leaf.SpecReport.LeafNodeLocation.LineNumber),
+		Labels: labels,
+		IDs:    ids,
+	}
 }
 
 // NewFromReports creates a new SuiteTree from a list of reports. The root of the tree will be `/`.
@@ -50,7 +95,17 @@ func NewFromReports(reports []types.Report) *SuiteTree {
 
 // NewFromFile creates a new SuiteTree from a Ginkgo report file. The root of the tree will be `/`.
 func NewFromFile(path string) (*SuiteTree, error) {
-	klog.V(100).Infof("Creating SuiteTree from Ginkgo JSON report at path %s", path)
+	reports, err := LoadReports(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromReports(reports), nil
+}
+
+// LoadReports reads the list of reports from a Ginkgo JSON report file.
+func LoadReports(path string) ([]types.Report, error) {
+	klog.V(100).Infof("Loading reports from Ginkgo JSON report at path %s", path)
 
 	file, err := os.Open(path)
 	if err != nil {
@@ -71,7 +126,7 @@ func NewFromFile(path string) (*SuiteTree, error) {
 		return nil, err
 	}
 
-	return NewFromReports(reports), nil
+	return reports, nil
 }
 
 // Insert adds a new suite to the tree as a leaf node and returns the node that was added. It will return nil if the
@@ -175,7 +230,7 @@ func (tree *SuiteTree) TrimRoot() *SuiteTree {
 }
 
 // String returns a string representation of the tree. It contains one line per node and is indented with a dot and two
-// spaces per level.
+// spaces per level. If results have been applied, each line also contains the summary of results for that node.
 func (tree *SuiteTree) String() string {
 	builder := &strings.Builder{}
 	tree.stringLevel(builder, 0)
@@ -193,6 +248,13 @@ func (tree *SuiteTree) stringLevel(builder *strings.Builder, level uint) {
 	builder.WriteString(tree.Name)
 	builder.WriteByte(' ')
 	builder.WriteString(strconv.Itoa(tree.Specs))
+
+	if tree.Results != nil {
+		builder.WriteString(" (")
+		builder.WriteString(tree.Results.String())
+		builder.WriteByte(')')
+	}
+
 	builder.WriteByte('\n')
 
 	for _, child := range tree.Children {
@@ -200,6 +262,48 @@ func (tree *SuiteTree) stringLevel(builder *strings.Builder, level uint) {
 	}
 }
 
+// Leaves returns an iterator over all the leaf nodes of the tree that represent specs, along with the suite node that
+// is the direct parent of each leaf. Leaves are yielded in depth-first order.
+func (tree *SuiteTree) Leaves() iter.Seq2[*SuiteTree, *SuiteTree] {
+	return func(yield func(suite, leaf *SuiteTree) bool) {
+		tree.yieldLeaves(tree, yield)
+	}
+}
+
+// ReportXMLIDs returns the reportxml IDs of the spec represented by this node. IDs are found through the labels added
+// by reportxml.ID, which have the form test_id:<ID>. It returns nil if the node is not a leaf or has no IDs.
+func (tree *SuiteTree) ReportXMLIDs() []string {
+	if tree.SpecReport == nil {
+		return nil
+	}
+
+	var ids []string
+
+	for _, label := range tree.SpecReport.Labels() {
+		if id, found := strings.CutPrefix(label, ReportXMLIDPrefix); found {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// yieldLeaves is the recursive helper for Leaves. It returns false once yield returns false so iteration can stop
+// early.
+func (tree *SuiteTree) yieldLeaves(parent *SuiteTree, yield func(suite, leaf *SuiteTree) bool) bool {
+	if tree.SpecReport != nil {
+		return yield(parent, tree)
+	}
+
+	for _, child := range tree.Children {
+		if !child.yieldLeaves(tree, yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // findChild returns the child with the given name or nil if no child with that name exists. It only searches direct
 // children of the tree.
 func (tree *SuiteTree) findChild(name string) *SuiteTree {
@@ -50,6 +50,13 @@ func NewAccelConfig() *AccelConfig {
 		return nil
 	}
 
+	err = config.ReadProfiles(&accelConfig)
+	if err != nil {
+		log.Printf("failed to read profiles for AccelConfig: %v", err)
+
+		return nil
+	}
+
 	err = envconfig.Process("eco_accel_", &accelConfig)
 	if err != nil {
 		log.Printf("failed to instantiate AccelConfig: %v", err)
@@ -1,8 +1,12 @@
 package accelinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/accel/internal/accelconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 )
 
@@ -15,8 +19,23 @@ var (
 	AccelConfig *accelconfig.AccelConfig
 )
 
+// init registers setup so the accel config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("accel", setup)
+}
+
+// setup loads the accel config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	HubAPIClient = inittools.APIClient
+
 	AccelConfig = accelconfig.NewAccelConfig()
+	if AccelConfig == nil {
+		return errors.New("failed to load accel config")
+	}
+
 	SpokeAPIClient = AccelConfig.SpokeAPIClient
+
+	return errors.Join(
+		clusterregistry.RegisterClientIfSet("hub", clusterregistry.RoleHub, HubAPIClient),
+		clusterregistry.RegisterClientIfSet("spoke", clusterregistry.RoleSpoke, SpokeAPIClient))
 }
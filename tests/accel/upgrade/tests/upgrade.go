@@ -25,7 +25,6 @@ import (
 var (
 	waitToUpgradeStart     = 5 * time.Minute
 	waitToUpgradeCompleted = 130 * time.Minute
-	desiredUpgradeChannel  = "stable-4." + AccelConfig.HubMinorVersion
 )
 
 var _ = Describe("OCP_UPGRADE", Ordered, Label("minor"), func() {
@@ -42,6 +41,7 @@ var _ = Describe("OCP_UPGRADE", Ordered, Label("minor"), func() {
 			workloadRoute := startTestWorkloadAndGetRoute()
 
 			By("Patch the clusterversion with the desired upgrade channel")
+			desiredUpgradeChannel := "stable-4." + AccelConfig.HubMinorVersion
 			klog.V(90).Infof("this is the desired upgrade channel: %+v", desiredUpgradeChannel)
 
 			if desiredUpgradeChannel == "stable-4." {
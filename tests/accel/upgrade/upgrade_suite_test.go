@@ -12,15 +12,26 @@ import (
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/accel/internal/accelinittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/accel/upgrade/internal/upgradeparams"
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/accel/upgrade/tests"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 )
 
 var (
 	_, currentFile, _, _ = runtime.Caller(0)
-	testNS               = namespace.NewBuilder(HubAPIClient, upgradeparams.TestNamespaceName)
+	testNS               *namespace.Builder
 )
 
 func TestUpgrade(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
+	testNS = namespace.NewBuilder(HubAPIClient, upgradeparams.TestNamespaceName)
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = AccelConfig.GetJunitReportPath(currentFile)
 
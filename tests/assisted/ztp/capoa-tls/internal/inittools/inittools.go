@@ -1,9 +1,11 @@
 package inittools
 
 import (
+	"context"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
-	"k8s.io/klog/v2"
+	generalinittools "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 )
 
 var (
@@ -13,16 +15,15 @@ var (
 	GeneralConfig *config.GeneralConfig
 )
 
+// init registers setup so the vars of this package are set by inittools.Setup rather than on import.
 func init() {
-	if GeneralConfig = config.NewConfig(); GeneralConfig == nil {
-		klog.Fatalf("failed to initialize GeneralConfig: config.NewConfig returned nil")
-	}
+	generalinittools.RegisterSetup("capoa-tls", setup)
+}
 
-	if HubAPIClient = clients.New(""); HubAPIClient == nil {
-		if GeneralConfig.DryRun {
-			return
-		}
+// setup copies the general config and hub API client loaded by inittools.Setup.
+func setup(context.Context) error {
+	GeneralConfig = generalinittools.GeneralConfig
+	HubAPIClient = generalinittools.APIClient
 
-		klog.Fatalf("failed to initialize HubAPIClient: clients.New returned nil")
-	}
+	return nil
 }
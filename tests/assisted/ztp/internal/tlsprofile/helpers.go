@@ -2,7 +2,6 @@ package tlsprofile
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -10,6 +9,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/apiservers"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
 )
 
 // SetAPIServerTLSProfile applies the given TLS security profile to the cluster APIServer.
@@ -47,38 +47,10 @@ func RemoveAPIServerTLSProfile(client *clients.Settings) {
 func WaitForClusterStability(client *clients.Settings, timeout time.Duration) {
 	By("Waiting for cluster operators to stabilize")
 
-	Eventually(func() string {
-		coList := &configv1.ClusterOperatorList{}
-
-		err := client.List(context.TODO(), coList)
-		if err != nil {
-			return fmt.Sprintf("failed to list cluster operators: %v", err)
-		}
-
-		for i := range coList.Items {
-			operator := &coList.Items[i]
-			available := false
-			progressing := true
-			degraded := true
-
-			for _, cond := range operator.Status.Conditions {
-				switch cond.Type { //nolint:exhaustive
-				case configv1.OperatorAvailable:
-					available = cond.Status == configv1.ConditionTrue
-				case configv1.OperatorProgressing:
-					progressing = cond.Status == configv1.ConditionTrue
-				case configv1.OperatorDegraded:
-					degraded = cond.Status == configv1.ConditionTrue
-				}
-			}
-
-			if !available || progressing || degraded {
-				return fmt.Sprintf("operator %s: available=%v progressing=%v degraded=%v",
-					operator.Name, available, progressing, degraded)
-			}
-		}
-
-		return ""
-	}).WithTimeout(timeout).WithPolling(15*time.Second).
-		Should(BeEmpty(), "cluster operators should be stable")
+	_, err := cluster.NewStabilityGate(client).
+		WithChecks(cluster.StabilityCheckClusterOperators).
+		WithSettleWindow(0).
+		WithPollInterval(15*time.Second).
+		Wait(context.TODO(), timeout)
+	Expect(err).ToNot(HaveOccurred(), "cluster operators should be stable")
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/assisted/ztp/internal/find"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/assisted/ztp/internal/ztpparams"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"k8s.io/klog/v2"
 )
@@ -28,6 +29,8 @@ type ZTPConfig struct {
 }
 
 // HubConfig contains environment information related to the hub cluster.
+//
+//nolint:lll
 type HubConfig struct {
 	HubAPIClient               *clients.Settings
 	HubOCPVersion              string
@@ -38,7 +41,7 @@ type HubConfig struct {
 	HubPullSecret              *secret.Builder
 	HubInstallConfig           *configmap.Builder
 	HubPullSecretOverride      map[string][]byte
-	HubPullSecretOverridePath  string `envconfig:"ECO_ASSISTED_ZTP_HUB_PULL_SECRET_OVERRIDE_PATH"`
+	HubPullSecretOverridePath  string `yaml:"ztp_hub_pull_secret_override_path" envconfig:"ECO_ASSISTED_ZTP_HUB_PULL_SECRET_OVERRIDE_PATH"`
 }
 
 // SpokeConfig contains environment information related to the spoke cluster.
@@ -47,8 +50,8 @@ type SpokeConfig struct {
 	SpokeOCPVersion          string
 	SpokeOCPXYVersion        string
 	SpokeClusterName         string
-	SpokeKubeConfig          string `envconfig:"ECO_ASSISTED_ZTP_SPOKE_KUBECONFIG"`
-	SpokeClusterImageSet     string `envconfig:"ECO_ASSISTED_ZTP_SPOKE_CLUSTERIMAGESET"`
+	SpokeKubeConfig          string `yaml:"ztp_spoke_kubeconfig" envconfig:"ECO_ASSISTED_ZTP_SPOKE_KUBECONFIG"`
+	SpokeClusterImageSet     string `yaml:"ztp_spoke_clusterimageset" envconfig:"ECO_ASSISTED_ZTP_SPOKE_CLUSTERIMAGESET"`
 	SpokeClusterDeployment   *hive.ClusterDeploymentBuilder
 	SpokeAgentClusterInstall *assisted.AgentClusterInstallBuilder
 	SpokeInfraEnv            *assisted.InfraEnvBuilder
@@ -87,7 +90,12 @@ func (ztpconfig *ZTPConfig) newHubConfig() error {
 
 	ztpconfig.HubConfig = new(HubConfig)
 
-	err := envconfig.Process("eco_assisted_ztp_hub_", ztpconfig.HubConfig)
+	err := config.ReadProfiles(ztpconfig.HubConfig)
+	if err != nil {
+		klog.V(ztpparams.ZTPLogLevel).Infof("failed to read profiles for HubConfig: %v", err)
+	}
+
+	err = envconfig.Process("eco_assisted_ztp_hub_", ztpconfig.HubConfig)
 	if err != nil {
 		klog.V(ztpparams.ZTPLogLevel).Infof("failed to instantiate HubConfig: %v", err)
 	}
@@ -158,7 +166,14 @@ func (ztpconfig *ZTPConfig) newHubConfig() error {
 func (ztpconfig *ZTPConfig) newSpokeConfig() error {
 	klog.V(ztpparams.ZTPLogLevel).Info("Creating new SpokeConfig struct")
 
-	err := envconfig.Process("eco_assisted_ztp_spoke_", ztpconfig.SpokeConfig)
+	err := config.ReadProfiles(ztpconfig.SpokeConfig)
+	if err != nil {
+		klog.V(ztpparams.ZTPLogLevel).Infof("failed to read profiles for SpokeConfig: %v", err)
+
+		return err
+	}
+
+	err = envconfig.Process("eco_assisted_ztp_spoke_", ztpconfig.SpokeConfig)
 	if err != nil {
 		klog.V(ztpparams.ZTPLogLevel).Infof("failed to instantiate SpokeConfig: %v", err)
 
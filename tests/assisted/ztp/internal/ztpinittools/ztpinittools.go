@@ -1,8 +1,13 @@
 package ztpinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/assisted/ztp/internal/ztpconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 )
 
 var (
@@ -14,8 +19,22 @@ var (
 	ZTPConfig *ztpconfig.ZTPConfig
 )
 
+// init registers setup so the ZTP config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ztp", setup)
+}
+
+// setup loads the ZTP config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	ZTPConfig = ztpconfig.NewZTPConfig()
+	if ZTPConfig == nil {
+		return errors.New("failed to load ZTP config")
+	}
+
 	HubAPIClient = ZTPConfig.HubAPIClient
 	SpokeAPIClient = ZTPConfig.SpokeAPIClient
+
+	return errors.Join(
+		clusterregistry.RegisterClientIfSet("hub", clusterregistry.RoleHub, HubAPIClient),
+		clusterregistry.RegisterClientIfSet("spoke", clusterregistry.RoleSpoke, SpokeAPIClient))
 }
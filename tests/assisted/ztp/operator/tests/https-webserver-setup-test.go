@@ -36,7 +36,6 @@ var (
 	newAgentServiceConfig *assisted.AgentServiceConfigBuilder
 	httpPodBuilder        *pod.Builder
 	testOSImage           v1beta1.OSImage
-	version               string = ZTPConfig.HubOCPXYVersion
 	httpsPVC              *storage.PVCBuilder
 	httpsPVCMode          = corev1.PersistentVolumeFilesystem
 )
@@ -135,7 +134,7 @@ var _ = Describe(
 				var imageName string
 
 				for _, image := range ZTPConfig.HubAgentServiceConfig.Object.Spec.OSImages {
-					if image.OpenshiftVersion == version {
+					if image.OpenshiftVersion == ZTPConfig.HubOCPXYVersion {
 						testOSImage = image
 						splitURL := strings.Split(testOSImage.Url, "/")
 						imageName = splitURL[len(splitURL)-1]
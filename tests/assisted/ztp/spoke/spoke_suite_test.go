@@ -12,12 +12,21 @@ import (
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/assisted/ztp/internal/ztpinittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/assisted/ztp/spoke/internal/tsparams"
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/assisted/ztp/spoke/tests"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 )
 
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestSpoke(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = ZTPConfig.GetJunitReportPath(currentFile)
 
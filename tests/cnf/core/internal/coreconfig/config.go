@@ -8,6 +8,7 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/internal/cnfconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"gopkg.in/yaml.v2"
 )
 
@@ -71,7 +72,12 @@ func readFile(coreConfig *CoreConfig, cfgFile string) error {
 }
 
 func readEnv(coreConfig *CoreConfig) error {
-	err := envconfig.Process("", coreConfig)
+	err := config.ReadProfiles(coreConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", coreConfig)
 	if err != nil {
 		return err
 	}
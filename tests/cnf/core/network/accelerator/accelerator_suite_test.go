@@ -14,16 +14,27 @@ import (
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/accelerator/tests"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/internal/netenv"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/internal/netinittools"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/params"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 )
 
 var (
 	_, currentFile, _, _ = runtime.Caller(0)
-	testNS               = namespace.NewBuilder(APIClient, tsparams.TestNamespaceName)
+	testNS               *namespace.Builder
 )
 
 func TestAccelerator(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
+	testNS = namespace.NewBuilder(APIClient, tsparams.TestNamespaceName)
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = NetConfig.GetJunitReportPath(currentFile)
 
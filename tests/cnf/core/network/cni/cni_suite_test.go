@@ -12,16 +12,27 @@ import (
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/cni/tests/tap"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/internal/netinittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/params"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 )
 
 var (
 	_, currentFile, _, _ = runtime.Caller(0)
-	testNS               = namespace.NewBuilder(APIClient, tsparams.TestNamespaceName)
+	testNS               *namespace.Builder
 )
 
 func TestLB(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
+	testNS = namespace.NewBuilder(APIClient, tsparams.TestNamespaceName)
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = NetConfig.GetJunitReportPath(currentFile)
 
@@ -53,7 +64,7 @@ var _ = AfterSuite(func() {
 
 var _ = JustAfterEach(func() {
 	reporter.ReportIfFailed(
-		CurrentSpecReport(), currentFile, tsparams.ReporterNamespacesToDump, tsparams.ReporterCRDsToDump)
+		CurrentSpecReport(), currentFile, tsparams.ReporterNamespacesToDump(), tsparams.ReporterCRDsToDump)
 })
 
 var _ = ReportAfterSuite("", func(report Report) {
@@ -14,12 +14,15 @@ var (
 	LabelTapTestCases = "tap"
 	// TestNamespaceName cni namespace where all test cases are performed.
 	TestNamespaceName = "cni-tests"
-	// ReporterNamespacesToDump tells to the reporter from where to collect logs.
-	ReporterNamespacesToDump = map[string]string{
-		NetConfig.MultusNamesapce: NetConfig.MultusNamesapce,
-		TestNamespaceName:         "other",
-	}
 	// ReporterCRDsToDump tells to the reporter what CRs to dump.
 	ReporterCRDsToDump = []k8sreporter.CRData{
 		{Cr: &nadv1.NetworkAttachmentDefinitionList{}}}
 )
+
+// ReporterNamespacesToDump tells to the reporter from where to collect logs.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
+		NetConfig.MultusNamesapce: NetConfig.MultusNamesapce,
+		TestNamespaceName:         "other",
+	}
+}
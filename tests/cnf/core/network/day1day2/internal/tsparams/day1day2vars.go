@@ -24,11 +24,13 @@ var (
 		{Cr: &nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}},
 		{Cr: &nmstatev1beta1.NodeNetworkStateList{}},
 	}
+)
 
-	// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
-	ReporterNamespacesToDump = map[string]string{
+// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
 		NetConfig.NMStateOperatorNamespace: NetConfig.NMStateOperatorNamespace,
 		TestNamespaceName:                  "other",
 		"openshift-nmstate":                "nmstate operator",
 	}
-)
+}
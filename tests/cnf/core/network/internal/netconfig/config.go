@@ -12,6 +12,7 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/internal/coreconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"gopkg.in/yaml.v2"
 )
 
@@ -289,7 +290,12 @@ func readFile(netConfig *NetworkConfig, cfgFile string) error {
 }
 
 func readEnv(netConfig *NetworkConfig) error {
-	err := envconfig.Process("", netConfig)
+	err := config.ReadProfiles(netConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", netConfig)
 	if err != nil {
 		return err
 	}
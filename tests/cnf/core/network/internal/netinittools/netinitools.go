@@ -1,6 +1,9 @@
 package netinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/internal/netconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
@@ -13,9 +16,19 @@ var (
 	NetConfig *netconfig.NetworkConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the network config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("network", setup)
+}
+
+// setup loads the network config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	NetConfig = netconfig.NewNetConfig()
+	if NetConfig == nil {
+		return errors.New("failed to load network config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
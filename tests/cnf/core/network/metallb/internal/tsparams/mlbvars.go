@@ -17,29 +17,6 @@ var (
 	// Labels represents the range of labels that can be used for test cases selection.
 	Labels = append(netparam.Labels, LabelSuite)
 
-	// ReporterNamespacesToDump tells to the reporter from where to collect logs.
-	ReporterNamespacesToDump = map[string]string{
-		"openshift-performance-addon-operator": "performance",
-		NetConfig.MlbOperatorNamespace:         "metallb-system",
-		TestNamespaceName:                      "other",
-		"openshift-nmstate":                    "nmstate operator",
-	}
-
-	// ReporterCRDsToDump tells to the reporter what CRs to dump.
-	ReporterCRDsToDump = []k8sreporter.CRData{
-		{Cr: setUnstructured(metallb.IPAddressPoolList)},
-		{Cr: setUnstructured("L2AdvertisementList")},
-		{Cr: setUnstructured(metallb.BGPAdvertisementListKind)},
-		{Cr: setUnstructured(metallb.BFDProfileList)},
-		{Cr: setUnstructured(metallb.BGPPeerListKind)},
-		{Cr: setUnstructured(metallb.MetalLBList)},
-		{Cr: setUnstructured("FRRConfigurationList")},
-		{Cr: &appsv1.DaemonSetList{}, Namespace: &NetConfig.MlbOperatorNamespace},
-		{Cr: &appsv1.DeploymentList{}, Namespace: &NetConfig.Frrk8sNamespace},
-		{Cr: &nadv1.NetworkAttachmentDefinitionList{}, Namespace: &TestNamespaceName},
-		{Cr: &corev1.ConfigMapList{}, Namespace: &TestNamespaceName},
-		{Cr: &corev1.ServiceList{}, Namespace: &TestNamespaceName},
-	}
 	// TestNamespaceName metalLb namespace where all test cases are performed.
 	TestNamespaceName = "metallb-tests"
 	// OperatorControllerManager defaults metalLb daemonset controller name.
@@ -132,3 +109,31 @@ func setUnstructured(kind string) *unstructured.UnstructuredList {
 
 	return resource
 }
+
+// ReporterNamespacesToDump tells to the reporter from where to collect logs.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
+		"openshift-performance-addon-operator": "performance",
+		NetConfig.MlbOperatorNamespace:         "metallb-system",
+		TestNamespaceName:                      "other",
+		"openshift-nmstate":                    "nmstate operator",
+	}
+}
+
+// ReporterCRDsToDump tells to the reporter what CRs to dump.
+func ReporterCRDsToDump() []k8sreporter.CRData {
+	return []k8sreporter.CRData{
+		{Cr: setUnstructured(metallb.IPAddressPoolList)},
+		{Cr: setUnstructured("L2AdvertisementList")},
+		{Cr: setUnstructured(metallb.BGPAdvertisementListKind)},
+		{Cr: setUnstructured(metallb.BFDProfileList)},
+		{Cr: setUnstructured(metallb.BGPPeerListKind)},
+		{Cr: setUnstructured(metallb.MetalLBList)},
+		{Cr: setUnstructured("FRRConfigurationList")},
+		{Cr: &appsv1.DaemonSetList{}, Namespace: &NetConfig.MlbOperatorNamespace},
+		{Cr: &appsv1.DeploymentList{}, Namespace: &NetConfig.Frrk8sNamespace},
+		{Cr: &nadv1.NetworkAttachmentDefinitionList{}, Namespace: &TestNamespaceName},
+		{Cr: &corev1.ConfigMapList{}, Namespace: &TestNamespaceName},
+		{Cr: &corev1.ServiceList{}, Namespace: &TestNamespaceName},
+	}
+}
@@ -20,8 +20,19 @@ var (
 	Labels = append(netparam.Labels, LabelSuite)
 	// DefaultTimeout represents the default timeout for most of Eventually/PollImmediate functions.
 	DefaultTimeout = 300 * time.Second
-	// ReporterCRDsToDump tells to the reporter what CRs to dump.
-	ReporterCRDsToDump = []k8sreporter.CRData{
+)
+
+// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
+		NetConfig.NMStateOperatorNamespace: NetConfig.NMStateOperatorNamespace,
+		TestNamespaceName:                  "other",
+	}
+}
+
+// ReporterCRDsToDump tells to the reporter what CRs to dump.
+func ReporterCRDsToDump() []k8sreporter.CRData {
+	return []k8sreporter.CRData{
 		{Cr: &mcfgv1.MachineConfigPoolList{}},
 		{Cr: &nmstateV1.NMStateList{}},
 		{Cr: &nmstateV1.NodeNetworkConfigurationPolicyList{}},
@@ -31,9 +42,4 @@ var (
 		{Cr: &sriovv1.SriovNetworkNodePolicyList{}},
 		{Cr: &corev1.NodeList{}},
 	}
-	// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
-	ReporterNamespacesToDump = map[string]string{
-		NetConfig.NMStateOperatorNamespace: NetConfig.NMStateOperatorNamespace,
-		TestNamespaceName:                  "other",
-	}
-)
+}
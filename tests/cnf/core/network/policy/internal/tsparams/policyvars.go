@@ -18,12 +18,6 @@ var (
 	Labels = append(netparam.Labels, LabelSuite)
 	// DefaultTimeout represents the default timeout for most of Eventually/PollImmediate functions.
 	DefaultTimeout = 300 * time.Second
-	// ReporterNamespacesToDump tells to the reporter from where to collect logs.
-	ReporterNamespacesToDump = map[string]string{
-		NetConfig.SriovOperatorNamespace: NetConfig.SriovOperatorNamespace,
-		NetConfig.MultusNamesapce:        NetConfig.MultusNamesapce,
-		TestNamespaceName:                "other",
-	}
 	// ReporterCRDsToDump tells to the reporter what CRs to dump.
 	ReporterCRDsToDump = []k8sreporter.CRData{
 		{Cr: &mcfgv1.MachineConfigPoolList{}},
@@ -76,3 +70,12 @@ type PodsData map[string]struct {
 	Protocols []string
 	Ports     []string
 }
+
+// ReporterNamespacesToDump tells to the reporter from where to collect logs.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
+		NetConfig.SriovOperatorNamespace: NetConfig.SriovOperatorNamespace,
+		NetConfig.MultusNamesapce:        NetConfig.MultusNamesapce,
+		TestNamespaceName:                "other",
+	}
+}
@@ -42,12 +42,6 @@ var (
 		{Cr: &nmstateV1beta1.NodeNetworkConfigurationEnactmentList{}},
 	}
 
-	// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
-	ReporterNamespacesToDump = map[string]string{
-		NetConfig.SriovOperatorNamespace: NetConfig.SriovOperatorNamespace,
-		TestNamespaceName:                "other",
-		"openshift-nmstate":              "nmstate operator",
-	}
 	// ClientIPv4IPAddress represents the IPv4 address (with CIDR range) for test pods.
 	ClientIPv4IPAddress = "192.168.0.1/24"
 	// ServerIPv4IPAddress represents the IPv4 address (with CIDR range) for test pods.
@@ -113,3 +107,12 @@ var (
 	// OperatorSriovDaemonsets represents all default SR-IOV operator daemonset names.
 	OperatorSriovDaemonsets = []string{OperatorConfigDaemon, OperatorWebhook, OperatorResourceInjector}
 )
+
+// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
+		NetConfig.SriovOperatorNamespace: NetConfig.SriovOperatorNamespace,
+		TestNamespaceName:                "other",
+		"openshift-nmstate":              "nmstate operator",
+	}
+}
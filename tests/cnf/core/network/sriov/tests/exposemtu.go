@@ -5,6 +5,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/resourcetracker"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/sriovoperator"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/namespace"
@@ -196,6 +197,7 @@ func testExposeMTU(mtu int, interfacesUnderTest []string, devType, workerName st
 		tsparams.MCOWaitTimeout,
 		tsparams.DefaultStableDuration)
 	Expect(err).ToNot(HaveOccurred(), "Failed to configure SR-IOV policy")
+	Expect(resourcetracker.Track(sriovPolicy)).To(Succeed(), "Failed to track SR-IOV policy")
 
 	By("Creating SR-IOV network")
 
@@ -206,6 +208,7 @@ func testExposeMTU(mtu int, interfacesUnderTest []string, devType, workerName st
 	Expect(err).ToNot(HaveOccurred(),
 		"Failed to create and wait for NAD creation for Sriov Network %s with error %v",
 		sriovAndResourceNameExposeMTU, err)
+	Expect(resourcetracker.Track(sriovNetworkBuilder)).To(Succeed(), "Failed to track SR-IOV network")
 
 	By("Creating test pod")
 
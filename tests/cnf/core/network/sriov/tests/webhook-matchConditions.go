@@ -23,6 +23,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/sriov/internal/sriovenv"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/core/network/sriov/internal/tsparams"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/resourcetracker"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/sriovoperator"
 	admv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -55,21 +56,23 @@ var _ = Describe("webhook-resource-injector", Ordered, Label(tsparams.LabelWebho
 
 			By("Creating SriovNetworkNodePolicy and SriovNetwork")
 
+			sriovPolicy := definePolicy("client", "netdevice", "", sriovInterfacesUnderTest[0], 0)
 			err = sriovoperator.CreateSriovPolicyAndWaitUntilItsApplied(
 				APIClient,
 				NetConfig.WorkerLabelEnvVar,
 				NetConfig.SriovOperatorNamespace,
-				definePolicy(
-					"client", "netdevice", "", sriovInterfacesUnderTest[0], 0),
+				sriovPolicy,
 				tsparams.MCOWaitTimeout,
 				tsparams.DefaultStableDuration)
 			Expect(err).ToNot(HaveOccurred(), "Failed to create SriovNetworkNodePolicy")
+			Expect(resourcetracker.Track(sriovPolicy)).To(Succeed(), "Failed to track SriovNetworkNodePolicy")
 
 			sriovNetworkBuilder := defineNetwork("client", "netdevice")
 			err = sriovenv.CreateSriovNetworkAndWaitForNADCreation(sriovNetworkBuilder, tsparams.NADWaitTimeout)
 			Expect(err).ToNot(HaveOccurred(),
 				"Failed to create and wait for NAD creation for Sriov Network %s with error %v",
 				sriovNetworkBuilder.Definition.Name, err)
+			Expect(resourcetracker.Track(sriovNetworkBuilder)).To(Succeed(), "Failed to track SriovNetwork")
 		})
 
 		AfterAll(func() {
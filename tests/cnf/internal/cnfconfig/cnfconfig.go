@@ -71,7 +71,12 @@ func readFile(cnfConfig *CNFConfig, cfgFile string) error {
 }
 
 func readEnv(cnfConfig *CNFConfig) error {
-	err := envconfig.Process("", cnfConfig)
+	err := config.ReadProfiles(cnfConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", cnfConfig)
 	if err != nil {
 		return err
 	}
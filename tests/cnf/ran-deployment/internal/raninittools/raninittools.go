@@ -1,8 +1,13 @@
 package raninittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran-deployment/internal/ranconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 )
 
 var (
@@ -16,9 +21,24 @@ var (
 	RANConfig *ranconfig.RANConfig
 )
 
+// init registers setup so the RAN deployment config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ran-deployment", setup)
+}
+
+// setup loads the RAN deployment config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	RANConfig = ranconfig.NewRANConfig()
+	if RANConfig == nil {
+		return errors.New("failed to load RAN deployment config")
+	}
+
 	HubAPIClient = RANConfig.HubAPIClient
 	Spoke1APIClient = RANConfig.Spoke1APIClient
 	Spoke2APIClient = RANConfig.Spoke2APIClient
+
+	return errors.Join(
+		clusterregistry.RegisterClientIfSet("hub", clusterregistry.RoleHub, HubAPIClient),
+		clusterregistry.RegisterClientIfSet("spoke1", clusterregistry.RoleSpoke, Spoke1APIClient),
+		clusterregistry.RegisterClientIfSet("spoke2", clusterregistry.RoleSpoke, Spoke2APIClient))
 }
@@ -10,11 +10,19 @@ import (
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/containernshide/internal/tsparams"
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/containernshide/tests"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/raninittools"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 )
 
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestContainerNsHide(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = RANConfig.GetJunitReportPath(currentFile)
 
@@ -25,50 +25,6 @@ var (
 	// Labels represents the range of labels that can be used for test cases selection.
 	Labels = append(ranparam.Labels, LabelSuite)
 
-	// ReporterHubNamespacesToDump tells to the reporter which namespaces on the hub to collect pod logs from.
-	ReporterHubNamespacesToDump = map[string]string{
-		TestNamespace:                       "",
-		ranparam.OpenshiftOperatorNamespace: "",
-		RANConfig.AcmOperatorNamespace:      "",
-	}
-
-	// ReporterSpokeNamespacesToDump tells the reporter which namespaces on the spokes to collect pod logs from.
-	ReporterSpokeNamespacesToDump = map[string]string{
-		TestNamespace:                    "",
-		kacv1.KlusterletAddonNamespace:   "",
-		RANConfig.SriovOperatorNamespace: "",
-	}
-
-	// ReporterHubCRsToDump is the CRs the reporter should dump on the hub.
-	ReporterHubCRsToDump = []k8sreporter.CRData{
-		{Cr: &corev1.NamespaceList{}},
-		{Cr: &corev1.PodList{}},
-		{Cr: &clusterv1.ManagedClusterList{}},
-		{Cr: &policiesv1.PolicyList{}},
-		{Cr: &placementrulev1.PlacementRuleList{}, Namespace: ptr.To(TestNamespace)},
-		{Cr: &cguv1alpha1.ClusterGroupUpgradeList{}},
-		{Cr: &corev1.ConfigMapList{}, Namespace: ptr.To(TestNamespace)},
-		{Cr: &corev1.SecretList{}, Namespace: ptr.To(TestNamespace)},
-		{Cr: &argocdoperator.ArgoCDList{}},
-		{Cr: &v1alpha1.ApplicationList{}},
-		{Cr: &siteconfigv1alpha1.ClusterInstanceList{}, Namespace: ptr.To(RANConfig.Spoke1Name)},
-	}
-
-	// ReporterSpokeCRsToDump is the CRs the reporter should dump on the spokes.
-	ReporterSpokeCRsToDump = []k8sreporter.CRData{
-		{Cr: &corev1.NamespaceList{}},
-		{Cr: &corev1.PodList{}},
-		{Cr: &policiesv1.PolicyList{}},
-		{Cr: &corev1.PersistentVolumeList{}},
-		{Cr: &corev1.PersistentVolumeClaimList{}, Namespace: ptr.To(ImageRegistryNamespace)},
-		{Cr: &storagev1.StorageClassList{}},
-		{Cr: &corev1.ServiceAccountList{}, Namespace: ptr.To(TestNamespace)},
-		{Cr: &sriovv1.SriovNetworkList{}, Namespace: ptr.To(RANConfig.SriovOperatorNamespace)},
-		{Cr: &sriovv1.SriovNetworkList{}, Namespace: ptr.To(TestNamespace)},
-		{Cr: &imageregistryv1.ConfigList{}},
-		{Cr: &certificatesv1.CertificateSigningRequestList{}},
-	}
-
 	// ImageRegistryPolicies is a slice of all the policies the image registry test creates.
 	ImageRegistryPolicies = []string{
 		"image-registry-policy-sc",
@@ -122,3 +78,55 @@ var (
 		Reason:  ClusterInstanceFailReason,
 	}
 )
+
+// ReporterHubNamespacesToDump tells to the reporter which namespaces on the hub to collect pod logs from.
+func ReporterHubNamespacesToDump() map[string]string {
+	return map[string]string{
+		TestNamespace:                       "",
+		ranparam.OpenshiftOperatorNamespace: "",
+		RANConfig.AcmOperatorNamespace:      "",
+	}
+}
+
+// ReporterSpokeNamespacesToDump tells the reporter which namespaces on the spokes to collect pod logs from.
+func ReporterSpokeNamespacesToDump() map[string]string {
+	return map[string]string{
+		TestNamespace:                    "",
+		kacv1.KlusterletAddonNamespace:   "",
+		RANConfig.SriovOperatorNamespace: "",
+	}
+}
+
+// ReporterHubCRsToDump is the CRs the reporter should dump on the hub.
+func ReporterHubCRsToDump() []k8sreporter.CRData {
+	return []k8sreporter.CRData{
+		{Cr: &corev1.NamespaceList{}},
+		{Cr: &corev1.PodList{}},
+		{Cr: &clusterv1.ManagedClusterList{}},
+		{Cr: &policiesv1.PolicyList{}},
+		{Cr: &placementrulev1.PlacementRuleList{}, Namespace: ptr.To(TestNamespace)},
+		{Cr: &cguv1alpha1.ClusterGroupUpgradeList{}},
+		{Cr: &corev1.ConfigMapList{}, Namespace: ptr.To(TestNamespace)},
+		{Cr: &corev1.SecretList{}, Namespace: ptr.To(TestNamespace)},
+		{Cr: &argocdoperator.ArgoCDList{}},
+		{Cr: &v1alpha1.ApplicationList{}},
+		{Cr: &siteconfigv1alpha1.ClusterInstanceList{}, Namespace: ptr.To(RANConfig.Spoke1Name)},
+	}
+}
+
+// ReporterSpokeCRsToDump is the CRs the reporter should dump on the spokes.
+func ReporterSpokeCRsToDump() []k8sreporter.CRData {
+	return []k8sreporter.CRData{
+		{Cr: &corev1.NamespaceList{}},
+		{Cr: &corev1.PodList{}},
+		{Cr: &policiesv1.PolicyList{}},
+		{Cr: &corev1.PersistentVolumeList{}},
+		{Cr: &corev1.PersistentVolumeClaimList{}, Namespace: ptr.To(ImageRegistryNamespace)},
+		{Cr: &storagev1.StorageClassList{}},
+		{Cr: &corev1.ServiceAccountList{}, Namespace: ptr.To(TestNamespace)},
+		{Cr: &sriovv1.SriovNetworkList{}, Namespace: ptr.To(RANConfig.SriovOperatorNamespace)},
+		{Cr: &sriovv1.SriovNetworkList{}, Namespace: ptr.To(TestNamespace)},
+		{Cr: &imageregistryv1.ConfigList{}},
+		{Cr: &certificatesv1.CertificateSigningRequestList{}},
+	}
+}
@@ -17,12 +17,21 @@ import (
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/gitopsztp/tests"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/rancluster"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/raninittools"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 )
 
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestZtp(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = RANConfig.GetJunitReportPath(currentFile)
 
@@ -73,15 +82,15 @@ var _ = JustAfterEach(func() {
 	)
 
 	reporter.ReportIfFailed(
-		report, currentFile, tsparams.ReporterSpokeNamespacesToDump, tsparams.ReporterSpokeCRsToDump)
+		report, currentFile, tsparams.ReporterSpokeNamespacesToDump(), tsparams.ReporterSpokeCRsToDump())
 
 	if HubAPIClient != nil {
 		reporter.ReportIfFailedOnCluster(
 			RANConfig.HubKubeconfig,
 			report,
 			hubReportPath,
-			tsparams.ReporterHubNamespacesToDump,
-			tsparams.ReporterHubCRsToDump)
+			tsparams.ReporterHubNamespacesToDump(),
+			tsparams.ReporterHubCRsToDump())
 	}
 })
 
@@ -13,6 +13,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/internal/cnfconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/version"
+	generalconfig "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"gopkg.in/yaml.v2"
 	"k8s.io/klog/v2"
@@ -45,6 +46,10 @@ type RANConfig struct {
 	TalmPreCachePolicies  []string `yaml:"talmPreCachePolicies" envconfig:"ECO_CNF_RAN_TALM_PRECACHE_POLICIES"`
 	ZtpSiteGenerateImage  string   `yaml:"ztpSiteGenerateImage" envconfig:"ECO_CNF_RAN_ZTP_SITE_GENERATE_IMAGE"`
 
+	// PtpStabilityClockClass is the ITU-T G.8273.2 clock class, A through D, that PTP stability analysis checks the
+	// ptp4l time error metrics against. No class is checked if it is empty.
+	PtpStabilityClockClass string `yaml:"ptpStabilityClockClass" envconfig:"ECO_CNF_RAN_PTP_STABILITY_CLOCK_CLASS"`
+
 	// PtpEventConsumerImage is the URL of the PTP event consumer image. It should not have a tag, since the
 	// expectation is that the program uses v1 or v2 as a tag.
 	PtpEventConsumerImage string `yaml:"ptpEventConsumerImage" envconfig:"ECO_CNF_RAN_PTP_EVENT_CONSUMER_IMAGE"`
@@ -345,7 +350,12 @@ func readFile[C any](config *C, configFile string) error {
 }
 
 func readEnv[C any](config *C) error {
-	err := envconfig.Process("", config)
+	err := generalconfig.ReadProfiles(config)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", config)
 
 	return err
 }
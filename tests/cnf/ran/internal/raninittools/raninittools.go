@@ -1,10 +1,14 @@
 package raninittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/onsi/ginkgo/v2" //nolint:depguard // necessary for logging
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"k8s.io/klog/v2"
 )
@@ -22,15 +26,31 @@ var (
 	BMCClient *bmc.BMC
 )
 
+// init registers setup so the RAN config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ran", setup)
+}
+
+// setup loads the RAN config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	// If LogToStderr is true, klog will ignore the output and only write to stderr. Instead, we want to write to
 	// the GinkgoWriter, which is also written to stderr when using the -v flag.
 	klog.LogToStderr(false)
 	klog.SetOutput(ginkgo.GinkgoWriter)
 
 	Spoke1APIClient = inittools.APIClient
+
 	RANConfig = ranconfig.NewRANConfig()
+	if RANConfig == nil {
+		return errors.New("failed to load RAN config")
+	}
+
 	HubAPIClient = RANConfig.HubAPIClient
 	Spoke2APIClient = RANConfig.Spoke2APIClient
 	BMCClient = RANConfig.Spoke1BMC
+
+	return errors.Join(
+		clusterregistry.RegisterClientIfSet("hub", clusterregistry.RoleHub, HubAPIClient),
+		clusterregistry.RegisterClientIfSet("spoke1", clusterregistry.RoleSpoke, Spoke1APIClient),
+		clusterregistry.RegisterClientIfSet("spoke2", clusterregistry.RoleSpoke, Spoke2APIClient))
 }
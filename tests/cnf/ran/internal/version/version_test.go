@@ -1,9 +1,5 @@
 package version
 
-// The version package imports cluster (via version.go), which pulls inittools. For local unit tests of
-// IsVersionStringInRange only, run:
-// UNIT_TEST=true go test ./tests/cnf/ran/internal/version/... -run TestIsVersionStringInRange
-
 import (
 	"testing"
 
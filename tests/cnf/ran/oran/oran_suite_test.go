@@ -14,6 +14,7 @@ import (
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/raninittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/oran/internal/tsparams"
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/oran/tests"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	subscriber "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/oran-subscriber"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 )
@@ -21,6 +22,14 @@ import (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestORAN(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = RANConfig.GetJunitReportPath(currentFile)
 
@@ -25,8 +25,10 @@ import (
 	"k8s.io/utils/ptr"
 )
 
-// subscriberURL is the URL of the subscriber, including the scheme. It should not be modified.
-var subscriberURL = "https://" + RANConfig.GetAppsURL(tsparams.SubscriberSubdomain)
+// subscriberURL returns the URL of the subscriber, including the scheme.
+func subscriberURL() string {
+	return "https://" + RANConfig.GetAppsURL(tsparams.SubscriberSubdomain)
+}
 
 var _ = Describe("ORAN Alarms Tests", Label(tsparams.LabelPostProvision, tsparams.LabelAlarms), func() {
 	var (
@@ -142,7 +144,7 @@ var _ = Describe("ORAN Alarms Tests", Label(tsparams.LabelPostProvision, tsparam
 		subscriptionID := uuid.New()
 		subscription, err := alarmsClient.CreateSubscription(oranapi.AlarmSubscriptionInfo{
 			ConsumerSubscriptionId: &subscriptionID,
-			Callback:               subscriberURL + "/" + subscriptionID.String(),
+			Callback:               subscriberURL() + "/" + subscriptionID.String(),
 		})
 		Expect(err).ToNot(HaveOccurred(), "Failed to create test subscription")
 
@@ -182,7 +184,7 @@ var _ = Describe("ORAN Alarms Tests", Label(tsparams.LabelPostProvision, tsparam
 		subscription1, err := alarmsClient.CreateSubscription(oranapi.AlarmSubscriptionInfo{
 			ConsumerSubscriptionId: &subscriptionID1,
 			// Callback URLs must be unique, so we use the subscription ID as a suffix.
-			Callback: subscriberURL + "/" + subscriptionID1.String(),
+			Callback: subscriberURL() + "/" + subscriptionID1.String(),
 		})
 		Expect(err).ToNot(HaveOccurred(), "Failed to create first test subscription")
 
@@ -192,7 +194,7 @@ var _ = Describe("ORAN Alarms Tests", Label(tsparams.LabelPostProvision, tsparam
 		subscription2, err := alarmsClient.CreateSubscription(oranapi.AlarmSubscriptionInfo{
 			ConsumerSubscriptionId: &subscriptionID2,
 			// Callback URLs must be unique, so we use the subscription ID as a suffix.
-			Callback: subscriberURL + "/" + subscriptionID2.String(),
+			Callback: subscriberURL() + "/" + subscriptionID2.String(),
 		})
 		Expect(err).ToNot(HaveOccurred(), "Failed to create second test subscription")
 
@@ -234,7 +236,7 @@ var _ = Describe("ORAN Alarms Tests", Label(tsparams.LabelPostProvision, tsparam
 		subscription, err := alarmsClient.CreateSubscription(oranapi.AlarmSubscriptionInfo{
 			ConsumerSubscriptionId: &subscriptionID,
 			// Callback URLs must be unique, so we use the subscription ID as a suffix.
-			Callback: subscriberURL + "/" + subscriptionID.String(),
+			Callback: subscriberURL() + "/" + subscriptionID.String(),
 		})
 		Expect(err).ToNot(HaveOccurred(), "Failed to create test subscription")
 
@@ -306,7 +308,7 @@ var _ = Describe("ORAN Alarms Tests", Label(tsparams.LabelPostProvision, tsparam
 		subscriptionID := uuid.New()
 		subscription, err := alarmsClient.CreateSubscription(oranapi.AlarmSubscriptionInfo{
 			ConsumerSubscriptionId: &subscriptionID,
-			Callback:               subscriberURL + "/" + subscriptionID.String(),
+			Callback:               subscriberURL() + "/" + subscriptionID.String(),
 			Filter:                 ptr.To(oranapi.AlarmSubscriptionFilterNEW),
 		})
 		Expect(err).ToNot(HaveOccurred(), "Failed to create test subscription")
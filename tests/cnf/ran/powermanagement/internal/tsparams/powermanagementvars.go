@@ -12,12 +12,6 @@ var (
 	// Labels represents the range of labels that can be used for test cases selection.
 	Labels = append(ranparam.Labels, LabelSuite)
 
-	// ReporterNamespacesToDump tells to the reporter which namespaces to collect pod logs from.
-	ReporterNamespacesToDump = map[string]string{
-		TestingNamespace:       "",
-		RANConfig.MCONamespace: "",
-	}
-
 	// ReporterCRsToDump is the CRs the reporter should dump.
 	ReporterCRsToDump = []k8sreporter.CRData{
 		{Cr: &corev1.PodList{}},
@@ -25,3 +19,11 @@ var (
 		{Cr: &performancev2.PerformanceProfileList{}},
 	}
 )
+
+// ReporterNamespacesToDump tells to the reporter which namespaces to collect pod logs from.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
+		TestingNamespace:       "",
+		RANConfig.MCONamespace: "",
+	}
+}
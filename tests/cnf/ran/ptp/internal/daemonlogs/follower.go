@@ -0,0 +1,452 @@
+package daemonlogs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/ptpdaemon"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/tsparams"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultRetryInterval is the default time to wait before reconnecting to the log stream after it ends or fails
+	// to open, such as while the container restarts or the pod is recreated.
+	defaultRetryInterval = 1 * time.Second
+	// subscriptionBuffer is the number of lines buffered for each subscription before the follower waits for the
+	// subscriber to receive them.
+	subscriptionBuffer = 1024
+	// maxLineSize is the maximum size of a single log line. Longer lines end the stream and it is reconnected.
+	maxLineSize = 1024 * 1024
+	// maxHistoryLines is the maximum number of lines kept in the history of a follower, regardless of its history
+	// window.
+	maxHistoryLines = 50000
+)
+
+// LogLine is a single line of the logs of a followed container.
+type LogLine struct {
+	// Node is the name of the node the logs were followed on.
+	Node string
+	// Time is the time the line was logged, as recorded by the kubelet.
+	Time time.Time
+	// Text is the line as logged by the container, without the timestamp added by the kubelet.
+	Text string
+}
+
+// streamOpener opens a stream of the logs of the followed container starting at since. Each line of the stream must be
+// prefixed by its RFC 3339 timestamp, as returned when PodLogOptions.Timestamps is set. The stream should stay open
+// until the container stops or ctx is cancelled.
+type streamOpener func(ctx context.Context, since time.Time) (io.ReadCloser, error)
+
+// Follower streams the logs of a container and fans them out to every Subscription. When the stream ends, such as when
+// the container restarts or the pod is deleted and recreated during a reboot, the pod is looked up again and the
+// stream is reopened starting at the last line received. Lines are deduplicated by timestamp so that subscribers never
+// receive the same line twice, even though the API server only resumes streams with second precision.
+//
+// The zero value is not usable; followers should be created with NewDaemonLogFollower or NewPodLogFollower, or shared
+// through SubscribeDaemonLogs.
+type Follower struct {
+	node          string
+	open          streamOpener
+	retryInterval time.Duration
+	// historyWindow is how long lines are kept after they are received so that later subscriptions can start in the
+	// past. No lines are kept if it is zero.
+	historyWindow time.Duration
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	startOnce sync.Once
+	done      chan struct{}
+
+	// lastTime and lastLines are only accessed by the goroutine following the logs. They are the timestamp of the
+	// last line received and every line received with that timestamp, which are used to skip lines that are
+	// received again after reconnecting.
+	lastTime  time.Time
+	lastLines map[string]bool
+
+	mutex         sync.Mutex
+	stopped       bool
+	subscriptions []*Subscription
+	errors        []error
+	// history is the lines received within historyWindow of the last line, oldest first. Every line logged at or
+	// after historyStart is in it.
+	history      []LogLine
+	historyStart time.Time
+}
+
+// Subscription receives the lines of a Follower starting at when it subscribed.
+type Subscription struct {
+	follower  *Follower
+	since     time.Time
+	lines     chan LogLine
+	done      chan struct{}
+	closeOnce sync.Once
+	// onClose is called when the subscription is closed, such as to release a shared follower.
+	onClose func()
+}
+
+// NewDaemonLogFollower returns a Follower for the linuxptp-daemon-container logs of the PTP daemon pod on nodeName,
+// starting at since. It does not follow the logs until Start is called.
+func NewDaemonLogFollower(client *clients.Settings, nodeName string, since time.Time) *Follower {
+	return NewPodLogFollower(client, nodeName, ranparam.PtpContainerName, since, func() (*pod.Builder, error) {
+		return ptpdaemon.GetPtpDaemonPodOnNode(client, nodeName)
+	})
+}
+
+// NewPodLogFollower returns a Follower for the logs of container in the pod returned by getPod, starting at since.
+// The getPod function is called each time the stream is opened so that it follows the pod if it is recreated. An empty
+// container follows the only container in the pod. It does not follow the logs until Start is called.
+func NewPodLogFollower(
+	client *clients.Settings, nodeName, container string, since time.Time, getPod func() (*pod.Builder, error),
+) *Follower {
+	return newFollower(nodeName, since, func(ctx context.Context, since time.Time) (io.ReadCloser, error) {
+		if client == nil {
+			return nil, fmt.Errorf("cannot follow logs with nil client")
+		}
+
+		logPod, err := getPod()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Pods(logPod.Definition.Namespace).GetLogs(logPod.Definition.Name, &corev1.PodLogOptions{
+			Container:  container,
+			Follow:     true,
+			Timestamps: true,
+			SinceTime:  &metav1.Time{Time: since},
+		}).Stream(ctx)
+	})
+}
+
+// newFollower returns a Follower for node that opens streams using open, starting at since.
+func newFollower(node string, since time.Time, open streamOpener) *Follower {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Follower{
+		node:          node,
+		open:          open,
+		retryInterval: defaultRetryInterval,
+		ctx:           ctx,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+		lastTime:      since,
+		lastLines:     make(map[string]bool),
+		historyStart:  since,
+	}
+}
+
+// Subscribe returns a new Subscription to the lines of the follower. Subscriptions made before Start receive every
+// line starting at the time the follower was created with, whereas later subscriptions only receive the lines that are
+// received after subscribing. If the follower is already stopped, the returned subscription receives no lines.
+func (follower *Follower) Subscribe() *Subscription {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	return follower.subscribe(time.Time{}, nil)
+}
+
+// subscribeSince returns a new Subscription that first receives the lines in the history logged at or after since and
+// then only the lines received afterwards that were logged at or after since. It returns false if the follower keeps no
+// history or some lines logged since then may not be in it, such as when since is before the follower started.
+func (follower *Follower) subscribeSince(since time.Time) (*Subscription, bool) {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	if follower.historyWindow <= 0 || since.Before(follower.historyStart) {
+		return nil, false
+	}
+
+	index, _ := slices.BinarySearchFunc(follower.history, since, func(line LogLine, since time.Time) int {
+		return line.Time.Compare(since)
+	})
+
+	return follower.subscribe(since, follower.history[index:]), true
+}
+
+// subscribe adds a subscription receiving the lines logged at or after since, starting with replay. The mutex must be
+// held.
+func (follower *Follower) subscribe(since time.Time, replay []LogLine) *Subscription {
+	subscription := &Subscription{
+		follower: follower,
+		since:    since,
+		lines:    make(chan LogLine, subscriptionBuffer+len(replay)),
+		done:     make(chan struct{}),
+	}
+
+	if follower.stopped {
+		close(subscription.lines)
+
+		return subscription
+	}
+
+	for _, line := range replay {
+		subscription.lines <- line
+	}
+
+	follower.subscriptions = append(follower.subscriptions, subscription)
+
+	return subscription
+}
+
+// Start starts following the logs in the background. It is a no-op if the follower has already been started.
+func (follower *Follower) Start() {
+	follower.startOnce.Do(func() {
+		go follower.run()
+	})
+}
+
+// Stop stops following the logs and closes the Lines channel of every subscription. It is a no-op if the follower has
+// already been stopped.
+func (follower *Follower) Stop() {
+	follower.cancel()
+
+	// Starting a cancelled follower makes it stop immediately, which ensures done is closed even if Start was never
+	// called.
+	follower.Start()
+	<-follower.done
+}
+
+// Errors returns the errors encountered while following the logs. Consecutive identical errors, such as the pod not
+// being found on every attempt while a node reboots, are only included once.
+func (follower *Follower) Errors() []error {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	return append([]error(nil), follower.errors...)
+}
+
+// Lines returns the channel the lines of the follower are sent on. It is closed when the subscription is closed or the
+// follower is stopped. Lines are buffered, but the follower waits for subscribers to receive them once the buffer is
+// full, so subscribers must keep receiving lines until they call Close.
+func (subscription *Subscription) Lines() <-chan LogLine {
+	return subscription.lines
+}
+
+// Close stops lines from being sent to the subscription and closes its Lines channel. If the subscription was returned
+// by SubscribeDaemonLogs, the shared follower is stopped once all of its subscriptions are closed. It is safe to call
+// more than once.
+func (subscription *Subscription) Close() {
+	subscription.closeOnce.Do(func() {
+		close(subscription.done)
+
+		if subscription.follower != nil {
+			subscription.follower.unsubscribe(subscription)
+		}
+
+		if subscription.onClose != nil {
+			subscription.onClose()
+		}
+	})
+}
+
+// Errors returns the errors encountered by the follower of the subscription, as returned by Follower.Errors.
+func (subscription *Subscription) Errors() []error {
+	if subscription.follower == nil {
+		return nil
+	}
+
+	return subscription.follower.Errors()
+}
+
+// WaitForMatch waits for a line matching matcher to be received by the subscription and returns it. It returns an
+// error if ctx is done or the follower is stopped before a matching line is received.
+func (subscription *Subscription) WaitForMatch(ctx context.Context, matcher LogMatcher) (LogLine, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return LogLine{}, ctx.Err()
+		case line, ok := <-subscription.lines:
+			if !ok {
+				return LogLine{}, fmt.Errorf("log follower stopped before a matching line was received")
+			}
+
+			if matcher(line.Text) {
+				return line, nil
+			}
+		}
+	}
+}
+
+// run follows the logs, reconnecting after retryInterval whenever the stream ends, until the follower is stopped.
+func (follower *Follower) run() {
+	defer close(follower.done)
+	defer follower.closeSubscriptions()
+
+	for follower.ctx.Err() == nil {
+		err := follower.follow()
+		if err != nil && follower.ctx.Err() == nil {
+			klog.V(tsparams.LogLevel).Infof("Failed to follow logs on node %s: %v", follower.node, err)
+
+			follower.addError(err)
+		}
+
+		select {
+		case <-follower.ctx.Done():
+			return
+		case <-time.After(follower.retryInterval):
+		}
+	}
+}
+
+// follow opens a single stream starting at the last line received and dispatches its lines until it ends.
+func (follower *Follower) follow() error {
+	stream, err := follower.open(follower.ctx, follower.lastTime)
+	if err != nil {
+		return fmt.Errorf("failed to open log stream: %w", err)
+	}
+
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line, ok := follower.parse(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		if !follower.dispatch(line) {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log stream: %w", err)
+	}
+
+	return nil
+}
+
+// parse splits the timestamp from raw and returns the line if it has not been received yet. Lines without a valid
+// timestamp are skipped.
+func (follower *Follower) parse(raw string) (LogLine, bool) {
+	rawTime, text, found := strings.Cut(raw, " ")
+	if !found {
+		return LogLine{}, false
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, rawTime)
+	if err != nil {
+		return LogLine{}, false
+	}
+
+	switch {
+	case timestamp.Before(follower.lastTime):
+		return LogLine{}, false
+	case timestamp.Equal(follower.lastTime):
+		if follower.lastLines[text] {
+			return LogLine{}, false
+		}
+	default:
+		follower.lastTime = timestamp
+		clear(follower.lastLines)
+	}
+
+	follower.lastLines[text] = true
+
+	return LogLine{Node: follower.node, Time: timestamp, Text: text}, true
+}
+
+// dispatch adds line to the history and sends it to every open subscription it was logged for, waiting for subscribers
+// whose buffer is full. The mutex is held while sending so that subscriptions are never closed during a send. It
+// returns false if the follower was stopped while sending.
+func (follower *Follower) dispatch(line LogLine) bool {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	follower.keep(line)
+
+	for _, subscription := range follower.subscriptions {
+		if line.Time.Before(subscription.since) {
+			continue
+		}
+
+		select {
+		case subscription.lines <- line:
+		case <-subscription.done:
+		case <-follower.ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// keep adds line to the history and drops the lines logged more than historyWindow before it, or the oldest lines if
+// there are more than maxHistoryLines. The mutex must be held.
+func (follower *Follower) keep(line LogLine) {
+	if follower.historyWindow <= 0 {
+		return
+	}
+
+	follower.history = append(follower.history, line)
+
+	cutoff := line.Time.Add(-follower.historyWindow)
+	dropped := 0
+
+	for dropped < len(follower.history) &&
+		(follower.history[dropped].Time.Before(cutoff) || len(follower.history)-dropped > maxHistoryLines) {
+		dropped++
+	}
+
+	if dropped == 0 {
+		return
+	}
+
+	follower.historyStart = follower.history[dropped-1].Time.Add(time.Nanosecond)
+	follower.history = follower.history[dropped:]
+}
+
+// unsubscribe removes subscription from the follower and closes its Lines channel, unless the follower already did so
+// when it stopped.
+func (follower *Follower) unsubscribe(subscription *Subscription) {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	index := slices.Index(follower.subscriptions, subscription)
+	if index < 0 {
+		return
+	}
+
+	follower.subscriptions = slices.Delete(follower.subscriptions, index, index+1)
+
+	close(subscription.lines)
+}
+
+// closeSubscriptions marks the follower as stopped and closes the Lines channel of every subscription.
+func (follower *Follower) closeSubscriptions() {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	follower.stopped = true
+
+	for _, subscription := range follower.subscriptions {
+		close(subscription.lines)
+	}
+
+	follower.subscriptions = nil
+}
+
+// addError records err unless it is identical to the last error recorded.
+func (follower *Follower) addError(err error) {
+	follower.mutex.Lock()
+	defer follower.mutex.Unlock()
+
+	if len(follower.errors) > 0 && follower.errors[len(follower.errors)-1].Error() == err.Error() {
+		return
+	}
+
+	follower.errors = append(follower.errors, err)
+}
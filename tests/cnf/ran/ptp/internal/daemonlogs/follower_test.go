@@ -0,0 +1,149 @@
+package daemonlogs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStreams is a streamOpener that returns its streams in order, recording the time each was opened since. Empty
+// strings are returned as errors instead. Once all streams have been returned, it returns streams that stay open until
+// the follower is stopped.
+type fakeStreams struct {
+	mutex   sync.Mutex
+	streams []string
+	since   []time.Time
+}
+
+func (fake *fakeStreams) open(ctx context.Context, since time.Time) (io.ReadCloser, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	fake.since = append(fake.since, since)
+
+	if len(fake.streams) == 0 {
+		reader, writer := io.Pipe()
+
+		context.AfterFunc(ctx, func() {
+			_ = writer.CloseWithError(ctx.Err())
+		})
+
+		return reader, nil
+	}
+
+	stream := fake.streams[0]
+	fake.streams = fake.streams[1:]
+
+	if stream == "" {
+		return nil, errors.New("pod not found")
+	}
+
+	return io.NopCloser(strings.NewReader(stream)), nil
+}
+
+func TestFollowerReconnects(t *testing.T) {
+	since := time.Date(2026, 10, 18, 10, 0, 0, 500000000, time.UTC)
+	fake := &fakeStreams{streams: []string{
+		"2026-10-18T10:00:00.2Z before\n" +
+			"2026-10-18T10:00:01Z first\n" +
+			"2026-10-18T10:00:01Z second\n",
+		"",
+		"",
+		"2026-10-18T10:00:01Z first\n" +
+			"2026-10-18T10:00:01Z second\n" +
+			"2026-10-18T10:00:01Z third\n" +
+			"not a timestamped line\n" +
+			"2026-10-18T10:00:02Z fourth\n",
+	}}
+
+	follower := newFollower("node-0", since, fake.open)
+	follower.retryInterval = time.Millisecond
+
+	subscriptions := []*Subscription{follower.Subscribe(), follower.Subscribe()}
+
+	follower.Start()
+
+	for _, subscription := range subscriptions {
+		var texts []string
+
+		for range 4 {
+			select {
+			case line := <-subscription.Lines():
+				assert.Equal(t, "node-0", line.Node)
+
+				texts = append(texts, line.Text)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for log lines, received %v", texts)
+			}
+		}
+
+		assert.Equal(t, []string{"first", "second", "third", "fourth"}, texts)
+	}
+
+	follower.Stop()
+
+	for _, subscription := range subscriptions {
+		_, ok := <-subscription.Lines()
+		assert.False(t, ok)
+	}
+
+	assert.Len(t, follower.Errors(), 1)
+
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	assert.Equal(t, since, fake.since[0])
+	assert.Equal(t, time.Date(2026, 10, 18, 10, 0, 1, 0, time.UTC), fake.since[1])
+}
+
+func TestSubscriptionWaitForMatch(t *testing.T) {
+	fake := &fakeStreams{streams: []string{
+		"2026-10-18T10:00:01Z load profiles\n" +
+			"2026-10-18T10:00:02Z starting ts2phc\n",
+	}}
+
+	follower := newFollower("node-0", time.Time{}, fake.open)
+	follower.retryInterval = time.Millisecond
+	subscription := follower.Subscribe()
+
+	follower.Start()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	line, err := subscription.WaitForMatch(ctx, ContainsMatcher("ts2phc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "starting ts2phc", line.Text)
+	assert.Equal(t, time.Date(2026, 10, 18, 10, 0, 2, 0, time.UTC), line.Time)
+
+	shortCtx, shortCancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer shortCancel()
+
+	_, err = subscription.WaitForMatch(shortCtx, ContainsMatcher("ts2phc"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	follower.Stop()
+
+	_, err = subscription.WaitForMatch(t.Context(), ContainsMatcher("ts2phc"))
+	assert.Error(t, err)
+}
+
+func TestFollowerStopWithoutStart(t *testing.T) {
+	follower := newFollower("node-0", time.Time{}, (&fakeStreams{}).open)
+	subscription := follower.Subscribe()
+
+	follower.Stop()
+	follower.Stop()
+
+	_, ok := <-subscription.Lines()
+	assert.False(t, ok)
+
+	_, ok = <-follower.Subscribe().Lines()
+	assert.False(t, ok)
+}
@@ -0,0 +1,118 @@
+package daemonlogs
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
+)
+
+// sharedHistoryWindow is how long shared followers keep lines so that waiters can subscribe starting shortly before
+// they are called, such as at the time of the action they wait on.
+const sharedHistoryWindow = 10 * time.Minute
+
+// followerKey identifies the container followed by a shared Follower.
+type followerKey struct {
+	client    *clients.Settings
+	node      string
+	container string
+}
+
+// sharedFollower is a Follower in the registry along with the number of its subscriptions that are still open.
+type sharedFollower struct {
+	follower      *Follower
+	subscriptions int
+}
+
+var (
+	// sharedFollowers holds the running followers shared between subscriptions, keyed by the container they follow.
+	// A container only has more than one follower when a subscription starts before the lines kept by the others.
+	sharedFollowers      = make(map[followerKey][]*sharedFollower)
+	sharedFollowersMutex sync.Mutex
+)
+
+// SubscribeDaemonLogs returns a Subscription to the linuxptp-daemon-container logs of the PTP daemon pod on nodeName,
+// starting at since. Subscriptions on the same node share a single Follower, so waiters, collectors, and recorders
+// running at the same time only open one log stream per node. Shared followers keep the lines of the last 10 minutes
+// so that a subscription may start before it is made, such as at the time of the action being waited on. A new
+// follower is only started when no running one has kept every line since since.
+//
+// A shared follower is stopped once all of its subscriptions are closed, so the returned subscription must always be
+// closed when it is no longer needed.
+func SubscribeDaemonLogs(client *clients.Settings, nodeName string, since time.Time) *Subscription {
+	return subscribeDaemonLogs(client, nodeName, since, defaultRetryInterval)
+}
+
+// subscribeDaemonLogs is SubscribeDaemonLogs with the interval used to reconnect if a new follower is started.
+func subscribeDaemonLogs(
+	client *clients.Settings, nodeName string, since time.Time, retryInterval time.Duration) *Subscription {
+	key := followerKey{client: client, node: nodeName, container: ranparam.PtpContainerName}
+
+	return subscribeShared(key, since, func() *Follower {
+		follower := NewDaemonLogFollower(client, nodeName, since)
+		follower.retryInterval = retryInterval
+
+		return follower
+	})
+}
+
+// subscribeShared subscribes to a shared follower for key that has kept every line since since. If there is none,
+// one is created with create, added to the registry, and started.
+func subscribeShared(key followerKey, since time.Time, create func() *Follower) *Subscription {
+	sharedFollowersMutex.Lock()
+	defer sharedFollowersMutex.Unlock()
+
+	for _, shared := range sharedFollowers[key] {
+		subscription, ok := shared.follower.subscribeSince(since)
+		if !ok {
+			continue
+		}
+
+		shared.subscriptions++
+		subscription.onClose = func() {
+			releaseShared(key, shared)
+		}
+
+		return subscription
+	}
+
+	shared := &sharedFollower{follower: create(), subscriptions: 1}
+	shared.follower.historyWindow = sharedHistoryWindow
+	sharedFollowers[key] = append(sharedFollowers[key], shared)
+
+	subscription := shared.follower.Subscribe()
+	subscription.onClose = func() {
+		releaseShared(key, shared)
+	}
+
+	shared.follower.Start()
+
+	return subscription
+}
+
+// releaseShared records that a subscription to shared was closed, removing it from the registry and stopping it once
+// none are left.
+func releaseShared(key followerKey, shared *sharedFollower) {
+	sharedFollowersMutex.Lock()
+
+	shared.subscriptions--
+	unused := shared.subscriptions == 0
+
+	if unused {
+		sharedFollowers[key] = slices.DeleteFunc(sharedFollowers[key], func(other *sharedFollower) bool {
+			return other == shared
+		})
+
+		if len(sharedFollowers[key]) == 0 {
+			delete(sharedFollowers, key)
+		}
+	}
+
+	sharedFollowersMutex.Unlock()
+
+	if unused {
+		shared.follower.Stop()
+	}
+}
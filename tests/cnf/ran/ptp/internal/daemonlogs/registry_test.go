@@ -0,0 +1,150 @@
+package daemonlogs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveTexts receives count lines from subscription and returns their text, failing the test if they are not
+// received in time.
+func receiveTexts(t *testing.T, subscription *Subscription, count int) []string {
+	t.Helper()
+
+	var texts []string
+
+	for range count {
+		select {
+		case line := <-subscription.Lines():
+			texts = append(texts, line.Text)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for log lines, received %v", texts)
+		}
+	}
+
+	return texts
+}
+
+func TestSubscribeShared(t *testing.T) {
+	key := followerKey{node: "node-0", container: "linuxptp-daemon-container"}
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	created := 0
+
+	creator := func(since time.Time) func() *Follower {
+		return func() *Follower {
+			created++
+
+			fake := &fakeStreams{streams: []string{
+				"2026-10-18T10:00:01Z first\n" +
+					"2026-10-18T10:00:02Z second\n" +
+					"2026-10-18T10:00:03Z third\n",
+			}}
+
+			follower := newFollower(key.node, since, fake.open)
+			follower.retryInterval = time.Millisecond
+
+			return follower
+		}
+	}
+
+	first := subscribeShared(key, start, creator(start))
+	assert.Equal(t, []string{"first", "second", "third"}, receiveTexts(t, first, 3))
+
+	later := start.Add(2 * time.Second)
+	second := subscribeShared(key, later, creator(later))
+	assert.Equal(t, []string{"second", "third"}, receiveTexts(t, second, 2))
+	assert.Equal(t, 1, created)
+
+	earlier := start.Add(-time.Minute)
+	third := subscribeShared(key, earlier, creator(earlier))
+	assert.Equal(t, []string{"first", "second", "third"}, receiveTexts(t, third, 3))
+	assert.Equal(t, 2, created)
+
+	third.Close()
+	first.Close()
+
+	_, ok := <-first.Lines()
+	assert.False(t, ok)
+
+	sharedFollowersMutex.Lock()
+	assert.Len(t, sharedFollowers[key], 1)
+	sharedFollowersMutex.Unlock()
+
+	second.Close()
+
+	sharedFollowersMutex.Lock()
+	assert.NotContains(t, sharedFollowers, key)
+	sharedFollowersMutex.Unlock()
+}
+
+func TestFollowerHistory(t *testing.T) {
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		historyWindow time.Duration
+		lineTimes     []time.Time
+		since         time.Time
+		expectedOk    bool
+		expectedLines int
+	}{
+		{
+			name:          "no history",
+			historyWindow: 0,
+			lineTimes:     []time.Time{start},
+			since:         start,
+			expectedOk:    false,
+		},
+		{
+			name:          "since before start",
+			historyWindow: time.Minute,
+			lineTimes:     []time.Time{start},
+			since:         start.Add(-time.Second),
+			expectedOk:    false,
+		},
+		{
+			name:          "all lines kept",
+			historyWindow: time.Minute,
+			lineTimes:     []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)},
+			since:         start.Add(time.Second),
+			expectedOk:    true,
+			expectedLines: 2,
+		},
+		{
+			name:          "since dropped line",
+			historyWindow: time.Second,
+			lineTimes:     []time.Time{start, start.Add(2 * time.Second)},
+			since:         start,
+			expectedOk:    false,
+		},
+		{
+			name:          "since after dropped line",
+			historyWindow: time.Second,
+			lineTimes:     []time.Time{start, start.Add(2 * time.Second)},
+			since:         start.Add(time.Nanosecond),
+			expectedOk:    true,
+			expectedLines: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			follower := newFollower("node-0", start, (&fakeStreams{}).open)
+			follower.historyWindow = testCase.historyWindow
+
+			for _, lineTime := range testCase.lineTimes {
+				assert.True(t, follower.dispatch(LogLine{Node: "node-0", Time: lineTime, Text: lineTime.String()}))
+			}
+
+			subscription, ok := follower.subscribeSince(testCase.since)
+			assert.Equal(t, testCase.expectedOk, ok)
+
+			if ok {
+				assert.Len(t, subscription.Lines(), testCase.expectedLines)
+			}
+
+			follower.Stop()
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package tempfile names the temporary files that daemonlogs.CollectDaemonLogs writes collected logs to. It has no
+// cluster dependencies so that tools analyzing the files offline can recognize them.
+package tempfile
+
+import "strings"
+
+const (
+	// Pattern is the pattern, as used by path.Match, of the names of the temporary files that collected logs are
+	// written to. The names are of the form ptp-daemon-logs-<node>-<random>.log.
+	Pattern = prefix + "*" + suffix
+
+	prefix = "ptp-daemon-logs-"
+	suffix = ".log"
+)
+
+// NodePattern returns the pattern, as used by os.CreateTemp, for the temporary file holding the logs of nodeName.
+func NodePattern(nodeName string) string {
+	return prefix + nodeName + "-*" + suffix
+}
+
+// NodeFromName returns the name of the node that the temporary file with the provided name, as created using
+// NodePattern, holds the logs of. It returns false if the name does not match Pattern or does not include the node, as
+// is the case for files written before the node was added to the name.
+func NodeFromName(fileName string) (string, bool) {
+	trimmed, hasPrefix := strings.CutPrefix(fileName, prefix)
+	trimmed, hasSuffix := strings.CutSuffix(trimmed, suffix)
+
+	if !hasPrefix || !hasSuffix {
+		return "", false
+	}
+
+	// os.CreateTemp replaces the asterisk with a random decimal number, which cannot contain the separator.
+	separatorIndex := strings.LastIndexByte(trimmed, '-')
+	if separatorIndex <= 0 || !isDecimal(trimmed[separatorIndex+1:]) {
+		return "", false
+	}
+
+	return trimmed[:separatorIndex], true
+}
+
+// isDecimal returns true if text is a non-empty string of decimal digits.
+func isDecimal(text string) bool {
+	return text != "" && strings.Trim(text, "0123456789") == ""
+}
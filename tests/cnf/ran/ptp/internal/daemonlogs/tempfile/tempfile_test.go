@@ -0,0 +1,41 @@
+package tempfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeFromName(t *testing.T) {
+	testCases := []struct {
+		name         string
+		fileName     string
+		expectedNode string
+		expectedOk   bool
+	}{
+		{
+			name:         "node in name",
+			fileName:     "ptp-daemon-logs-worker-0-1234567.log",
+			expectedNode: "worker-0",
+			expectedOk:   true,
+		},
+		{
+			name:         "fully qualified node",
+			fileName:     "ptp-daemon-logs-sno.example.com-42.log",
+			expectedNode: "sno.example.com",
+			expectedOk:   true,
+		},
+		{name: "no node", fileName: "ptp-daemon-logs-1234567.log", expectedOk: false},
+		{name: "random part not decimal", fileName: "ptp-daemon-logs-worker-0-abc.log", expectedOk: false},
+		{name: "empty node", fileName: "ptp-daemon-logs--1234567.log", expectedOk: false},
+		{name: "other file", fileName: "daemon.log", expectedOk: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			node, ok := NodeFromName(testCase.fileName)
+			assert.Equal(t, testCase.expectedOk, ok)
+			assert.Equal(t, testCase.expectedNode, node)
+		})
+	}
+}
@@ -10,12 +10,8 @@ import (
 	"time"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
-	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/ptpdaemon"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/tsparams"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 )
 
@@ -72,8 +68,10 @@ func WithTimeout(timeout time.Duration) WaitForPodLogOption {
 	}
 }
 
-// WithPollingInterval sets the interval between polling attempts. Values less than or equal to zero will print a log
-// and this option will be a no-op. It defaults to 1 second.
+// WithPollingInterval sets the interval between attempts to reconnect to the PTP daemon pod logs when the stream ends,
+// such as while the pod is being recreated. It only applies if the logs on the node are not already followed for
+// another subscription. Values less than or equal to zero will print a log and this option will be a no-op. It
+// defaults to 1 second.
 func WithPollingInterval(interval time.Duration) WaitForPodLogOption {
 	if interval <= 0 {
 		klog.V(tsparams.LogLevel).Infof("Polling interval cannot be less than or equal to zero, falling back to the default")
@@ -116,9 +114,11 @@ func getDefaultWaitForPodLogOptions() *waitForPodLogOptions {
 	}
 }
 
-// WaitForPodLog waits for a message to appear in the PTP daemon pod logs on the specified node. It polls the logs at
-// the specified interval until either the matcher function returns true for a log line or the timeout is reached. Note
-// that the function will lookup the PTP daemon pod on each poll to account for the pod being deleted and recreated.
+// WaitForPodLog waits for a message to appear in the PTP daemon pod logs on the specified node. It follows the logs
+// starting at the start time until either the matcher function returns true for a log line or the timeout is reached,
+// so matching lines are found as soon as they are logged. The stream is reopened if it ends to account for the pod
+// being deleted and recreated. Logs are followed through SubscribeDaemonLogs, so concurrent waiters on the same node
+// share one stream. If the timeout is reached, the returned error wraps context.DeadlineExceeded.
 func WaitForPodLog(client *clients.Settings, nodeName string, options ...WaitForPodLogOption) error {
 	logOptions := getDefaultWaitForPodLogOptions()
 
@@ -130,48 +130,24 @@ func WaitForPodLog(client *clients.Settings, nodeName string, options ...WaitFor
 		return fmt.Errorf("matcher function must be provided using WithMatcher option")
 	}
 
-	// Track the last time we successfully fetched logs to avoid missing log entries between polls.
-	lastFetchTime := logOptions.startTime
+	subscription := subscribeDaemonLogs(client, nodeName, logOptions.startTime, logOptions.pollingInterval)
+	defer subscription.Close()
 
-	return wait.PollUntilContextTimeout(
-		context.TODO(), logOptions.pollingInterval, logOptions.timeout, true, func(ctx context.Context) (bool, error) {
-			// Get the PTP daemon pod on each poll to handle pod restarts.
-			daemonPod, err := ptpdaemon.GetPtpDaemonPodOnNode(client, nodeName)
-			if err != nil {
-				klog.V(tsparams.LogLevel).Infof("Failed to get PTP daemon pod on node %s: %v", nodeName, err)
-
-				return false, nil
-			}
-
-			// We save the time of the next last fetch before getting the logs to avoid missing log entries
-			// between polls. Once the logs are fetched, we update the last fetch time to this time.
-			localFetchTime := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), logOptions.timeout)
+	defer cancel()
 
-			logs, err := daemonPod.GetLogsWithOptions(&corev1.PodLogOptions{
-				SinceTime: &metav1.Time{Time: lastFetchTime},
-				Container: ranparam.PtpContainerName,
-			})
-			if err != nil {
-				klog.V(tsparams.LogLevel).Infof("Failed to get logs starting at %s for PTP daemon pod on node %s: %v",
-					lastFetchTime, nodeName, err)
-
-				return false, nil
-			}
-
-			// Update the last fetch time to the current time before processing logs. This could cause
-			// duplicates, but this will not affect the result.
-			lastFetchTime = localFetchTime
+	line, err := subscription.WaitForMatch(ctx, logOptions.matcher)
+	if err != nil {
+		if followErrors := subscription.Errors(); len(followErrors) > 0 {
+			err = fmt.Errorf("%w (errors following logs: %w)", err, errors.Join(followErrors...))
+		}
 
-			for line := range strings.SplitSeq(string(logs), "\n") {
-				if logOptions.matcher(line) {
-					klog.V(tsparams.LogLevel).Infof("Found matching log line in PTP daemon pod on node %s: %q", nodeName, line)
+		return fmt.Errorf("failed to find matching log line in PTP daemon pod on node %s: %w", nodeName, err)
+	}
 
-					return true, nil
-				}
-			}
+	klog.V(tsparams.LogLevel).Infof("Found matching log line in PTP daemon pod on node %s: %q", nodeName, line.Text)
 
-			return false, nil
-		})
+	return nil
 }
 
 // profileLoadMessage is the message that appears in the linuxptp-daemon-container logs when the profiles are loaded.
@@ -22,6 +22,11 @@
 // When [ExecuteAssertion] is called, the package automatically checks if events are enabled on the cluster before
 // running the event assertion. If events are disabled, only the metric assertion runs. This allows tests to work
 // correctly regardless of whether events are configured.
+//
+// # Recorded Window
+//
+// Use [WithRecordedWindow] with the recorder from [ptptimeline.Current] to check for events and metrics since the start
+// of the spec and to add the outcome of the assertion to the PTP timeline written when the spec fails.
 package eventmetric
 
 import (
@@ -37,6 +42,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/consumer"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/events"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/metrics"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/ptptimeline"
 	"golang.org/x/exp/constraints"
 )
 
@@ -75,6 +81,9 @@ type AssertConfig[V constraints.Integer] struct {
 
 	// NodeName is the name of the node to use for the event assertion.
 	NodeName string
+
+	// Recorder is the PTP timeline recorder that the outcome of the assertion is added to. It may be nil.
+	Recorder *ptptimeline.Recorder
 }
 
 // NewAssertion creates a new assertion config with the core assertion parameters. The type parameter V is inferred from
@@ -116,6 +125,20 @@ func (assertConfig *AssertConfig[V]) WithStartTime(startTime time.Time) *AssertC
 	return assertConfig
 }
 
+// WithRecordedWindow sets the start time for the assertion to the start of the window recorded by recorder and adds the
+// outcome of the assertion to the timeline of recorder. This allows asserting that an event and metric occurred at any
+// point during the spec. If recorder is nil, the start time is left unchanged and nothing is recorded.
+func (assertConfig *AssertConfig[V]) WithRecordedWindow(recorder *ptptimeline.Recorder) *AssertConfig[V] {
+	if recorder == nil {
+		return assertConfig
+	}
+
+	assertConfig.Recorder = recorder
+	assertConfig.StartTime = recorder.Window().Start
+
+	return assertConfig
+}
+
 // WithTimeout sets the timeout for the assertion.
 func (assertConfig *AssertConfig[V]) WithTimeout(timeout time.Duration) *AssertConfig[V] {
 	assertConfig.Timeout = timeout
@@ -199,9 +222,22 @@ func (assertConfig *AssertConfig[V]) ExecuteAssertion(ctx context.Context) error
 		combinedErr = errors.Join(combinedErr, err)
 	}
 
+	assertConfig.record(startTime, combinedErr)
+
 	return combinedErr
 }
 
+// record adds the outcome of the assertion, which started checking at startTime, to the timeline of the recorder.
+func (assertConfig *AssertConfig[V]) record(startTime time.Time, assertErr error) {
+	assertConfig.Recorder.Add(ptptimeline.AssertionEntry(
+		time.Now(),
+		assertConfig.NodeName,
+		assertConfig.MetricQuery.ToMetricQuery().String(),
+		int64(assertConfig.ExpectedMetricValue),
+		startTime,
+		assertErr))
+}
+
 // validate validates the assert config. It ensures all the required options are provided.
 func (assertConfig *AssertConfig[V]) validate() error {
 	if isInterfaceNil(assertConfig.PrometheusAPI) {
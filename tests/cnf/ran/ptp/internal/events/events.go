@@ -91,7 +91,7 @@ func WaitForEvent(
 
 			klog.V(tsparams.LogLevel).Infof("Logs: %s", string(logs))
 
-			extractedEvents := extractEventsFromLogs(logs, combinedOptions.ignoreCurrentState)
+			extractedEvents := ExtractEventsFromLogs(logs, combinedOptions.ignoreCurrentState)
 
 			klog.V(tsparams.LogLevel).Infof("Extracted events: %#v\nFilter: %#v", extractedEvents, filter)
 
@@ -99,10 +99,11 @@ func WaitForEvent(
 		})
 }
 
-// extractEventsFromLogs extracts events from the logs of either the cloud event consumer or the cloud event proxy
-// containers. Rather than return errors, this function logs them and ignores the line. All lines that were able to be
-// parsed into events are returned.
-func extractEventsFromLogs(logs []byte, ignoreCurrentState bool) []event.Event {
+// ExtractEventsFromLogs extracts events from the logs of either the cloud event consumer or the cloud event proxy
+// containers. If ignoreCurrentState is true, only events received through a subscription are extracted. Rather than
+// return errors, this function logs them and ignores the line. All lines that were able to be parsed into events are
+// returned.
+func ExtractEventsFromLogs(logs []byte, ignoreCurrentState bool) []event.Event {
 	var extractedEvents []event.Event
 
 	for line := range bytes.Lines(logs) {
@@ -0,0 +1,360 @@
+// Package linuxptp models the configuration files of the linuxptp daemons: ptp4l, phc2sys, ts2phc, and synce4l. Files
+// are parsed into a Config that keeps every line, including comments and formatting, so that a Config serialized
+// without modification is identical to the original text and modifications only touch the lines they change.
+//
+// Tests that need to tweak a PtpProfile should parse it with ParseProfile, modify the typed configs, and write them
+// back with ProfileConfigs.Apply. Lint and LintProfile check a ptp4l config against the G.8275.1 and G.8275.2 telecom
+// profiles, explaining each violation.
+package linuxptp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GlobalSection is the name of the section containing the options that apply to the whole daemon.
+const GlobalSection = "global"
+
+// lineKind is the kind of a single line of a config file.
+type lineKind int
+
+const (
+	// lineOther is a blank line, a comment, or a line that could not be parsed. It is kept but otherwise ignored.
+	lineOther lineKind = iota
+	// lineOption is an option, which is a key followed by whitespace and a value.
+	lineOption
+)
+
+// line is a single line of a config file. Raw always holds the exact text of the line, including its line ending.
+type line struct {
+	raw   string
+	kind  lineKind
+	key   string
+	value string
+}
+
+// Section is a named section of a Config and the lines that follow its header.
+type Section struct {
+	// Name is the name of the section, without the surrounding brackets.
+	Name string
+
+	header string
+	lines  []line
+}
+
+// Config is a parsed linuxptp configuration file.
+type Config struct {
+	// preamble is the lines before the first section header. The daemons reject options outside a section, so these
+	// are kept but ignored.
+	preamble []line
+	sections []*Section
+}
+
+// Parse parses the text of a linuxptp configuration file. Parsing never fails: lines that are not a section header or
+// an option are kept as is and ignored, the same as the daemons ignore comments.
+func Parse(text string) *Config {
+	config := &Config{}
+
+	var current *Section
+
+	for raw := range strings.Lines(text) {
+		trimmed := strings.TrimSpace(raw)
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") && len(trimmed) > 2 {
+			current = &Section{Name: trimmed[1 : len(trimmed)-1], header: raw}
+			config.sections = append(config.sections, current)
+
+			continue
+		}
+
+		parsed := parseLine(raw)
+
+		if current == nil {
+			config.preamble = append(config.preamble, parsed)
+
+			continue
+		}
+
+		current.lines = append(current.lines, parsed)
+	}
+
+	return config
+}
+
+// parseLine parses a single line that is not a section header.
+func parseLine(raw string) line {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return line{raw: raw}
+	}
+
+	key, value, found := strings.Cut(trimmed, " ")
+	if tabKey, tabValue, tabFound := strings.Cut(trimmed, "\t"); tabFound && (!found || len(tabKey) < len(key)) {
+		key, value, found = tabKey, tabValue, true
+	}
+
+	value = strings.TrimSpace(value)
+	if !found || value == "" {
+		return line{raw: raw}
+	}
+
+	return line{raw: raw, kind: lineOption, key: key, value: value}
+}
+
+// String serializes the config. If the config has not been modified, the result is identical to the text it was parsed
+// from.
+func (config *Config) String() string {
+	var builder strings.Builder
+
+	for _, line := range config.preamble {
+		builder.WriteString(line.raw)
+	}
+
+	for _, section := range config.sections {
+		builder.WriteString(section.header)
+
+		for _, line := range section.lines {
+			builder.WriteString(line.raw)
+		}
+	}
+
+	return builder.String()
+}
+
+// Sections returns the sections of the config in the order they appear.
+func (config *Config) Sections() []*Section {
+	return config.sections
+}
+
+// SectionNames returns the names of the sections of the config in the order they appear.
+func (config *Config) SectionNames() []string {
+	names := make([]string, 0, len(config.sections))
+
+	for _, section := range config.sections {
+		names = append(names, section.Name)
+	}
+
+	return names
+}
+
+// Section returns the first section with the provided name, or nil if there is none.
+func (config *Config) Section(name string) *Section {
+	for _, section := range config.sections {
+		if section.Name == name {
+			return section
+		}
+	}
+
+	return nil
+}
+
+// EnsureSection returns the first section with the provided name, adding an empty one to the end of the config if there
+// is none.
+func (config *Config) EnsureSection(name string) *Section {
+	if section := config.Section(name); section != nil {
+		return section
+	}
+
+	config.terminateLastLine()
+
+	section := &Section{Name: name, header: "[" + name + "]\n"}
+	config.sections = append(config.sections, section)
+
+	return section
+}
+
+// RemoveSection removes every section with the provided name and returns whether any was removed.
+func (config *Config) RemoveSection(name string) bool {
+	removed := false
+
+	for index := len(config.sections) - 1; index >= 0; index-- {
+		if config.sections[index].Name == name {
+			config.sections = append(config.sections[:index], config.sections[index+1:]...)
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// Get returns the value of key in the named section. If the section or key does not exist, ok is false.
+func (config *Config) Get(section, key string) (value string, ok bool) {
+	if found := config.Section(section); found != nil {
+		return found.Get(key)
+	}
+
+	return "", false
+}
+
+// GetInt returns the value of key in the named section parsed as an integer. If the section or key does not exist or
+// the value is not an integer, ok is false.
+func (config *Config) GetInt(section, key string) (value int, ok bool) {
+	if found := config.Section(section); found != nil {
+		return found.GetInt(key)
+	}
+
+	return 0, false
+}
+
+// Set sets key to value in the named section, adding the section if it does not exist.
+func (config *Config) Set(section, key, value string) {
+	config.EnsureSection(section).Set(key, value)
+}
+
+// Delete removes key from the named section and returns whether it was present.
+func (config *Config) Delete(section, key string) bool {
+	if found := config.Section(section); found != nil {
+		return found.Delete(key)
+	}
+
+	return false
+}
+
+// terminateLastLine ensures the last line of the config ends with a newline, so that lines can be appended after it.
+func (config *Config) terminateLastLine() {
+	if len(config.sections) == 0 {
+		if len(config.preamble) > 0 {
+			terminate(&config.preamble[len(config.preamble)-1].raw)
+		}
+
+		return
+	}
+
+	config.sections[len(config.sections)-1].terminateLastLine()
+}
+
+// Keys returns the keys of the options of the section in the order they appear. Keys that appear more than once are
+// only returned once.
+func (section *Section) Keys() []string {
+	var (
+		keys []string
+		seen = make(map[string]bool)
+	)
+
+	for _, line := range section.lines {
+		if line.kind == lineOption && !seen[line.key] {
+			keys = append(keys, line.key)
+			seen[line.key] = true
+		}
+	}
+
+	return keys
+}
+
+// Options returns the options of the section as a map. If a key appears more than once, the last value is used, the
+// same as the daemons do.
+func (section *Section) Options() map[string]string {
+	options := make(map[string]string)
+
+	for _, line := range section.lines {
+		if line.kind == lineOption {
+			options[line.key] = line.value
+		}
+	}
+
+	return options
+}
+
+// Get returns the value of key in the section. If the key appears more than once, the last value is returned.
+func (section *Section) Get(key string) (value string, ok bool) {
+	index := section.lastIndex(key)
+	if index < 0 {
+		return "", false
+	}
+
+	return section.lines[index].value, true
+}
+
+// GetInt returns the value of key in the section parsed as an integer. If the key does not exist or the value is not an
+// integer, ok is false.
+func (section *Section) GetInt(key string) (value int, ok bool) {
+	raw, ok := section.Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// Set sets key to value. If the key already exists, its last occurrence is updated in place, keeping the indentation
+// and separator of the line. Otherwise, the option is added after the last option of the section.
+func (section *Section) Set(key, value string) {
+	if index := section.lastIndex(key); index >= 0 {
+		existing := section.lines[index]
+		keyStart := strings.Index(existing.raw, key)
+		valueStart := keyStart + len(key) + strings.Index(existing.raw[keyStart+len(key):], existing.value)
+		lineEnd := existing.raw[len(strings.TrimRight(existing.raw, "\r\n")):]
+
+		section.lines[index] = line{
+			raw:   existing.raw[:valueStart] + value + lineEnd,
+			kind:  lineOption,
+			key:   key,
+			value: value,
+		}
+
+		return
+	}
+
+	insertAt := len(section.lines)
+	for insertAt > 0 && section.lines[insertAt-1].kind != lineOption &&
+		strings.TrimSpace(section.lines[insertAt-1].raw) == "" {
+		insertAt--
+	}
+
+	if insertAt > 0 {
+		terminate(&section.lines[insertAt-1].raw)
+	} else {
+		terminate(&section.header)
+	}
+
+	added := line{raw: key + " " + value + "\n", kind: lineOption, key: key, value: value}
+	section.lines = append(section.lines[:insertAt], append([]line{added}, section.lines[insertAt:]...)...)
+}
+
+// Delete removes every occurrence of key from the section and returns whether it was present.
+func (section *Section) Delete(key string) bool {
+	removed := false
+
+	for index := len(section.lines) - 1; index >= 0; index-- {
+		if section.lines[index].kind == lineOption && section.lines[index].key == key {
+			section.lines = append(section.lines[:index], section.lines[index+1:]...)
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// lastIndex returns the index of the last line setting key, or -1 if there is none.
+func (section *Section) lastIndex(key string) int {
+	for index := len(section.lines) - 1; index >= 0; index-- {
+		if section.lines[index].kind == lineOption && section.lines[index].key == key {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// terminateLastLine ensures the last line of the section ends with a newline.
+func (section *Section) terminateLastLine() {
+	if len(section.lines) == 0 {
+		terminate(&section.header)
+
+		return
+	}
+
+	terminate(&section.lines[len(section.lines)-1].raw)
+}
+
+// terminate appends a newline to raw if it does not already end with one.
+func terminate(raw *string) {
+	if !strings.HasSuffix(*raw, "\n") {
+		*raw += "\n"
+	}
+}
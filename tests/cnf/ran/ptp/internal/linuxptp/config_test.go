@@ -0,0 +1,214 @@
+package linuxptp
+
+import (
+	"testing"
+
+	ptpv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/ptp/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+const testPtp4lConf = `# comment before the first section
+[ens1f0]
+masterOnly 0
+[ens1f1]
+masterOnly	1
+[global]
+#
+# Default Data Set
+#
+twoStepFlag 1
+domainNumber   24
+clockClass 248
+
+dataset_comparison G.8275.x
+  logSyncInterval -4
+[unicast_master_table]
+table_id 1`
+
+func TestParseRoundTrip(t *testing.T) {
+	testCases := []string{
+		"",
+		"\n\n",
+		testPtp4lConf,
+		testPtp4lConf + "\n",
+		"[global]\r\nkey value\r\n",
+		"no section\n[global]\nkeyWithoutValue\n[]\n",
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase, Parse(testCase).String())
+	}
+}
+
+func TestParse(t *testing.T) {
+	config := Parse(testPtp4lConf)
+
+	assert.Equal(t, []string{"ens1f0", "ens1f1", "global", "unicast_master_table"}, config.SectionNames())
+	assert.Equal(t, []string{"twoStepFlag", "domainNumber", "clockClass", "dataset_comparison", "logSyncInterval"},
+		config.Section(GlobalSection).Keys())
+
+	testCases := []struct {
+		section       string
+		key           string
+		expectedValue string
+		expectedOk    bool
+	}{
+		{section: "ens1f1", key: "masterOnly", expectedValue: "1", expectedOk: true},
+		{section: GlobalSection, key: "domainNumber", expectedValue: "24", expectedOk: true},
+		{section: GlobalSection, key: "logSyncInterval", expectedValue: "-4", expectedOk: true},
+		{section: GlobalSection, key: "priority1", expectedOk: false},
+		{section: "ens2f0", key: "masterOnly", expectedOk: false},
+	}
+
+	for _, testCase := range testCases {
+		value, ok := config.Get(testCase.section, testCase.key)
+
+		assert.Equal(t, testCase.expectedOk, ok)
+		assert.Equal(t, testCase.expectedValue, value)
+	}
+
+	domainNumber, ok := config.GetInt(GlobalSection, "domainNumber")
+	assert.True(t, ok)
+	assert.Equal(t, 24, domainNumber)
+
+	_, ok = config.GetInt(GlobalSection, "dataset_comparison")
+	assert.False(t, ok)
+}
+
+func TestConfigModify(t *testing.T) {
+	config := Parse(testPtp4lConf)
+
+	config.Set(GlobalSection, "domainNumber", "25")
+	config.Set(GlobalSection, "logSyncInterval", "-3")
+	config.Set(GlobalSection, "priority2", "127")
+	config.Set("ens1f0", "masterOnly", "1")
+	config.Set("unicast_master_table", "logQueryInterval", "2")
+	config.Set("ens2f0", "masterOnly", "0")
+	assert.True(t, config.Delete(GlobalSection, "twoStepFlag"))
+	assert.False(t, config.Delete(GlobalSection, "twoStepFlag"))
+
+	expected := `# comment before the first section
+[ens1f0]
+masterOnly 1
+[ens1f1]
+masterOnly	1
+[global]
+#
+# Default Data Set
+#
+domainNumber   25
+clockClass 248
+
+dataset_comparison G.8275.x
+  logSyncInterval -3
+priority2 127
+[unicast_master_table]
+table_id 1
+logQueryInterval 2
+[ens2f0]
+masterOnly 0
+`
+
+	assert.Equal(t, expected, config.String())
+	assert.Equal(t, expected, Parse(config.String()).String())
+
+	assert.True(t, config.RemoveSection("ens2f0"))
+	assert.False(t, config.RemoveSection("ens2f0"))
+	assert.Nil(t, config.Section("ens2f0"))
+}
+
+func TestConfigModifyTrailingBlankLines(t *testing.T) {
+	config := Parse("[global]\nkey value\n\n[ens1f0]\n")
+
+	config.Set(GlobalSection, "other", "1")
+	config.Set("ens1f0", "masterOnly", "1")
+
+	assert.Equal(t, "[global]\nkey value\nother 1\n\n[ens1f0]\nmasterOnly 1\n", config.String())
+}
+
+func TestPtp4lConfig(t *testing.T) {
+	config := ParsePtp4l(testPtp4lConf)
+
+	assert.Equal(t, []string{"ens1f0", "ens1f1"}, config.Ports())
+	assert.False(t, config.ClientOnly())
+	assert.False(t, config.ServerOnly("ens1f0"))
+	assert.True(t, config.ServerOnly("ens1f1"))
+
+	value, ok := config.PortOption("ens1f0", "domainNumber")
+	assert.True(t, ok)
+	assert.Equal(t, "24", value)
+
+	config.Set(GlobalSection, "slaveOnly", "1")
+	assert.True(t, config.ClientOnly())
+}
+
+func TestTs2phcConfig(t *testing.T) {
+	testCases := []struct {
+		text            string
+		expectedSources []string
+		expectedSinks   []string
+	}{
+		{
+			text:            "[nmea]\nts2phc.master 1\n[ens1f0]\nts2phc.master  1\n",
+			expectedSources: []string{"nmea", "ens1f0"},
+		},
+		{
+			text:            "[nmea]\nts2phc.master 1\n[global]\nts2phc.nmea_serialport /dev/gnss0\n[ens4f0]\n",
+			expectedSources: []string{"nmea"},
+		},
+		{
+			text:            "[global]\n[ens1f0]\nts2phc.master 1\n[ens2f0]\nts2phc.master 0\n[ens3f0]\n",
+			expectedSources: []string{"ens1f0"},
+			expectedSinks:   []string{"ens2f0"},
+		},
+		{
+			text: "[global]\nts2phc.pulsewidth 100000000\n[ens1f0]\nts2phc.extts_polarity rising\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		config := ParseTs2phc(testCase.text)
+
+		assert.Equal(t, testCase.expectedSources, config.Sources())
+		assert.Equal(t, testCase.expectedSinks, config.Sinks())
+	}
+}
+
+func TestSynce4lConfig(t *testing.T) {
+	config := ParseSynce4l(
+		"[global]\nlogging_level 7\n[<synce1>]\nnetwork_option 1\n[ens1f0]\ntx_heartbeat_msec 1000\n[{SMA1}]\n")
+
+	assert.Equal(t, []string{"synce1"}, config.Devices())
+	assert.Equal(t, []string{"SMA1"}, config.ExternalSources())
+	assert.Equal(t, []string{"ens1f0"}, config.Ports())
+}
+
+func TestProfileConfigs(t *testing.T) {
+	profile := ptpv1.PtpProfile{
+		Ptp4lConf:   ptr.To(testPtp4lConf),
+		Phc2sysConf: ptr.To(""),
+		Ts2PhcConf:  ptr.To("[ens1f0]\nts2phc.master 1\n"),
+	}
+
+	configs := ParseProfile(profile)
+
+	assert.NotNil(t, configs.Ptp4l)
+	assert.NotNil(t, configs.Phc2sys)
+	assert.NotNil(t, configs.Ts2phc)
+	assert.Nil(t, configs.Synce4l)
+
+	// Applying unmodified configs leaves the profile unchanged.
+	unmodified := profile
+	configs.Apply(&unmodified)
+	assert.Equal(t, profile, unmodified)
+
+	configs.Ptp4l.Set(GlobalSection, "domainNumber", "25")
+	configs.Phc2sys.Set(GlobalSection, "step_threshold", "2.0")
+	configs.Apply(&profile)
+
+	assert.Contains(t, *profile.Ptp4lConf, "domainNumber   25\n")
+	assert.Equal(t, "[global]\nstep_threshold 2.0\n", *profile.Phc2sysConf)
+	assert.Equal(t, "[ens1f0]\nts2phc.master 1\n", *profile.Ts2PhcConf)
+	assert.Nil(t, profile.Synce4lConf)
+}
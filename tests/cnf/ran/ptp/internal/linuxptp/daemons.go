@@ -0,0 +1,189 @@
+package linuxptp
+
+import "strings"
+
+const (
+	// UnicastMasterTableSection is the name of the ptp4l section listing unicast servers. Unlike other non-global
+	// sections, it does not name a port.
+	UnicastMasterTableSection = "unicast_master_table"
+	// NMEASection is the name of the ts2phc section configuring the NMEA source.
+	NMEASection = "nmea"
+)
+
+// Ptp4lConfig is the config file of ptp4l. Sections other than global and unicast_master_table configure the port with
+// the same name as the section, and options set for a port override the same option in the global section.
+type Ptp4lConfig struct {
+	*Config
+}
+
+// ParsePtp4l parses the text of a ptp4l config file.
+func ParsePtp4l(text string) Ptp4lConfig {
+	return Ptp4lConfig{Config: Parse(text)}
+}
+
+// Ports returns the names of the ports configured in the config in the order they appear.
+func (config Ptp4lConfig) Ports() []string {
+	var ports []string
+
+	for _, name := range config.SectionNames() {
+		if name != GlobalSection && name != UnicastMasterTableSection {
+			ports = append(ports, name)
+		}
+	}
+
+	return ports
+}
+
+// PortOption returns the value of key for the provided port, falling back to the global section if the port does not
+// set it. If port is empty, only the global section is checked.
+func (config Ptp4lConfig) PortOption(port, key string) (value string, ok bool) {
+	if port != "" {
+		if value, ok := config.Get(port, key); ok {
+			return value, true
+		}
+	}
+
+	return config.Get(GlobalSection, key)
+}
+
+// ClientOnly returns whether the global section makes every port a client, through either clientOnly or the deprecated
+// slaveOnly.
+func (config Ptp4lConfig) ClientOnly() bool {
+	return isEnabled(config.Config, GlobalSection, "clientOnly") || isEnabled(config.Config, GlobalSection, "slaveOnly")
+}
+
+// ServerOnly returns whether the provided port is a server only port, through either serverOnly or the deprecated
+// masterOnly set on the port or in the global section.
+func (config Ptp4lConfig) ServerOnly(port string) bool {
+	for _, key := range []string{"serverOnly", "masterOnly"} {
+		if value, ok := config.PortOption(port, key); ok && value == "1" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Phc2sysConfig is the config file of phc2sys. It uses the same format as ptp4l, with options for the clocks to
+// synchronize in sections named after them.
+type Phc2sysConfig struct {
+	*Config
+}
+
+// ParsePhc2sys parses the text of a phc2sys config file.
+func ParsePhc2sys(text string) Phc2sysConfig {
+	return Phc2sysConfig{Config: Parse(text)}
+}
+
+// Ts2phcConfig is the config file of ts2phc. Sections other than global and nmea configure the interface with the same
+// name as the section, which is either a time source or a time sink depending on ts2phc.master.
+type Ts2phcConfig struct {
+	*Config
+}
+
+// ParseTs2phc parses the text of a ts2phc config file.
+func ParseTs2phc(text string) Ts2phcConfig {
+	return Ts2phcConfig{Config: Parse(text)}
+}
+
+// Interfaces returns the names of the interfaces configured in the config in the order they appear.
+func (config Ts2phcConfig) Interfaces() []string {
+	var interfaces []string
+
+	for _, name := range config.SectionNames() {
+		if name != GlobalSection && name != NMEASection {
+			interfaces = append(interfaces, name)
+		}
+	}
+
+	return interfaces
+}
+
+// Sources returns the sections with ts2phc.master set to 1, which provide time to the other interfaces. This includes
+// the nmea section when the NMEA sentences of a GNSS receiver are the time source, as in grandmaster configs.
+func (config Ts2phcConfig) Sources() []string {
+	var sources []string
+
+	for _, name := range config.SectionNames() {
+		if name != GlobalSection && isEnabled(config.Config, name, "ts2phc.master") {
+			sources = append(sources, name)
+		}
+	}
+
+	return sources
+}
+
+// Sinks returns the interfaces with ts2phc.master explicitly set to 0, which receive time from a source on another NIC.
+func (config Ts2phcConfig) Sinks() []string {
+	var sinks []string
+
+	for _, name := range config.Interfaces() {
+		if value, ok := config.Get(name, "ts2phc.master"); ok && value == "0" {
+			sinks = append(sinks, name)
+		}
+	}
+
+	return sinks
+}
+
+// Synce4lConfig is the config file of synce4l. Besides the global section, device sections have names between angle
+// brackets, external source sections have names between braces, and all other sections configure the port with the
+// same name as the section.
+type Synce4lConfig struct {
+	*Config
+}
+
+// ParseSynce4l parses the text of a synce4l config file.
+func ParseSynce4l(text string) Synce4lConfig {
+	return Synce4lConfig{Config: Parse(text)}
+}
+
+// Devices returns the names of the devices configured in the config, without the angle brackets.
+func (config Synce4lConfig) Devices() []string {
+	return config.enclosedSections("<", ">")
+}
+
+// ExternalSources returns the names of the external sources configured in the config, without the braces.
+func (config Synce4lConfig) ExternalSources() []string {
+	return config.enclosedSections("{", "}")
+}
+
+// Ports returns the names of the ports configured in the config in the order they appear.
+func (config Synce4lConfig) Ports() []string {
+	var ports []string
+
+	for _, name := range config.SectionNames() {
+		if name == GlobalSection || isEnclosed(name, "<", ">") || isEnclosed(name, "{", "}") {
+			continue
+		}
+
+		ports = append(ports, name)
+	}
+
+	return ports
+}
+
+// enclosedSections returns the names of the sections enclosed by prefix and suffix, with them removed.
+func (config Synce4lConfig) enclosedSections(prefix, suffix string) []string {
+	var names []string
+
+	for _, name := range config.SectionNames() {
+		if isEnclosed(name, prefix, suffix) {
+			names = append(names, name[len(prefix):len(name)-len(suffix)])
+		}
+	}
+
+	return names
+}
+
+// isEnclosed returns whether name starts with prefix and ends with suffix.
+func isEnclosed(name, prefix, suffix string) bool {
+	return len(name) > len(prefix)+len(suffix) && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix)
+}
+
+// isEnabled returns whether key is set to 1 in the named section of config.
+func isEnabled(config *Config, section, key string) bool {
+	value, ok := config.Get(section, key)
+
+	return ok && value == "1"
+}
@@ -0,0 +1,389 @@
+package linuxptp
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	ptpv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/ptp/v1"
+)
+
+// TelecomProfile is an ITU-T PTP telecom profile that a ptp4l config can be linted against.
+type TelecomProfile string
+
+const (
+	// TelecomProfileNone means no telecom profile applies and nothing is linted.
+	TelecomProfileNone TelecomProfile = ""
+	// TelecomProfileFullTiming is ITU-T G.8275.1, the profile for networks with full timing support from the network,
+	// where every node is PTP aware and messages use Ethernet multicast.
+	TelecomProfileFullTiming TelecomProfile = "G.8275.1"
+	// TelecomProfilePartialTiming is ITU-T G.8275.2, the profile for networks with partial timing support from the
+	// network, where messages use unicast IP.
+	TelecomProfilePartialTiming TelecomProfile = "G.8275.2"
+)
+
+// ValueSource is where the value of a ptp4l option comes from.
+type ValueSource string
+
+const (
+	// ValueSourceConfig means the option is set in the ptp4l config file.
+	ValueSourceConfig ValueSource = "ptp4lConf"
+	// ValueSourceOpts means the option is set on the ptp4l command line, which overrides the global section.
+	ValueSourceOpts ValueSource = "ptp4lOpts"
+	// ValueSourceDefault means the option is not set, so the linuxptp default is used.
+	ValueSourceDefault ValueSource = "linuxptp default"
+)
+
+// Violation is a ptp4l option whose value does not comply with a telecom profile.
+type Violation struct {
+	// Profile is the telecom profile that was violated.
+	Profile TelecomProfile
+	// Section is the section the value applies to, either the global section or a port.
+	Section string
+	// Option is the name of the ptp4l option.
+	Option string
+	// Value is the value of the option that ptp4l uses.
+	Value string
+	// Source is where Value comes from.
+	Source ValueSource
+	// Explanation describes what the telecom profile requires and why.
+	Explanation string
+}
+
+// String returns a single line describing the violation, such as:
+//
+//	G.8275.1 [global] domainNumber is 0 (linuxptp default): must be between 24 and 43 ...
+func (violation Violation) String() string {
+	return fmt.Sprintf("%s [%s] %s is %s (%s): %s",
+		violation.Profile, violation.Section, violation.Option, violation.Value, violation.Source, violation.Explanation)
+}
+
+// lintRule is a single requirement of a telecom profile on a ptp4l option.
+type lintRule struct {
+	option string
+	// perPort is whether the option may be set for individual ports rather than only in the global section.
+	perPort bool
+	// check returns whether value complies with the rule.
+	check func(value string) bool
+	// explanation describes what the rule requires and why.
+	explanation string
+}
+
+// linuxptpDefaults are the values ptp4l uses for the linted options when they are not set.
+var linuxptpDefaults = map[string]string{
+	"domainNumber":                   "0",
+	"network_transport":              "UDPv4",
+	"logAnnounceInterval":            "1",
+	"logSyncInterval":                "0",
+	"logMinDelayReqInterval":         "0",
+	"dataset_comparison":             "ieee1588",
+	"priority1":                      "128",
+	"clockClass":                     "248",
+	"ptp_dst_mac":                    "01:1B:19:00:00:00",
+	"G.8275.defaultDS.localPriority": "128",
+	"G.8275.portDS.localPriority":    "128",
+}
+
+// telecomClockClasses are the clock classes defined by both G.8275.1 and G.8275.2.
+var telecomClockClasses = []int{6, 7, 135, 140, 150, 160, 165, 248, 255}
+
+// commonRules are the rules shared by G.8275.1 and G.8275.2.
+var commonRules = []lintRule{
+	{
+		option:      "dataset_comparison",
+		check:       equals("G.8275.x"),
+		explanation: "must be G.8275.x since the telecom profiles use the alternate BMCA, which compares localPriority",
+	},
+	{
+		option:      "priority1",
+		check:       equals("128"),
+		explanation: "must be 128 since priority1 is not used by the alternate BMCA and is fixed by the telecom profiles",
+	},
+	{
+		option: "clockClass",
+		check: func(value string) bool {
+			class, err := strconv.Atoi(value)
+
+			return err == nil && slices.Contains(telecomClockClasses, class)
+		},
+		explanation: "must be one of 6, 7, 135, 140, 150, 160, 165, 248, or 255, the only clock classes the telecom " +
+			"profiles define",
+	},
+	{
+		option:      "G.8275.defaultDS.localPriority",
+		check:       inRange(1, 255),
+		explanation: "must be between 1 and 255, with a default of 128",
+	},
+	{
+		option:      "G.8275.portDS.localPriority",
+		perPort:     true,
+		check:       inRange(1, 255),
+		explanation: "must be between 1 and 255, with a default of 128",
+	},
+}
+
+// telecomRules are the rules of each telecom profile, including commonRules.
+var telecomRules = map[TelecomProfile][]lintRule{
+	TelecomProfileFullTiming: append([]lintRule{
+		{
+			option: "domainNumber",
+			check:  inRange(24, 43),
+			explanation: "must be between 24 and 43, with a default of 24, so that G.8275.1 clocks do not " +
+				"synchronize with clocks using other profiles",
+		},
+		{
+			option:      "network_transport",
+			perPort:     true,
+			check:       equals("L2"),
+			explanation: "must be L2 (ptp4l -2) since G.8275.1 uses the Ethernet mapping of PTP",
+		},
+		{
+			option:  "ptp_dst_mac",
+			perPort: true,
+			check: func(value string) bool {
+				return strings.EqualFold(value, "01:80:C2:00:00:0E") || strings.EqualFold(value, "01:1B:19:00:00:00")
+			},
+			explanation: "must be the non-forwardable 01:80:C2:00:00:0E or forwardable 01:1B:19:00:00:00 " +
+				"multicast address",
+		},
+		{
+			option:      "logAnnounceInterval",
+			perPort:     true,
+			check:       equals("-3"),
+			explanation: "must be -3 since G.8275.1 fixes the announce rate at 8 messages per second",
+		},
+		{
+			option:      "logSyncInterval",
+			perPort:     true,
+			check:       equals("-4"),
+			explanation: "must be -4 since G.8275.1 fixes the sync rate at 16 messages per second",
+		},
+		{
+			option:      "logMinDelayReqInterval",
+			perPort:     true,
+			check:       equals("-4"),
+			explanation: "must be -4 since G.8275.1 fixes the delay request rate at 16 messages per second",
+		},
+	}, commonRules...),
+	TelecomProfilePartialTiming: append([]lintRule{
+		{
+			option: "domainNumber",
+			check:  inRange(44, 63),
+			explanation: "must be between 44 and 63, with a default of 44, so that G.8275.2 clocks do not " +
+				"synchronize with clocks using other profiles",
+		},
+		{
+			option:  "network_transport",
+			perPort: true,
+			check: func(value string) bool {
+				return value == "UDPv4" || value == "UDPv6"
+			},
+			explanation: "must be UDPv4 or UDPv6 since G.8275.2 uses the IP mappings of PTP",
+		},
+		{
+			option:      "logAnnounceInterval",
+			perPort:     true,
+			check:       inRange(-3, 0),
+			explanation: "must be between -3 and 0 since G.8275.2 allows 1 to 8 announce messages per second",
+		},
+		{
+			option:      "logSyncInterval",
+			perPort:     true,
+			check:       inRange(-7, 0),
+			explanation: "must be between -7 and 0 since G.8275.2 allows 1 to 128 sync messages per second",
+		},
+		{
+			option:      "logMinDelayReqInterval",
+			perPort:     true,
+			check:       inRange(-7, 0),
+			explanation: "must be between -7 and 0 since G.8275.2 allows 1 to 128 delay requests per second",
+		},
+	}, commonRules...),
+}
+
+// LintProfile lints the ptp4l config and options of profile against telecomProfile. If telecomProfile is
+// TelecomProfileNone, it is detected using DetectTelecomProfile. Profiles without a ptp4l config, or for which no
+// telecom profile applies, have no violations.
+func LintProfile(profile ptpv1.PtpProfile, telecomProfile TelecomProfile) []Violation {
+	if profile.Ptp4lConf == nil {
+		return nil
+	}
+
+	ptp4lOpts := ""
+	if profile.Ptp4lOpts != nil {
+		ptp4lOpts = *profile.Ptp4lOpts
+	}
+
+	config := ParsePtp4l(*profile.Ptp4lConf)
+
+	if telecomProfile == TelecomProfileNone {
+		telecomProfile = DetectTelecomProfile(config, ptp4lOpts)
+	}
+
+	return Lint(config, ptp4lOpts, telecomProfile)
+}
+
+// DetectTelecomProfile returns the telecom profile config is meant to follow. Configs with dataset_comparison set to
+// G.8275.x are telecom profiles, which are G.8275.1 when using the L2 transport and G.8275.2 otherwise. For all other
+// configs, TelecomProfileNone is returned.
+func DetectTelecomProfile(config Ptp4lConfig, ptp4lOpts string) TelecomProfile {
+	options := newEffectiveOptions(config, ptp4lOpts)
+
+	if comparison, _ := options.get(GlobalSection, "dataset_comparison"); comparison != "G.8275.x" {
+		return TelecomProfileNone
+	}
+
+	if transport, _ := options.get(GlobalSection, "network_transport"); transport == "L2" {
+		return TelecomProfileFullTiming
+	}
+
+	return TelecomProfilePartialTiming
+}
+
+// Lint checks the options ptp4l uses given config and the ptp4lOpts command line against the rules of telecomProfile.
+// Options are checked in the global section, including the command line and linuxptp defaults, and options that may be
+// set per port are additionally checked in each port that sets them.
+func Lint(config Ptp4lConfig, ptp4lOpts string, telecomProfile TelecomProfile) []Violation {
+	rules := telecomRules[telecomProfile]
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var violations []Violation
+
+	options := newEffectiveOptions(config, ptp4lOpts)
+	sections := append([]string{GlobalSection}, config.Ports()...)
+
+	for _, section := range sections {
+		for _, rule := range rules {
+			if section != GlobalSection && !rule.perPort {
+				continue
+			}
+
+			// Ports that do not set the option use the global value, which has already been checked.
+			if _, ok := config.Get(section, rule.option); section != GlobalSection && !ok {
+				continue
+			}
+
+			value, source := options.get(section, rule.option)
+
+			if rule.check(value) {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Profile:     telecomProfile,
+				Section:     section,
+				Option:      rule.option,
+				Value:       value,
+				Source:      source,
+				Explanation: rule.explanation,
+			})
+		}
+	}
+
+	return violations
+}
+
+// effectiveOptions resolves the values ptp4l uses for options from the config, command line, and defaults.
+type effectiveOptions struct {
+	config    Ptp4lConfig
+	overrides map[string]string
+}
+
+// newEffectiveOptions returns the effective options for config with the overrides from the ptp4lOpts command line.
+func newEffectiveOptions(config Ptp4lConfig, ptp4lOpts string) effectiveOptions {
+	return effectiveOptions{config: config, overrides: parsePtp4lOpts(ptp4lOpts)}
+}
+
+// get returns the value ptp4l uses for option in section and where it comes from. Options set in a port section take
+// precedence over the command line, which takes precedence over the global section.
+func (options effectiveOptions) get(section, option string) (string, ValueSource) {
+	if section != GlobalSection {
+		if value, ok := options.config.Get(section, option); ok {
+			return value, ValueSourceConfig
+		}
+	}
+
+	if value, ok := options.overrides[option]; ok {
+		return value, ValueSourceOpts
+	}
+
+	if value, ok := options.config.Get(GlobalSection, option); ok {
+		return value, ValueSourceConfig
+	}
+
+	return linuxptpDefaults[option], ValueSourceDefault
+}
+
+// ptp4lShortOptions are the ptp4l short options that set a config option, and the option and value they set.
+var ptp4lShortOptions = map[string][2]string{
+	"-2": {"network_transport", "L2"},
+	"-4": {"network_transport", "UDPv4"},
+	"-6": {"network_transport", "UDPv6"},
+	"-E": {"delay_mechanism", "E2E"},
+	"-P": {"delay_mechanism", "P2P"},
+	"-s": {"clientOnly", "1"},
+}
+
+// ptp4lShortOptionsWithArgument are the ptp4l short options that take an argument but do not set a config option.
+var ptp4lShortOptionsWithArgument = []string{"-f", "-i", "-p", "-l"}
+
+// parsePtp4lOpts returns the config options set by the ptp4l command line in ptp4lOpts. Long options may be given as
+// either --option=value or --option value, the same as ptp4l accepts.
+func parsePtp4lOpts(ptp4lOpts string) map[string]string {
+	overrides := make(map[string]string)
+	fields := strings.Fields(ptp4lOpts)
+
+	for index := 0; index < len(fields); index++ {
+		field := fields[index]
+
+		if setting, ok := ptp4lShortOptions[field]; ok {
+			overrides[setting[0]] = setting[1]
+
+			continue
+		}
+
+		if slices.Contains(ptp4lShortOptionsWithArgument, field) {
+			index++
+
+			continue
+		}
+
+		option, found := strings.CutPrefix(field, "--")
+		if !found {
+			continue
+		}
+
+		if key, value, hasValue := strings.Cut(option, "="); hasValue {
+			overrides[key] = value
+
+			continue
+		}
+
+		// Every ptp4l long option takes an argument, which may start with a dash, as in --summary_interval -4.
+		if index+1 < len(fields) {
+			overrides[option] = fields[index+1]
+			index++
+		}
+	}
+
+	return overrides
+}
+
+// equals returns a check that value is exactly expected.
+func equals(expected string) func(string) bool {
+	return func(value string) bool {
+		return value == expected
+	}
+}
+
+// inRange returns a check that value is an integer between low and high, inclusive.
+func inRange(low, high int) func(string) bool {
+	return func(value string) bool {
+		parsed, err := strconv.Atoi(value)
+
+		return err == nil && parsed >= low && parsed <= high
+	}
+}
@@ -0,0 +1,157 @@
+package linuxptp
+
+import (
+	"testing"
+
+	ptpv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/ptp/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+const testFullTimingConf = `[ens1f0]
+masterOnly 0
+[ens1f1]
+masterOnly 1
+[global]
+domainNumber 24
+dataset_comparison G.8275.x
+G.8275.defaultDS.localPriority 128
+logAnnounceInterval -3
+logSyncInterval -4
+logMinDelayReqInterval -4
+ptp_dst_mac 01:80:C2:00:00:0E
+clockClass 248
+`
+
+const testPartialTimingConf = `[global]
+domainNumber 44
+dataset_comparison G.8275.x
+network_transport UDPv4
+logAnnounceInterval 0
+logSyncInterval -6
+logMinDelayReqInterval -6
+[unicast_master_table]
+table_id 1
+UDPv4 192.0.2.1
+[ens1f0]
+unicast_master_table 1
+`
+
+// violationKey identifies a violation in tests without depending on its explanation.
+type violationKey struct {
+	Section string
+	Option  string
+	Value   string
+	Source  ValueSource
+}
+
+func TestDetectTelecomProfile(t *testing.T) {
+	testCases := []struct {
+		conf            string
+		ptp4lOpts       string
+		expectedProfile TelecomProfile
+	}{
+		{conf: testFullTimingConf, ptp4lOpts: "-2 -s", expectedProfile: TelecomProfileFullTiming},
+		{conf: testFullTimingConf, ptp4lOpts: "", expectedProfile: TelecomProfilePartialTiming},
+		{conf: testPartialTimingConf, ptp4lOpts: "", expectedProfile: TelecomProfilePartialTiming},
+		{conf: "[global]\nnetwork_transport L2\n", ptp4lOpts: "-2", expectedProfile: TelecomProfileNone},
+		{conf: "[global]\n", ptp4lOpts: "--dataset_comparison G.8275.x -2", expectedProfile: TelecomProfileFullTiming},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedProfile, DetectTelecomProfile(ParsePtp4l(testCase.conf), testCase.ptp4lOpts))
+	}
+}
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		name               string
+		conf               string
+		ptp4lOpts          string
+		profile            TelecomProfile
+		expectedViolations []violationKey
+	}{
+		{
+			name:      "compliant G.8275.1",
+			conf:      testFullTimingConf,
+			ptp4lOpts: "-2 --summary_interval -4",
+			profile:   TelecomProfileFullTiming,
+		},
+		{
+			name:    "compliant G.8275.2",
+			conf:    testPartialTimingConf,
+			profile: TelecomProfilePartialTiming,
+		},
+		{
+			name:    "no profile",
+			conf:    "[global]\n",
+			profile: TelecomProfileNone,
+		},
+		{
+			name:    "G.8275.1 missing transport",
+			conf:    testFullTimingConf,
+			profile: TelecomProfileFullTiming,
+			expectedViolations: []violationKey{
+				{Section: GlobalSection, Option: "network_transport", Value: "UDPv4", Source: ValueSourceDefault},
+			},
+		},
+		{
+			name:      "G.8275.1 wrong global and port values",
+			conf:      testFullTimingConf + "domainNumber 44\nclockClass 52\n[ens1f2]\nlogSyncInterval -3\n",
+			ptp4lOpts: "-2 --logAnnounceInterval=1",
+			profile:   TelecomProfileFullTiming,
+			expectedViolations: []violationKey{
+				{Section: GlobalSection, Option: "domainNumber", Value: "44", Source: ValueSourceConfig},
+				{Section: GlobalSection, Option: "logAnnounceInterval", Value: "1", Source: ValueSourceOpts},
+				{Section: GlobalSection, Option: "clockClass", Value: "52", Source: ValueSourceConfig},
+				{Section: "ens1f2", Option: "logSyncInterval", Value: "-3", Source: ValueSourceConfig},
+			},
+		},
+		{
+			name:    "G.8275.2 defaults",
+			conf:    "[global]\n[ens1f0]\nnetwork_transport L2\n",
+			profile: TelecomProfilePartialTiming,
+			expectedViolations: []violationKey{
+				{Section: GlobalSection, Option: "domainNumber", Value: "0", Source: ValueSourceDefault},
+				{Section: GlobalSection, Option: "logAnnounceInterval", Value: "1", Source: ValueSourceDefault},
+				{Section: GlobalSection, Option: "dataset_comparison", Value: "ieee1588", Source: ValueSourceDefault},
+				{Section: "ens1f0", Option: "network_transport", Value: "L2", Source: ValueSourceConfig},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		violations := Lint(ParsePtp4l(testCase.conf), testCase.ptp4lOpts, testCase.profile)
+
+		var keys []violationKey
+
+		for _, violation := range violations {
+			assert.Equal(t, testCase.profile, violation.Profile, testCase.name)
+			assert.NotEmpty(t, violation.Explanation, testCase.name)
+
+			keys = append(keys, violationKey{
+				Section: violation.Section,
+				Option:  violation.Option,
+				Value:   violation.Value,
+				Source:  violation.Source,
+			})
+		}
+
+		assert.Equal(t, testCase.expectedViolations, keys, testCase.name)
+	}
+}
+
+func TestLintProfile(t *testing.T) {
+	profile := ptpv1.PtpProfile{
+		Ptp4lOpts: ptr.To("-2 -s"),
+		Ptp4lConf: ptr.To(testFullTimingConf + "logSyncInterval 0\n"),
+	}
+
+	violations := LintProfile(profile, TelecomProfileNone)
+
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "G.8275.1 [global] logSyncInterval is 0 (ptp4lConf): must be -4 since G.8275.1 fixes the sync "+
+		"rate at 16 messages per second", violations[0].String())
+
+	assert.Empty(t, LintProfile(ptpv1.PtpProfile{Ptp4lOpts: ptr.To("-2")}, TelecomProfileFullTiming))
+}
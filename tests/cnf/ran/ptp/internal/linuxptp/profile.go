@@ -0,0 +1,67 @@
+package linuxptp
+
+import (
+	ptpv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/ptp/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ProfileConfigs are the linuxptp config files of a single PtpProfile. Configs that the profile does not set are nil.
+type ProfileConfigs struct {
+	Ptp4l   *Ptp4lConfig
+	Phc2sys *Phc2sysConfig
+	Ts2phc  *Ts2phcConfig
+	Synce4l *Synce4lConfig
+}
+
+// ParseProfile parses the linuxptp config files of profile. Configs that are nil in the profile are nil in the result,
+// while configs that are set but empty are parsed as empty configs so that options can be added to them.
+func ParseProfile(profile ptpv1.PtpProfile) ProfileConfigs {
+	var configs ProfileConfigs
+
+	if profile.Ptp4lConf != nil {
+		config := ParsePtp4l(*profile.Ptp4lConf)
+		configs.Ptp4l = &config
+	}
+
+	if profile.Phc2sysConf != nil {
+		config := ParsePhc2sys(*profile.Phc2sysConf)
+		configs.Phc2sys = &config
+	}
+
+	if profile.Ts2PhcConf != nil {
+		config := ParseTs2phc(*profile.Ts2PhcConf)
+		configs.Ts2phc = &config
+	}
+
+	if profile.Synce4lConf != nil {
+		config := ParseSynce4l(*profile.Synce4lConf)
+		configs.Synce4l = &config
+	}
+
+	return configs
+}
+
+// Apply serializes the configs back into profile. Configs that are nil are left unchanged in the profile, so a config
+// can only be removed by setting the field of the profile to nil directly. Since serialization is lossless, configs
+// that were not modified are written back exactly as they were parsed.
+func (configs ProfileConfigs) Apply(profile *ptpv1.PtpProfile) {
+	if profile == nil {
+		return
+	}
+
+	if configs.Ptp4l != nil {
+		profile.Ptp4lConf = ptr.To(configs.Ptp4l.String())
+	}
+
+	if configs.Phc2sys != nil {
+		profile.Phc2sysConf = ptr.To(configs.Phc2sys.String())
+	}
+
+	if configs.Ts2phc != nil {
+		profile.Ts2PhcConf = ptr.To(configs.Ts2phc.String())
+	}
+
+	if configs.Synce4l != nil {
+		profile.Synce4lConf = ptr.To(configs.Synce4l.String())
+	}
+}
@@ -2,11 +2,9 @@ package mustgather
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo/v2/types"
@@ -19,6 +17,9 @@ import (
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/raninittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/version"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/resourcetracker"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -47,6 +48,8 @@ const (
 	mustGatherPodTimeout = 10 * time.Minute
 	// mustGatherPodName is the name of the must-gather pod.
 	mustGatherPodName = "ptp-must-gather"
+	// mustGatherTarballName is the name of the must-gather output in the artifacts of the failed spec.
+	mustGatherTarballName = "ptp-must-gather.tar"
 )
 
 var majorMinorVersionRegex = regexp.MustCompile(`(\d+)\.(\d+)`)
@@ -67,41 +70,35 @@ func MustGatherIfFailed(
 		return
 	}
 
-	dumpDir := RANConfig.GetDumpFailedTestReportLocation(testSuite)
-	if dumpDir == "" {
+	artifacts, err := reporter.SpecArtifacts(report, testSuite)
+	if errors.Is(err, reporter.ErrDumpDisabled) {
 		klog.V(ranparam.LogLevel).Info("No dump directory configured, skipping PTP must-gather")
 
 		return
 	}
 
-	image, err := getMustGatherImage(client)
 	if err != nil {
-		klog.V(ranparam.LogLevel).Infof("Failed to get must-gather image: %v, skipping PTP must-gather", err)
+		klog.V(ranparam.LogLevel).Infof("Failed to open artifacts for must-gather: %v", err)
 
 		return
 	}
 
-	klog.V(ranparam.LogLevel).Infof("Running PTP must-gather with image: %s", image)
-
-	// Create the test case specific directory for the must-gather output
-	tcReportFolderName := strings.ReplaceAll(report.FullText(), " ", "_")
-	mustGatherDir := filepath.Join(dumpDir, tcReportFolderName)
-
-	if err := os.MkdirAll(mustGatherDir, 0755); err != nil {
-		klog.V(ranparam.LogLevel).Infof("Failed to create must-gather directory: %v", err)
+	image, err := getMustGatherImage(client)
+	if err != nil {
+		klog.V(ranparam.LogLevel).Infof("Failed to get must-gather image: %v, skipping PTP must-gather", err)
 
 		return
 	}
 
-	tarballPath := filepath.Join(mustGatherDir, "ptp-must-gather.tar")
+	klog.V(ranparam.LogLevel).Infof("Running PTP must-gather with image: %s", image)
 
-	if err := runMustGather(client, image, tarballPath); err != nil {
+	if err := runMustGather(client, image, artifacts); err != nil {
 		klog.V(ranparam.LogLevel).Infof("Failed to run PTP must-gather: %v", err)
 
 		return
 	}
 
-	klog.V(ranparam.LogLevel).Infof("PTP must-gather completed successfully, output saved to: %s", tarballPath)
+	klog.V(ranparam.LogLevel).Infof("PTP must-gather completed successfully, output saved as %s", mustGatherTarballName)
 }
 
 // getMustGatherImage retrieves the must-gather image, trying in order:
@@ -164,17 +161,21 @@ func getPtpMustGatherImageForVersion(ocpVersion string) (string, error) {
 	return fmt.Sprintf("registry.redhat.io/openshift4/ptp-must-gather-rhel8:v%s.%s", major, minor), nil
 }
 
-// runMustGather creates the necessary resources, runs the must-gather pod, and downloads the output.
-func runMustGather(client *clients.Settings, image, tarballPath string) error {
+// runMustGather creates the necessary resources, runs the must-gather pod, and adds the output to artifacts.
+func runMustGather(client *clients.Settings, image string, artifacts failurebundle.Writer) error {
 	runID := time.Now().UnixNano()
 	nsName := fmt.Sprintf("%s%d", mustGatherNamespacePrefix, runID)
 	crbName := fmt.Sprintf("%s%d", mustGatherCRBPrefix, runID)
 
-	_, err := createMustGatherNamespace(client, nsName)
+	nsBuilder, err := createMustGatherNamespace(client, nsName)
 	if err != nil {
 		return fmt.Errorf("failed to create must-gather namespace: %w", err)
 	}
 
+	// The resources are also tracked so that they are deleted at the end of the spec even if the cleanup below fails
+	// or the must-gather is interrupted.
+	trackResource(client, nsBuilder)
+
 	// Ensure cleanup happens regardless of success or failure.
 	defer func() {
 		cleanupErr := cleanupMustGatherResources(client, nsName, crbName)
@@ -183,11 +184,13 @@ func runMustGather(client *clients.Settings, image, tarballPath string) error {
 		}
 	}()
 
-	_, err = serviceaccount.NewBuilder(client, mustGatherServiceAccountName, nsName).Create()
+	saBuilder, err := serviceaccount.NewBuilder(client, mustGatherServiceAccountName, nsName).Create()
 	if err != nil {
 		return fmt.Errorf("failed to create service account: %w", err)
 	}
 
+	trackResource(client, saBuilder)
+
 	saSubject := rbacv1.Subject{
 		Kind:      "ServiceAccount",
 		Name:      mustGatherServiceAccountName,
@@ -196,22 +199,26 @@ func runMustGather(client *clients.Settings, image, tarballPath string) error {
 
 	crbBuilder := rbac.NewClusterRoleBindingBuilder(client, crbName, "cluster-admin", saSubject)
 
-	_, err = crbBuilder.Create()
+	crbBuilder, err = crbBuilder.Create()
 	if err != nil {
 		return fmt.Errorf("failed to create cluster role binding: %w", err)
 	}
 
+	trackResource(client, crbBuilder)
+
 	podBuilder, err := createMustGatherPod(client, nsName, image)
 	if err != nil {
 		return fmt.Errorf("failed to create must-gather pod: %w", err)
 	}
 
+	trackResource(client, podBuilder)
+
 	err = waitForGatherComplete(podBuilder)
 	if err != nil {
 		return fmt.Errorf("failed waiting for must-gather to complete: %w", err)
 	}
 
-	err = downloadMustGatherOutput(podBuilder, tarballPath)
+	err = downloadMustGatherOutput(podBuilder, artifacts)
 	if err != nil {
 		return fmt.Errorf("failed to download must-gather output: %w", err)
 	}
@@ -219,6 +226,15 @@ func runMustGather(client *clients.Settings, image, tarballPath string) error {
 	return nil
 }
 
+// trackResource tracks the resource of builder on the cluster of client so that it is deleted at the end of the spec.
+// Failing to track is only logged since the resources are also deleted by cleanupMustGatherResources.
+func trackResource(client *clients.Settings, builder any) {
+	err := resourcetracker.TrackWithClient(client, builder)
+	if err != nil {
+		klog.V(ranparam.LogLevel).Infof("Failed to track must-gather resource: %v", err)
+	}
+}
+
 // createMustGatherNamespace creates a privileged namespace for the must-gather pod.
 func createMustGatherNamespace(client *clients.Settings, name string) (*namespace.Builder, error) {
 	nsBuilder := namespace.NewBuilder(client, name).
@@ -331,16 +347,16 @@ func waitForGatherComplete(podBuilder *pod.Builder) error {
 		})
 }
 
-// downloadMustGatherOutput copies the must-gather output from the pod to the local filesystem.
-func downloadMustGatherOutput(podBuilder *pod.Builder, tarballPath string) error {
-	klog.V(ranparam.LogLevel).Infof("Downloading must-gather output to %s", tarballPath)
+// downloadMustGatherOutput copies the must-gather output from the pod and adds it to artifacts as a tarball.
+func downloadMustGatherOutput(podBuilder *pod.Builder, artifacts failurebundle.Writer) error {
+	klog.V(ranparam.LogLevel).Infof("Downloading must-gather output to %s", mustGatherTarballName)
 
 	buffer, err := podBuilder.Copy(mustGatherOutputPath, copyContainerName, true)
 	if err != nil {
 		return fmt.Errorf("failed to copy from pod: %w", err)
 	}
 
-	err = os.WriteFile(tarballPath, buffer.Bytes(), 0644)
+	err = artifacts.Add(mustGatherTarballName, buffer.Bytes())
 	if err != nil {
 		return fmt.Errorf("failed to write tarball: %w", err)
 	}
@@ -1,17 +1,14 @@
 package profiles
 
 import (
-	"bufio"
 	"fmt"
 	"strings"
 
 	ptpv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/ptp/v1"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/iface"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/linuxptp"
 )
 
-// configSections is a map of section names to their key-value pairs. It represents the format used by ptp4l and ts2phc.
-type configSections = map[string]map[string]string
-
 // parsePtpProfile parses the PTP profile and the ptp4l information to get the interfaces and their types before making
 // a determination on the profile type. Maps in the parsedPtp4lConf struct are guaranteed to not be nil when returned.
 func parsePtpProfile(
@@ -24,19 +21,12 @@ func parsePtpProfile(
 	}
 	clientFlag := hasClientFlag(profile.Ptp4lOpts)
 
-	var (
-		err           error
-		ptp4lSections = make(configSections)
-	)
-
-	if profile.Ptp4lConf != nil && *profile.Ptp4lConf != "" {
-		ptp4lSections, err = getSectionsFromPtp4lConf(*profile.Ptp4lConf)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get sections from ptp4lConf: %w", err)
-		}
+	ptp4lConfig := linuxptp.ParsePtp4l("")
+	if profile.Ptp4lConf != nil {
+		ptp4lConfig = linuxptp.ParsePtp4l(*profile.Ptp4lConf)
 	}
 
-	profileInfo.Interfaces = getInterfacesFromPtp4lSections(clientFlag, ptp4lSections)
+	profileInfo.Interfaces = getInterfacesFromPtp4lConfig(clientFlag, ptp4lConfig)
 
 	if profile.Interface != nil && *profile.Interface != "" {
 		ifaceName := iface.Name(*profile.Interface)
@@ -53,94 +43,42 @@ func parsePtpProfile(
 		interfaceInfo.Profile = profileInfo
 	}
 
-	profileInfo.ProfileType, err = determineProfileType(profileInfo.Interfaces, profile, controlledNames)
+	profileType, err := determineProfileType(profileInfo.Interfaces, profile, controlledNames)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine profile type: %w", err)
 	}
 
-	return profileInfo, nil
-}
-
-// getSectionsFromPtp4lConf parses the ptp4l configuration file and returns a map of sections and their key-value pairs.
-func getSectionsFromPtp4lConf(ptp4lConf string) (configSections, error) {
-	var currentSectionName string
-
-	sections := make(configSections)
-	scanner := bufio.NewScanner(strings.NewReader(ptp4lConf))
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Ignore empty lines and comments.
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Lines with text between brackets are considered section names.
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && len(line) > 2 {
-			currentSectionName = line[1 : len(line)-1]
-
-			if _, ok := sections[currentSectionName]; !ok {
-				sections[currentSectionName] = make(map[string]string)
-			}
-
-			continue
-		}
-
-		// If the first section has not been found yet, skip the line.
-		if currentSectionName == "" {
-			continue
-		}
-
-		// This is not a section name, so it should be a key-value pair, separated by a space.
-		keyValue := strings.SplitN(line, " ", 2)
-		if len(keyValue) < 2 {
-			continue
-		}
-
-		sections[currentSectionName][keyValue[0]] = keyValue[1]
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading ptp4l configuration: %w", err)
-	}
+	profileInfo.ProfileType = profileType
 
-	return sections, nil
+	return profileInfo, nil
 }
 
-// getInterfacesFromPtp4lSections extracts the interfaces and their clock types from the ptp4l configuration sections.
-// The provided clientFlag indicates whether the clientOnly command line flag is set in ptp4lOpts. The returned map is
+// getInterfacesFromPtp4lConfig extracts the interfaces and their clock types from the ptp4l configuration. The
+// provided clientFlag indicates whether the clientOnly command line flag is set in ptp4lOpts. The returned map is
 // guaranteed to not be nil.
-func getInterfacesFromPtp4lSections(clientFlag bool, sections configSections) map[iface.Name]*InterfaceInfo {
+func getInterfacesFromPtp4lConfig(clientFlag bool, config linuxptp.Ptp4lConfig) map[iface.Name]*InterfaceInfo {
 	interfaces := make(map[iface.Name]*InterfaceInfo)
 
 	// Setting clientOnly in the global section is equivalent to setting it as a command line flag, meaning all
-	// interfaces are client only.
-	if globalSection, ok := sections["global"]; ok && globalSection != nil {
-		// slaveOnly is deprecated but still used and supported by ptp4l.
-		if globalSection["clientOnly"] == "1" || globalSection["slaveOnly"] == "1" {
-			clientFlag = true
-		}
+	// interfaces are client only. This includes the deprecated slaveOnly, which is still used and supported by ptp4l.
+	if config.ClientOnly() {
+		clientFlag = true
 	}
 
-	for sectionName, sectionValues := range sections {
-		if sectionName == "global" || sectionName == "unicast_master_table" {
-			continue
-		}
-
+	for _, port := range config.Ports() {
 		var clockType PtpClockType
 
 		switch {
 		case clientFlag:
 			clockType = ClockTypeClient
-		// masterOnly is deprecated but still used and supported by ptp4l, similar to slaveOnly.
-		case sectionValues["serverOnly"] == "1" || sectionValues["masterOnly"] == "1":
+		// ServerOnly also covers masterOnly, which is deprecated but still used and supported by ptp4l.
+		case config.ServerOnly(port):
 			clockType = ClockTypeServer
 		default:
 			clockType = ClockTypeClient
 		}
 
-		ifaceName := iface.Name(sectionName)
+		ifaceName := iface.Name(port)
 		interfaces[ifaceName] = &InterfaceInfo{
 			Name:      ifaceName,
 			ClockType: clockType,
@@ -167,12 +105,15 @@ func determineProfileType(
 	// If the profile has ts2phc.master set to 1, it means there is a time source and the profile is a GM profile.
 	// If there is also ts2phc.master set to 0, it means there is another NIC acting as a time sink, so it is a
 	// multi-NIC GM profile.
-	if profile.Ts2PhcConf != nil && strings.Contains(*profile.Ts2PhcConf, "ts2phc.master 1") {
-		if strings.Contains(*profile.Ts2PhcConf, "ts2phc.master 0") {
-			return ProfileTypeMultiNICGM, nil
-		}
+	if profile.Ts2PhcConf != nil {
+		ts2phcConfig := linuxptp.ParseTs2phc(*profile.Ts2PhcConf)
+		if len(ts2phcConfig.Sources()) > 0 {
+			if len(ts2phcConfig.Sinks()) > 0 {
+				return ProfileTypeMultiNICGM, nil
+			}
 
-		return ProfileTypeGM, nil
+			return ProfileTypeGM, nil
+		}
 	}
 
 	// If the profile has PtpSettings and haProfiles is set, it must be a highly available profile.
@@ -267,9 +208,5 @@ func hasTelecomSlaveConfig(profile ptpv1.PtpProfile) bool {
 		return false
 	}
 
-	if strings.Contains(*profile.Ts2PhcConf, "ts2phc.master 1") {
-		return false
-	}
-
-	return true
+	return len(linuxptp.ParseTs2phc(*profile.Ts2PhcConf).Sources()) == 0
 }
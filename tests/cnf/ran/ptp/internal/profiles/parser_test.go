@@ -0,0 +1,131 @@
+package profiles
+
+import (
+	"testing"
+
+	ptpv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/ptp/v1"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/iface"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+// gmTs2phcConf is the ts2phc config of the reference single NIC grandmaster profile, where the NMEA sentences of the
+// GNSS receiver are the time source.
+const gmTs2phcConf = `[nmea]
+ts2phc.master 1
+[global]
+use_syslog  0
+verbose 1
+logging_level 7
+ts2phc.cable_delay 0
+ts2phc.pulsewidth 100000000
+ts2phc.nmea_serialport /dev/gnss0
+leapfile  /usr/share/zoneinfo/leap-seconds.list
+[ens4f0]
+ts2phc.extts_polarity rising
+ts2phc.extts_correction 0
+`
+
+// multiNICGMTs2phcConf is the ts2phc config of the reference multi-NIC grandmaster profile, where the second NIC is a
+// time sink of the first.
+const multiNICGMTs2phcConf = `[nmea]
+ts2phc.master 1
+[global]
+use_syslog  0
+verbose 1
+logging_level 7
+ts2phc.pulsewidth 100000000
+ts2phc.nmea_serialport /dev/gnss0
+[ens4f0]
+ts2phc.extts_polarity rising
+ts2phc.extts_correction 0
+[ens8f0]
+ts2phc.master 0
+ts2phc.extts_polarity rising
+ts2phc.extts_correction -10
+`
+
+// ttscTs2phcConf is a ts2phc config without a time source, as used by telecom time slave clocks.
+const ttscTs2phcConf = `[global]
+use_syslog  0
+verbose 1
+logging_level 7
+[ens4f0]
+ts2phc.extts_polarity rising
+`
+
+func TestDetermineProfileType(t *testing.T) {
+	serverInterfaces := map[iface.Name]*InterfaceInfo{
+		"ens4f0": {Name: "ens4f0", ClockType: ClockTypeServer},
+		"ens4f1": {Name: "ens4f1", ClockType: ClockTypeServer},
+	}
+	clientInterface := map[iface.Name]*InterfaceInfo{
+		"ens4f1": {Name: "ens4f1", ClockType: ClockTypeClient},
+	}
+
+	testCases := []struct {
+		name         string
+		interfaces   map[iface.Name]*InterfaceInfo
+		profile      ptpv1.PtpProfile
+		expectedType PtpProfileType
+	}{
+		{
+			name:         "single NIC GM with NMEA source",
+			interfaces:   serverInterfaces,
+			profile:      ptpv1.PtpProfile{Ts2PhcConf: ptr.To(gmTs2phcConf), Phc2sysOpts: ptr.To("-r -n 24")},
+			expectedType: ProfileTypeGM,
+		},
+		{
+			name:         "multi-NIC GM with NMEA source",
+			interfaces:   serverInterfaces,
+			profile:      ptpv1.PtpProfile{Ts2PhcConf: ptr.To(multiNICGMTs2phcConf), Phc2sysOpts: ptr.To("-r -n 24")},
+			expectedType: ProfileTypeMultiNICGM,
+		},
+		{
+			name:         "T-TSC without ts2phc source",
+			interfaces:   clientInterface,
+			profile:      ptpv1.PtpProfile{Ts2PhcConf: ptr.To(ttscTs2phcConf), Phc2sysOpts: ptr.To("-r -n 24")},
+			expectedType: ProfileTypeTTSC,
+		},
+		{
+			name:         "T-BC transmitter without ts2phc",
+			interfaces:   serverInterfaces,
+			profile:      ptpv1.PtpProfile{},
+			expectedType: ProfileTypeTBCTransmitter,
+		},
+	}
+
+	for _, testCase := range testCases {
+		profileType, err := determineProfileType(testCase.interfaces, testCase.profile, nil)
+		assert.NoError(t, err, testCase.name)
+		assert.Equal(t, testCase.expectedType, profileType, testCase.name)
+	}
+}
+
+func TestHasTelecomSlaveConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		profile  ptpv1.PtpProfile
+		expected bool
+	}{
+		{
+			name:     "GM with NMEA source",
+			profile:  ptpv1.PtpProfile{Ts2PhcConf: ptr.To(gmTs2phcConf), Phc2sysOpts: ptr.To("-r -n 24")},
+			expected: false,
+		},
+		{
+			name:     "T-TSC without ts2phc source",
+			profile:  ptpv1.PtpProfile{Ts2PhcConf: ptr.To(ttscTs2phcConf), Phc2sysOpts: ptr.To("-r -n 24")},
+			expected: true,
+		},
+		{
+			name:     "no phc2sys",
+			profile:  ptpv1.PtpProfile{Ts2PhcConf: ptr.To(ttscTs2phcConf)},
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, hasTelecomSlaveConfig(testCase.profile), testCase.name)
+	}
+}
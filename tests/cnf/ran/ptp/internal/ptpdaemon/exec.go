@@ -2,12 +2,14 @@
 package ptpdaemon
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/tsparams"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
 	"k8s.io/klog/v2"
 )
 
@@ -100,24 +102,33 @@ func ExecuteCommandInPtpDaemonPod(
 			continue
 		}
 
+		// The TTY from WithCombinedOutput matches the output of pod.Builder.ExecCommand, which callers parse, and
+		// non-zero exit codes are treated as errors the same way.
+		result, err := cluster.ExecInPod(context.TODO(), client, daemonPod, execOptions.containerName,
+			[]string{"sh", "-c", command}, cluster.WithCombinedOutput(true))
+		if err == nil {
+			err = result.Err()
+		}
+
+		output := result.Stdout
+
 		// If there is the option to retry on error, we only log the error before continuing to retry.
 		// Otherwise, we return the error immediately since we do not retry on it.
-		output, err := daemonPod.ExecCommand([]string{"sh", "-c", command}, execOptions.containerName)
 		if execOptions.retryOnError && err != nil {
 			klog.V(tsparams.LogLevel).Infof("Failed to execute command %q in PTP daemon pod on node %s\nerror: %v\noutput: %s",
-				command, nodeName, err, output.String())
+				command, nodeName, err, output)
 
 			continue
 		} else if err != nil {
 			klog.V(tsparams.LogLevel).Infof("Failed to execute command %q in PTP daemon pod on node %s\nerror: %v\noutput: %s",
-				command, nodeName, err, output.String())
+				command, nodeName, err, output)
 
 			return "", fmt.Errorf("failed to execute command %q in PTP daemon pod on node %s: %w", command, nodeName, err)
 		}
 
 		// Empty output is only considered an error if retrying on empty output, so there is no else if for this
 		// check.
-		if execOptions.retryOnEmptyOutput && output.Len() == 0 {
+		if execOptions.retryOnEmptyOutput && len(output) == 0 {
 			klog.V(tsparams.LogLevel).Infof("Failed to execute command %q in PTP daemon pod on node %s: no output returned",
 				command, nodeName)
 
@@ -125,7 +136,7 @@ func ExecuteCommandInPtpDaemonPod(
 		}
 
 		// In the success case, we do not need to retry and we can return the output.
-		return output.String(), nil
+		return output, nil
 	}
 
 	return "", fmt.Errorf(
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/stability"
+)
+
+// profilePattern matches the linuxptp config file that a daemon log line belongs to, such as [ptp4l.0.config:6]. Lines
+// of phc2sys are tagged with the ptp4l config of their profile.
+var profilePattern = regexp.MustCompile(`\[((?:ptp4l|phc2sys|ts2phc|synce4l)\.\d+\.config)(?::\d+)?\]`)
+
+// Report is the result of analyzing every LogGroup.
+type Report struct {
+	GeneratedAt          time.Time `json:"generatedAt"`
+	ThresholdNanoseconds int64     `json:"thresholdNanoseconds"`
+	TargetClass          string    `json:"targetClass,omitempty"`
+	// Passed is true if the analysis of every node passed.
+	Passed bool         `json:"passed"`
+	Nodes  []NodeReport `json:"nodes"`
+}
+
+// NodeReport is the result of analyzing a single LogGroup.
+type NodeReport struct {
+	Node      string   `json:"node"`
+	Origin    string   `json:"origin"`
+	Files     []string `json:"files"`
+	LineCount int      `json:"lineCount"`
+	// Result is the analysis of every line of the node, the same as in the PTP stability test.
+	Result ResultReport `json:"result"`
+	// Profiles is the analysis of the lines of each linuxptp config file on the node. Since not every profile runs
+	// phc2sys, the result of a profile may fail only because no phc2sys offsets were parsed.
+	Profiles []ProfileReport `json:"profiles,omitempty"`
+}
+
+// ProfileReport is the result of analyzing the lines of a single linuxptp config file.
+type ProfileReport struct {
+	Profile string       `json:"profile"`
+	Result  ResultReport `json:"result"`
+}
+
+// ResultReport is the JSON form of stability.AnalysisResult.
+type ResultReport struct {
+	Passed           bool          `json:"passed"`
+	Details          []string      `json:"details,omitempty"`
+	ParseWarnings    []string      `json:"parseWarnings,omitempty"`
+	ClassViolations  []string      `json:"classViolations,omitempty"`
+	PTP4LStartCount  uint          `json:"ptp4lStartCount"`
+	FaultyLineCount  int           `json:"faultyLineCount"`
+	TimeoutLineCount int           `json:"timeoutLineCount"`
+	PTP4L            ProcessReport `json:"ptp4l"`
+	PHC2SYS          ProcessReport `json:"phc2sys"`
+}
+
+// ProcessReport is the JSON form of stability.ProcessResult.
+type ProcessReport struct {
+	SampleCount             int                `json:"sampleCount"`
+	MaxAbsOffset            int64              `json:"maxAbsOffset"`
+	MinAbsOffset            int64              `json:"minAbsOffset"`
+	AvgAbsOffset            float64            `json:"avgAbsOffset"`
+	ThresholdViolationCount int                `json:"thresholdViolationCount"`
+	StateTransitions        []TransitionReport `json:"stateTransitions,omitempty"`
+	// TimeErrors is the time error of each config file and clock whose offsets the process logged.
+	TimeErrors []TimeErrorReport `json:"timeErrors,omitempty"`
+}
+
+// TransitionReport is the JSON form of stability.StateTransition.
+type TransitionReport struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Raw  string `json:"raw"`
+}
+
+// TimeErrorReport is the JSON form of stability.SeriesTimeError. All values are in nanoseconds.
+type TimeErrorReport struct {
+	Source         string         `json:"source,omitempty"`
+	SampleCount    int            `json:"sampleCount"`
+	CTE            float64        `json:"cte"`
+	MaxAbsTE       int64          `json:"maxAbsTE"`
+	MaxAbsTEL      float64        `json:"maxAbsTEL"`
+	DTEHPeakToPeak float64        `json:"dteHPeakToPeak"`
+	MTIE           []WindowReport `json:"mtie,omitempty"`
+	TDEV           []WindowReport `json:"tdev,omitempty"`
+	Gaps           []GapReport    `json:"gaps,omitempty"`
+	Violations     []string       `json:"violations,omitempty"`
+
+	// segments is the resampled offsets used for plotting, split at each gap, which are left out of the JSON to keep
+	// it small.
+	segments [][]float64
+}
+
+// GapReport is the JSON form of stability.Gap.
+type GapReport struct {
+	AfterSeconds    int64   `json:"afterSeconds"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// WindowReport is the JSON form of stability.WindowMetric.
+type WindowReport struct {
+	ObservationIntervalSeconds float64 `json:"observationIntervalSeconds"`
+	Nanoseconds                float64 `json:"nanoseconds"`
+}
+
+// AnalyzeLogGroups runs the stability analysis on each group as a whole and on the lines of each linuxptp config file
+// in the group.
+func AnalyzeLogGroups(groups []LogGroup, threshold int64, class stability.ClockClass) (Report, error) {
+	if threshold <= 0 {
+		threshold = stability.DefaultOffsetThresholdAbsoluteNanoseconds
+	}
+
+	report := Report{
+		GeneratedAt:          time.Now(),
+		ThresholdNanoseconds: threshold,
+		TargetClass:          string(class),
+		Passed:               true,
+	}
+
+	for _, group := range groups {
+		nodeReport, err := analyzeLogGroup(group, threshold, class)
+		if err != nil {
+			return Report{}, err
+		}
+
+		report.Passed = report.Passed && nodeReport.Result.Passed
+		report.Nodes = append(report.Nodes, nodeReport)
+	}
+
+	return report, nil
+}
+
+// analyzeLogGroup analyzes the files of group in order, splitting the lines tagged with a config file between the
+// analyzers of their profiles.
+func analyzeLogGroup(group LogGroup, threshold int64, class stability.ClockClass) (NodeReport, error) {
+	var (
+		nodeReport       = NodeReport{Node: group.Node, Origin: group.Origin}
+		nodeAnalyzer     = stability.NewAnalyzer(threshold, class)
+		profileAnalyzers = make(map[string]*stability.Analyzer)
+	)
+
+	for _, logFile := range group.Files {
+		nodeReport.Files = append(nodeReport.Files, logFile.Name)
+
+		lineCount, err := analyzeLogFile(logFile.Path, func(line string) {
+			nodeAnalyzer.ProcessLine(line)
+
+			match := profilePattern.FindStringSubmatch(line)
+			if match == nil {
+				return
+			}
+
+			profileAnalyzer, ok := profileAnalyzers[match[1]]
+			if !ok {
+				profileAnalyzer = stability.NewAnalyzer(threshold, class)
+				profileAnalyzers[match[1]] = profileAnalyzer
+			}
+
+			profileAnalyzer.ProcessLine(line)
+		})
+		if err != nil {
+			return NodeReport{}, fmt.Errorf("failed to read %s from %s: %w", logFile.Name, group.Origin, err)
+		}
+
+		nodeReport.LineCount += lineCount
+	}
+
+	nodeReport.Result = newResultReport(nodeAnalyzer.Result())
+
+	for _, profile := range slices.Sorted(maps.Keys(profileAnalyzers)) {
+		nodeReport.Profiles = append(nodeReport.Profiles, ProfileReport{
+			Profile: profile,
+			Result:  newResultReport(profileAnalyzers[profile].Result()),
+		})
+	}
+
+	return nodeReport, nil
+}
+
+// analyzeLogFile calls processLine for each line of the file at filePath and returns the number of lines.
+func analyzeLogFile(filePath string, processLine func(line string)) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+
+	lineCount := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		processLine(scanner.Text())
+
+		lineCount++
+	}
+
+	return lineCount, scanner.Err()
+}
+
+// newResultReport converts result to its JSON form.
+func newResultReport(result stability.AnalysisResult) ResultReport {
+	return ResultReport{
+		Passed:           result.Passed,
+		Details:          result.Details,
+		ParseWarnings:    result.ParseWarnings,
+		ClassViolations:  result.ClassViolations,
+		PTP4LStartCount:  result.PTP4LStartCount,
+		FaultyLineCount:  result.FaultyLineCount,
+		TimeoutLineCount: result.TimeoutLineCount,
+		PTP4L:            newProcessReport(result.PTP4L),
+		PHC2SYS:          newProcessReport(result.PHC2SYS),
+	}
+}
+
+// newProcessReport converts result to its JSON form.
+func newProcessReport(result stability.ProcessResult) ProcessReport {
+	report := ProcessReport{
+		SampleCount:             result.Stats.SampleCount,
+		MaxAbsOffset:            result.Stats.MaxAbs,
+		MinAbsOffset:            result.Stats.MinAbs,
+		AvgAbsOffset:            result.Stats.AvgAbs,
+		ThresholdViolationCount: result.ThresholdViolationCount,
+	}
+
+	for _, transition := range result.StateTransitions {
+		report.StateTransitions = append(report.StateTransitions,
+			TransitionReport{From: transition.From, To: transition.To, Raw: transition.Raw})
+	}
+
+	for _, timeError := range result.TimeErrors {
+		report.TimeErrors = append(report.TimeErrors, newTimeErrorReport(timeError))
+	}
+
+	return report
+}
+
+// newTimeErrorReport converts timeError to its JSON form.
+func newTimeErrorReport(timeError stability.SeriesTimeError) TimeErrorReport {
+	report := TimeErrorReport{
+		Source:         timeError.Source,
+		SampleCount:    timeError.Metrics.SampleCount,
+		CTE:            timeError.Metrics.CTE,
+		MaxAbsTE:       timeError.Metrics.MaxAbsTE,
+		MaxAbsTEL:      timeError.Metrics.MaxAbsTEL,
+		DTEHPeakToPeak: timeError.Metrics.DTEHPeakToPeak,
+		MTIE:           newWindowReports(timeError.Metrics.MTIE),
+		TDEV:           newWindowReports(timeError.Metrics.TDEV),
+		Violations:     timeError.Violations,
+	}
+
+	for _, gap := range timeError.Metrics.Gaps {
+		report.Gaps = append(report.Gaps, GapReport{AfterSeconds: gap.After, DurationSeconds: gap.Duration.Seconds()})
+	}
+
+	for _, segment := range timeError.Segments {
+		report.segments = append(report.segments, segment.Samples)
+	}
+
+	return report
+}
+
+// newWindowReports converts windows to their JSON form.
+func newWindowReports(windows []stability.WindowMetric) []WindowReport {
+	var reports []WindowReport
+
+	for _, window := range windows {
+		reports = append(reports, WindowReport{
+			ObservationIntervalSeconds: window.ObservationInterval.Seconds(),
+			Nanoseconds:                window.Value,
+		})
+	}
+
+	return reports
+}
+
+// WriteJSON writes report as indented JSON to the file at filePath.
+func WriteJSON(report Report, filePath string) error {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, append(content, '\n'), 0644)
+}
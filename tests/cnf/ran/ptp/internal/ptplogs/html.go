@@ -0,0 +1,180 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// plotWidth is the width of offset plots in SVG units. Series with more samples than this are reduced to the
+	// minimum and maximum of each column so that peaks are never hidden.
+	plotWidth = 800
+	// plotHeight is the height of the plotting area of offset plots in SVG units.
+	plotHeight = 200
+	// plotLabelHeight is the height below the plotting area reserved for labels.
+	plotLabelHeight = 20
+	// maxTransitionRows is the number of state transitions shown per process before the rest are summarized.
+	maxTransitionRows = 100
+)
+
+var (
+	//go:embed report_template.html
+	reportTemplateFile string
+
+	funcMap = template.FuncMap{
+		"dict":             dict,
+		"offsetPlot":       offsetPlot,
+		"windows":          formatWindows,
+		"gaps":             formatGaps,
+		"firstTransitions": firstTransitions,
+		"moreTransitions":  moreTransitions,
+	}
+	reportTemplate = template.Must(template.New("report_template.html").Funcs(funcMap).Parse(reportTemplateFile))
+)
+
+// WriteHTML writes report as an HTML report to the file at filePath.
+func WriteHTML(report Report, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return reportTemplate.Execute(file, report)
+}
+
+// offsetPlot renders the resampled offsets of timeError, one per second, as an SVG line plot with dashed lines at plus
+// and minus threshold. Segments are plotted next to each other with a dotted line marking each gap between them. It
+// returns an empty string if there are no samples.
+func offsetPlot(timeError TimeErrorReport, threshold int64) template.HTML {
+	series := slices.Concat(timeError.segments...)
+	if len(series) == 0 {
+		return ""
+	}
+
+	low, high := slices.Min(series), slices.Max(series)
+	low, high = min(low, -1), max(high, 1)
+	padding := (high - low) * 0.05
+	low, high = low-padding, high+padding
+
+	scaleY := func(value float64) float64 {
+		return plotHeight * (high - value) / (high - low)
+	}
+
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, `<svg class="plot" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`,
+		plotWidth, plotHeight+plotLabelHeight)
+	fmt.Fprintf(&builder, `<line class="zero" x1="0" x2="%d" y1="%.1f" y2="%.1f"/>`, plotWidth, scaleY(0), scaleY(0))
+
+	for _, limit := range []float64{float64(threshold), -float64(threshold)} {
+		if limit > low && limit < high {
+			fmt.Fprintf(&builder, `<line class="threshold" x1="0" x2="%d" y1="%.1f" y2="%.1f"/>`,
+				plotWidth, scaleY(limit), scaleY(limit))
+		}
+	}
+
+	columns := min(len(series), plotWidth)
+	// segmentEnd is the index in series of the first sample after the segment currently being plotted.
+	segmentIndex, segmentEnd := 0, len(timeError.segments[0])
+
+	builder.WriteString(`<polyline class="offset" points="`)
+
+	for column := range columns {
+		start, end := column*len(series)/columns, (column+1)*len(series)/columns
+		x := float64(column) * plotWidth / float64(max(columns-1, 1))
+
+		if start >= segmentEnd {
+			for start >= segmentEnd {
+				segmentIndex++
+				segmentEnd += len(timeError.segments[segmentIndex])
+			}
+
+			fmt.Fprintf(&builder, `"/><line class="gap" x1="%.1f" x2="%.1f" y1="0" y2="%d"/>`, x, x, plotHeight)
+			builder.WriteString(`<polyline class="offset" points="`)
+		}
+
+		fmt.Fprintf(&builder, "%.1f,%.1f %.1f,%.1f ",
+			x, scaleY(slices.Max(series[start:end])), x, scaleY(slices.Min(series[start:end])))
+	}
+
+	builder.WriteString(`"/>`)
+
+	fmt.Fprintf(&builder, `<text x="2" y="12">%.0f ns</text>`, high)
+	fmt.Fprintf(&builder, `<text x="2" y="%d">%.0f ns</text>`, plotHeight-4, low)
+	fmt.Fprintf(&builder, `<text x="0" y="%d">0s</text>`, plotHeight+plotLabelHeight-4)
+	fmt.Fprintf(&builder, `<text x="%d" y="%d" text-anchor="end">%s</text>`,
+		plotWidth, plotHeight+plotLabelHeight-4, time.Duration(len(series))*time.Second)
+	builder.WriteString(`</svg>`)
+
+	//nolint:gosec // the SVG only contains numbers and durations formatted above.
+	return template.HTML(builder.String())
+}
+
+// formatGaps renders gaps as a comma separated list of when each gap started and how long it lasted.
+func formatGaps(gaps []GapReport) string {
+	var formatted []string
+
+	for _, gap := range gaps {
+		duration := time.Duration(gap.DurationSeconds * float64(time.Second))
+		formatted = append(formatted, fmt.Sprintf("%s after %d s", duration, gap.AfterSeconds))
+	}
+
+	if len(formatted) == 0 {
+		return "-"
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// formatWindows renders windows as a comma separated list of interval and value pairs.
+func formatWindows(windows []WindowReport) string {
+	var formatted []string
+
+	for _, window := range windows {
+		interval := time.Duration(window.ObservationIntervalSeconds * float64(time.Second))
+		formatted = append(formatted, fmt.Sprintf("%s: %.3f ns", interval, window.Nanoseconds))
+	}
+
+	if len(formatted) == 0 {
+		return "-"
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// firstTransitions returns at most maxTransitionRows of transitions.
+func firstTransitions(transitions []TransitionReport) []TransitionReport {
+	return transitions[:min(len(transitions), maxTransitionRows)]
+}
+
+// moreTransitions returns how many of transitions are not returned by firstTransitions.
+func moreTransitions(transitions []TransitionReport) int {
+	return max(len(transitions)-maxTransitionRows, 0)
+}
+
+// dict builds a map from alternating keys and values so that templates can pass several values to a sub-template.
+func dict(keysAndValues ...any) (map[string]any, error) {
+	if len(keysAndValues)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments, got %d", len(keysAndValues))
+	}
+
+	result := make(map[string]any, len(keysAndValues)/2)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", keysAndValues[i])
+		}
+
+		result[key] = keysAndValues[i+1]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/daemonlogs/tempfile"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// mustGatherLogPattern matches the paths of the logs of the linuxptp daemon container in must-gather output, such
+	// as namespaces/openshift-ptp/pods/<pod>/linuxptp-daemon-container/linuxptp-daemon-container/logs/current.log.
+	mustGatherLogPattern = regexp.MustCompile(
+		`(?:^|/)pods/(?P<pod>[^/]+)/` + ranparam.PtpContainerName + `/[^/]+/logs/(?P<log>current|previous)\.log$`)
+	// podManifestPattern matches the paths of pod manifests in must-gather output, such as
+	// namespaces/openshift-ptp/pods/<pod>/<pod>.yaml.
+	podManifestPattern = regexp.MustCompile(`(?:^|/)pods/(?P<pod>[^/]+)/(?P<file>[^/]+)\.yaml$`)
+	// nodeNamePattern matches the node name in the spec of a pod manifest.
+	nodeNamePattern = regexp.MustCompile(`(?m)^\s*nodeName:\s*"?([^"\s]+)"?\s*$`)
+	// archiveSuffixes are the suffixes of the archives that are extracted and searched for logs.
+	archiveSuffixes = []string{".tar", ".tar.gz", ".tgz", failurebundle.Extension}
+)
+
+// unknownNode is the node of daemonlogs temporary files whose names do not include one.
+const unknownNode = "unknown"
+
+// LogFile is a single daemon log file in a LogGroup.
+type LogFile struct {
+	// Path is the path of the file on disk, which may be in the directory archives are extracted to.
+	Path string
+	// Name is the name of the file shown in results, relative to the origin of its group.
+	Name string
+}
+
+// LogGroup is the daemon logs of a single node from a single origin. The files of a group are analyzed together, in
+// order, as if they were a single log.
+type LogGroup struct {
+	// Node is the name of the node the logs were collected from. If the node is unknown, it is the name of the pod or
+	// file they came from, or unknownNode for daemonlogs temporary files since their names are otherwise random.
+	Node string
+	// Origin is the input, or the archive within an input, that the logs were found in.
+	Origin string
+	// Files is the log files of the group, oldest first.
+	Files []LogFile
+}
+
+// CollectLogGroups finds the daemon logs in each input and groups them by origin and node. Archives are extracted to
+// extractDir, which the caller is responsible for removing. Each input may be prefixed with a node name and an equals
+// sign to override the node of every log found in it.
+func CollectLogGroups(inputs []string, extractDir string) ([]LogGroup, error) {
+	var groups []LogGroup
+
+	for _, input := range inputs {
+		node, inputPath := splitInput(input)
+
+		info, err := os.Stat(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat input %s: %w", inputPath, err)
+		}
+
+		var inputGroups []LogGroup
+
+		switch {
+		case info.IsDir():
+			inputGroups, err = discoverDirectory(inputPath, inputPath, extractDir)
+		case isArchive(inputPath):
+			inputGroups, err = discoverArchive(inputPath, inputPath, extractDir)
+		default:
+			inputGroups = []LogGroup{{
+				Node:   nodeFromFileName(inputPath),
+				Origin: inputPath,
+				Files:  []LogFile{{Path: inputPath, Name: filepath.Base(inputPath)}},
+			}}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if node != "" {
+			for index := range inputGroups {
+				inputGroups[index].Node = node
+			}
+		}
+
+		groups = append(groups, inputGroups...)
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no linuxptp daemon logs found in inputs %v", inputs)
+	}
+
+	return groups, nil
+}
+
+// splitInput splits an input into the node prefix, if any, and the path. Inputs that exist as is are never split.
+func splitInput(input string) (string, string) {
+	if _, err := os.Stat(input); err == nil {
+		return "", input
+	}
+
+	node, inputPath, found := strings.Cut(input, "=")
+	if !found || node == "" || strings.ContainsRune(node, filepath.Separator) {
+		return "", input
+	}
+
+	return node, inputPath
+}
+
+// discoverDirectory walks root for must-gather output, daemonlogs temporary files, and archives. Groups from
+// must-gather output use origin as their origin while each temporary file and archive gets its own.
+func discoverDirectory(root, origin, extractDir string) ([]LogGroup, error) {
+	var (
+		groups   []LogGroup
+		podLogs  = make(map[string][]LogFile)
+		podNodes = make(map[string]string)
+	)
+
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(relativePath)
+
+		if isArchive(name) {
+			archiveGroups, err := discoverArchive(filePath, path.Join(origin, name), extractDir)
+			if err != nil {
+				return err
+			}
+
+			groups = append(groups, archiveGroups...)
+
+			return nil
+		}
+
+		if match := mustGatherLogPattern.FindStringSubmatch(name); match != nil {
+			pod := match[mustGatherLogPattern.SubexpIndex("pod")]
+			podLogs[pod] = append(podLogs[pod], LogFile{Path: filePath, Name: name})
+
+			return nil
+		}
+
+		if match := podManifestPattern.FindStringSubmatch(name); match != nil &&
+			match[podManifestPattern.SubexpIndex("pod")] == match[podManifestPattern.SubexpIndex("file")] {
+			node, err := nodeFromPodManifest(filePath)
+			if err != nil {
+				return err
+			}
+
+			podNodes[match[podManifestPattern.SubexpIndex("pod")]] = node
+
+			return nil
+		}
+
+		if isDaemonLogsTempFile(name) {
+			groups = append(groups, LogGroup{
+				Node:   nodeFromFileName(name),
+				Origin: path.Join(origin, name),
+				Files:  []LogFile{{Path: filePath, Name: path.Base(name)}},
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for logs: %w", origin, err)
+	}
+
+	var podGroups []LogGroup
+
+	for pod, files := range podLogs {
+		// The previous log of a container is older than its current log, but sorts after it by name.
+		slices.SortFunc(files, func(a, b LogFile) int {
+			return strings.Compare(strings.Replace(a.Name, "previous.log", "0.log", 1),
+				strings.Replace(b.Name, "previous.log", "0.log", 1))
+		})
+
+		node := podNodes[pod]
+		if node == "" {
+			node = pod
+		}
+
+		podGroups = append(podGroups, LogGroup{Node: node, Origin: origin, Files: files})
+	}
+
+	slices.SortFunc(podGroups, func(a, b LogGroup) int {
+		return strings.Compare(a.Node, b.Node)
+	})
+
+	return append(podGroups, groups...), nil
+}
+
+// discoverArchive extracts the archive at archivePath to a new directory in extractDir and searches it for logs. Since
+// archives in failure bundles may be truncated by their size budget, read errors only cause a warning and whatever was
+// extracted before the error is still searched.
+func discoverArchive(archivePath, origin, extractDir string) ([]LogGroup, error) {
+	destination, err := os.MkdirTemp(extractDir, "archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for extracting %s: %w", origin, err)
+	}
+
+	err = extractArchive(archivePath, destination)
+	if err != nil {
+		klog.Warningf("Failed to fully extract %s, continuing with the files extracted so far: %v", origin, err)
+	}
+
+	return discoverDirectory(destination, origin, extractDir)
+}
+
+// extractArchive extracts the files of the archive at archivePath that may contain logs, or the nodes of logs, to
+// destination.
+func extractArchive(archivePath, destination string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	switch {
+	case strings.HasSuffix(archivePath, failurebundle.Extension):
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return err
+		}
+
+		defer decoder.Close()
+
+		reader = decoder
+	case strings.HasSuffix(archivePath, ".tar.gz"), strings.HasSuffix(archivePath, ".tgz"):
+		decompressor, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+
+		defer decompressor.Close()
+
+		reader = decompressor
+	}
+
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if header.Typeflag != tar.TypeReg || name == ".." || strings.HasPrefix(name, "../") || !isRelevant(name) {
+			continue
+		}
+
+		err = extractFile(tarReader, filepath.Join(destination, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractFile copies the current file of reader to filePath, creating any missing directories.
+func extractFile(reader io.Reader, filePath string) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, reader)
+
+	return errors.Join(err, file.Close())
+}
+
+// isRelevant returns true if the file name in an archive is worth extracting.
+func isRelevant(name string) bool {
+	return isArchive(name) || isDaemonLogsTempFile(name) || mustGatherLogPattern.MatchString(name) ||
+		podManifestPattern.MatchString(name)
+}
+
+// isArchive returns true if name has one of the archiveSuffixes.
+func isArchive(name string) bool {
+	return slices.ContainsFunc(archiveSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(name, suffix)
+	})
+}
+
+// isDaemonLogsTempFile returns true if the base of name matches tempfile.Pattern.
+func isDaemonLogsTempFile(name string) bool {
+	matched, _ := path.Match(tempfile.Pattern, path.Base(name))
+
+	return matched
+}
+
+// nodeFromPodManifest returns the node name in the pod manifest at filePath, or an empty string if it has none.
+func nodeFromPodManifest(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	match := nodeNamePattern.FindSubmatch(content)
+	if match == nil {
+		return "", nil
+	}
+
+	return string(match[1]), nil
+}
+
+// nodeFromFileName returns the node of the logs at filePath based on their file name. For daemonlogs temporary files
+// this is the node in the name, or unknownNode if there is none. For all other files, it is the base of filePath
+// without its .log extension.
+func nodeFromFileName(filePath string) string {
+	fileName := path.Base(filepath.ToSlash(filePath))
+
+	if isDaemonLogsTempFile(fileName) {
+		if node, ok := tempfile.NodeFromName(fileName); ok {
+			return node
+		}
+
+		return unknownNode
+	}
+
+	return strings.TrimSuffix(fileName, ".log")
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// podLogDir is the directory of the daemon container logs of pod linuxptp-daemon-a in must-gather output.
+	podLogDir = "namespaces/openshift-ptp/pods/linuxptp-daemon-a/linuxptp-daemon-container/linuxptp-daemon-container/logs"
+	// podManifest is the path of the manifest of pod linuxptp-daemon-a in must-gather output.
+	podManifest = "namespaces/openshift-ptp/pods/linuxptp-daemon-a/linuxptp-daemon-a.yaml"
+	// otherPodLogDir is the directory of the daemon container logs of pod linuxptp-daemon-b, which has no manifest.
+	otherPodLogDir = "namespaces/openshift-ptp/pods/linuxptp-daemon-b/linuxptp-daemon-container/current/logs"
+)
+
+// mustGatherFiles is a minimal PTP must-gather with logs from two pods, only one of which has its node known.
+var mustGatherFiles = map[string]string{
+	podLogDir + "/current.log":      "current\n",
+	podLogDir + "/previous.log":     "previous\n",
+	podManifest:                     "metadata:\n  name: linuxptp-daemon-a\nspec:\n  nodeName: node-a\n",
+	otherPodLogDir + "/current.log": "current\n",
+	"namespaces/openshift-ptp/pods/linuxptp-daemon-a/other-container/other-container/logs/current.log": "other\n",
+}
+
+// groupSummary is the part of a LogGroup that does not depend on where archives are extracted.
+type groupSummary struct {
+	node   string
+	origin string
+	files  []string
+}
+
+func TestCollectLogGroups(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		archives map[string]map[string]string
+		inputs   []string
+		// unordered is true if the expected groups come from separate archives or temporary files, which are found
+		// in walk order.
+		unordered bool
+		expected  []groupSummary
+	}{
+		{
+			name:   "must-gather directory",
+			files:  mustGatherFiles,
+			inputs: []string{"."},
+			expected: []groupSummary{
+				{node: "linuxptp-daemon-b", origin: ".", files: []string{otherPodLogDir + "/current.log"}},
+				{node: "node-a", origin: ".", files: []string{podLogDir + "/previous.log", podLogDir + "/current.log"}},
+			},
+		},
+		{
+			name:     "must-gather archive",
+			archives: map[string]map[string]string{"ptp-must-gather.tar": mustGatherFiles},
+			inputs:   []string{"ptp-must-gather.tar"},
+			expected: []groupSummary{
+				{
+					node:   "linuxptp-daemon-b",
+					origin: "ptp-must-gather.tar",
+					files:  []string{otherPodLogDir + "/current.log"},
+				},
+				{
+					node:   "node-a",
+					origin: "ptp-must-gather.tar",
+					files:  []string{podLogDir + "/previous.log", podLogDir + "/current.log"},
+				},
+			},
+		},
+		{
+			name: "archives in directory",
+			archives: map[string]map[string]string{
+				"reports/spec-1/ptp-must-gather.tar.gz": {
+					podLogDir + "/current.log": "current\n",
+					podManifest:                "spec:\n  nodeName: node-a\n",
+				},
+				"reports/spec-2/bundle.tar.zst": {"ptp-daemon-logs-node-b-123456.log": "line\n"},
+			},
+			inputs:    []string{"reports"},
+			unordered: true,
+			expected: []groupSummary{
+				{node: "node-a", origin: "reports/spec-1/ptp-must-gather.tar.gz", files: []string{podLogDir + "/current.log"}},
+				{
+					node:   "node-b",
+					origin: "reports/spec-2/bundle.tar.zst/ptp-daemon-logs-node-b-123456.log",
+					files:  []string{"ptp-daemon-logs-node-b-123456.log"},
+				},
+			},
+		},
+		{
+			name: "daemonlogs temporary files",
+			files: map[string]string{
+				"logs/ptp-daemon-logs-node-c-789.log": "line\n",
+				"logs/ptp-daemon-logs-42.log":         "line\n",
+				"logs/unrelated.log":                  "line\n",
+			},
+			inputs:    []string{"logs"},
+			unordered: true,
+			expected: []groupSummary{
+				{node: unknownNode, origin: "logs/ptp-daemon-logs-42.log", files: []string{"ptp-daemon-logs-42.log"}},
+				{
+					node:   "node-c",
+					origin: "logs/ptp-daemon-logs-node-c-789.log",
+					files:  []string{"ptp-daemon-logs-node-c-789.log"},
+				},
+			},
+		},
+		{
+			name:   "single files",
+			files:  map[string]string{"daemon.log": "line\n", "ptp-daemon-logs-1.log": "line\n"},
+			inputs: []string{"daemon.log", "ptp-daemon-logs-1.log", "node-d=daemon.log"},
+			expected: []groupSummary{
+				{node: "daemon", origin: "daemon.log", files: []string{"daemon.log"}},
+				{node: unknownNode, origin: "ptp-daemon-logs-1.log", files: []string{"ptp-daemon-logs-1.log"}},
+				{node: "node-d", origin: "daemon.log", files: []string{"daemon.log"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			root := t.TempDir()
+
+			writeFiles(t, root, testCase.files)
+
+			for archiveName, files := range testCase.archives {
+				writeArchive(t, filepath.Join(root, archiveName), files)
+			}
+
+			var inputs []string
+
+			for _, input := range testCase.inputs {
+				node, inputPath, found := strings.Cut(input, "=")
+				if !found {
+					node, inputPath = "", input
+				}
+
+				inputs = append(inputs, strings.TrimPrefix(node+"="+filepath.Join(root, inputPath), "="))
+			}
+
+			groups, err := CollectLogGroups(inputs, t.TempDir())
+			assert.NoError(t, err)
+
+			var summaries []groupSummary
+
+			for _, group := range groups {
+				origin, err := filepath.Rel(root, group.Origin)
+				assert.NoError(t, err)
+
+				summary := groupSummary{node: group.Node, origin: filepath.ToSlash(origin)}
+
+				for _, logFile := range group.Files {
+					summary.files = append(summary.files, logFile.Name)
+				}
+
+				summaries = append(summaries, summary)
+			}
+
+			if testCase.unordered {
+				assert.ElementsMatch(t, testCase.expected, summaries)
+			} else {
+				assert.Equal(t, testCase.expected, summaries)
+			}
+		})
+	}
+}
+
+func TestCollectLogGroupsNoLogs(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, map[string]string{"unrelated.txt": "text\n"})
+	writeArchive(t, filepath.Join(root, "empty.tar"), map[string]string{"unrelated.txt": "text\n"})
+
+	_, err := CollectLogGroups([]string{root}, t.TempDir())
+	assert.Error(t, err)
+}
+
+// writeFiles writes each file in files, keyed by its slash separated path relative to root, creating any missing
+// directories.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+}
+
+// writeArchive writes files, keyed by their slash separated path in the archive, to a tar archive at archivePath that
+// is compressed based on its suffix.
+func writeArchive(t *testing.T, archivePath string, files map[string]string) {
+	t.Helper()
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(archivePath), 0755))
+
+	file, err := os.Create(archivePath)
+	assert.NoError(t, err)
+
+	defer file.Close()
+
+	var (
+		writer     io.Writer = file
+		compressor io.WriteCloser
+	)
+
+	switch {
+	case strings.HasSuffix(archivePath, ".tar.gz"):
+		compressor = gzip.NewWriter(file)
+	case strings.HasSuffix(archivePath, ".tar.zst"):
+		compressor, err = zstd.NewWriter(file)
+		assert.NoError(t, err)
+	}
+
+	if compressor != nil {
+		writer = compressor
+	}
+
+	tarWriter := tar.NewWriter(writer)
+
+	for name, content := range files {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+
+		_, err = io.WriteString(tarWriter, content)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, tarWriter.Close())
+
+	if compressor != nil {
+		assert.NoError(t, compressor.Close())
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Ptplogs is a tool to run the PTP stability analysis of the PTP stability test on linuxptp daemon logs that have already
+been collected, so that failures can be triaged without a cluster or a rerun. For each node found in the inputs, the
+logs are analyzed as a whole, the same as in the test, and once for each linuxptp config file, such as ptp4l.0.config,
+which corresponds to a single profile on the node. The results are written as JSON and as an HTML report with plots of
+the offsets and tables of the servo state transitions.
+
+Each input may be one of the following:
+
+  - A linuxptp daemon log file, such as the output of oc logs or a temporary file from daemonlogs.CollectDaemonLogs.
+  - A directory, which is searched for PTP must-gather output, daemonlogs temporary files, and archives.
+  - A .tar, .tar.gz, .tgz, or .tar.zst archive, such as the ptp-must-gather.tar written by mustgather.MustGatherIfFailed
+    or a failure bundle containing one, which is searched the same way as a directory.
+
+The node of a log is taken from the pod in the must-gather output when possible and otherwise from the name of the
+file. It may be set explicitly by prefixing the input with the node name and an equals sign, as in node-1=daemon.log.
+Logs from different inputs, or from different archives in the same input, are never merged, since must-gathers from
+several failed specs of the same run overlap.
+
+Upon successful analysis the exit code is 0, even if the analysis of some nodes failed. If no inputs are given, the help
+message is printed and the exit code will be 2. If no logs are found or any other error occurs it will be logged to
+stderr and the exit code will be 1.
+
+Usage:
+
+	ptplogs [flags] [node=]input...
+
+The flags are:
+
+	-c, -class string
+		ITU-T G.8273.2 clock class to check the ptp4l time error against. One of A, B, C, or D. Not checked if blank
+
+	-h, -help
+		Print this help message
+
+	-o, -output string
+		Directory to write ptp-analysis.json and ptp-analysis.html to. Uses the current directory if left blank
+
+	-t, -threshold int
+		Absolute offset threshold in nanoseconds for s2 offsets. Uses 100 if left blank
+
+	-v int
+		Log level verbosity for klog. Use 100 for logging all messages or leave blank for none
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/stability"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// jsonFileName is the name of the JSON result in the output directory.
+	jsonFileName = "ptp-analysis.json"
+	// htmlFileName is the name of the HTML report in the output directory.
+	htmlFileName = "ptp-analysis.html"
+)
+
+var (
+	help       bool
+	class      string
+	output     string
+	threshold  int64
+	inputPaths []string
+)
+
+//nolint:gochecknoinits // This is a main package so init is fine.
+func init() {
+	const (
+		helpUsage      = "Print this help message"
+		classUsage     = "ITU-T G.8273.2 clock class to check the ptp4l time error against. Not checked if blank"
+		outputUsage    = "Directory to write the JSON and HTML results to. Uses the current directory if left blank"
+		thresholdUsage = "Absolute offset threshold in nanoseconds for s2 offsets"
+
+		defaultHelp      = false
+		defaultClass     = ""
+		defaultOutput    = "."
+		defaultThreshold = stability.DefaultOffsetThresholdAbsoluteNanoseconds
+
+		shorthand = " (shorthand)"
+	)
+
+	klog.InitFlags(nil)
+
+	_ = flag.Set("logtostderr", "true")
+
+	flag.BoolVar(&help, "help", defaultHelp, helpUsage)
+	flag.BoolVar(&help, "h", defaultHelp, helpUsage+shorthand)
+
+	flag.StringVar(&class, "class", defaultClass, classUsage)
+	flag.StringVar(&class, "c", defaultClass, classUsage+shorthand)
+
+	flag.StringVar(&output, "output", defaultOutput, outputUsage)
+	flag.StringVar(&output, "o", defaultOutput, outputUsage+shorthand)
+
+	flag.Int64Var(&threshold, "threshold", defaultThreshold, thresholdUsage)
+	flag.Int64Var(&threshold, "t", defaultThreshold, thresholdUsage+shorthand)
+}
+
+func main() {
+	flag.Parse()
+
+	inputPaths = flag.Args()
+
+	if help {
+		flag.Usage()
+
+		return
+	}
+
+	if len(inputPaths) == 0 {
+		flag.Usage()
+
+		os.Exit(2)
+	}
+
+	clockClass, err := stability.ParseClockClass(class)
+	if err != nil {
+		klog.Errorf("Invalid class=\"%s\": %v", class, err)
+
+		os.Exit(1)
+	}
+
+	extractDir, err := os.MkdirTemp("", "ptplogs-*")
+	if err != nil {
+		klog.Errorf("Failed to create directory for extracting archives: %v", err)
+
+		os.Exit(1)
+	}
+
+	groups, err := CollectLogGroups(inputPaths, extractDir)
+	if err != nil {
+		_ = os.RemoveAll(extractDir)
+
+		klog.Errorf("Failed to collect logs from inputs: %v", err)
+
+		os.Exit(1)
+	}
+
+	report, err := AnalyzeLogGroups(groups, threshold, clockClass)
+
+	_ = os.RemoveAll(extractDir)
+
+	if err != nil {
+		klog.Errorf("Failed to analyze logs: %v", err)
+
+		os.Exit(1)
+	}
+
+	err = writeResults(report, output)
+	if err != nil {
+		klog.Errorf("Failed to write results to output=\"%s\": %v", output, err)
+
+		os.Exit(1)
+	}
+
+	for _, node := range report.Nodes {
+		status := "PASS"
+		if !node.Result.Passed {
+			status = "FAIL"
+		}
+
+		fmt.Printf("%s %s (%s)\n", status, node.Node, node.Origin)
+	}
+}
+
+// writeResults writes the JSON result and HTML report of report to the output directory, creating it if needed.
+func writeResults(report Report, outputDir string) error {
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = WriteJSON(report, filepath.Join(outputDir, jsonFileName))
+	if err != nil {
+		return err
+	}
+
+	return WriteHTML(report, filepath.Join(outputDir, htmlFileName))
+}
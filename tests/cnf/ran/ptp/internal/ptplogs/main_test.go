@@ -0,0 +1,16 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoClusterFlags(t *testing.T) {
+	// The tool is meant to run without a cluster, so none of the packages it imports should pull in inittools, which
+	// registers these flags when imported.
+	for _, name := range []string{"kubeconfig", "ginkgo.focus"} {
+		assert.Nilf(t, flag.Lookup(name), "flag %s is registered by a cluster dependency", name)
+	}
+}
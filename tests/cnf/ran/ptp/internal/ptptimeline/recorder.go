@@ -0,0 +1,452 @@
+package ptptimeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/consumer"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/daemonlogs"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/metrics"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/ptpdaemon"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/tsparams"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tempFilePattern is the pattern, as used by os.CreateTemp, of the files recorded log lines are written to.
+	tempFilePattern = "ptp-timeline-*.log"
+	// maxQueryPoints is the maximum number of points requested per series when querying metrics. Prometheus rejects
+	// range queries with more than 11,000 points per series.
+	maxQueryPoints = 10000
+	// metricsTimeout is how long querying all the metrics for the timeline may take.
+	metricsTimeout = 2 * time.Minute
+)
+
+// timelineMetrics are the metrics whose changes are added to the timeline.
+var timelineMetrics = []metrics.PtpMetric{
+	metrics.MetricClockState,
+	metrics.MetricProcessStatus,
+	metrics.MetricInterfaceRole,
+	metrics.MetricClockClass,
+	metrics.MetricNMEAStatus,
+	metrics.MetricHAProfileStatus,
+	metrics.MetricPPSStatus,
+}
+
+var (
+	// current is the recorder started by StartRecording for the current spec.
+	current      *Recorder
+	currentMutex sync.Mutex
+)
+
+// Window is the time range recorded by a Recorder. End is zero while the recorder is still recording.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Recorder records the linuxptp daemon logs and cloud events on every PTP daemon node from when it is started until it
+// is stopped. Logs are followed as they are logged and written to temporary files to keep memory usage bounded. All
+// methods are safe to call concurrently and on a nil Recorder, in which case they do nothing, so that specs do not need
+// to check whether recording started successfully.
+type Recorder struct {
+	client        *clients.Settings
+	prometheusAPI prometheusv1.API
+	start         time.Time
+	sources       []*logSource
+	waitGroup     sync.WaitGroup
+
+	mutex   sync.Mutex
+	end     time.Time
+	entries []Entry
+}
+
+// logSource is the logs of a single container that are being recorded to a temporary file.
+type logSource struct {
+	node         string
+	source       Source
+	subscription *daemonlogs.Subscription
+	// follower is the follower owned by the source, if any. It is nil for sources subscribed to shared followers.
+	follower *daemonlogs.Follower
+	file     *os.File
+	writeErr error
+}
+
+// Start starts recording the daemon logs on every PTP daemon node and, if events are enabled, the events received by
+// the consumer on each node. The prometheusAPI is used to query metrics over the recorded window when the timeline is
+// collected.
+func Start(client *clients.Settings, prometheusAPI prometheusv1.API) (*Recorder, error) {
+	if client == nil {
+		return nil, fmt.Errorf("cannot record PTP timeline with nil client")
+	}
+
+	daemonNodes, err := ptpdaemon.ListPtpDaemonNodes(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PTP daemon nodes: %w", err)
+	}
+
+	eventsEnabled, err := consumer.AreEventsEnabled(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if events are enabled: %w", err)
+	}
+
+	recorder := &Recorder{client: client, prometheusAPI: prometheusAPI, start: time.Now()}
+
+	for _, daemonNode := range daemonNodes {
+		nodeName := daemonNode.Definition.Name
+
+		err = recorder.addSource(
+			nodeName, SourceLog, daemonlogs.SubscribeDaemonLogs(client, nodeName, recorder.start), nil)
+		if err != nil {
+			recorder.Stop()
+			recorder.removeFiles()
+
+			return nil, err
+		}
+
+		if !eventsEnabled {
+			continue
+		}
+
+		eventFollower := daemonlogs.NewPodLogFollower(
+			client, nodeName, "", recorder.start, func() (*pod.Builder, error) {
+				return consumer.GetConsumerPodforNode(client, nodeName)
+			})
+
+		err = recorder.addSource(nodeName, SourceEvent, eventFollower.Subscribe(), eventFollower)
+		if err != nil {
+			recorder.Stop()
+			recorder.removeFiles()
+
+			return nil, err
+		}
+	}
+
+	return recorder, nil
+}
+
+// Window returns the time range recorded so far.
+func (recorder *Recorder) Window() Window {
+	if recorder == nil {
+		return Window{}
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return Window{Start: recorder.start, End: recorder.end}
+}
+
+// Add adds entry to the timeline directly. Entries are added even after the recorder is stopped, as long as the
+// timeline has not been collected yet.
+func (recorder *Recorder) Add(entry Entry) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.entries = append(recorder.entries, entry)
+}
+
+// Stop stops recording logs. It is a no-op if the recorder has already been stopped.
+func (recorder *Recorder) Stop() {
+	if recorder == nil {
+		return
+	}
+
+	for _, source := range recorder.sources {
+		source.stop()
+	}
+
+	recorder.waitGroup.Wait()
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if recorder.end.IsZero() {
+		recorder.end = time.Now()
+	}
+}
+
+// Entries stops the recorder and returns the merged timeline, sorted by time. It includes the recorded log lines and
+// events, the entries added with Add, and the changes of the PTP metrics over the recorded window. Entries that could
+// be collected are returned along with the errors for the ones that could not.
+func (recorder *Recorder) Entries(ctx context.Context) ([]Entry, error) {
+	if recorder == nil {
+		return nil, nil
+	}
+
+	recorder.Stop()
+
+	var (
+		entries []Entry
+		errs    []error
+	)
+
+	for _, source := range recorder.sources {
+		sourceEntries, err := source.entries()
+		entries = append(entries, sourceEntries...)
+		errs = append(errs, err)
+
+		if followErrors := source.subscription.Errors(); len(followErrors) > 0 {
+			errs = append(errs, fmt.Errorf("failed to follow some %s logs on node %s: %w",
+				source.source, source.node, errors.Join(followErrors...)))
+		}
+
+		if source.writeErr != nil {
+			errs = append(errs, fmt.Errorf("failed to record some %s logs on node %s: %w",
+				source.source, source.node, source.writeErr))
+		}
+	}
+
+	metricEntries, err := recorder.metricEntries(ctx)
+	entries = append(entries, metricEntries...)
+	errs = append(errs, err)
+
+	recorder.mutex.Lock()
+	entries = append(entries, recorder.entries...)
+	recorder.mutex.Unlock()
+
+	sortEntries(entries)
+
+	return entries, errors.Join(errs...)
+}
+
+// WriteIfFailed stops the recorder and, if the spec in report failed, adds the timeline to the artifacts of the spec
+// in the dump directory of testSuite, next to the must-gather output. The temporary files of the recorder are always
+// removed.
+func (recorder *Recorder) WriteIfFailed(report types.SpecReport, testSuite string) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Stop()
+
+	defer recorder.removeFiles()
+
+	if !report.State.Is(types.SpecStateFailureStates) {
+		return
+	}
+
+	artifacts, err := reporter.SpecArtifacts(report, testSuite)
+	if errors.Is(err, reporter.ErrDumpDisabled) {
+		klog.V(tsparams.LogLevel).Info("No dump directory configured, skipping PTP timeline")
+
+		return
+	}
+
+	if err != nil {
+		klog.V(tsparams.LogLevel).Infof("Failed to open artifacts for PTP timeline: %v", err)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metricsTimeout)
+	defer cancel()
+
+	entries, err := recorder.Entries(ctx)
+	if err != nil {
+		klog.V(tsparams.LogLevel).Infof("Failed to collect parts of the PTP timeline: %v", err)
+	}
+
+	var jsonBuffer, textBuffer bytes.Buffer
+
+	err = writeEntries(entries, &jsonBuffer, &textBuffer)
+	if err != nil {
+		klog.V(tsparams.LogLevel).Infof("Failed to write PTP timeline: %v", err)
+
+		return
+	}
+
+	err = errors.Join(artifacts.Add(jsonFileName, jsonBuffer.Bytes()), artifacts.Add(textFileName, textBuffer.Bytes()))
+	if err != nil {
+		klog.V(tsparams.LogLevel).Infof("Failed to add PTP timeline to artifacts: %v", err)
+
+		return
+	}
+
+	klog.V(tsparams.LogLevel).Infof("Wrote PTP timeline with %d entries as %s", len(entries), textFileName)
+}
+
+// StartRecording starts a Recorder for the current spec, stopping the previous one if it was not already. Failing to
+// start is logged rather than returned since recording is only for triaging failures. It returns the new recorder,
+// which is nil if it could not be started.
+func StartRecording(client *clients.Settings, prometheusAPI prometheusv1.API) *Recorder {
+	recorder, err := Start(client, prometheusAPI)
+	if err != nil {
+		klog.V(tsparams.LogLevel).Infof("Failed to start recording PTP timeline: %v", err)
+	}
+
+	currentMutex.Lock()
+	previous := current
+	current = recorder
+	currentMutex.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+		previous.removeFiles()
+	}
+
+	return recorder
+}
+
+// Current returns the recorder started by StartRecording for the current spec. It is nil if recording could not be
+// started or the timeline has already been written, which is safe to use with every method of Recorder.
+func Current() *Recorder {
+	currentMutex.Lock()
+	defer currentMutex.Unlock()
+
+	return current
+}
+
+// RecordIfFailed stops the recorder started by StartRecording and writes the timeline if the spec in report failed.
+// It should be called in a JustAfterEach before the must-gather runs, so that the timeline only covers the spec.
+func RecordIfFailed(report types.SpecReport, testSuite string) {
+	currentMutex.Lock()
+	recorder := current
+	current = nil
+	currentMutex.Unlock()
+
+	recorder.WriteIfFailed(report, testSuite)
+}
+
+// addSource creates the temporary file for the logs received by subscription on node, adds it to the sources of the
+// recorder, and starts recording the logs. If follower is not nil, it is owned by the source and started. If the file
+// cannot be created, the subscription is closed and the follower stopped.
+func (recorder *Recorder) addSource(
+	node string, source Source, subscription *daemonlogs.Subscription, follower *daemonlogs.Follower) error {
+	logSource := &logSource{node: node, source: source, subscription: subscription, follower: follower}
+
+	file, err := os.CreateTemp("", tempFilePattern)
+	if err != nil {
+		logSource.stop()
+
+		return fmt.Errorf("failed to create temp file for %s logs on node %s: %w", source, node, err)
+	}
+
+	logSource.file = file
+	recorder.sources = append(recorder.sources, logSource)
+
+	if follower != nil {
+		follower.Start()
+	}
+
+	recorder.waitGroup.Go(func() {
+		logSource.record()
+	})
+
+	return nil
+}
+
+// metricEntries queries every metric in timelineMetrics over the recorded window and returns their changes.
+func (recorder *Recorder) metricEntries(ctx context.Context) ([]Entry, error) {
+	if recorder.prometheusAPI == nil {
+		return nil, nil
+	}
+
+	window := recorder.Window()
+	step := max(time.Second, window.End.Sub(window.Start)/maxQueryPoints).Round(time.Second)
+
+	var (
+		entries []Entry
+		errs    []error
+	)
+
+	for _, metric := range timelineMetrics {
+		matrix, err := metrics.ExecuteQueryRange(ctx, recorder.prometheusAPI, metrics.MetricQuery[int64]{
+			Start:  window.Start,
+			End:    window.End,
+			Step:   step,
+			Metric: metric,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to query %s: %w", metric, err))
+
+			continue
+		}
+
+		entries = append(entries, metricEntries(metric, matrix)...)
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// removeFiles closes and removes the temporary files of every source.
+func (recorder *Recorder) removeFiles() {
+	for _, source := range recorder.sources {
+		_ = source.file.Close()
+		_ = os.Remove(source.file.Name())
+	}
+}
+
+// stop closes the subscription of the source and stops its follower, if it owns one. It is safe to call more than once.
+func (source *logSource) stop() {
+	source.subscription.Close()
+
+	if source.follower != nil {
+		source.follower.Stop()
+	}
+}
+
+// record writes every line received by the subscription of the source to its file, prefixed by its timestamp, until
+// the source is stopped. After the first write error, lines are still received but no longer written.
+func (source *logSource) record() {
+	for line := range source.subscription.Lines() {
+		if source.writeErr != nil || strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+
+		_, err := fmt.Fprintf(source.file, "%s %s\n", line.Time.Format(time.RFC3339Nano), line.Text)
+		if err != nil {
+			source.writeErr = fmt.Errorf("failed to write log line to temp file: %w", err)
+		}
+	}
+}
+
+// entries reads the recorded lines of the source back and converts them into timeline entries.
+func (source *logSource) entries() ([]Entry, error) {
+	_, err := source.file.Seek(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded %s logs on node %s: %w", source.source, source.node, err)
+	}
+
+	var (
+		entries []Entry
+		scanner = bufio.NewScanner(source.file)
+	)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch source.source {
+		case SourceEvent:
+			entries = append(entries, eventEntries(source.node, line)...)
+		default:
+			if entry, ok := logEntry(source.node, line); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read recorded %s logs on node %s: %w", source.source, source.node, err)
+	}
+
+	return entries, nil
+}
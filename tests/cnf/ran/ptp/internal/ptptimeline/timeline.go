@@ -0,0 +1,278 @@
+// Package ptptimeline records what happens to PTP during a spec so that failures can be triaged from a single merged
+// timeline rather than by correlating sources by hand. While a spec runs, a Recorder follows the linuxptp daemon logs
+// and the cloud events received by the consumer on every PTP daemon node. If the spec fails, the Prometheus series of
+// the PTP metrics over the same window are added and the timeline is written with the other artifacts of the spec.
+package ptptimeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/redhat-cne/sdk-go/pkg/event"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/events"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/metrics"
+)
+
+const (
+	// jsonFileName is the name of the artifact the timeline is written to as one JSON object per line.
+	jsonFileName = "ptp-timeline.jsonl"
+	// textFileName is the name of the artifact the human readable timeline is written to.
+	textFileName = "ptp-timeline.txt"
+)
+
+// Source is what an Entry was recorded from.
+type Source string
+
+const (
+	// SourceLog is used for entries recorded from linuxptp daemon log lines.
+	SourceLog Source = "Log"
+	// SourceEvent is used for entries recorded from cloud events received by the consumer.
+	SourceEvent Source = "Event"
+	// SourceMetric is used for entries recorded from changes in the value of a PTP metric.
+	SourceMetric Source = "Metric"
+	// SourceAssertion is used for entries recorded from the outcome of an eventmetric assertion.
+	SourceAssertion Source = "Assertion"
+)
+
+var (
+	// processRegexp matches the process prefix of linuxptp daemon log lines, such as ptp4l[1234.567]: or T-BC[1234]:.
+	processRegexp = regexp.MustCompile(`^([A-Za-z][\w-]*)\[\d+(?:\.\d+)?\]:`)
+	// configRegexp matches the config file tag of linuxptp daemon log lines, such as [ptp4l.0.config:6].
+	configRegexp = regexp.MustCompile(`\[([\w.-]+\.config)(?::\d+)?\]`)
+	// interfaceRegexp matches the first name of a network interface in a log line, such as ens1f0 or (ens1f0).
+	interfaceRegexp = regexp.MustCompile(`(?:^|[\s(])((?:en|eth)[a-z0-9]*\d[a-z0-9.]*)(?:[\s):,]|$)`)
+)
+
+// metricValueNames are the names of the values of the enum metrics, used to make metric entries readable.
+var metricValueNames = map[metrics.PtpMetric]map[int64]string{
+	metrics.MetricClockState: {
+		int64(metrics.ClockStateFreerun):  "FREERUN",
+		int64(metrics.ClockStateLocked):   "LOCKED",
+		int64(metrics.ClockStateHoldover): "HOLDOVER",
+	},
+	metrics.MetricProcessStatus: {
+		int64(metrics.ProcessStatusDown): "DOWN",
+		int64(metrics.ProcessStatusUp):   "UP",
+	},
+	metrics.MetricInterfaceRole: {
+		int64(metrics.InterfaceRolePassive):   "PASSIVE",
+		int64(metrics.InterfaceRoleFollower):  "FOLLOWER",
+		int64(metrics.InterfaceRoleLeader):    "LEADER",
+		int64(metrics.InterfaceRoleFaulty):    "FAULTY",
+		int64(metrics.InterfaceRoleUnknown):   "UNKNOWN",
+		int64(metrics.InterfaceRoleListening): "LISTENING",
+	},
+}
+
+// Entry is a single log line, event, metric change, or assertion in the timeline of a spec.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Source    Source    `json:"source"`
+	Node      string    `json:"node,omitempty"`
+	Interface string    `json:"interface,omitempty"`
+	Process   string    `json:"process,omitempty"`
+	// Config is the linuxptp config file the entry is about, such as ptp4l.0.config.
+	Config string `json:"config,omitempty"`
+	// Type is the type of the event, the name of the metric, or the query of the assertion. It is empty for logs.
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the entry as a single line of the text timeline.
+func (entry Entry) String() string {
+	var tags []string
+
+	for _, tag := range []string{entry.Node, entry.Interface, entry.Process, entry.Config} {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	line := fmt.Sprintf("%s %-9s [%s]", entry.Time.UTC().Format(time.RFC3339Nano), entry.Source, strings.Join(tags, " "))
+
+	if entry.Type != "" {
+		line += " " + entry.Type
+	}
+
+	return line + " " + strings.ReplaceAll(entry.Message, "\n", " ")
+}
+
+// sortEntries sorts entries chronologically, keeping the order entries were recorded in for equal times.
+func sortEntries(entries []Entry) {
+	slices.SortStableFunc(entries, func(first, second Entry) int {
+		return first.Time.Compare(second.Time)
+	})
+}
+
+// writeEntries writes entries as JSON lines to jsonWriter and as text to textWriter.
+func writeEntries(entries []Entry, jsonWriter, textWriter io.Writer) error {
+	encoder := json.NewEncoder(jsonWriter)
+
+	for _, entry := range entries {
+		err := encoder.Encode(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode timeline entry: %w", err)
+		}
+
+		_, err = fmt.Fprintln(textWriter, entry.String())
+		if err != nil {
+			return fmt.Errorf("failed to write timeline entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// splitTimestamp splits a pod log line fetched with timestamps into its time and the original line.
+func splitTimestamp(line string) (time.Time, string, bool) {
+	rawTime, rest, found := strings.Cut(line, " ")
+	if !found {
+		return time.Time{}, "", false
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, rawTime)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return timestamp, rest, true
+}
+
+// logEntry converts a line of the linuxptp daemon logs on node, fetched with timestamps, into an entry. The process,
+// config, and interface are taken from the line on a best effort basis.
+func logEntry(node, line string) (Entry, bool) {
+	timestamp, message, ok := splitTimestamp(line)
+	if !ok || strings.TrimSpace(message) == "" {
+		return Entry{}, false
+	}
+
+	entry := Entry{Time: timestamp, Source: SourceLog, Node: node, Message: message}
+
+	if match := processRegexp.FindStringSubmatch(message); match != nil {
+		entry.Process = match[1]
+	}
+
+	if match := configRegexp.FindStringSubmatch(message); match != nil {
+		entry.Config = match[1]
+	}
+
+	if match := interfaceRegexp.FindStringSubmatch(message); match != nil {
+		entry.Interface = match[1]
+	}
+
+	return entry, true
+}
+
+// eventEntries converts a line of the consumer logs on node, fetched with timestamps, into an entry for each event it
+// contains. Entries use the time of the event, falling back to the time of the log line if the event has none.
+func eventEntries(node, line string) []Entry {
+	timestamp, message, ok := splitTimestamp(line)
+	if !ok {
+		return nil
+	}
+
+	var entries []Entry
+
+	for _, extracted := range events.ExtractEventsFromLogs([]byte(message), true) {
+		entry := Entry{Time: timestamp, Source: SourceEvent, Node: node, Type: extracted.Type}
+
+		if extracted.Time != nil && !extracted.Time.IsZero() {
+			entry.Time = extracted.Time.Time
+		}
+
+		entry.Interface, entry.Message = describeEventData(extracted.Data)
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// describeEventData returns the interface of the first value of data and all of its values formatted as
+// resource=value.
+func describeEventData(data *event.Data) (string, string) {
+	if data == nil {
+		return "", ""
+	}
+
+	var (
+		eventInterface string
+		values         []string
+	)
+
+	for _, value := range data.Values {
+		// Resources are in the format /cluster/node/<node-name>/<interface-name>/..., so the interface is the
+		// fifth field after splitting on the leading slash.
+		resourceFields := strings.Split(value.Resource, "/")
+		if eventInterface == "" && len(resourceFields) >= 5 {
+			eventInterface = resourceFields[4]
+		}
+
+		values = append(values, fmt.Sprintf("%s=%v", value.Resource, value.Value))
+	}
+
+	return eventInterface, strings.Join(values, " ")
+}
+
+// AssertionEntry returns the entry for the outcome of an eventmetric assertion on node that finished at finishTime. The
+// query is the metric query of the assertion, which expected the value expected since startTime, and assertErr is nil
+// if the assertion passed.
+func AssertionEntry(
+	finishTime time.Time, node, query string, expected int64, startTime time.Time, assertErr error) Entry {
+	message := fmt.Sprintf("expected value %d since %s: passed", expected, startTime.UTC().Format(time.RFC3339))
+	if assertErr != nil {
+		message = fmt.Sprintf("expected value %d since %s: failed: %v",
+			expected, startTime.UTC().Format(time.RFC3339), assertErr)
+	}
+
+	return Entry{Time: finishTime, Source: SourceAssertion, Node: node, Type: query, Message: message}
+}
+
+// metricEntries converts the samples of metric in matrix into an entry for the first sample of each series and for
+// every change in its value afterwards, so that the timeline only shows transitions.
+func metricEntries(metric metrics.PtpMetric, matrix model.Matrix) []Entry {
+	var entries []Entry
+
+	for _, series := range matrix {
+		profile := string(series.Metric[model.LabelName(metrics.KeyConfig)])
+		if profile == "" {
+			profile = string(series.Metric[model.LabelName(metrics.KeyProfile)])
+		}
+
+		for index, sample := range series.Values {
+			if index > 0 && sample.Value == series.Values[index-1].Value {
+				continue
+			}
+
+			entries = append(entries, Entry{
+				Time:      sample.Timestamp.Time(),
+				Source:    SourceMetric,
+				Node:      string(series.Metric[model.LabelName(metrics.KeyNode)]),
+				Interface: string(series.Metric[model.LabelName(metrics.KeyInterface)]),
+				Process:   string(series.Metric[model.LabelName(metrics.KeyProcess)]),
+				Config:    profile,
+				Type:      string(metric),
+				Message:   formatMetricValue(metric, sample.Value),
+			})
+		}
+	}
+
+	return entries
+}
+
+// formatMetricValue formats value of metric, including the name of the value for enum metrics.
+func formatMetricValue(metric metrics.PtpMetric, value model.SampleValue) string {
+	formatted := value.String()
+
+	if name, ok := metricValueNames[metric][int64(value)]; ok && float64(int64(value)) == float64(value) {
+		formatted += " (" + name + ")"
+	}
+
+	return formatted
+}
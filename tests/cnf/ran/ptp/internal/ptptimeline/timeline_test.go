@@ -0,0 +1,338 @@
+package ptptimeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/daemonlogs"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePrometheusAPI returns the matrix for the metric of each range query and an empty matrix for any other metric.
+// All other methods of prometheusv1.API panic.
+type fakePrometheusAPI struct {
+	prometheusv1.API
+
+	matrices map[metrics.PtpMetric]model.Matrix
+}
+
+// QueryRange implements the prometheusv1.API interface.
+func (fakeAPI *fakePrometheusAPI) QueryRange(
+	_ context.Context, query string, _ prometheusv1.Range, _ ...prometheusv1.Option,
+) (model.Value, prometheusv1.Warnings, error) {
+	metric, _, _ := strings.Cut(query, "{")
+
+	matrix, ok := fakeAPI.matrices[metrics.PtpMetric(metric)]
+	if !ok {
+		return model.Matrix{}, nil, nil
+	}
+
+	return matrix, nil, nil
+}
+
+// eventLogLine returns a line of the consumer logs, as recorded with the time it was logged at logTime, for an event
+// of eventType at eventTime with a single value for interface ens1fx on node-0.
+func eventLogLine(logTime, eventTime time.Time, eventType, value string) string {
+	eventJSON := `{"id":"1","type":"` + eventType + `","source":"/sync/ptp-status/lock-state",` +
+		`"dataContentType":"application/json","time":"` + eventTime.Format(time.RFC3339) + `",` +
+		`"data":{"version":"1.0","values":[{"ResourceAddress":"/cluster/node/node-0/ens1fx/master",` +
+		`"data_type":"notification","value_type":"enumeration","value":"` + value + `"}]}}`
+	quoted := strconv.Quote(eventJSON)
+
+	return logTime.Format(time.RFC3339) + " level=info msg=\"received event " + quoted[1:len(quoted)-1] + "\""
+}
+
+func TestLogEntry(t *testing.T) {
+	testCases := []struct {
+		line          string
+		expectedOk    bool
+		expectedEntry Entry
+	}{
+		{
+			line:       "2026-10-18T10:00:00.5Z ptp4l[1234.567]: [ptp4l.0.config:6] port 1 (ens1f0): SLAVE to UNCALIBRATED",
+			expectedOk: true,
+			expectedEntry: Entry{
+				Time:      time.Date(2026, 10, 18, 10, 0, 0, 500000000, time.UTC),
+				Process:   "ptp4l",
+				Config:    "ptp4l.0.config",
+				Interface: "ens1f0",
+				Message:   "ptp4l[1234.567]: [ptp4l.0.config:6] port 1 (ens1f0): SLAVE to UNCALIBRATED",
+			},
+		},
+		{
+			line:       "2026-10-18T10:00:01Z T-BC[1760781601]:[ts2phc.0.config] ens2f0np0 T-BC-STATUS s2",
+			expectedOk: true,
+			expectedEntry: Entry{
+				Time:      time.Date(2026, 10, 18, 10, 0, 1, 0, time.UTC),
+				Process:   "T-BC",
+				Config:    "ts2phc.0.config",
+				Interface: "ens2f0np0",
+				Message:   "T-BC[1760781601]:[ts2phc.0.config] ens2f0np0 T-BC-STATUS s2",
+			},
+		},
+		{
+			line:       "2026-10-18T10:00:02Z phc2sys[1234.567]: [ptp4l.0.config:6] CLOCK_REALTIME phc offset -3 s2 freq -1",
+			expectedOk: true,
+			expectedEntry: Entry{
+				Time:    time.Date(2026, 10, 18, 10, 0, 2, 0, time.UTC),
+				Process: "phc2sys",
+				Config:  "ptp4l.0.config",
+				Message: "phc2sys[1234.567]: [ptp4l.0.config:6] CLOCK_REALTIME phc offset -3 s2 freq -1",
+			},
+		},
+		{line: "ptp4l[1234.567]: no timestamp", expectedOk: false},
+		{line: "2026-10-18T10:00:02Z ", expectedOk: false},
+	}
+
+	for _, testCase := range testCases {
+		entry, ok := logEntry("node-0", testCase.line)
+
+		assert.Equal(t, testCase.expectedOk, ok, testCase.line)
+
+		if testCase.expectedOk {
+			testCase.expectedEntry.Source = SourceLog
+			testCase.expectedEntry.Node = "node-0"
+
+			assert.Equal(t, testCase.expectedEntry, entry, testCase.line)
+		}
+	}
+}
+
+func TestAssertionEntry(t *testing.T) {
+	finishTime := time.Date(2026, 10, 18, 10, 0, 5, 0, time.UTC)
+	startTime := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		assertErr       error
+		expectedMessage string
+	}{
+		{
+			name:            "passed",
+			assertErr:       nil,
+			expectedMessage: "expected value 1 since 2026-10-18T10:00:00Z: passed",
+		},
+		{
+			name:            "failed",
+			assertErr:       errors.New("metric assertion failed"),
+			expectedMessage: "expected value 1 since 2026-10-18T10:00:00Z: failed: metric assertion failed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			entry := AssertionEntry(finishTime, "node-0", "openshift_ptp_clock_state{}", 1, startTime, testCase.assertErr)
+
+			assert.Equal(t, Entry{
+				Time:    finishTime,
+				Source:  SourceAssertion,
+				Node:    "node-0",
+				Type:    "openshift_ptp_clock_state{}",
+				Message: testCase.expectedMessage,
+			}, entry)
+		})
+	}
+}
+
+func TestEventEntries(t *testing.T) {
+	eventJSON := `{"id":"1","type":"event.sync.ptp-status.ptp-state-change",` +
+		`"source":"/sync/ptp-status/lock-state","dataContentType":"application/json","time":"2026-10-18T10:00:03Z",` +
+		`"data":{"version":"1.0","values":[` +
+		`{"ResourceAddress":"/cluster/node/node-0/ens1fx/master","data_type":"notification",` +
+		`"value_type":"enumeration","value":"FREERUN"},` +
+		`{"ResourceAddress":"/cluster/node/node-0/ens1fx/master","data_type":"metric",` +
+		`"value_type":"decimal64.3","value":"-2"}]}}`
+	quoted := strconv.Quote(eventJSON)
+	line := "2026-10-18T10:00:04Z time=\"2026-10-18T10:00:04Z\" level=info msg=\"received event " +
+		quoted[1:len(quoted)-1] + "\""
+
+	entries := eventEntries("node-0", line)
+
+	assert.Equal(t, []Entry{{
+		Time:      time.Date(2026, 10, 18, 10, 0, 3, 0, time.UTC),
+		Source:    SourceEvent,
+		Node:      "node-0",
+		Interface: "ens1fx",
+		Type:      "event.sync.ptp-status.ptp-state-change",
+		Message:   "/cluster/node/node-0/ens1fx/master=FREERUN /cluster/node/node-0/ens1fx/master=-2",
+	}}, entries)
+
+	assert.Empty(t, eventEntries("node-0", "2026-10-18T10:00:04Z level=info msg=\"subscribed\""))
+}
+
+func TestMetricEntries(t *testing.T) {
+	start := model.TimeFromUnix(1760781600)
+	matrix := model.Matrix{
+		{
+			Metric: model.Metric{"node": "node-0", "iface": "ens1fx", "process": "ptp4l", "__name__": "x"},
+			Values: []model.SamplePair{
+				{Timestamp: start, Value: 1},
+				{Timestamp: start.Add(time.Second), Value: 1},
+				{Timestamp: start.Add(2 * time.Second), Value: 2},
+				{Timestamp: start.Add(3 * time.Second), Value: 2},
+				{Timestamp: start.Add(4 * time.Second), Value: 1},
+			},
+		},
+	}
+
+	entries := metricEntries(metrics.MetricClockState, matrix)
+
+	var messages []string
+
+	for _, entry := range entries {
+		assert.Equal(t, SourceMetric, entry.Source)
+		assert.Equal(t, "node-0", entry.Node)
+		assert.Equal(t, "ens1fx", entry.Interface)
+		assert.Equal(t, "ptp4l", entry.Process)
+		assert.Equal(t, string(metrics.MetricClockState), entry.Type)
+
+		messages = append(messages, entry.Message)
+	}
+
+	assert.Equal(t, []string{"1 (LOCKED)", "2 (HOLDOVER)", "1 (LOCKED)"}, messages)
+	assert.Equal(t, start.Add(2*time.Second).Time(), entries[1].Time)
+
+	assert.Equal(t, "6", formatMetricValue(metrics.MetricClockClass, 6))
+}
+
+func TestWriteEntries(t *testing.T) {
+	entries := []Entry{
+		{Time: time.Date(2026, 10, 18, 10, 0, 2, 0, time.UTC), Source: SourceLog, Node: "node-0", Message: "second"},
+		{
+			Time:      time.Date(2026, 10, 18, 10, 0, 1, 0, time.UTC),
+			Source:    SourceMetric,
+			Node:      "node-0",
+			Interface: "ens1fx",
+			Process:   "ptp4l",
+			Type:      "openshift_ptp_clock_state",
+			Message:   "1 (LOCKED)",
+		},
+		{Time: time.Date(2026, 10, 18, 10, 0, 2, 0, time.UTC), Source: SourceAssertion, Message: "third"},
+	}
+
+	sortEntries(entries)
+
+	var jsonBuffer, textBuffer bytes.Buffer
+
+	err := writeEntries(entries, &jsonBuffer, &textBuffer)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "2026-10-18T10:00:01Z Metric    [node-0 ens1fx ptp4l] openshift_ptp_clock_state 1 (LOCKED)\n"+
+		"2026-10-18T10:00:02Z Log       [node-0] second\n"+
+		"2026-10-18T10:00:02Z Assertion [] third\n", textBuffer.String())
+
+	lines := strings.Split(strings.TrimSpace(jsonBuffer.String()), "\n")
+	assert.Len(t, lines, 3)
+
+	var decoded Entry
+
+	err = json.Unmarshal([]byte(lines[0]), &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, entries[0], decoded)
+}
+
+func TestLogSourceEntries(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), tempFilePattern)
+	assert.NoError(t, err)
+
+	defer file.Close()
+
+	_, err = file.WriteString("2026-10-18T10:00:00Z ptp4l[1.0]: first\n" +
+		"2026-10-18T10:00:01Z ptp4l[2.0]: second\n" +
+		"not a log line\n")
+	assert.NoError(t, err)
+
+	source := &logSource{node: "node-0", source: SourceLog, file: file}
+
+	entries, err := source.entries()
+	assert.NoError(t, err)
+
+	var messages []string
+
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+
+	assert.Equal(t, []string{"ptp4l[1.0]: first", "ptp4l[2.0]: second"}, messages)
+}
+
+// TestRecorderEntries checks that the events and metrics an eventmetric assertion waits on, along with its outcome,
+// land in the timeline of the recorded window it references.
+func TestRecorderEntries(t *testing.T) {
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	clockState := model.Metric{"node": "node-0", "iface": "ens1fx", "process": "ptp4l"}
+
+	fakeAPI := &fakePrometheusAPI{matrices: map[metrics.PtpMetric]model.Matrix{
+		metrics.MetricClockState: {{
+			Metric: clockState,
+			Values: []model.SamplePair{
+				{Timestamp: model.TimeFromUnix(start.Add(time.Second).Unix()), Value: 0},
+				{Timestamp: model.TimeFromUnix(start.Add(4 * time.Second).Unix()), Value: 1},
+			},
+		}},
+	}}
+
+	file, err := os.CreateTemp(t.TempDir(), tempFilePattern)
+	assert.NoError(t, err)
+
+	defer file.Close()
+
+	_, err = file.WriteString(eventLogLine(start.Add(3*time.Second), start.Add(3*time.Second),
+		"event.sync.ptp-status.ptp-state-change", "LOCKED") + "\n")
+	assert.NoError(t, err)
+
+	follower := daemonlogs.NewPodLogFollower(nil, "node-0", "", start, nil)
+	recorder := &Recorder{prometheusAPI: fakeAPI, start: start, end: start.Add(10 * time.Second)}
+	recorder.sources = append(recorder.sources, &logSource{
+		node:         "node-0",
+		source:       SourceEvent,
+		subscription: follower.Subscribe(),
+		follower:     follower,
+		file:         file,
+	})
+
+	window := recorder.Window()
+	assert.Equal(t, Window{Start: start, End: start.Add(10 * time.Second)}, window)
+
+	query := metrics.MetricQuery[int64]{Metric: metrics.MetricClockState}.String()
+	recorder.Add(AssertionEntry(start.Add(5*time.Second), "node-0", query, 1, window.Start, nil))
+
+	entries, err := recorder.Entries(t.Context())
+	assert.NoError(t, err)
+
+	var summaries []string
+
+	for _, entry := range entries {
+		summaries = append(summaries, entry.Time.UTC().Format(time.TimeOnly)+" "+string(entry.Source)+" "+entry.Message)
+	}
+
+	assert.Equal(t, []string{
+		"10:00:01 Metric 0 (FREERUN)",
+		"10:00:03 Event /cluster/node/node-0/ens1fx/master=LOCKED",
+		"10:00:04 Metric 1 (LOCKED)",
+		"10:00:05 Assertion expected value 1 since 2026-10-18T10:00:00Z: passed",
+	}, summaries)
+}
+
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+
+	recorder.Add(Entry{Message: "ignored"})
+	recorder.Stop()
+
+	assert.Equal(t, Window{}, recorder.Window())
+
+	entries, err := recorder.Entries(t.Context())
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
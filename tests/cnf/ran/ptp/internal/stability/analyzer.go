@@ -3,12 +3,12 @@ package stability
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
 
-	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/processes"
-	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/tsparams"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/internal/ranparam"
 	"k8s.io/klog/v2"
 )
 
@@ -73,6 +73,9 @@ type ProcessResult struct {
 	ThresholdViolationCount int
 	// StateTransitions is the servo state transitions between adjacent entries.
 	StateTransitions []StateTransition
+	// TimeErrors is the ITU-T time error of the process's s2 offsets for each config file and clock, in the order
+	// they were first logged. Each is computed separately so that one clock cannot hide or skew another.
+	TimeErrors []SeriesTimeError
 
 	name      string
 	pattern   *regexp.Regexp
@@ -81,7 +84,9 @@ type ProcessResult struct {
 	candidateLines int
 	droppedLines   int
 
-	prevState string
+	prevState   string
+	series      map[string]*timeErrorSeries
+	seriesOrder []string
 }
 
 // processEntry tries to parse a log line and updates per-process accumulators if it matches.
@@ -94,7 +99,7 @@ func (p *ProcessResult) processEntry(line string) {
 	p.candidateLines++
 
 	if result.Dropped {
-		klog.V(tsparams.LogLevel).Infof("%s: dropping line with unparseable offset %q", p.name, line)
+		klog.V(ranparam.LogLevel).Infof("%s: dropping line with unparseable offset %q", p.name, line)
 
 		p.droppedLines++
 
@@ -103,8 +108,12 @@ func (p *ProcessResult) processEntry(line string) {
 
 	p.Stats.observe(result.Entry.Offset)
 
-	if result.Entry.State == "s2" && abs(result.Entry.Offset) > p.threshold {
-		p.ThresholdViolationCount++
+	if result.Entry.State == "s2" {
+		p.observeSeries(result.Entry)
+
+		if abs(result.Entry.Offset) > p.threshold {
+			p.ThresholdViolationCount++
+		}
 	}
 
 	if p.prevState != "" && p.prevState != result.Entry.State {
@@ -118,6 +127,53 @@ func (p *ProcessResult) processEntry(line string) {
 	p.prevState = result.Entry.State
 }
 
+// observeSeries adds the offset of entry to the time error series of its source, creating the series if needed.
+func (p *ProcessResult) observeSeries(entry LogEntry) {
+	if p.series == nil {
+		p.series = make(map[string]*timeErrorSeries)
+	}
+
+	series, ok := p.series[entry.Source]
+	if !ok {
+		series = &timeErrorSeries{}
+		p.series[entry.Source] = series
+		p.seriesOrder = append(p.seriesOrder, entry.Source)
+	}
+
+	series.observe(entry.Timestamp, entry.Offset)
+}
+
+// finalize computes the time error metrics of each series of the process and checks them against class.
+func (p *ProcessResult) finalize(class ClockClass) {
+	p.TimeErrors = nil
+
+	for _, source := range p.seriesOrder {
+		series := p.series[source]
+		metrics := series.metrics()
+
+		p.TimeErrors = append(p.TimeErrors, SeriesTimeError{
+			Source:     source,
+			Metrics:    metrics,
+			Segments:   series.segments,
+			Violations: metrics.Violations(class),
+		})
+	}
+}
+
+// gapDetails returns a failure detail for each gap in the time error series of the process.
+func (p *ProcessResult) gapDetails() []string {
+	var details []string
+
+	for _, timeError := range p.TimeErrors {
+		for _, gap := range timeError.Metrics.Gaps {
+			details = append(details, p.name+" "+withSource(timeError.Source,
+				fmt.Sprintf("s2 offsets missing for %s after %d s", gap.Duration, gap.After)))
+		}
+	}
+
+	return details
+}
+
 // parseWarning returns a human-readable warning if any lines were dropped during parsing, or an empty string otherwise.
 func (p *ProcessResult) parseWarning() string {
 	if p.droppedLines == 0 {
@@ -140,6 +196,14 @@ type AnalysisResult struct {
 	// PHC2SYS is the per-process result for phc2sys.
 	PHC2SYS ProcessResult
 
+	// TargetClass is the G.8273.2 clock class the ptp4l time error metrics are checked against. No class is checked
+	// if it is ClockClassNone.
+	TargetClass ClockClass
+	// ClassViolations is a list of the limits of TargetClass exceeded by any ptp4l time error series, each prefixed
+	// by the source of the series. The phc2sys series have their own violations too, but they are informational only
+	// since G.8273.2 covers the PTP clock rather than the system clock.
+	ClassViolations []string
+
 	// PTP4LStartCount is the number of times the ptp4l process was started.
 	PTP4LStartCount uint
 	// FaultyLineCount is the count of lines containing the word "FAULTY".
@@ -153,45 +217,80 @@ type AnalysisResult struct {
 }
 
 // AnalyzeFromFile performs a single-pass streaming analysis of the daemon log file at filePath. It reads the file line
-// by line to keep memory bounded regardless of file size.
-func AnalyzeFromFile(filePath string, thresholdAbsoluteNanoseconds int64) (AnalysisResult, error) {
+// by line to keep memory bounded regardless of file size, apart from the one sample per second kept for the time error
+// metrics. The ptp4l time error metrics are checked against targetClass unless it is ClockClassNone.
+func AnalyzeFromFile(
+	filePath string, thresholdAbsoluteNanoseconds int64, targetClass ClockClass) (AnalysisResult, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return AnalysisResult{}, fmt.Errorf("failed to open log file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
+	result, err := Analyze(file, thresholdAbsoluteNanoseconds, targetClass)
+	if err != nil {
+		return AnalysisResult{}, fmt.Errorf("error reading log file %s: %w", filePath, err)
+	}
+
+	return result, nil
+}
+
+// Analyze is the same as AnalyzeFromFile but reads the daemon log from reader.
+func Analyze(reader io.Reader, thresholdAbsoluteNanoseconds int64, targetClass ClockClass) (AnalysisResult, error) {
+	analyzer := NewAnalyzer(thresholdAbsoluteNanoseconds, targetClass)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		analyzer.ProcessLine(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return AnalysisResult{}, err
+	}
+
+	return analyzer.Result(), nil
+}
+
+// Analyzer performs the same analysis as AnalyzeFromFile one line at a time, for callers that need to split a log
+// between several analyses. It is not safe for concurrent use.
+type Analyzer struct {
+	result AnalysisResult
+}
+
+// NewAnalyzer returns an Analyzer using the same threshold and target class as AnalyzeFromFile. A threshold of zero or
+// less uses DefaultOffsetThresholdAbsoluteNanoseconds.
+func NewAnalyzer(thresholdAbsoluteNanoseconds int64, targetClass ClockClass) *Analyzer {
 	if thresholdAbsoluteNanoseconds <= 0 {
 		thresholdAbsoluteNanoseconds = DefaultOffsetThresholdAbsoluteNanoseconds
 	}
 
-	result := AnalysisResult{
+	return &Analyzer{result: AnalysisResult{
+		TargetClass: targetClass,
 		PTP4L: ProcessResult{
-			name:      string(processes.Ptp4l),
+			name:      ptp4lName,
 			pattern:   ptp4lPattern,
 			threshold: thresholdAbsoluteNanoseconds,
 		},
 		PHC2SYS: ProcessResult{
-			name:      string(processes.Phc2sys),
+			name:      phc2sysName,
 			pattern:   phc2sysPattern,
 			threshold: thresholdAbsoluteNanoseconds,
 		},
-	}
-
-	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-	for scanner.Scan() {
-		result.processLine(scanner.Text())
-	}
+	}}
+}
 
-	if err := scanner.Err(); err != nil {
-		return AnalysisResult{}, fmt.Errorf("error reading log file %s: %w", filePath, err)
-	}
+// ProcessLine adds a single daemon log line, without its trailing newline, to the analysis.
+func (analyzer *Analyzer) ProcessLine(line string) {
+	analyzer.result.processLine(line)
+}
 
-	result.finalize()
+// Result returns the result of the analysis of all the lines processed so far.
+func (analyzer *Analyzer) Result() AnalysisResult {
+	analyzer.result.finalize()
 
-	return result, nil
+	return analyzer.result
 }
 
 // processLine parses and accumulates a single log line.
@@ -214,6 +313,16 @@ func (a *AnalysisResult) processLine(line string) {
 
 // finalize processes the accumulated data and determines the pass/fail decision.
 func (a *AnalysisResult) finalize() {
+	a.PTP4L.finalize(a.TargetClass)
+	a.PHC2SYS.finalize(a.TargetClass)
+	a.ClassViolations = nil
+
+	for _, timeError := range a.PTP4L.TimeErrors {
+		for _, violation := range timeError.Violations {
+			a.ClassViolations = append(a.ClassViolations, withSource(timeError.Source, violation))
+		}
+	}
+
 	a.ParseWarnings = a.buildParseWarnings()
 	a.Details = buildFailureDetails(*a)
 	a.Passed = len(a.Details) == 0
@@ -262,8 +371,23 @@ func (a AnalysisResult) DiagnosticMessage() string {
 	builder.WriteString(formatStatsLine("ptp4l", a.PTP4L.Stats))
 	builder.WriteByte('\n')
 	builder.WriteString(formatStatsLine("phc2sys", a.PHC2SYS.Stats))
+
+	for _, timeError := range a.PTP4L.TimeErrors {
+		builder.WriteByte('\n')
+		builder.WriteString(formatTimeErrorLine("ptp4l", timeError))
+	}
+
+	for _, timeError := range a.PHC2SYS.TimeErrors {
+		builder.WriteByte('\n')
+		builder.WriteString(formatTimeErrorLine("phc2sys", timeError))
+	}
+
 	fmt.Fprintf(&builder, "\nptp4l_start_count=%d", a.PTP4LStartCount)
 
+	if a.TargetClass != ClockClassNone {
+		fmt.Fprintf(&builder, "\ntarget_class=%s", a.TargetClass)
+	}
+
 	return builder.String()
 }
 
@@ -305,9 +429,25 @@ func buildFailureDetails(result AnalysisResult) []string {
 		details = append(details, fmt.Sprintf("found %d ptp4l restarts", result.PTP4LStartCount-1))
 	}
 
+	details = append(details, result.PTP4L.gapDetails()...)
+	details = append(details, result.PHC2SYS.gapDetails()...)
+
+	for _, violation := range result.ClassViolations {
+		details = append(details, "ptp4l "+violation)
+	}
+
 	return details
 }
 
+// withSource prefixes message with the source of a time error series, if there is one.
+func withSource(source, message string) string {
+	if source == "" {
+		return message
+	}
+
+	return source + ": " + message
+}
+
 // formatStatsLine renders an OffsetStatistics value as a single key=value diagnostic line.
 func formatStatsLine(process string, stats OffsetStatistics) string {
 	return fmt.Sprintf("%s_offsets_max_abs=%d min_abs=%d avg_abs=%.3f samples=%d",
@@ -11,6 +11,12 @@ import (
 type LogEntry struct {
 	// Raw is the full raw log line that was matched.
 	Raw string
+	// Timestamp is the monotonic time in seconds at which the line was logged, or zero if the line does not include
+	// one.
+	Timestamp float64
+	// Source is the config file and clock that the offset was logged for, such as "ptp4l.0.config" or
+	// "ptp4l.0.config CLOCK_REALTIME". Either part is left out if the line does not include it.
+	Source string
 	// Offset is the offset of the log line in nanoseconds.
 	Offset int64
 	// State is the servo state of the log line. It is the letter s followed by a number. For example, "s1" means
@@ -18,13 +24,27 @@ type LogEntry struct {
 	State string
 }
 
+const (
+	// ptp4lName and phc2sysName are the names of the processes whose log lines are analyzed. They are the same as in
+	// the processes package, which is not imported so this package has no cluster dependencies and can be used by the
+	// ptplogs tool.
+	ptp4lName   = "ptp4l"
+	phc2sysName = "phc2sys"
+)
+
 var (
 	// ptp4lPattern is a regular expression that matches the ptp4l log lines. For example:
 	//  ptp4l[401304.873]: [ptp4l.1.config:6] master offset         -3 s2 freq  -94379 path delay       161
-	ptp4lPattern = regexp.MustCompile(`^ptp4l\[.*?\boffset\s+(?P<offset>-?\d+)\s+(?P<state>s\d+).*delay`)
+	ptp4lPattern = regexp.MustCompile(
+		`^ptp4l\[(?P<timestamp>\d+(?:\.\d+)?)?.*?\boffset\s+(?P<offset>-?\d+)\s+(?P<state>s\d+).*delay`)
 	// phc2sysPattern is a regular expression that matches the phc2sys log lines. For example:
 	//  phc2sys[401304.879]: [ptp4l.1.config:6] CLOCK_REALTIME phc offset        -5 s2 freq  -19334 delay    470
-	phc2sysPattern = regexp.MustCompile(`^phc2sys\[.*?\boffset\s+(?P<offset>-?\d+)\s+(?P<state>s\d+).*delay`)
+	phc2sysPattern = regexp.MustCompile(
+		`^phc2sys\[(?P<timestamp>\d+(?:\.\d+)?)?.*?\boffset\s+(?P<offset>-?\d+)\s+(?P<state>s\d+).*delay`)
+	// sourcePattern matches the config file and clock before the offset of ptp4l and phc2sys log lines. For example,
+	// [ptp4l.1.config:6] master offset has only a config and [ptp4l.1.config:6] CLOCK_REALTIME phc offset has both.
+	sourcePattern = regexp.MustCompile(
+		`(?:\[(?P<config>[\w.-]+\.config)(?::\d+)?\]\s+)?(?:(?P<clock>[\w.-]+)\s+)?(?:master|phc|sys)\s+offset`)
 )
 
 // ParseResult holds the outcome of attempting to parse a single log line.
@@ -46,22 +66,40 @@ func tryParseEntry(line string, pattern *regexp.Regexp) ParseResult {
 
 	offsetIndex := pattern.SubexpIndex("offset")
 	stateIndex := pattern.SubexpIndex("state")
+	timestampIndex := pattern.SubexpIndex("timestamp")
 
 	offset, err := strconv.ParseInt(match[offsetIndex], 10, 64)
 	if err != nil {
 		return ParseResult{Matched: true, Dropped: true}
 	}
 
+	// The timestamp is optional, so failing to parse it only loses the timing of the entry.
+	timestamp, _ := strconv.ParseFloat(match[timestampIndex], 64)
+
 	return ParseResult{
 		Entry: LogEntry{
-			Raw:    line,
-			Offset: offset,
-			State:  match[stateIndex],
+			Raw:       line,
+			Timestamp: timestamp,
+			Source:    parseSource(line),
+			Offset:    offset,
+			State:     match[stateIndex],
 		},
 		Matched: true,
 	}
 }
 
+// parseSource returns the config file and clock of the offset in line, separated by a space, leaving out either if
+// line does not include it.
+func parseSource(line string) string {
+	match := sourcePattern.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(
+		match[sourcePattern.SubexpIndex("config")] + " " + match[sourcePattern.SubexpIndex("clock")])
+}
+
 // isPTP4LStart returns true if the line indicates a ptp4l process start.
 func isPTP4LStart(line string) bool {
 	return strings.Contains(line, "Starting ptp4l")
@@ -0,0 +1,423 @@
+package stability
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ClockClass is a performance class of a telecom boundary clock or telecom time slave clock, as defined by ITU-T
+// G.8273.2. Classes are ordered from least to most accurate.
+type ClockClass string
+
+const (
+	// ClockClassNone means no class is targeted. Time error metrics are still computed but never fail the analysis.
+	ClockClassNone ClockClass = ""
+	// ClockClassA is G.8273.2 class A.
+	ClockClassA ClockClass = "A"
+	// ClockClassB is G.8273.2 class B.
+	ClockClassB ClockClass = "B"
+	// ClockClassC is G.8273.2 class C.
+	ClockClassC ClockClass = "C"
+	// ClockClassD is G.8273.2 class D.
+	ClockClassD ClockClass = "D"
+)
+
+// ParseClockClass parses a clock class name such as "C" or "c". An empty name parses to ClockClassNone.
+func ParseClockClass(name string) (ClockClass, error) {
+	class := ClockClass(strings.ToUpper(strings.TrimSpace(name)))
+
+	switch class {
+	case ClockClassNone, ClockClassA, ClockClassB, ClockClassC, ClockClassD:
+		return class, nil
+	default:
+		return ClockClassNone, fmt.Errorf("unknown G.8273.2 clock class %q, expected one of A, B, C, or D", name)
+	}
+}
+
+// ClassLimits are the time error limits of a clock class, all in nanoseconds. A limit of zero is not specified by
+// G.8273.2 for the class and is not checked.
+type ClassLimits struct {
+	// MaxAbsTE is the limit on max|TE|, the maximum absolute unfiltered time error.
+	MaxAbsTE float64
+	// CTE is the limit on the absolute constant time error.
+	CTE float64
+	// MaxAbsTEL is the limit on max|TE_L|, the maximum absolute time error after the 0.1 Hz low pass filter.
+	MaxAbsTEL float64
+	// MTIE is the limit on the MTIE of dTE_L for observation intervals up to 1000 s.
+	MTIE float64
+	// TDEV is the limit on the TDEV of dTE_L for observation intervals up to 1000 s.
+	TDEV float64
+}
+
+// classLimits holds the limits of G.8273.2 tables 7-1 and 7-2 for each class. Classes A and B limit the unfiltered
+// max|TE| while classes C and D limit max|TE_L|. Most limits of class D are still for further study, so only max|TE_L|
+// is checked for it.
+var classLimits = map[ClockClass]ClassLimits{
+	ClockClassA: {MaxAbsTE: 100, CTE: 50, MTIE: 40, TDEV: 4},
+	ClockClassB: {MaxAbsTE: 70, CTE: 20, MTIE: 40, TDEV: 4},
+	ClockClassC: {MaxAbsTEL: 30, CTE: 10, MTIE: 10, TDEV: 2},
+	ClockClassD: {MaxAbsTEL: 5},
+}
+
+// Limits returns the time error limits of the class. ClockClassNone has no limits.
+func (class ClockClass) Limits() ClassLimits {
+	return classLimits[class]
+}
+
+// StandardObservationIntervals are the observation intervals at which MTIE and TDEV are computed. They cover the range
+// of the G.8273.2 dTE_L masks. Intervals longer than the collected series allows are skipped.
+var StandardObservationIntervals = []time.Duration{time.Second, 10 * time.Second, 100 * time.Second, 1000 * time.Second}
+
+const (
+	// lowPassCutoffHertz is the corner frequency of the filter separating dTE_L from dTE_H in G.8273.2.
+	lowPassCutoffHertz = 0.1
+	// samplePeriod is the period of the resampled time error series.
+	samplePeriod = time.Second
+	// maxGapSeconds is the most missing seconds that are filled by repeating the previous sample. Longer gaps are
+	// reported and split the series into segments, since the metrics would not be meaningful across them.
+	maxGapSeconds = 60
+)
+
+// WindowMetric is the value of a metric at a single observation interval.
+type WindowMetric struct {
+	// ObservationInterval is the observation interval, often written as tau.
+	ObservationInterval time.Duration
+	// Value is the value of the metric in nanoseconds.
+	Value float64
+}
+
+// Gap is a period longer than maxGapSeconds during which no s2 offsets were logged.
+type Gap struct {
+	// After is the monotonic time in seconds of the last sample before the gap.
+	After int64
+	// Duration is the time between the samples before and after the gap.
+	Duration time.Duration
+}
+
+// SeriesSegment is a part of a resampled time error series without any gaps.
+type SeriesSegment struct {
+	// Start is the monotonic time in seconds of the first sample.
+	Start int64
+	// Samples is the offsets in nanoseconds, one per second.
+	Samples []float64
+}
+
+// TimeErrorMetrics are the ITU-T time error metrics of the offsets of a single config file and clock while its servo
+// was locked. Offsets are resampled to one sample per second before filtering, so all metrics except MaxAbsTE use the
+// resampled series. Filtering, MTIE, and TDEV are computed separately for each segment of the series between gaps and
+// the largest value of any segment is reported. All values are in nanoseconds.
+type TimeErrorMetrics struct {
+	// SampleCount is the number of one second samples in the resampled series.
+	SampleCount int
+	// Gaps is the gaps in the series, which are reported instead of being included in the metrics.
+	Gaps []Gap
+	// MaxAbsTE is max|TE|, the maximum absolute unfiltered offset of any locked sample.
+	MaxAbsTE int64
+	// CTE is the constant time error, the mean of the resampled series.
+	CTE float64
+	// MaxAbsTEL is max|TE_L|, the maximum absolute value of the series after the 0.1 Hz low pass filter.
+	MaxAbsTEL float64
+	// DTEHPeakToPeak is the peak to peak amplitude of dTE_H, the series after the 0.1 Hz high pass filter.
+	DTEHPeakToPeak float64
+	// MTIE is the maximum time interval error of dTE_L at each of the StandardObservationIntervals that at least one
+	// segment is long enough for.
+	MTIE []WindowMetric
+	// TDEV is the time deviation of dTE_L at each of the StandardObservationIntervals that at least one segment is
+	// long enough for.
+	TDEV []WindowMetric
+}
+
+// SeriesTimeError is the time error of the s2 offsets that a process logged for a single config file and clock.
+type SeriesTimeError struct {
+	// Source is the config file and clock of the offsets, as in LogEntry.Source.
+	Source string
+	// Metrics is the time error metrics of the series.
+	Metrics TimeErrorMetrics
+	// Segments is the resampled offsets that Metrics is computed from, split at each of Metrics.Gaps.
+	Segments []SeriesSegment
+	// Violations is the limits of the target class of the analysis that Metrics exceeds.
+	Violations []string
+}
+
+// Violations returns a message for each limit of class that the metrics exceed. It returns nothing if there are no
+// samples, since the missing samples are reported separately.
+func (metrics TimeErrorMetrics) Violations(class ClockClass) []string {
+	if metrics.SampleCount == 0 {
+		return nil
+	}
+
+	var (
+		limits     = class.Limits()
+		violations []string
+	)
+
+	check := func(name string, value, limit float64) {
+		if limit > 0 && value > limit {
+			violations = append(violations,
+				fmt.Sprintf("%s %.3f ns exceeds class %s limit of %.0f ns", name, value, class, limit))
+		}
+	}
+
+	check("max|TE|", float64(metrics.MaxAbsTE), limits.MaxAbsTE)
+	check("|cTE|", math.Abs(metrics.CTE), limits.CTE)
+	check("max|TE_L|", metrics.MaxAbsTEL, limits.MaxAbsTEL)
+
+	for _, window := range metrics.MTIE {
+		check(fmt.Sprintf("dTE_L MTIE(%s)", window.ObservationInterval), window.Value, limits.MTIE)
+	}
+
+	for _, window := range metrics.TDEV {
+		check(fmt.Sprintf("dTE_L TDEV(%s)", window.ObservationInterval), window.Value, limits.TDEV)
+	}
+
+	return violations
+}
+
+// timeErrorSeries accumulates the locked offsets of a single config file and clock into a series with one sample per
+// second. Offsets logged in the same second are averaged and up to maxGapSeconds without offsets repeat the previous
+// sample. Longer gaps, and offsets going back in time such as after a reboot, start a new segment. Memory grows with
+// the length of the series rather than the number of log lines.
+type timeErrorSeries struct {
+	segments []SeriesSegment
+	gaps     []Gap
+	maxAbsTE int64
+
+	bucketSecond int64
+	bucketSum    float64
+	bucketCount  int
+}
+
+// observe adds an offset logged at timestamp, in seconds, to the series. A timestamp of zero is treated as one second
+// after the previous offset.
+func (series *timeErrorSeries) observe(timestamp float64, offset int64) {
+	series.maxAbsTE = max(series.maxAbsTE, abs(offset))
+
+	second := int64(math.Floor(timestamp))
+	if timestamp <= 0 {
+		second = series.bucketSecond + 1
+	}
+
+	if series.bucketCount > 0 && second != series.bucketSecond {
+		previousSecond := series.bucketSecond
+		series.flush()
+
+		switch missingSeconds := second - previousSecond - 1; {
+		case missingSeconds < 0:
+			series.segments = append(series.segments, SeriesSegment{Start: second})
+		case missingSeconds > maxGapSeconds:
+			series.gaps = append(series.gaps,
+				Gap{After: previousSecond, Duration: time.Duration(second-previousSecond) * time.Second})
+			series.segments = append(series.segments, SeriesSegment{Start: second})
+		default:
+			segment := &series.segments[len(series.segments)-1]
+
+			for range missingSeconds {
+				segment.Samples = append(segment.Samples, segment.Samples[len(segment.Samples)-1])
+			}
+		}
+	}
+
+	if series.bucketCount == 0 {
+		series.bucketSecond = second
+	}
+
+	if len(series.segments) == 0 {
+		series.segments = append(series.segments, SeriesSegment{Start: second})
+	}
+
+	series.bucketSum += float64(offset)
+	series.bucketCount++
+}
+
+// flush appends the average of the current bucket to the last segment and starts a new bucket.
+func (series *timeErrorSeries) flush() {
+	if series.bucketCount == 0 {
+		return
+	}
+
+	segment := &series.segments[len(series.segments)-1]
+	segment.Samples = append(segment.Samples, series.bucketSum/float64(series.bucketCount))
+	series.bucketSum = 0
+	series.bucketCount = 0
+}
+
+// metrics flushes the last bucket and computes the time error metrics of the series.
+func (series *timeErrorSeries) metrics() TimeErrorMetrics {
+	series.flush()
+
+	metrics := TimeErrorMetrics{MaxAbsTE: series.maxAbsTE, Gaps: series.gaps}
+
+	for _, segment := range series.segments {
+		metrics.SampleCount += len(segment.Samples)
+
+		for _, sample := range segment.Samples {
+			metrics.CTE += sample
+		}
+	}
+
+	if metrics.SampleCount == 0 {
+		return TimeErrorMetrics{}
+	}
+
+	metrics.CTE /= float64(metrics.SampleCount)
+
+	var (
+		minHigh, maxHigh = math.Inf(1), math.Inf(-1)
+		mtieValues       = make(map[time.Duration]float64)
+		tdevValues       = make(map[time.Duration]float64)
+	)
+
+	for _, segment := range series.segments {
+		lowPassed := lowPass(segment.Samples, samplePeriod.Seconds(), lowPassCutoffHertz)
+
+		for index, sample := range lowPassed {
+			metrics.MaxAbsTEL = max(metrics.MaxAbsTEL, math.Abs(sample))
+
+			highPassed := segment.Samples[index] - sample
+			minHigh = min(minHigh, highPassed)
+			maxHigh = max(maxHigh, highPassed)
+		}
+
+		for _, interval := range StandardObservationIntervals {
+			windowSamples := int(interval / samplePeriod)
+
+			if value, ok := mtie(lowPassed, windowSamples); ok {
+				mtieValues[interval] = max(mtieValues[interval], value)
+			}
+
+			if value, ok := tdev(lowPassed, windowSamples); ok {
+				tdevValues[interval] = max(tdevValues[interval], value)
+			}
+		}
+	}
+
+	metrics.DTEHPeakToPeak = maxHigh - minHigh
+
+	for _, interval := range StandardObservationIntervals {
+		if value, ok := mtieValues[interval]; ok {
+			metrics.MTIE = append(metrics.MTIE, WindowMetric{ObservationInterval: interval, Value: value})
+		}
+
+		if value, ok := tdevValues[interval]; ok {
+			metrics.TDEV = append(metrics.TDEV, WindowMetric{ObservationInterval: interval, Value: value})
+		}
+	}
+
+	return metrics
+}
+
+// lowPass applies a first order low pass filter with the given cutoff to samples taken every period seconds. The
+// filter starts at the first sample to avoid a startup transient.
+func lowPass(samples []float64, period, cutoffHertz float64) []float64 {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	timeConstant := 1 / (2 * math.Pi * cutoffHertz)
+	alpha := period / (timeConstant + period)
+
+	filtered := make([]float64, len(samples))
+	filtered[0] = samples[0]
+
+	for index := 1; index < len(samples); index++ {
+		filtered[index] = filtered[index-1] + alpha*(samples[index]-filtered[index-1])
+	}
+
+	return filtered
+}
+
+// mtie returns the maximum time interval error of samples over windows spanning windowSamples sample periods, which is
+// the largest peak to peak amplitude of any such window. It returns false if there are not enough samples.
+func mtie(samples []float64, windowSamples int) (float64, bool) {
+	if windowSamples < 1 || len(samples) <= windowSamples {
+		return 0, false
+	}
+
+	// The deques hold the indices of the candidates for the maximum and minimum of the current window, so that each
+	// window is evaluated in constant amortized time.
+	var (
+		maxIndices []int
+		minIndices []int
+		result     float64
+	)
+
+	for index, sample := range samples {
+		for len(maxIndices) > 0 && samples[maxIndices[len(maxIndices)-1]] <= sample {
+			maxIndices = maxIndices[:len(maxIndices)-1]
+		}
+
+		for len(minIndices) > 0 && samples[minIndices[len(minIndices)-1]] >= sample {
+			minIndices = minIndices[:len(minIndices)-1]
+		}
+
+		maxIndices = append(maxIndices, index)
+		minIndices = append(minIndices, index)
+
+		windowStart := index - windowSamples
+		if maxIndices[0] < windowStart {
+			maxIndices = maxIndices[1:]
+		}
+
+		if minIndices[0] < windowStart {
+			minIndices = minIndices[1:]
+		}
+
+		if windowStart >= 0 {
+			result = max(result, samples[maxIndices[0]]-samples[minIndices[0]])
+		}
+	}
+
+	return result, true
+}
+
+// tdev returns the time deviation of samples at an observation interval of windowSamples sample periods, using the
+// estimator of ITU-T G.810. It returns false if there are fewer than 3*windowSamples samples.
+func tdev(samples []float64, windowSamples int) (float64, bool) {
+	count := len(samples) - 3*windowSamples + 1
+	if windowSamples < 1 || count < 1 {
+		return 0, false
+	}
+
+	prefixSums := make([]float64, len(samples)+1)
+	for index, sample := range samples {
+		prefixSums[index+1] = prefixSums[index] + sample
+	}
+
+	windowSum := func(start int) float64 {
+		return prefixSums[start+windowSamples] - prefixSums[start]
+	}
+
+	var total float64
+
+	for start := range count {
+		secondDifference := windowSum(start+2*windowSamples) - 2*windowSum(start+windowSamples) + windowSum(start)
+		total += secondDifference * secondDifference
+	}
+
+	return math.Sqrt(total / (6 * float64(windowSamples*windowSamples) * float64(count))), true
+}
+
+// formatTimeErrorLine renders the TimeErrorMetrics of a series as a single key=value diagnostic line.
+func formatTimeErrorLine(process string, timeError SeriesTimeError) string {
+	var (
+		builder strings.Builder
+		metrics = timeError.Metrics
+	)
+
+	fmt.Fprintf(&builder,
+		"%s_time_error source=%q cte=%.3f max_abs_te=%d max_abs_te_l=%.3f dte_h_pk_pk=%.3f samples=%d gaps=%d",
+		process, timeError.Source, metrics.CTE, metrics.MaxAbsTE, metrics.MaxAbsTEL, metrics.DTEHPeakToPeak,
+		metrics.SampleCount, len(metrics.Gaps))
+
+	for _, window := range metrics.MTIE {
+		fmt.Fprintf(&builder, " mtie_%s=%.3f", window.ObservationInterval, window.Value)
+	}
+
+	for _, window := range metrics.TDEV {
+		fmt.Fprintf(&builder, " tdev_%s=%.3f", window.ObservationInterval, window.Value)
+	}
+
+	return builder.String()
+}
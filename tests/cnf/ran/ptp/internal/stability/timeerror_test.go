@@ -0,0 +1,249 @@
+package stability
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClockClass(t *testing.T) {
+	testCases := []struct {
+		name          string
+		expectedClass ClockClass
+		expectedError bool
+	}{
+		{name: "", expectedClass: ClockClassNone},
+		{name: "A", expectedClass: ClockClassA},
+		{name: " c ", expectedClass: ClockClassC},
+		{name: "d", expectedClass: ClockClassD},
+		{name: "E", expectedClass: ClockClassNone, expectedError: true},
+	}
+
+	for _, testCase := range testCases {
+		class, err := ParseClockClass(testCase.name)
+
+		assert.Equal(t, testCase.expectedClass, class)
+		assert.Equal(t, testCase.expectedError, err != nil)
+	}
+}
+
+func TestClockClassLimits(t *testing.T) {
+	// The limits of G.8273.2 tables 7-1 and 7-2, where class D has only max|TE_L| specified.
+	testCases := []struct {
+		class          ClockClass
+		expectedLimits ClassLimits
+	}{
+		{class: ClockClassNone, expectedLimits: ClassLimits{}},
+		{class: ClockClassA, expectedLimits: ClassLimits{MaxAbsTE: 100, CTE: 50, MTIE: 40, TDEV: 4}},
+		{class: ClockClassB, expectedLimits: ClassLimits{MaxAbsTE: 70, CTE: 20, MTIE: 40, TDEV: 4}},
+		{class: ClockClassC, expectedLimits: ClassLimits{MaxAbsTEL: 30, CTE: 10, MTIE: 10, TDEV: 2}},
+		{class: ClockClassD, expectedLimits: ClassLimits{MaxAbsTEL: 5}},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedLimits, testCase.class.Limits(), "class %q", testCase.class)
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	testCases := []struct {
+		line           string
+		expectedSource string
+	}{
+		{
+			line:           "ptp4l[1000.250]: [ptp4l.0.config:6] master offset 25 s2 freq -94379 path delay 161",
+			expectedSource: "ptp4l.0.config",
+		},
+		{
+			line:           "phc2sys[1000.500]: [ptp4l.1.config:6] CLOCK_REALTIME phc offset -5 s2 freq -19334 delay 470",
+			expectedSource: "ptp4l.1.config CLOCK_REALTIME",
+		},
+		{line: "phc2sys[1000.500]: CLOCK_REALTIME phc offset -5 s2 freq -19334 delay 470", expectedSource: "CLOCK_REALTIME"},
+		{line: "ptp4l[1000.250]: master offset 25 s2 freq -94379 path delay 161", expectedSource: ""},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedSource, parseSource(testCase.line), testCase.line)
+	}
+}
+
+func TestMTIE(t *testing.T) {
+	testCases := []struct {
+		samples       []float64
+		windowSamples int
+		expectedValue float64
+		expectedOk    bool
+	}{
+		{samples: []float64{0, 1, 2, 3, 10, 3, 2}, windowSamples: 1, expectedValue: 7, expectedOk: true},
+		{samples: []float64{0, 1, 2, 3, 10, 3, 2}, windowSamples: 3, expectedValue: 9, expectedOk: true},
+		{samples: []float64{5, -5, 5}, windowSamples: 2, expectedValue: 10, expectedOk: true},
+		{samples: []float64{1, 2, 3}, windowSamples: 3, expectedOk: false},
+	}
+
+	for _, testCase := range testCases {
+		value, ok := mtie(testCase.samples, testCase.windowSamples)
+
+		assert.Equal(t, testCase.expectedOk, ok)
+		assert.InDelta(t, testCase.expectedValue, value, 1e-9)
+	}
+}
+
+func TestTDEV(t *testing.T) {
+	testCases := []struct {
+		samples       []float64
+		windowSamples int
+		expectedValue float64
+		expectedOk    bool
+	}{
+		// A constant frequency offset is a ramp, which has no second difference.
+		{samples: []float64{0, 2, 4, 6, 8, 10, 12}, windowSamples: 2, expectedValue: 0, expectedOk: true},
+		{samples: []float64{0, 0, 0, 1}, windowSamples: 1, expectedValue: 0.2886751345948129, expectedOk: true},
+		{samples: []float64{0, 0, 0, 1, 2}, windowSamples: 2, expectedValue: 0, expectedOk: false},
+	}
+
+	for _, testCase := range testCases {
+		value, ok := tdev(testCase.samples, testCase.windowSamples)
+
+		assert.Equal(t, testCase.expectedOk, ok)
+		assert.InDelta(t, testCase.expectedValue, value, 1e-9)
+	}
+}
+
+func TestTimeErrorSeries(t *testing.T) {
+	var series timeErrorSeries
+
+	series.observe(100.1, 10)
+	series.observe(100.6, 20)
+	series.observe(101.2, 30)
+	series.observe(104.0, -10)
+
+	metrics := series.metrics()
+
+	assert.Equal(t, []SeriesSegment{{Start: 100, Samples: []float64{15, 30, 30, 30, -10}}}, series.segments)
+	assert.Empty(t, metrics.Gaps)
+	assert.Equal(t, 5, metrics.SampleCount)
+	assert.Equal(t, int64(30), metrics.MaxAbsTE)
+	assert.InDelta(t, 19, metrics.CTE, 1e-9)
+
+	if assert.Len(t, metrics.MTIE, 1) {
+		assert.InDelta(t, 1, metrics.MTIE[0].ObservationInterval.Seconds(), 1e-9)
+	}
+
+	if assert.Len(t, metrics.TDEV, 1) {
+		assert.InDelta(t, 1, metrics.TDEV[0].ObservationInterval.Seconds(), 1e-9)
+	}
+}
+
+func TestTimeErrorSeriesGaps(t *testing.T) {
+	var series timeErrorSeries
+
+	for second := range 3 {
+		series.observe(float64(100+second), 10)
+	}
+
+	// A gap over maxGapSeconds starts a new segment rather than being filled.
+	for second := range 3 {
+		series.observe(float64(200+second), -10)
+	}
+
+	// Going back in time, such as after a reboot, also starts a new segment.
+	series.observe(5, 0)
+
+	metrics := series.metrics()
+
+	assert.Equal(t, []SeriesSegment{
+		{Start: 100, Samples: []float64{10, 10, 10}},
+		{Start: 200, Samples: []float64{-10, -10, -10}},
+		{Start: 5, Samples: []float64{0}},
+	}, series.segments)
+	assert.Equal(t, []Gap{{After: 102, Duration: 98 * time.Second}}, metrics.Gaps)
+	assert.Equal(t, 7, metrics.SampleCount)
+
+	// Each segment is constant, so nothing is measured across the gap.
+	if assert.Len(t, metrics.MTIE, 1) {
+		assert.InDelta(t, 0, metrics.MTIE[0].Value, 1e-9)
+	}
+
+	assert.InDelta(t, 0, metrics.DTEHPeakToPeak, 1e-9)
+}
+
+func TestTimeErrorMetricsViolations(t *testing.T) {
+	metrics := TimeErrorMetrics{SampleCount: 10, MaxAbsTE: 40, CTE: -15, MaxAbsTEL: 20}
+
+	assert.Empty(t, metrics.Violations(ClockClassNone))
+	assert.Empty(t, metrics.Violations(ClockClassA))
+	assert.Equal(t, []string{"max|TE_L| 20.000 ns exceeds class D limit of 5 ns"}, metrics.Violations(ClockClassD))
+	assert.Equal(t, []string{"|cTE| 15.000 ns exceeds class C limit of 10 ns"}, metrics.Violations(ClockClassC))
+	assert.Equal(t, []string{"max|TE| 80.000 ns exceeds class B limit of 70 ns"},
+		TimeErrorMetrics{SampleCount: 10, MaxAbsTE: 80}.Violations(ClockClassB))
+	assert.Equal(t, []string{"max|TE_L| 35.000 ns exceeds class C limit of 30 ns"},
+		TimeErrorMetrics{SampleCount: 10, MaxAbsTE: 80, MaxAbsTEL: 35}.Violations(ClockClassC))
+	assert.Empty(t, TimeErrorMetrics{}.Violations(ClockClassD))
+}
+
+func TestAnalyzeFromFileTimeError(t *testing.T) {
+	var lines []string
+
+	for second := range 20 {
+		lines = append(lines,
+			fmt.Sprintf("ptp4l[%d.250]: [ptp4l.0.config:6] master offset         25 s2 freq  -94379 path delay       161",
+				1000+second),
+			fmt.Sprintf("ptp4l[%d.300]: [ptp4l.1.config:6] master offset          1 s2 freq  -94379 path delay       161",
+				1000+second),
+			fmt.Sprintf("phc2sys[%d.500]: [ptp4l.0.config:6] CLOCK_REALTIME phc offset        -5 s2 freq  -19334 delay    470",
+				1000+second))
+	}
+
+	// The second ptp4l instance stops logging offsets for longer than maxGapSeconds.
+	lines = append(lines,
+		"ptp4l[1100.300]: [ptp4l.1.config:6] master offset          1 s2 freq  -94379 path delay       161")
+
+	logPath := filepath.Join(t.TempDir(), "daemon.log")
+	assert.Nil(t, os.WriteFile(logPath, []byte(strings.Join(lines, "\n")), 0644))
+
+	result, err := AnalyzeFromFile(logPath, 0, ClockClassC)
+	assert.Nil(t, err)
+
+	// Each ptp4l instance has its own series, so the good one neither hides nor averages out the bad one.
+	if assert.Len(t, result.PTP4L.TimeErrors, 2) {
+		badPort, goodPort := result.PTP4L.TimeErrors[0], result.PTP4L.TimeErrors[1]
+
+		assert.Equal(t, "ptp4l.0.config", badPort.Source)
+		assert.Equal(t, 20, badPort.Metrics.SampleCount)
+		assert.InDelta(t, 25, badPort.Metrics.CTE, 1e-9)
+		assert.Len(t, badPort.Metrics.MTIE, 2)
+		assert.Len(t, badPort.Metrics.TDEV, 1)
+		assert.Equal(t, []string{"|cTE| 25.000 ns exceeds class C limit of 10 ns"}, badPort.Violations)
+
+		assert.Equal(t, "ptp4l.1.config", goodPort.Source)
+		assert.Equal(t, 21, goodPort.Metrics.SampleCount)
+		assert.InDelta(t, 1, goodPort.Metrics.CTE, 1e-9)
+		assert.Equal(t, []Gap{{After: 1019, Duration: 81 * time.Second}}, goodPort.Metrics.Gaps)
+		assert.Len(t, goodPort.Segments, 2)
+		assert.Empty(t, goodPort.Violations)
+	}
+
+	if assert.Len(t, result.PHC2SYS.TimeErrors, 1) {
+		assert.Equal(t, "ptp4l.0.config CLOCK_REALTIME", result.PHC2SYS.TimeErrors[0].Source)
+		assert.InDelta(t, -5, result.PHC2SYS.TimeErrors[0].Metrics.CTE, 1e-9)
+	}
+
+	assert.Equal(t, []string{"ptp4l.0.config: |cTE| 25.000 ns exceeds class C limit of 10 ns"}, result.ClassViolations)
+	assert.Equal(t, []string{
+		"ptp4l ptp4l.1.config: s2 offsets missing for 1m21s after 1019 s",
+		"ptp4l ptp4l.0.config: |cTE| 25.000 ns exceeds class C limit of 10 ns",
+	}, result.Details)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.DiagnosticMessage(), "target_class=C")
+	assert.Contains(t, result.DiagnosticMessage(), `ptp4l_time_error source="ptp4l.1.config"`)
+
+	result, err = AnalyzeFromFile(logPath, 0, ClockClassA)
+	assert.Nil(t, err)
+	assert.Empty(t, result.ClassViolations)
+	assert.False(t, result.Passed)
+}
@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	monv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/nodes"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/reportxml"
@@ -18,9 +19,12 @@ import (
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/consumer"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/metrics"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/mustgather"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/ptptimeline"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/internal/tsparams"
 	_ "github.com/rh-ecosystem-edge/eco-gotests/tests/cnf/ran/ptp/tests"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/resourcetracker"
 )
 
 var (
@@ -28,9 +32,21 @@ var (
 
 	// savedPtpServiceMonitor holds the original PTP ServiceMonitor state so it can be restored in AfterSuite.
 	savedPtpServiceMonitor *monv1.ServiceMonitor
+
+	// prometheusAPI is the Prometheus API client used to query metrics for the PTP timeline of failed specs.
+	prometheusAPI prometheusv1.API
 )
 
 func TestPTP(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+	resourcetracker.RegisterLeakCheck()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = RANConfig.GetJunitReportPath(currentFile)
 
@@ -46,7 +62,9 @@ var _ = BeforeSuite(func() {
 
 	By("creating a Prometheus API client")
 
-	prometheusAPI, err := querier.CreatePrometheusAPIForCluster(RANConfig.Spoke1APIClient)
+	var err error
+
+	prometheusAPI, err = querier.CreatePrometheusAPIForCluster(RANConfig.Spoke1APIClient)
 	Expect(err).ToNot(HaveOccurred(), "Failed to create Prometheus API client")
 
 	By("ensuring clocks are locked before testing")
@@ -84,6 +102,10 @@ var _ = AfterSuite(func() {
 	Expect(err).ToNot(HaveOccurred(), "Failed to cleanup Prometheus API client resources")
 })
 
+var _ = BeforeEach(func() {
+	ptptimeline.StartRecording(RANConfig.Spoke1APIClient, prometheusAPI)
+})
+
 var _ = JustAfterEach(func() {
 	// If the JustAfterEach runs when the cluster is not reachable, we waste ~4.5 minutes waiting for the
 	// k8sreporter to finish timing out. To prevent that case, we poll the cluster to ensure we can list nodes as a
@@ -97,6 +119,7 @@ var _ = JustAfterEach(func() {
 		return err
 	}).WithTimeout(time.Minute).WithPolling(10*time.Second).Should(Succeed(), "Reachability check to spoke 1 failed")
 
+	ptptimeline.RecordIfFailed(CurrentSpecReport(), currentFile)
 	reporter.ReportIfFailed(
 		CurrentSpecReport(), currentFile, tsparams.ReporterSpokeNamespacesToDump, tsparams.ReporterSpokeCRsToDump)
 	mustgather.MustGatherIfFailed(CurrentSpecReport(), currentFile, RANConfig.Spoke1APIClient)
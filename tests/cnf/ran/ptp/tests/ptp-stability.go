@@ -50,6 +50,9 @@ var _ = Describe("PTP Stability", Label(tsparams.LabelStability), func() {
 	It("validates PTP stability and offset behavior over configured duration", reportxml.ID("38228"), func() {
 		testRanAtLeastOnce := false
 
+		clockClass, err := stability.ParseClockClass(RANConfig.PtpStabilityClockClass)
+		Expect(err).ToNot(HaveOccurred(), "Failed to parse PTP stability clock class")
+
 		nodeInfoMap, err := profiles.GetNodeInfoMap(RANConfig.Spoke1APIClient)
 		Expect(err).ToNot(HaveOccurred(), "Failed to get node info map")
 
@@ -98,7 +101,7 @@ var _ = Describe("PTP Stability", Label(tsparams.LabelStability), func() {
 			By("analyzing collected daemon logs for node " + nodeInfo.Name)
 
 			analysisResult, err := stability.AnalyzeFromFile(
-				collectionResult.TempFilePath, RANConfig.PtpStabilityThreshold)
+				collectionResult.TempFilePath, RANConfig.PtpStabilityThreshold, clockClass)
 			Expect(err).ToNot(HaveOccurred(), "Failed to analyze daemon logs for node %s", nodeInfo.Name)
 
 			AddReportEntry("ptp_stability_analysis_"+nodeInfo.Name, analysisResult.DiagnosticMessage())
@@ -20,13 +20,6 @@ var (
 	// Labels represents the range of labels that can be used for test cases selection.
 	Labels = append(ranparam.Labels, LabelSuite)
 
-	// ReporterHubNamespacesToDump tells to the reporter which namespaces on the hub to collect pod logs from.
-	ReporterHubNamespacesToDump = map[string]string{
-		TestNamespace:                       "",
-		ranparam.OpenshiftOperatorNamespace: "",
-		RANConfig.AcmOperatorNamespace:      "",
-	}
-
 	// ReporterSpokeNamespacesToDump tells the reporter which namespaces on the spokes to collect pod logs from.
 	ReporterSpokeNamespacesToDump = map[string]string{
 		TemporaryNamespace:  "",
@@ -88,3 +81,12 @@ var (
 		Reason:  PartiallyDoneReason,
 	}
 )
+
+// ReporterHubNamespacesToDump tells to the reporter which namespaces on the hub to collect pod logs from.
+func ReporterHubNamespacesToDump() map[string]string {
+	return map[string]string{
+		TestNamespace:                       "",
+		ranparam.OpenshiftOperatorNamespace: "",
+		RANConfig.AcmOperatorNamespace:      "",
+	}
+}
@@ -16,6 +16,14 @@ import (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestBasic(t *testing.T) {
+	err := Setup(t.Context(), Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = GeneralConfig.GetJunitReportPath(currentFile)
 
@@ -8,21 +8,23 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	amdgpuparams "github.com/rh-ecosystem-edge/eco-gotests/tests/hw-accel/amdgpu/params"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 )
 
-// amdGPUConfigHelper Helps to convert different strings to bool.
+// amdGPUConfigHelper Helps to convert different strings to bool. Its fields start from the values in AMDConfig so
+// profiles are kept unless the environment variables are set.
 type amdGPUConfigHelper struct {
-	SkipCleanup        string `envconfig:"ECO_HWACCEL_AMD_SKIP_CLEANUP" split_words:"true" default:"false"`
-	SkipCleanupOnError string `envconfig:"ECO_HWACCEL_AMD_SKIP_CLEANUP_ON_ERROR" split_words:"true" default:"false"`
+	SkipCleanup        string `envconfig:"ECO_HWACCEL_AMD_SKIP_CLEANUP" split_words:"true"`
+	SkipCleanupOnError string `envconfig:"ECO_HWACCEL_AMD_SKIP_CLEANUP_ON_ERROR" split_words:"true"`
 }
 
 // AMDConfig contains environment information related to amd tests.
 type AMDConfig struct {
-	AMDDriverVersion   string `envconfig:"ECO_HWACCEL_AMD_DRIVER_VERSION"`
-	AMDOperatorVersion string `envconfig:"ECO_HWACCEL_AMD_OPERATOR_VERSION"`
+	AMDDriverVersion   string `yaml:"amd_driver_version" envconfig:"ECO_HWACCEL_AMD_DRIVER_VERSION"`
+	AMDOperatorVersion string `yaml:"amd_operator_version" envconfig:"ECO_HWACCEL_AMD_OPERATOR_VERSION"`
 
-	SkipCleanup        bool
-	SkipCleanupOnError bool
+	SkipCleanup        bool `yaml:"amd_skip_cleanup"`
+	SkipCleanupOnError bool `yaml:"amd_skip_cleanup_on_error"`
 }
 
 // NewAMDConfig returns instance of AMDConfig type.
@@ -31,14 +33,24 @@ func NewAMDConfig() *AMDConfig {
 
 	AMDConfig := new(AMDConfig)
 
-	err := envconfig.Process("eco_hwaccel_amd_", AMDConfig)
+	err := config.ReadProfiles(AMDConfig)
+	if err != nil {
+		log.Printf("failed to read profiles for AMDConfig: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("eco_hwaccel_amd_", AMDConfig)
 	if err != nil {
 		log.Printf("failed to instantiate AMDConfig: %v", err)
 
 		return nil
 	}
 
-	var configHelper amdGPUConfigHelper
+	configHelper := amdGPUConfigHelper{
+		SkipCleanup:        strconv.FormatBool(AMDConfig.SkipCleanup),
+		SkipCleanupOnError: strconv.FormatBool(AMDConfig.SkipCleanupOnError),
+	}
 
 	configHelperErr := envconfig.Process("eco_hwaccel_amd_", &configHelper)
 	if configHelperErr != nil {
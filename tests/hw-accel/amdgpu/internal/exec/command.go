@@ -9,6 +9,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/hw-accel/amdgpu/internal/amdgpucommon"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -402,12 +403,17 @@ func (p *PodCommand) Execute() (string, error) {
 	}
 
 	// Execute the command
-	output, err := p.ActualPod.ExecCommand([]string{"sh", "-c", strings.Join(p.commad, " ")})
+	result, err := cluster.ExecInPod(context.TODO(), p.apiClient, p.ActualPod, p.containerName,
+		[]string{"sh", "-c", strings.Join(p.commad, " ")}, cluster.WithCombinedOutput(true))
+	if err == nil {
+		err = result.Err()
+	}
+
 	if err != nil {
-		return output.String(), fmt.Errorf("command execution failed: %w", err)
+		return result.Stdout, fmt.Errorf("command execution failed: %w", err)
 	}
 
-	return strings.TrimSpace(output.String()), nil
+	return strings.TrimSpace(result.Stdout), nil
 }
 
 // ExecuteAndCleanup runs the pod command, returns output, and cleans up the pod.
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/hw-accel/kmm/internal/kmmparams"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"k8s.io/klog/v2"
 
 	"github.com/kelseyhightower/envconfig"
@@ -12,15 +13,15 @@ import (
 
 // ModulesConfig contains environment information related to kmm tests.
 type ModulesConfig struct {
-	PullSecret           string `envconfig:"ECO_HWACCEL_KMM_PULL_SECRET"`
-	Registry             string `envconfig:"ECO_HWACCEL_KMM_REGISTRY"`
-	DevicePluginImage    string `envconfig:"ECO_HWACCEL_KMM_DEVICE_PLUGIN_IMAGE"`
-	SubscriptionName     string `envconfig:"ECO_HWACCEL_KMM_SUBSCRIPTION_NAME"`
-	CatalogSourceName    string `envconfig:"ECO_HWACCEL_KMM_CATALOG_SOURCE_NAME"`
-	CatalogSourceChannel string `envconfig:"ECO_HWACCEL_KMM_CATALOG_SOURCE_CHANNEL"`
-	UpgradeTargetVersion string `envconfig:"ECO_HWACCEL_KMM_UPGRADE_TARGET_VERSION"`
-	SpokeKubeConfig      string `envconfig:"ECO_HWACCEL_KMM_SPOKE_KUBECONFIG"`
-	SpokeClusterName     string `envconfig:"ECO_HWACCEL_KMM_SPOKE_CLUSTER_NAME"`
+	PullSecret           string `yaml:"kmm_pull_secret" envconfig:"ECO_HWACCEL_KMM_PULL_SECRET"`
+	Registry             string `yaml:"kmm_registry" envconfig:"ECO_HWACCEL_KMM_REGISTRY"`
+	DevicePluginImage    string `yaml:"kmm_device_plugin_image" envconfig:"ECO_HWACCEL_KMM_DEVICE_PLUGIN_IMAGE"`
+	SubscriptionName     string `yaml:"kmm_subscription_name" envconfig:"ECO_HWACCEL_KMM_SUBSCRIPTION_NAME"`
+	CatalogSourceName    string `yaml:"kmm_catalog_source_name" envconfig:"ECO_HWACCEL_KMM_CATALOG_SOURCE_NAME"`
+	CatalogSourceChannel string `yaml:"kmm_catalog_source_channel" envconfig:"ECO_HWACCEL_KMM_CATALOG_SOURCE_CHANNEL"`
+	UpgradeTargetVersion string `yaml:"kmm_upgrade_target_version" envconfig:"ECO_HWACCEL_KMM_UPGRADE_TARGET_VERSION"`
+	SpokeKubeConfig      string `yaml:"kmm_spoke_kubeconfig" envconfig:"ECO_HWACCEL_KMM_SPOKE_KUBECONFIG"`
+	SpokeClusterName     string `yaml:"kmm_spoke_cluster_name" envconfig:"ECO_HWACCEL_KMM_SPOKE_CLUSTER_NAME"`
 	SpokeAPIClient       *clients.Settings
 }
 
@@ -30,7 +31,14 @@ func NewModulesConfig() *ModulesConfig {
 
 	modulesConfig := new(ModulesConfig)
 
-	err := envconfig.Process("eco_hwaccel_kmm_", modulesConfig)
+	err := config.ReadProfiles(modulesConfig)
+	if err != nil {
+		log.Printf("failed to read profiles for ModulesConfig: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("eco_hwaccel_kmm_", modulesConfig)
 	if err != nil {
 		log.Printf("failed to instantiate ModulesConfig: %v", err)
 
@@ -1,12 +1,29 @@
 package kmminittools
 
-import "github.com/rh-ecosystem-edge/eco-gotests/tests/hw-accel/kmm/internal/kmmconfig"
+import (
+	"context"
+	"errors"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/hw-accel/kmm/internal/kmmconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
+)
 
 var (
 	// ModulesConfig provides access to general configuration parameters.
 	ModulesConfig *kmmconfig.ModulesConfig
 )
 
+// init registers setup so the KMM modules config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("kmm", setup)
+}
+
+// setup loads the KMM modules config once the general config is loaded.
+func setup(context.Context) error {
 	ModulesConfig = kmmconfig.NewModulesConfig()
+	if ModulesConfig == nil {
+		return errors.New("failed to load KMM modules config")
+	}
+
+	return nil
 }
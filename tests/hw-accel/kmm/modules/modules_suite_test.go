@@ -35,6 +35,14 @@ var (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestModules(t *testing.T) {
+	err := Setup(t.Context(), Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = GeneralConfig.GetJunitReportPath(currentFile)
 
@@ -14,6 +14,13 @@ import (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestUpgrade(t *testing.T) {
+	err := Setup(t.Context(), Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	RegisterConfigDump()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = GeneralConfig.GetJunitReportPath(currentFile)
 
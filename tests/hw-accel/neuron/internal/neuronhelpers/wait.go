@@ -8,6 +8,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/nodes"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/hw-accel/neuron/params"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -19,7 +20,10 @@ import (
 func WaitForClusterStability(apiClient *clients.Settings, timeout time.Duration) error {
 	klog.V(params.NeuronLogLevel).Info("Waiting for cluster stability (checking node readiness)")
 
-	err := WaitForAllNodesReady(apiClient, timeout)
+	_, err := cluster.NewStabilityGate(apiClient).
+		WithChecks(cluster.StabilityCheckNodes).
+		WithSettleWindow(0).
+		Wait(context.TODO(), timeout)
 	if err != nil {
 		return fmt.Errorf("nodes not ready: %w", err)
 	}
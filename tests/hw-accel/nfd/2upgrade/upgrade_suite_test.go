@@ -17,6 +17,14 @@ import (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestUpgrade(tt *testing.T) {
+	err := Setup(tt.Context(), Options{})
+	if err != nil {
+		tt.Fatalf("failed to set up suite: %v", err)
+	}
+
+	RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = GeneralConfig.GetJunitReportPath(currentFile)
 
@@ -26,6 +26,14 @@ var NFDInstaller *deploy.OperatorInstaller
 var NFDCRUtils *deploy.NFDCRUtils
 
 func TestFeatures(t *testing.T) {
+	err := Setup(t.Context(), Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = GeneralConfig.GetJunitReportPath(currentFile)
 
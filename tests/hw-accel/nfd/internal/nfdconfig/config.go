@@ -4,17 +4,18 @@ import (
 	"log"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 )
 
 // NfdConfig contains environment information related to nfd tests.
 type NfdConfig struct {
-	SubscriptionName     string `envconfig:"ECO_HWACCEL_NFD_SUBSCRIPTION_NAME"`
-	Image                string `envconfig:"ECO_HWACCEL_NFD_CR_IMAGE"`
-	CatalogSource        string `envconfig:"ECO_HWACCEL_NFD_CATALOG_SOURCE"`
-	CustomCatalogSource  string `envconfig:"ECO_HWACCEL_NFD_CUSTOM_NFD_CATALOG_SOURCE"`
-	AwsTest              bool   `envconfig:"ECO_HWACCEL_NFD_AWS_TESTS"`
-	UpgradeTargetVersion string `envconfig:"ECO_HWACCEL_NFD_UPGRADE_TARGET_VERSION"`
-	CPUFlagsHelperImage  string `envconfig:"ECO_HWACCEL_NFD_CPU_FLAGS_HELPER_IMAGE"`
+	SubscriptionName     string `yaml:"nfd_subscription_name" envconfig:"ECO_HWACCEL_NFD_SUBSCRIPTION_NAME"`
+	Image                string `yaml:"nfd_cr_image" envconfig:"ECO_HWACCEL_NFD_CR_IMAGE"`
+	CatalogSource        string `yaml:"nfd_catalog_source" envconfig:"ECO_HWACCEL_NFD_CATALOG_SOURCE"`
+	CustomCatalogSource  string `yaml:"nfd_custom_catalog_source" envconfig:"ECO_HWACCEL_NFD_CUSTOM_NFD_CATALOG_SOURCE"`
+	AwsTest              bool   `yaml:"nfd_aws_tests" envconfig:"ECO_HWACCEL_NFD_AWS_TESTS"`
+	UpgradeTargetVersion string `yaml:"nfd_upgrade_target_version" envconfig:"ECO_HWACCEL_NFD_UPGRADE_TARGET_VERSION"`
+	CPUFlagsHelperImage  string `yaml:"nfd_cpu_flags_helper_image" envconfig:"ECO_HWACCEL_NFD_CPU_FLAGS_HELPER_IMAGE"`
 }
 
 // NewNfdConfig returns instance of NfdConfig type.
@@ -23,7 +24,14 @@ func NewNfdConfig() *NfdConfig {
 
 	nfdConfig := new(NfdConfig)
 
-	err := envconfig.Process("eco_hwaccel_nfd_", nfdConfig)
+	err := config.ReadProfiles(nfdConfig)
+	if err != nil {
+		log.Printf("failed to read profiles for NfdConfig: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("eco_hwaccel_nfd_", nfdConfig)
 	if err != nil {
 		log.Printf("failed to instantiate NfdConfig: %v", err)
 
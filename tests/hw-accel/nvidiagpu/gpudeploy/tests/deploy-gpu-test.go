@@ -39,11 +39,6 @@ import (
 var (
 	gpuInstallPlanApproval v1alpha1.Approval = "Automatic"
 
-	gpuWorkerNodeSelector = map[string]string{
-		GeneralConfig.WorkerLabel:                     "",
-		"feature.node.kubernetes.io/pci-10de.present": "true",
-	}
-
 	gpuBurnImageName = "undefined"
 
 	machineSetNamespace         = "openshift-machine-api"
@@ -309,6 +304,11 @@ var _ = Describe("GPU", Ordered, Label(tsparams.LabelSuite), func() {
 				time.Sleep(2 * time.Minute)
 
 				By("Get Cluster Architecture from first GPU enabled worker node")
+				gpuWorkerNodeSelector := map[string]string{
+					GeneralConfig.WorkerLabel:                     "",
+					"feature.node.kubernetes.io/pci-10de.present": "true",
+				}
+
 				klog.V(gpuparams.GpuLogLevel).Infof("Getting cluster architecture from nodes with "+
 					"gpuWorkerNodeSelector: %v", gpuWorkerNodeSelector)
 				clusterArch, err := get.GetClusterArchitecture(APIClient, gpuWorkerNodeSelector)
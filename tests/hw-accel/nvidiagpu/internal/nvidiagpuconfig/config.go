@@ -4,13 +4,14 @@ import (
 	"log"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 )
 
 // NvidiaGPUConfig contains environment information related to nvidiagpu tests.
 type NvidiaGPUConfig struct {
-	InstanceType  string `envconfig:"ECO_HWACCEL_NVIDIAGPU_INSTANCE_TYPE"`
-	CatalogSource string `envconfig:"ECO_HWACCEL_NVIDIAGPU_CATALOGSOURCE"`
-	GPUBurnImage  string `envconfig:"ECO_HWACCEL_NVIDIAGPU_GPUBURN_IMAGE"`
+	InstanceType  string `yaml:"nvidiagpu_instance_type" envconfig:"ECO_HWACCEL_NVIDIAGPU_INSTANCE_TYPE"`
+	CatalogSource string `yaml:"nvidiagpu_catalogsource" envconfig:"ECO_HWACCEL_NVIDIAGPU_CATALOGSOURCE"`
+	GPUBurnImage  string `yaml:"nvidiagpu_gpuburn_image" envconfig:"ECO_HWACCEL_NVIDIAGPU_GPUBURN_IMAGE"`
 }
 
 // NewNvidiaGPUConfig returns instance of NvidiaGPUConfig type.
@@ -19,7 +20,14 @@ func NewNvidiaGPUConfig() *NvidiaGPUConfig {
 
 	nvidiaGPUConfig := new(NvidiaGPUConfig)
 
-	err := envconfig.Process("eco_hwaccel_nvidiagpu_", nvidiaGPUConfig)
+	err := config.ReadProfiles(nvidiaGPUConfig)
+	if err != nil {
+		log.Printf("failed to read profiles for nvidiaGPUConfig: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("eco_hwaccel_nvidiagpu_", nvidiaGPUConfig)
 	if err != nil {
 		log.Printf("failed to instantiate nvidiaGPUConfig: %v", err)
 
@@ -1,12 +1,9 @@
 package cluster
 
 import (
-	"regexp"
-
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/infrastructure"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/mco"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
@@ -36,38 +33,32 @@ func IsSNOCluster(apiClient *clients.Settings) (bool, error) {
 	return infraConfig.Object.Status.ControlPlaneTopology == configv1.SingleReplicaTopologyMode, nil
 }
 
-// PullTestImageOnNodes pulls given image on range of relevant nodes based on nodeSelector.
+// PullTestImageOnNodes pulls given image on range of relevant nodes based on nodeSelector. The image is pulled on all
+// nodes in parallel using an ImagePrePuller, waiting up to pullTimeout seconds.
 func PullTestImageOnNodes(apiClient *clients.Settings, nodeSelector, image string, pullTimeout int) error {
 	klog.V(90).Infof("Pulling image %s to nodes with the following label %v", image, nodeSelector)
 
-	nodesList, err := nodes.List(
-		apiClient,
-		metav1.ListOptions{LabelSelector: labels.Set(map[string]string{nodeSelector: ""}).String()},
-	)
+	report, err := NewImagePrePuller(apiClient, image).
+		WithNodeSelector(map[string]string{nodeSelector: ""}).
+		WithTimeout(time.Duration(pullTimeout) * time.Second).
+		PrePull(context.TODO())
 	if err != nil {
 		return err
 	}
 
-	for _, node := range nodesList {
-		klog.V(90).Infof("Pulling image %s to node %s", image, node.Object.Name)
-		podBuilder := pod.NewBuilder(
-			apiClient, fmt.Sprintf("pullpod-%s", node.Object.Name), "default", image)
-
-		err := podBuilder.PullImage(time.Duration(pullTimeout)*time.Second, []string{
-			"/bin/sh", "-c", "echo image Pulled && exit 0"})
-		if err != nil {
-			return err
-		}
+	for _, result := range report.Nodes {
+		klog.V(90).Infof(
+			"Pulling image %s to node %s took %s, error: %v", image, result.NodeName, result.Duration, result.Err)
 	}
 
-	return nil
+	return report.Err()
 }
 
 // ExecCmd runc cmd on all nodes that match nodeSelector.
 func ExecCmd(apiClient *clients.Settings, nodeSelector string, shellCmd string) error {
 	klog.V(90).Infof("Executing cmd: %v on nodes based on label: %v using mcp pods", shellCmd, nodeSelector)
 
-	nodeList, err := nodes.List(
+	nodeNames, err := listNodeNames(
 		apiClient,
 		metav1.ListOptions{LabelSelector: labels.Set(map[string]string{nodeSelector: ""}).String()},
 	)
@@ -75,40 +66,23 @@ func ExecCmd(apiClient *clients.Settings, nodeSelector string, shellCmd string)
 		return err
 	}
 
-	for _, node := range nodeList {
-		listOptions := metav1.ListOptions{
-			FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node.Definition.Name}).String(),
-			LabelSelector: labels.SelectorFromSet(labels.Set{"k8s-app": GeneralConfig.MCOConfigDaemonName}).String(),
-		}
-
-		mcPodList, err := pod.List(apiClient, GeneralConfig.MCONamespace, listOptions)
-		if err != nil {
-			return err
-		}
-
-		for _, mcPod := range mcPodList {
-			err = mcPod.WaitUntilRunning(300 * time.Second)
-			if err != nil {
-				return err
-			}
-
-			cmdToExec := []string{"sh", "-c", fmt.Sprintf("nsenter --mount=/proc/1/ns/mnt -- sh -c '%s'", shellCmd)}
-
-			klog.V(90).Infof("Exec cmd %v on pod %s", cmdToExec, mcPod.Definition.Name)
+	results, err := ExecOnNodes(
+		context.TODO(), NewMCDExecutor(apiClient), nodeNames, legacyShellCmd(shellCmd), WithCombinedOutput(true))
+	if err != nil {
+		return err
+	}
 
-			buf, err := mcPod.ExecCommand(cmdToExec)
-			if err != nil {
-				return fmt.Errorf("%w\n%s", err, buf.String())
-			}
+	for _, result := range results {
+		if err := result.Err(); err != nil {
+			return fmt.Errorf("%w\n%s", err, result.Stdout)
 		}
 	}
 
 	return nil
 }
 
-// ExecCmdWithStdout runs cmd on all selected nodes and returns their stdout.
-//
-//nolint:funlen
+// ExecCmdWithStdout runs cmd on all selected nodes and returns their stdout, keyed by the hostname of each node.
+// Since the command runs with a TTY, stderr is included in the output.
 func ExecCmdWithStdout(
 	apiClient *clients.Settings, shellCmd string, options ...metav1.ListOptions) (map[string]string, error) {
 	klog.V(90).Infof("Executing command '%s' with stdout and options ('%v')", shellCmd, options)
@@ -121,8 +95,6 @@ func ExecCmdWithStdout(
 		return nil, fmt.Errorf("error: mco namespace cannot be empty")
 	}
 
-	logMessage := fmt.Sprintf("Executing cmd: %v on nodes", shellCmd)
-
 	passedOptions := metav1.ListOptions{}
 
 	if len(options) > 1 {
@@ -133,61 +105,37 @@ func ExecCmdWithStdout(
 
 	if len(options) == 1 {
 		passedOptions = options[0]
-		logMessage += fmt.Sprintf(" with the options %v", passedOptions)
 	}
 
-	klog.V(90).Info(logMessage)
-
-	nodeList, err := nodes.List(
-		apiClient,
-		passedOptions,
-	)
+	nodeNames, err := listNodeNames(apiClient, passedOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	klog.V(90).Infof("Found %d nodes matching selector", len(nodeList))
+	klog.V(90).Infof("Found %d nodes matching selector", len(nodeNames))
 
-	outputMap := make(map[string]string)
+	executor := NewMCDExecutor(apiClient)
 
-	for _, node := range nodeList {
-		listOptions := metav1.ListOptions{
-			FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node.Definition.Name}).String(),
-			LabelSelector: labels.SelectorFromSet(labels.Set{"k8s-app": GeneralConfig.MCOConfigDaemonName}).String(),
-		}
-
-		mcPodList, err := pod.List(apiClient, GeneralConfig.MCONamespace, listOptions)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, mcPod := range mcPodList {
-			err = mcPod.WaitUntilRunning(300 * time.Second)
-			if err != nil {
-				return nil, err
-			}
-
-			hostnameCmd := []string{"sh", "-c", "nsenter --mount=/proc/1/ns/mnt -- sh -c 'printf $(hostname)'"}
-
-			hostnameBuf, err := mcPod.ExecCommand(hostnameCmd)
-			if err != nil {
-				return nil, fmt.Errorf("failed gathering node hostname: %w", err)
-			}
-
-			cmdToExec := []string{"sh", "-c", fmt.Sprintf("nsenter --mount=/proc/1/ns/mnt -- sh -c '%s'", shellCmd)}
+	hostnames, err := ExecOnNodes(context.TODO(), executor, nodeNames, "printf $(hostname)", WithCombinedOutput(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed gathering node hostname: %w", err)
+	}
 
-			klog.V(90).Infof("Exec cmd %v on pod %s", cmdToExec, mcPod.Definition.Name)
+	results, err := ExecOnNodes(context.TODO(), executor, nodeNames, legacyShellCmd(shellCmd), WithCombinedOutput(true))
+	if err != nil {
+		return nil, err
+	}
 
-			commandBuf, err := mcPod.ExecCommand(cmdToExec)
-			if err != nil {
-				return nil, fmt.Errorf("failed executing command '%s' on node %s: %w", shellCmd, hostnameBuf.String(), err)
-			}
+	outputMap := make(map[string]string)
 
-			hostname := regexp.MustCompile(`\r`).ReplaceAllString(hostnameBuf.String(), "")
-			output := regexp.MustCompile(`\r`).ReplaceAllString(commandBuf.String(), "")
+	for nodeName, result := range results {
+		hostname := strings.ReplaceAll(hostnames[nodeName].Stdout, "\r", "")
 
-			outputMap[hostname] = output
+		if err := result.Err(); err != nil {
+			return nil, fmt.Errorf("failed executing command '%s' on node %s: %w", shellCmd, hostname, err)
 		}
+
+		outputMap[hostname] = strings.ReplaceAll(result.Stdout, "\r", "")
 	}
 
 	return outputMap, nil
@@ -358,6 +306,28 @@ func waitForReachable(client *clients.Settings, timeout time.Duration) error {
 		})
 }
 
+// listNodeNames returns the names of the nodes matching options.
+func listNodeNames(apiClient *clients.Settings, options metav1.ListOptions) ([]string, error) {
+	nodeList, err := nodes.List(apiClient, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeNames []string
+
+	for _, node := range nodeList {
+		nodeNames = append(nodeNames, node.Definition.Name)
+	}
+
+	return nodeNames, nil
+}
+
+// legacyShellCmd wraps shellCmd in single quotes for another shell the same way ExecCmd and ExecCmdWithStdout did
+// before they used NodeExecutor, so callers that escape single quotes themselves keep working.
+func legacyShellCmd(shellCmd string) string {
+	return fmt.Sprintf("sh -c '%s'", shellCmd)
+}
+
 // isErrorExecuting matches errors that contain the message "error executing command in container".
 func isErrorExecuting(err error) bool {
 	if err == nil {
@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ErrCommandFailed is returned by ExecResult.Err when the command exited with a non-zero exit code.
+var ErrCommandFailed = errors.New("command exited with non-zero exit code")
+
+// ExecResult is the result of running a command that started successfully on a node.
+type ExecResult struct {
+	// Stdout is the standard output of the command. When the command was run WithCombinedOutput, it also contains the
+	// standard error.
+	Stdout string
+	// Stderr is the standard error of the command. It is always empty when the command was run WithCombinedOutput.
+	Stderr string
+	// ExitCode is the exit code of the command.
+	ExitCode int
+}
+
+// Err returns an error wrapping ErrCommandFailed if the command exited with a non-zero exit code, otherwise nil. The
+// error includes Stderr or, if it is empty such as when running WithCombinedOutput, Stdout.
+func (result ExecResult) Err() error {
+	if result.ExitCode == 0 {
+		return nil
+	}
+
+	output := strings.TrimSpace(result.Stderr)
+	if output == "" {
+		output = strings.TrimSpace(result.Stdout)
+	}
+
+	return fmt.Errorf("%w %d: %s", ErrCommandFailed, result.ExitCode, output)
+}
+
+// NodeExecutor runs shell commands on the host of a node. Implementations must be safe for concurrent use so commands
+// can be run on several nodes at once with ExecOnNodes.
+//
+// The returned error is only non-nil when the command could not be run or did not finish, such as when the context is
+// canceled. A command that ran but failed is reported through the exit code of the ExecResult instead.
+type NodeExecutor interface {
+	ExecOnNode(ctx context.Context, nodeName, command string, options ...ExecOption) (ExecResult, error)
+}
+
+// execOptions is a struct that contains the options for running a command. It should not be used directly since the
+// ExecOption type is used to set the options.
+type execOptions struct {
+	timeout        time.Duration
+	combinedOutput bool
+}
+
+// ExecOption is a function type that can be used to set the options for running a command on a node. It should not be
+// implemented outside of the functions provided by this package.
+type ExecOption func(*execOptions)
+
+// WithExecTimeout sets the maximum time a single command may run for, after which it is canceled. It defaults to no
+// timeout other than the deadline of the context.
+func WithExecTimeout(timeout time.Duration) ExecOption {
+	return func(o *execOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithCombinedOutput sets whether standard error should be written to Stdout along with standard output rather than
+// kept separately. For pod based executors this allocates a TTY, matching the behavior of pod.Builder.ExecCommand. It
+// defaults to false.
+func WithCombinedOutput(combinedOutput bool) ExecOption {
+	return func(o *execOptions) {
+		o.combinedOutput = combinedOutput
+	}
+}
+
+// newExecContext applies options to a copy of ctx for running a single command, returning the new context, its cancel
+// function, and the options.
+func newExecContext(ctx context.Context, options ...ExecOption) (context.Context, context.CancelFunc, execOptions) {
+	execOpts := execOptions{}
+
+	for _, option := range options {
+		option(&execOpts)
+	}
+
+	if execOpts.timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, execOpts.timeout)
+
+		return ctx, cancel, execOpts
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return ctx, cancel, execOpts
+}
+
+// ExecOnNodes runs command on each of nodeNames concurrently using executor. The results of the nodes the command ran
+// on are returned even if it could not be run on others, in which case the error joins the error for each node. Options
+// apply to each node separately, so WithExecTimeout limits how long each node may take rather than the total.
+func ExecOnNodes(
+	ctx context.Context,
+	executor NodeExecutor,
+	nodeNames []string,
+	command string,
+	options ...ExecOption) (map[string]ExecResult, error) {
+	if executor == nil {
+		return nil, fmt.Errorf("executor cannot be nil")
+	}
+
+	klog.V(90).Infof("Executing command %q on nodes %v", command, nodeNames)
+
+	var (
+		waitGroup sync.WaitGroup
+		mutex     sync.Mutex
+		results   = make(map[string]ExecResult, len(nodeNames))
+		errs      []error
+	)
+
+	for _, nodeName := range nodeNames {
+		waitGroup.Go(func() {
+			result, err := executor.ExecOnNode(ctx, nodeName, command, options...)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to execute command on node %s: %w", nodeName, err))
+
+				return
+			}
+
+			results[nodeName] = result
+		})
+	}
+
+	waitGroup.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// ShellQuote wraps arg in single quotes so it is passed as a single word to a POSIX shell. Single quotes in arg are
+// closed, escaped, and reopened.
+func ShellQuote(arg string) string {
+	return `'` + strings.ReplaceAll(arg, `'`, `'\''`) + `'`
+}
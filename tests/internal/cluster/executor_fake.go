@@ -0,0 +1,106 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrNoFakeResponse is returned by FakeExecutor when no response matches the node and command.
+var ErrNoFakeResponse = errors.New("no fake response for command")
+
+// FakeExecutorCall is a single command run through a FakeExecutor.
+type FakeExecutorCall struct {
+	NodeName string
+	Command  string
+}
+
+// FakeExecutorHandler computes the response of a FakeExecutor to a command. It receives the context with any timeout
+// from the options already applied.
+type FakeExecutorHandler func(ctx context.Context, nodeName, command string) (ExecResult, error)
+
+// fakeResponse is a response registered on a FakeExecutor along with the node and command it matches.
+type fakeResponse struct {
+	nodeName string
+	command  string
+	handler  FakeExecutorHandler
+}
+
+// FakeExecutor is a NodeExecutor with scripted responses for unit tests of helpers that run commands on nodes. It
+// records every call so tests can assert on the commands that were run.
+type FakeExecutor struct {
+	mutex     sync.Mutex
+	responses []fakeResponse
+	calls     []FakeExecutorCall
+}
+
+// Ensure FakeExecutor implements NodeExecutor at compile time.
+var _ NodeExecutor = (*FakeExecutor)(nil)
+
+// NewFakeExecutor returns a FakeExecutor without any responses.
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{}
+}
+
+// WithResponse makes command on nodeName return result and err. An empty nodeName or command matches any node or
+// command. When several responses match, the one added first is used.
+func (executor *FakeExecutor) WithResponse(nodeName, command string, result ExecResult, err error) *FakeExecutor {
+	return executor.WithHandler(nodeName, command, func(context.Context, string, string) (ExecResult, error) {
+		return result, err
+	})
+}
+
+// WithHandler makes command on nodeName return the result of handler. It matches the same way as WithResponse and
+// allows responses to change between calls or wait on the context.
+func (executor *FakeExecutor) WithHandler(nodeName, command string, handler FakeExecutorHandler) *FakeExecutor {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+
+	executor.responses = append(executor.responses, fakeResponse{nodeName: nodeName, command: command, handler: handler})
+
+	return executor
+}
+
+// Calls returns the calls made to the executor in the order they were made.
+func (executor *FakeExecutor) Calls() []FakeExecutorCall {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+
+	return slices.Clone(executor.calls)
+}
+
+// ExecOnNode records the call and returns the first matching response. If ctx is already done, its error is returned
+// without using a response.
+func (executor *FakeExecutor) ExecOnNode(
+	ctx context.Context, nodeName, command string, options ...ExecOption) (ExecResult, error) {
+	ctx, cancel, _ := newExecContext(ctx, options...)
+	defer cancel()
+
+	executor.mutex.Lock()
+
+	executor.calls = append(executor.calls, FakeExecutorCall{NodeName: nodeName, Command: command})
+
+	index := slices.IndexFunc(executor.responses, func(response fakeResponse) bool {
+		return (response.nodeName == "" || response.nodeName == nodeName) &&
+			(response.command == "" || response.command == command)
+	})
+
+	var handler FakeExecutorHandler
+	if index >= 0 {
+		handler = executor.responses[index].handler
+	}
+
+	executor.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return ExecResult{}, err
+	}
+
+	if handler == nil {
+		return ExecResult{}, fmt.Errorf("%w %q on node %s", ErrNoFakeResponse, command, nodeName)
+	}
+
+	return handler(ctx, nodeName, command)
+}
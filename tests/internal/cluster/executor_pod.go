@@ -0,0 +1,401 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/execlog"
+	. "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultPodRunningTimeout is how long to wait for the pod used to run a command to be running when the context has
+	// no deadline.
+	defaultPodRunningTimeout = 5 * time.Minute
+	// debugPodHostMountPath is where the root filesystem of the node is mounted in debug pods.
+	debugPodHostMountPath = "/host"
+)
+
+// ExecInPod runs command in containerName of podBuilder, or its first container if containerName is empty. Unlike
+// pod.Builder.ExecCommand, stdout and stderr are kept separate unless WithCombinedOutput is set and a non-zero exit
+// code is returned in the ExecResult rather than as an error.
+func ExecInPod(
+	ctx context.Context,
+	apiClient *clients.Settings,
+	podBuilder *pod.Builder,
+	containerName string,
+	command []string,
+	options ...ExecOption) (ExecResult, error) {
+	if podBuilder == nil || podBuilder.Definition == nil {
+		return ExecResult{}, fmt.Errorf("pod cannot be nil")
+	}
+
+	if apiClient == nil || apiClient.Config == nil {
+		return ExecResult{}, fmt.Errorf("cannot execute command in pod %s without a rest config", podBuilder.Definition.Name)
+	}
+
+	ctx, cancel, execOpts := newExecContext(ctx, options...)
+	defer cancel()
+
+	if containerName == "" && len(podBuilder.Definition.Spec.Containers) > 0 {
+		containerName = podBuilder.Definition.Spec.Containers[0].Name
+	}
+
+	klog.V(90).Infof("Exec cmd %v in container %s of pod %s in namespace %s",
+		command, containerName, podBuilder.Definition.Name, podBuilder.Definition.Namespace)
+
+	executor, err := newPodExecutor(apiClient, podBuilder.Definition, containerName, command, execOpts.combinedOutput)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	streamOptions := remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr, Tty: execOpts.combinedOutput}
+	if execOpts.combinedOutput {
+		streamOptions.Stderr = nil
+	}
+
+	start := time.Now()
+	err = executor.StreamWithContext(ctx, streamOptions)
+	result, err := resultFromStream(stdout.String(), stderr.String(), err)
+
+	execlog.Record(ctx, execlog.Entry{
+		Target: fmt.Sprintf("pod %s/%s/%s",
+			podBuilder.Definition.Namespace, podBuilder.Definition.Name, containerName),
+		Command:  strings.Join(command, " "),
+		Start:    start,
+		Duration: time.Since(start),
+		ExitCode: result.ExitCode,
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		Err:      err,
+	})
+
+	return result, err
+}
+
+// newPodExecutor returns a remotecommand.Executor that uses websockets, falling back to SPDY if the API server does
+// not support them.
+//
+//nolint:ireturn // remotecommand only returns interfaces, so we must too.
+func newPodExecutor(
+	apiClient *clients.Settings,
+	podDefinition *corev1.Pod,
+	containerName string,
+	command []string,
+	tty bool) (remotecommand.Executor, error) {
+	request := apiClient.CoreV1Interface.RESTClient().
+		Post().
+		Namespace(podDefinition.Namespace).
+		Resource("pods").
+		Name(podDefinition.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(apiClient.Config, "POST", request.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SPDY executor: %w", err)
+	}
+
+	webSocketExecutor, err := remotecommand.NewWebSocketExecutor(apiClient.Config, "GET", request.URL().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WebSocket executor: %w", err)
+	}
+
+	return remotecommand.NewFallbackExecutor(webSocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
+
+// resultFromStream converts the output and error from streaming a command into an ExecResult. Errors reporting the
+// exit code of the command become the exit code of the result while all other errors are returned as is.
+func resultFromStream(stdout, stderr string, err error) (ExecResult, error) {
+	result := ExecResult{Stdout: stdout, Stderr: stderr}
+
+	if err == nil {
+		return result, nil
+	}
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		result.ExitCode = exitErr.ExitStatus()
+
+		return result, nil
+	}
+
+	return result, err
+}
+
+// DaemonPodExecutor runs commands in the pod of a DaemonSet scheduled on each node, such as the machine-config-daemon.
+type DaemonPodExecutor struct {
+	apiClient     *clients.Settings
+	namespace     string
+	labelSelector string
+	containerName string
+	hostMountNS   bool
+}
+
+// Ensure DaemonPodExecutor implements NodeExecutor at compile time.
+var _ NodeExecutor = (*DaemonPodExecutor)(nil)
+
+// NewDaemonPodExecutor returns a DaemonPodExecutor that runs commands in containerName of the pod on the node matching
+// labelSelector in namespace. Commands run in the container itself, so to run them on the host use NewMCDExecutor or
+// WithHostMountNamespace.
+func NewDaemonPodExecutor(
+	apiClient *clients.Settings, namespace, labelSelector, containerName string) *DaemonPodExecutor {
+	return &DaemonPodExecutor{
+		apiClient:     apiClient,
+		namespace:     namespace,
+		labelSelector: labelSelector,
+		containerName: containerName,
+	}
+}
+
+// NewMCDExecutor returns a DaemonPodExecutor that runs commands in the host mount namespace through the
+// machine-config-daemon pods from the namespace and DaemonSet in the general config.
+func NewMCDExecutor(apiClient *clients.Settings) *DaemonPodExecutor {
+	labelSelector := labels.SelectorFromSet(labels.Set{"k8s-app": GeneralConfig.MCOConfigDaemonName}).String()
+
+	return NewDaemonPodExecutor(apiClient, GeneralConfig.MCONamespace, labelSelector, "").WithHostMountNamespace()
+}
+
+// WithHostMountNamespace makes commands run in the mount namespace of the host process with PID 1 using nsenter. The
+// daemon pod must use the host PID namespace and be privileged.
+func (executor *DaemonPodExecutor) WithHostMountNamespace() *DaemonPodExecutor {
+	executor.hostMountNS = true
+
+	return executor
+}
+
+// ExecOnNode runs command with sh on nodeName. It waits for the daemon pod on the node to be running before running the
+// command.
+func (executor *DaemonPodExecutor) ExecOnNode(
+	ctx context.Context, nodeName, command string, options ...ExecOption) (ExecResult, error) {
+	args := []string{"sh", "-c", command}
+
+	if executor.hostMountNS {
+		args = append([]string{"nsenter", "--mount=/proc/1/ns/mnt", "--"}, args...)
+	}
+
+	return executor.ExecArgsOnNode(ctx, nodeName, args, options...)
+}
+
+// ExecArgsOnNode runs args in the daemon pod container on nodeName without a shell and ignoring
+// WithHostMountNamespace.
+func (executor *DaemonPodExecutor) ExecArgsOnNode(
+	ctx context.Context, nodeName string, args []string, options ...ExecOption) (ExecResult, error) {
+	ctx, cancel, _ := newExecContext(ctx, options...)
+	defer cancel()
+
+	daemonPod, err := executor.getPodOnNode(ctx, nodeName)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	return ExecInPod(ctx, executor.apiClient, daemonPod, executor.containerName, args, options...)
+}
+
+// getPodOnNode returns the daemon pod on nodeName once it is running.
+func (executor *DaemonPodExecutor) getPodOnNode(ctx context.Context, nodeName string) (*pod.Builder, error) {
+	if executor.apiClient == nil {
+		return nil, fmt.Errorf("cannot execute command on node %s with nil apiClient", nodeName)
+	}
+
+	if executor.namespace == "" || executor.labelSelector == "" {
+		return nil, fmt.Errorf("daemon pod namespace and label selector cannot be empty")
+	}
+
+	daemonPods, err := pod.List(executor.apiClient, executor.namespace, metav1.ListOptions{
+		LabelSelector: executor.labelSelector,
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": nodeName}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemon pods on node %s: %w", nodeName, err)
+	}
+
+	if len(daemonPods) != 1 {
+		return nil, fmt.Errorf("expected exactly one daemon pod matching %q on node %s, found %d",
+			executor.labelSelector, nodeName, len(daemonPods))
+	}
+
+	err = daemonPods[0].WaitUntilRunning(podRunningTimeout(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("daemon pod %s on node %s is not running: %w", daemonPods[0].Definition.Name, nodeName, err)
+	}
+
+	return daemonPods[0], nil
+}
+
+// DebugPodExecutor runs commands on nodes through privileged debug pods with the root filesystem of the node mounted,
+// similar to oc debug node. A debug pod is created on each node the first time a command is run there and reused until
+// Cleanup is called.
+type DebugPodExecutor struct {
+	apiClient *clients.Settings
+	namespace string
+	image     string
+
+	mutex sync.Mutex
+	pods  map[string]*pod.Builder
+}
+
+// Ensure DebugPodExecutor implements NodeExecutor at compile time.
+var _ NodeExecutor = (*DebugPodExecutor)(nil)
+
+// NewDebugPodExecutor returns a DebugPodExecutor that creates its debug pods in namespace using image. The image must
+// provide bash and the namespace must allow privileged pods.
+func NewDebugPodExecutor(apiClient *clients.Settings, namespace, image string) *DebugPodExecutor {
+	return &DebugPodExecutor{
+		apiClient: apiClient,
+		namespace: namespace,
+		image:     image,
+		pods:      make(map[string]*pod.Builder),
+	}
+}
+
+// ExecOnNode runs command with sh on nodeName after changing root to the filesystem of the node.
+func (executor *DebugPodExecutor) ExecOnNode(
+	ctx context.Context, nodeName, command string, options ...ExecOption) (ExecResult, error) {
+	args := []string{"chroot", debugPodHostMountPath, "sh", "-c", command}
+
+	return executor.ExecArgsOnNode(ctx, nodeName, args, options...)
+}
+
+// ExecArgsOnNode runs args in the debug pod container on nodeName without a shell. The root filesystem of the node is
+// mounted at /host.
+func (executor *DebugPodExecutor) ExecArgsOnNode(
+	ctx context.Context, nodeName string, args []string, options ...ExecOption) (ExecResult, error) {
+	ctx, cancel, _ := newExecContext(ctx, options...)
+	defer cancel()
+
+	debugPod, err := executor.getPodOnNode(ctx, nodeName)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	return ExecInPod(ctx, executor.apiClient, debugPod, "", args, options...)
+}
+
+// Cleanup deletes all of the debug pods created by the executor, waiting up to timeout for each to be deleted.
+func (executor *DebugPodExecutor) Cleanup(timeout time.Duration) error {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+
+	var errs []error
+
+	for nodeName, debugPod := range executor.pods {
+		klog.V(90).Infof("Deleting debug pod %s on node %s", debugPod.Definition.Name, nodeName)
+
+		_, err := debugPod.DeleteAndWait(timeout)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete debug pod on node %s: %w", nodeName, err))
+
+			continue
+		}
+
+		delete(executor.pods, nodeName)
+	}
+
+	return errors.Join(errs...)
+}
+
+// getPodOnNode returns the debug pod for nodeName, creating it and waiting for it to be running if it does not exist
+// yet. A leftover pod with the same name, such as from an earlier run, is deleted first.
+func (executor *DebugPodExecutor) getPodOnNode(ctx context.Context, nodeName string) (*pod.Builder, error) {
+	executor.mutex.Lock()
+	debugPod, ok := executor.pods[nodeName]
+	executor.mutex.Unlock()
+
+	if ok && debugPod.Exists() {
+		return debugPod, nil
+	}
+
+	if executor.apiClient == nil {
+		return nil, fmt.Errorf("cannot execute command on node %s with nil apiClient", nodeName)
+	}
+
+	debugPod = executor.newDebugPod(nodeName)
+
+	if debugPod.Exists() {
+		klog.V(90).Infof("Deleting leftover debug pod %s on node %s", debugPod.Definition.Name, nodeName)
+
+		_, err := debugPod.DeleteAndWait(time.Minute)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete leftover debug pod on node %s: %w", nodeName, err)
+		}
+	}
+
+	klog.V(90).Infof("Creating debug pod %s on node %s", debugPod.Definition.Name, nodeName)
+
+	debugPod, err := debugPod.CreateAndWaitUntilRunning(podRunningTimeout(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug pod on node %s: %w", nodeName, err)
+	}
+
+	executor.mutex.Lock()
+	executor.pods[nodeName] = debugPod
+	executor.mutex.Unlock()
+
+	return debugPod, nil
+}
+
+// newDebugPod returns the builder for the debug pod on nodeName without creating it.
+func (executor *DebugPodExecutor) newDebugPod(nodeName string) *pod.Builder {
+	hostPathType := corev1.HostPathDirectory
+
+	debugPodName := fmt.Sprintf("eco-debug-%s", nodeName)
+
+	debugPod := pod.NewBuilder(executor.apiClient, debugPodName, executor.namespace, executor.image).
+		WithPrivilegedFlag().
+		WithHostNetwork().
+		WithHostPid(true).
+		WithToleration(corev1.Toleration{Operator: corev1.TolerationOpExists}).
+		WithVolume(corev1.Volume{
+			Name: "host",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/", Type: &hostPathType},
+			},
+		})
+
+	debugPod.Definition.Spec.NodeName = nodeName
+	debugPod.Definition.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	for index := range debugPod.Definition.Spec.Containers {
+		debugPod.Definition.Spec.Containers[index].VolumeMounts = append(
+			debugPod.Definition.Spec.Containers[index].VolumeMounts,
+			corev1.VolumeMount{Name: "host", MountPath: debugPodHostMountPath})
+	}
+
+	return debugPod
+}
+
+// podRunningTimeout returns how long to wait for a pod to be running based on the deadline of ctx.
+func podRunningTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+
+	return defaultPodRunningTimeout
+}
@@ -0,0 +1,368 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/execlog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"k8s.io/klog/v2"
+)
+
+// defaultSSHPort is the port SSHExecutor connects to unless overridden using WithPort.
+const defaultSSHPort = 22
+
+// SSHExecutor runs commands on nodes over SSH. It does not depend on the cluster API, so it can be used when the API
+// server is unavailable, such as during reboots or upgrades. It is safe for concurrent use once configured.
+type SSHExecutor struct {
+	config      *ssh.ClientConfig
+	port        int
+	addresses   map[string]string
+	jumpAddress string
+	// buildErr is set by the With methods when they fail and is returned by ExecOnNode.
+	buildErr error
+	// hostKeyErr is set when the known_hosts files cannot be loaded and is returned by ExecOnNode. It is kept separate
+	// from buildErr so a failure to load the default file is cleared once other files or insecure mode are chosen.
+	hostKeyErr error
+
+	// jumpClient is the connection to the jump host, shared by all commands and created on first use.
+	jumpMutex  sync.Mutex
+	jumpClient *ssh.Client
+}
+
+// Ensure SSHExecutor implements NodeExecutor at compile time.
+var _ NodeExecutor = (*SSHExecutor)(nil)
+
+// NewSSHExecutor returns an SSHExecutor that authenticates as user with the private key at privateKeyPath. Nodes are
+// connected to by their name unless an address is provided using WithNodeAddress. Host keys are verified against
+// ~/.ssh/known_hosts unless other files are provided using WithKnownHosts or verification is explicitly disabled using
+// WithInsecureIgnoreHostKey.
+func NewSSHExecutor(user, privateKeyPath string) (*SSHExecutor, error) {
+	if user == "" {
+		return nil, fmt.Errorf("ssh user cannot be empty")
+	}
+
+	if privateKeyPath == "" {
+		return nil, fmt.Errorf("ssh private key path cannot be empty")
+	}
+
+	privateKey, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private ssh key %s: %w", privateKeyPath, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private ssh key %s: %w", privateKeyPath, err)
+	}
+
+	executor := &SSHExecutor{
+		config: &ssh.ClientConfig{
+			User: user,
+			Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		},
+		port:      defaultSSHPort,
+		addresses: make(map[string]string),
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		executor.hostKeyErr = fmt.Errorf("failed to find default known_hosts file: %w", err)
+
+		return executor, nil
+	}
+
+	executor.setKnownHosts(filepath.Join(homeDir, ".ssh", "known_hosts"))
+
+	return executor, nil
+}
+
+// WithPort sets the port to connect to. It defaults to 22.
+func (executor *SSHExecutor) WithPort(port int) *SSHExecutor {
+	executor.port = port
+
+	return executor
+}
+
+// WithNodeAddress sets the address to connect to for nodeName, such as its IP address when the node name cannot be
+// resolved.
+func (executor *SSHExecutor) WithNodeAddress(nodeName, address string) *SSHExecutor {
+	executor.addresses[nodeName] = address
+
+	return executor
+}
+
+// WithKnownHosts verifies the host keys of the nodes and any jump host against the known_hosts files at paths instead
+// of ~/.ssh/known_hosts. Connections to hosts that are missing from the files or whose keys do not match fail.
+func (executor *SSHExecutor) WithKnownHosts(paths ...string) *SSHExecutor {
+	if len(paths) == 0 {
+		executor.buildErr = fmt.Errorf("known_hosts paths cannot be empty")
+
+		return executor
+	}
+
+	executor.setKnownHosts(paths...)
+
+	return executor
+}
+
+// WithInsecureIgnoreHostKey disables host key verification, so any host is trusted. It should only be used when the
+// host keys cannot be known in advance, since it allows the connection to be intercepted. A warning is logged when it
+// is used.
+func (executor *SSHExecutor) WithInsecureIgnoreHostKey() *SSHExecutor {
+	klog.Warningf("Host key verification is disabled for ssh user %s, connections may be intercepted",
+		executor.config.User)
+
+	executor.config.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // explicitly requested by the caller
+	executor.hostKeyErr = nil
+
+	return executor
+}
+
+// WithJumpHost connects to nodes through the SSH server at address, in the form host or host:port, such as a
+// provisioning host for nodes that are not reachable directly. The same user and key are used for the jump host.
+func (executor *SSHExecutor) WithJumpHost(address string) *SSHExecutor {
+	if address == "" {
+		executor.buildErr = fmt.Errorf("jump host address cannot be empty")
+
+		return executor
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, strconv.Itoa(defaultSSHPort))
+	}
+
+	executor.jumpAddress = address
+
+	return executor
+}
+
+// Close closes the connection to the jump host, if there is one. The executor may still be used afterwards, in which
+// case a new connection is made.
+func (executor *SSHExecutor) Close() error {
+	executor.jumpMutex.Lock()
+	defer executor.jumpMutex.Unlock()
+
+	if executor.jumpClient == nil {
+		return nil
+	}
+
+	err := executor.jumpClient.Close()
+	executor.jumpClient = nil
+
+	return err
+}
+
+// setKnownHosts verifies host keys against the known_hosts files at paths, replacing any previous host key
+// verification. If the files cannot be loaded, the error is returned by ExecOnNode.
+func (executor *SSHExecutor) setKnownHosts(paths ...string) {
+	hostKeyCallback, err := knownhosts.New(paths...)
+	if err != nil {
+		executor.config.HostKeyCallback = nil
+		executor.hostKeyErr = fmt.Errorf("failed to load known_hosts files %v: %w", paths, err)
+
+		return
+	}
+
+	executor.config.HostKeyCallback = hostKeyCallback
+	executor.hostKeyErr = nil
+}
+
+// ExecOnNode runs command in the login shell of the user on nodeName. The connection is closed if ctx is canceled
+// before the command finishes.
+func (executor *SSHExecutor) ExecOnNode(
+	ctx context.Context, nodeName, command string, options ...ExecOption) (ExecResult, error) {
+	if executor.buildErr != nil {
+		return ExecResult{}, executor.buildErr
+	}
+
+	if executor.hostKeyErr != nil {
+		return ExecResult{}, executor.hostKeyErr
+	}
+
+	start := time.Now()
+	result, err := executor.execOnNode(ctx, nodeName, command, options...)
+
+	execlog.Record(ctx, execlog.Entry{
+		Target:   fmt.Sprintf("node %s over ssh", nodeName),
+		Command:  command,
+		Start:    start,
+		Duration: time.Since(start),
+		ExitCode: result.ExitCode,
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		Err:      err,
+	})
+
+	return result, err
+}
+
+// execOnNode runs command on nodeName for ExecOnNode, which records it in the exec transcript.
+func (executor *SSHExecutor) execOnNode(
+	ctx context.Context, nodeName, command string, options ...ExecOption) (ExecResult, error) {
+	ctx, cancel, execOpts := newExecContext(ctx, options...)
+	defer cancel()
+
+	address := nodeName
+	if nodeAddress, ok := executor.addresses[nodeName]; ok {
+		address = nodeAddress
+	}
+
+	address = net.JoinHostPort(address, strconv.Itoa(executor.port))
+
+	klog.V(90).Infof("Executing command %q on node %s over ssh at %s", command, nodeName, address)
+
+	conn, err := executor.dial(ctx, address)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	// Closing the connection unblocks the handshake and the session when the context is done.
+	stopClose := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stopClose()
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, executor.config)
+	if err != nil {
+		_ = conn.Close()
+
+		err = fmt.Errorf("failed to establish ssh connection to %s: %w", address, err)
+
+		return ExecResult{}, errors.Join(ctx.Err(), err)
+	}
+
+	client := ssh.NewClient(clientConn, channels, requests)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return ExecResult{}, errors.Join(ctx.Err(), fmt.Errorf("failed to create ssh session on %s: %w", address, err))
+	}
+
+	defer session.Close()
+
+	var stdout, stderr lockedBuffer
+
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	if execOpts.combinedOutput {
+		session.Stderr = &stdout
+	}
+
+	err = session.Run(command)
+
+	result := ExecResult{Stdout: stdout.String(), Stderr: stderr.String()}
+
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitStatus()
+
+		return result, nil
+	}
+
+	return result, err
+}
+
+// dial connects to address, through the jump host if there is one.
+func (executor *SSHExecutor) dial(ctx context.Context, address string) (net.Conn, error) {
+	if executor.jumpAddress == "" {
+		dialer := net.Dialer{}
+
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	jumpClient, err := executor.getJumpClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := jumpClient.DialContext(ctx, "tcp", address)
+	if err != nil {
+		// The connection to the jump host may have been lost, so drop it for the next command to reconnect.
+		executor.jumpMutex.Lock()
+
+		if executor.jumpClient == jumpClient {
+			_ = jumpClient.Close()
+			executor.jumpClient = nil
+		}
+
+		executor.jumpMutex.Unlock()
+
+		return nil, fmt.Errorf("failed to connect through jump host %s: %w", executor.jumpAddress, err)
+	}
+
+	return conn, nil
+}
+
+// getJumpClient returns the connection to the jump host, connecting if there is none yet.
+func (executor *SSHExecutor) getJumpClient(ctx context.Context) (*ssh.Client, error) {
+	executor.jumpMutex.Lock()
+	defer executor.jumpMutex.Unlock()
+
+	if executor.jumpClient != nil {
+		return executor.jumpClient, nil
+	}
+
+	klog.V(90).Infof("Connecting to ssh jump host %s", executor.jumpAddress)
+
+	dialer := net.Dialer{}
+
+	conn, err := dialer.DialContext(ctx, "tcp", executor.jumpAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to jump host %s: %w", executor.jumpAddress, err)
+	}
+
+	stopClose := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stopClose()
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, executor.jumpAddress, executor.config)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("failed to establish ssh connection to jump host %s: %w", executor.jumpAddress, err)
+	}
+
+	executor.jumpClient = ssh.NewClient(clientConn, channels, requests)
+
+	return executor.jumpClient, nil
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent writes, since the ssh session copies stdout and stderr in
+// separate goroutines that may write to the same buffer.
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+// Ensure lockedBuffer implements io.Writer at compile time.
+var _ io.Writer = (*lockedBuffer)(nil)
+
+// Write appends data to the buffer.
+func (buffer *lockedBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	return buffer.buffer.Write(data)
+}
+
+// String returns the contents of the buffer.
+func (buffer *lockedBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	return buffer.buffer.String()
+}
@@ -0,0 +1,341 @@
+package cluster
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/execlog"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestSSHExecutor(t *testing.T) {
+	keyPath, clientKey := writeTestSSHKey(t)
+	server := startTestSSHServer(t, clientKey)
+
+	executor, err := NewSSHExecutor("core", keyPath)
+	assert.Nil(t, err)
+
+	host, port := splitTestAddress(t, server.address)
+	executor.WithPort(port).WithNodeAddress("node-1", host).WithKnownHosts(writeKnownHosts(t, server))
+
+	transcript := execlog.NewTranscript()
+
+	result, err := executor.ExecOnNode(execlog.NewContext(t.Context(), transcript), "node-1", "exit 3")
+	assert.Nil(t, err)
+	assert.Equal(t, ExecResult{Stdout: "ran exit 3\n", Stderr: "stderr\n", ExitCode: 3}, result)
+	assert.Contains(t, transcript.String(), "node node-1 over ssh")
+	assert.Contains(t, transcript.String(), "$ exit 3\nexit code: 3\n--- stdout ---\nran exit 3\n--- stderr ---\nstderr\n")
+
+	// A different server listening at an address the known_hosts file has another key for must be rejected.
+	otherServer := startTestSSHServer(t, clientKey)
+	_, otherPort := splitTestAddress(t, otherServer.address)
+
+	_, err = executor.WithPort(otherPort).ExecOnNode(t.Context(), "node-1", "true")
+	assert.ErrorContains(t, err, "failed to establish ssh connection")
+
+	_, err = NewSSHExecutor("", keyPath)
+	assert.NotNil(t, err)
+
+	_, err = executor.WithKnownHosts(filepath.Join(t.TempDir(), "missing")).ExecOnNode(t.Context(), "node-1", "true")
+	assert.ErrorContains(t, err, "failed to load known_hosts files")
+}
+
+func TestSSHExecutorHostKeys(t *testing.T) {
+	keyPath, clientKey := writeTestSSHKey(t)
+	server := startTestSSHServer(t, clientKey)
+	host, port := splitTestAddress(t, server.address)
+
+	testCases := []struct {
+		name          string
+		knownHosts    bool
+		configure     func(executor *SSHExecutor)
+		expectedError string
+	}{
+		{
+			name:       "default-known-hosts",
+			knownHosts: true,
+		},
+		{
+			name:          "default-known-hosts-missing",
+			expectedError: "failed to load known_hosts files",
+		},
+		{
+			name:          "known-hosts-without-host",
+			configure:     func(executor *SSHExecutor) { executor.WithKnownHosts(writeKnownHosts(t)) },
+			expectedError: "failed to establish ssh connection",
+		},
+		{
+			name:      "insecure-ignore-host-key",
+			configure: func(executor *SSHExecutor) { executor.WithInsecureIgnoreHostKey() },
+		},
+		{
+			name: "known-hosts-after-insecure",
+			configure: func(executor *SSHExecutor) {
+				executor.WithInsecureIgnoreHostKey().WithKnownHosts(writeKnownHosts(t))
+			},
+			expectedError: "failed to establish ssh connection",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			homeDir := t.TempDir()
+			t.Setenv("HOME", homeDir)
+
+			if testCase.knownHosts {
+				sshDir := filepath.Join(homeDir, ".ssh")
+				assert.Nil(t, os.Mkdir(sshDir, 0700))
+				assert.Nil(t, os.Rename(writeKnownHosts(t, server), filepath.Join(sshDir, "known_hosts")))
+			}
+
+			executor, err := NewSSHExecutor("core", keyPath)
+			assert.Nil(t, err)
+
+			executor.WithPort(port).WithNodeAddress("node-1", host)
+
+			if testCase.configure != nil {
+				testCase.configure(executor)
+			}
+
+			result, err := executor.ExecOnNode(t.Context(), "node-1", "true")
+			if testCase.expectedError != "" {
+				assert.ErrorContains(t, err, testCase.expectedError)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "ran true\n", result.Stdout)
+		})
+	}
+}
+
+func TestSSHExecutorJumpHost(t *testing.T) {
+	keyPath, clientKey := writeTestSSHKey(t)
+	jumpServer := startTestSSHServer(t, clientKey)
+	nodeServer := startTestSSHServer(t, clientKey)
+
+	executor, err := NewSSHExecutor("core", keyPath)
+	assert.Nil(t, err)
+
+	host, port := splitTestAddress(t, nodeServer.address)
+	executor.WithPort(port).
+		WithNodeAddress("node-1", host).
+		WithJumpHost(jumpServer.address).
+		WithKnownHosts(writeKnownHosts(t, jumpServer, nodeServer))
+
+	defer executor.Close()
+
+	for range 2 {
+		result, err := executor.ExecOnNode(t.Context(), "node-1", "hostname")
+		assert.Nil(t, err)
+		assert.Equal(t, "ran hostname\n", result.Stdout)
+	}
+
+	// Both commands are forwarded over the same connection to the jump host.
+	assert.Equal(t, 1, jumpServer.connectionCount())
+	assert.Nil(t, executor.Close())
+}
+
+// testSSHServer is a minimal SSH server that echoes the commands it is asked to run and forwards connections for use
+// as a jump host.
+type testSSHServer struct {
+	address     string
+	hostKey     ssh.Signer
+	connections chan struct{}
+}
+
+// connectionCount returns how many SSH connections the server has accepted.
+func (server *testSSHServer) connectionCount() int {
+	return len(server.connections)
+}
+
+func startTestSSHServer(t *testing.T, clientKey ssh.PublicKey) *testSSHServer {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	hostKey, err := ssh.NewSignerFromKey(privateKey)
+	assert.Nil(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, fmt.Errorf("unknown key")
+			}
+
+			return nil, nil //nolint:nilnil // nil permissions mean the key is accepted
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &testSSHServer{address: listener.Addr().String(), hostKey: hostKey, connections: make(chan struct{}, 16)}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn, config)
+		}
+	}()
+
+	return server
+}
+
+func (server *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	server.connections <- struct{}{}
+
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		switch newChannel.ChannelType() {
+		case "session":
+			go serveTestSession(newChannel)
+		case "direct-tcpip":
+			go serveTestForward(newChannel)
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+		}
+	}
+}
+
+func serveTestSession(newChannel ssh.NewChannel) {
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+
+	defer channel.Close()
+
+	for request := range requests {
+		if request.Type != "exec" {
+			_ = request.Reply(false, nil)
+
+			continue
+		}
+
+		_ = request.Reply(true, nil)
+
+		command := string(request.Payload[4:])
+		exitStatus := uint32(0)
+
+		_, _ = fmt.Sscanf(command, "exit %d", &exitStatus)
+		_, _ = fmt.Fprintf(channel, "ran %s\n", command)
+		_, _ = fmt.Fprint(channel.Stderr(), "stderr\n")
+
+		_, _ = channel.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, exitStatus))
+
+		return
+	}
+}
+
+func serveTestForward(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+
+	err := ssh.Unmarshal(newChannel.ExtraData(), &payload)
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, fmt.Sprint(payload.Port)))
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+
+		return
+	}
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		_ = target.Close()
+
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+
+	go func() {
+		_, _ = io.Copy(target, channel)
+		_ = target.Close()
+	}()
+
+	_, _ = io.Copy(channel, target)
+	_ = channel.Close()
+}
+
+func writeTestSSHKey(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	pemBlock, err := ssh.MarshalPrivateKey(privateKey, "")
+	assert.Nil(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	assert.Nil(t, os.WriteFile(keyPath, pem.EncodeToMemory(pemBlock), 0600))
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	assert.Nil(t, err)
+
+	return keyPath, signer.PublicKey()
+}
+
+func writeKnownHosts(t *testing.T, servers ...*testSSHServer) string {
+	t.Helper()
+
+	var content string
+
+	for _, server := range servers {
+		content += knownhosts.Line([]string{server.address}, server.hostKey.PublicKey()) + "\n"
+	}
+
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	assert.Nil(t, os.WriteFile(knownHostsPath, []byte(content), 0600))
+
+	return knownHostsPath
+}
+
+func splitTestAddress(t *testing.T, address string) (string, int) {
+	t.Helper()
+
+	host, portString, err := net.SplitHostPort(address)
+	assert.Nil(t, err)
+
+	var port int
+
+	_, err = fmt.Sscan(portString, &port)
+	assert.Nil(t, err)
+
+	return host, port
+}
@@ -0,0 +1,178 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestExecResultErr(t *testing.T) {
+	testCases := []struct {
+		result          ExecResult
+		expectedError   error
+		expectedMessage string
+	}{
+		{
+			result:          ExecResult{Stdout: "ok", ExitCode: 0},
+			expectedError:   nil,
+			expectedMessage: "",
+		},
+		{
+			result:          ExecResult{Stdout: "partial", Stderr: "no such file\n", ExitCode: 2},
+			expectedError:   ErrCommandFailed,
+			expectedMessage: "2: no such file",
+		},
+		{
+			result:          ExecResult{Stdout: "no such file\r\n", ExitCode: 1},
+			expectedError:   ErrCommandFailed,
+			expectedMessage: "1: no such file",
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.result.Err()
+		if testCase.expectedError == nil {
+			assert.Nil(t, err)
+		} else {
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.True(t, strings.HasSuffix(err.Error(), testCase.expectedMessage))
+		}
+	}
+}
+
+func TestExecOnNodes(t *testing.T) {
+	errTestExec := errors.New("test exec failed")
+
+	executor := NewFakeExecutor().
+		WithResponse("node-1", "uptime", ExecResult{Stdout: "up 1 day"}, nil).
+		WithResponse("node-2", "", ExecResult{Stderr: "not found", ExitCode: 127}, nil).
+		WithResponse("node-3", "", ExecResult{}, errTestExec)
+
+	results, err := ExecOnNodes(t.Context(), executor, []string{"node-1", "node-2", "node-3"}, "uptime")
+	assert.ErrorIs(t, err, errTestExec)
+	assert.Contains(t, err.Error(), "node-3")
+	assert.Equal(t, map[string]ExecResult{
+		"node-1": {Stdout: "up 1 day"},
+		"node-2": {Stderr: "not found", ExitCode: 127},
+	}, results)
+	assert.ElementsMatch(t, []FakeExecutorCall{
+		{NodeName: "node-1", Command: "uptime"},
+		{NodeName: "node-2", Command: "uptime"},
+		{NodeName: "node-3", Command: "uptime"},
+	}, executor.Calls())
+
+	_, err = ExecOnNodes(t.Context(), nil, []string{"node-1"}, "uptime")
+	assert.NotNil(t, err)
+}
+
+func TestExecOnNodesConcurrent(t *testing.T) {
+	nodeNames := []string{"node-1", "node-2", "node-3"}
+
+	var started sync.WaitGroup
+
+	started.Add(len(nodeNames))
+
+	// Each command only finishes once all of them have started, so running them one at a time would time out.
+	executor := NewFakeExecutor().WithHandler("", "", func(ctx context.Context, _, _ string) (ExecResult, error) {
+		started.Done()
+		started.Wait()
+
+		return ExecResult{Stdout: "done"}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	results, err := ExecOnNodes(ctx, executor, nodeNames, "true")
+	assert.Nil(t, err)
+	assert.Len(t, results, len(nodeNames))
+}
+
+func TestExecOnNodesTimeout(t *testing.T) {
+	executor := NewFakeExecutor().
+		WithHandler("slow", "", func(ctx context.Context, _, _ string) (ExecResult, error) {
+			<-ctx.Done()
+
+			return ExecResult{}, ctx.Err()
+		}).
+		WithResponse("fast", "", ExecResult{Stdout: "ok"}, nil)
+
+	results, err := ExecOnNodes(
+		t.Context(), executor, []string{"slow", "fast"}, "sleep 60", WithExecTimeout(10*time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, map[string]ExecResult{"fast": {Stdout: "ok"}}, results)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	results, err = ExecOnNodes(ctx, executor, []string{"fast"}, "true")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, results)
+}
+
+func TestFakeExecutor(t *testing.T) {
+	executor := NewFakeExecutor().
+		WithResponse("node-1", "hostname", ExecResult{Stdout: "first"}, nil).
+		WithResponse("", "hostname", ExecResult{Stdout: "second"}, nil)
+
+	result, err := executor.ExecOnNode(t.Context(), "node-1", "hostname")
+	assert.Nil(t, err)
+	assert.Equal(t, "first", result.Stdout)
+
+	result, err = executor.ExecOnNode(t.Context(), "node-2", "hostname")
+	assert.Nil(t, err)
+	assert.Equal(t, "second", result.Stdout)
+
+	_, err = executor.ExecOnNode(t.Context(), "node-2", "uptime")
+	assert.ErrorIs(t, err, ErrNoFakeResponse)
+
+	assert.Equal(t, []FakeExecutorCall{
+		{NodeName: "node-1", Command: "hostname"},
+		{NodeName: "node-2", Command: "hostname"},
+		{NodeName: "node-2", Command: "uptime"},
+	}, executor.Calls())
+}
+
+func TestResultFromStream(t *testing.T) {
+	errTestStream := errors.New("stream closed")
+
+	testCases := []struct {
+		err              error
+		expectedExitCode int
+		expectedError    error
+	}{
+		{
+			err:              nil,
+			expectedExitCode: 0,
+			expectedError:    nil,
+		},
+		{
+			err:              utilexec.CodeExitError{Err: errors.New("command terminated with exit code 3"), Code: 3},
+			expectedExitCode: 3,
+			expectedError:    nil,
+		},
+		{
+			err:              errTestStream,
+			expectedExitCode: 0,
+			expectedError:    errTestStream,
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := resultFromStream("out", "err", testCase.err)
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Equal(t, ExecResult{Stdout: "out", Stderr: "err", ExitCode: testCase.expectedExitCode}, result)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'echo hello'`, ShellQuote("echo hello"))
+	assert.Equal(t, `'echo '\''hello'\'''`, ShellQuote("echo 'hello'"))
+	assert.Equal(t, `sh -c 'cat /proc/cmdline'`, legacyShellCmd("cat /proc/cmdline"))
+}
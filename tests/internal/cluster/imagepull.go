@@ -0,0 +1,407 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/daemonset"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/namespace"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// imagePrePullPrefix is the prefix of the names of the DaemonSet and temporary namespace used to pre-pull images.
+	imagePrePullPrefix = "eco-image-prepull"
+	// imagePrePullLabel is the label identifying the pods of a pre-pull DaemonSet.
+	imagePrePullLabel = "eco-gotests/image-prepull"
+	// defaultImagePullTimeout is how long PrePull waits by default for the images to be pulled on all nodes.
+	defaultImagePullTimeout = 10 * time.Minute
+	// imagePullPollInterval is how often PrePull checks the pods of the DaemonSet.
+	imagePullPollInterval = 5 * time.Second
+	// imagePrePullCleanupTimeout is how long PrePull waits for the temporary namespace to be deleted.
+	imagePrePullCleanupTimeout = 5 * time.Minute
+)
+
+// ErrImagePull is wrapped by the error of an ImagePullReport when images were not pulled on some nodes.
+var ErrImagePull = errors.New("failed to pull images")
+
+// imagePullFailureReasons are the waiting reasons of containers that indicate the image could not be pulled or run.
+var imagePullFailureReasons = []string{
+	"ErrImagePull",
+	"ImagePullBackOff",
+	"InvalidImageName",
+	"ErrImageNeverPull",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+	"CrashLoopBackOff",
+}
+
+// NodeImagePullResult is the result of pre-pulling images on a single node.
+type NodeImagePullResult struct {
+	// NodeName is the name of the node.
+	NodeName string
+	// Duration is how long it took from the pull pod being created until all of the images were pulled, or until
+	// giving up if Err is set.
+	Duration time.Duration
+	// Err is why the images were not pulled on the node, nil if they were.
+	Err error
+}
+
+// ImagePullReport describes the result of pre-pulling images on each node.
+type ImagePullReport struct {
+	// Images are the images that were pulled.
+	Images []string
+	// Nodes has the result for each selected node, sorted by node name.
+	Nodes []NodeImagePullResult
+}
+
+// Failed returns the results of the nodes where the images were not pulled.
+func (report ImagePullReport) Failed() []NodeImagePullResult {
+	var failed []NodeImagePullResult
+
+	for _, result := range report.Nodes {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Err returns an error wrapping ErrImagePull that lists the failed nodes, or nil if the images were pulled on all of
+// the nodes.
+func (report ImagePullReport) Err() error {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	var details []string
+
+	for _, result := range failed {
+		details = append(details, fmt.Sprintf("%s: %v", result.NodeName, result.Err))
+	}
+
+	return fmt.Errorf("%w %v on %d of %d nodes: %s",
+		ErrImagePull, report.Images, len(failed), len(report.Nodes), strings.Join(details, "; "))
+}
+
+// ImagePrePuller pulls images on nodes in parallel using a temporary DaemonSet. Each image is an init container
+// running a no-op shell command, so the images must contain /bin/sh.
+type ImagePrePuller struct {
+	apiClient    *clients.Settings
+	name         string
+	namespace    string
+	images       []string
+	nodeSelector map[string]string
+	pullSecrets  []string
+	timeout      time.Duration
+}
+
+// NewImagePrePuller returns an ImagePrePuller that pulls images on all nodes using a temporary namespace, waiting up to
+// 10 minutes.
+func NewImagePrePuller(apiClient *clients.Settings, images ...string) *ImagePrePuller {
+	return &ImagePrePuller{
+		apiClient: apiClient,
+		name:      fmt.Sprintf("%s-%s", imagePrePullPrefix, utilrand.String(5)),
+		images:    images,
+		timeout:   defaultImagePullTimeout,
+	}
+}
+
+// WithNodeSelector limits the nodes the images are pulled on to those matching nodeSelector.
+func (puller *ImagePrePuller) WithNodeSelector(nodeSelector map[string]string) *ImagePrePuller {
+	puller.nodeSelector = nodeSelector
+
+	return puller
+}
+
+// WithNamespace creates the DaemonSet in an existing namespace rather than a temporary one. This is needed when using
+// pull secrets, which must be in the same namespace.
+func (puller *ImagePrePuller) WithNamespace(namespace string) *ImagePrePuller {
+	puller.namespace = namespace
+
+	return puller
+}
+
+// WithPullSecrets sets the names of the secrets in the namespace from WithNamespace to pull the images with.
+func (puller *ImagePrePuller) WithPullSecrets(secretNames ...string) *ImagePrePuller {
+	puller.pullSecrets = secretNames
+
+	return puller
+}
+
+// WithTimeout sets how long to wait for the images to be pulled on all of the nodes.
+func (puller *ImagePrePuller) WithTimeout(timeout time.Duration) *ImagePrePuller {
+	puller.timeout = timeout
+
+	return puller
+}
+
+// PrePull creates the DaemonSet, waits until the images are pulled on every selected node or the timeout expires, then
+// deletes the DaemonSet and any temporary namespace. Nodes where the images were not pulled in time are reported in
+// the ImagePullReport rather than the error, which is only for failing to run the DaemonSet. Use ImagePullReport.Err
+// to treat any failed node as an error.
+func (puller *ImagePrePuller) PrePull(ctx context.Context) (ImagePullReport, error) {
+	report := ImagePullReport{Images: puller.images}
+
+	if err := puller.validate(); err != nil {
+		return report, err
+	}
+
+	nodeNames, err := listNodeNames(
+		puller.apiClient, metav1.ListOptions{LabelSelector: labels.Set(puller.nodeSelector).String()})
+	if err != nil {
+		return report, fmt.Errorf("failed to list nodes to pull images on: %w", err)
+	}
+
+	if len(nodeNames) == 0 {
+		return report, fmt.Errorf("no nodes match node selector %v", puller.nodeSelector)
+	}
+
+	slices.Sort(nodeNames)
+
+	klog.V(90).Infof("Pulling images %v on %d nodes with DaemonSet %s", puller.images, len(nodeNames), puller.name)
+
+	namespaceName, cleanup, err := puller.createDaemonSet()
+	if err != nil {
+		return report, err
+	}
+
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(ctx, puller.timeout)
+	defer cancel()
+
+	start := time.Now()
+	results := make(map[string]NodeImagePullResult)
+
+	for {
+		podList, err := pod.List(puller.apiClient, namespaceName, metav1.ListOptions{
+			LabelSelector: labels.Set{imagePrePullLabel: puller.name}.String()})
+		if err != nil {
+			klog.V(90).Infof("Failed to list image pull pods: %v", err)
+		}
+
+		results = collectImagePullResults(podList, nodeNames, results, start)
+
+		if countPulled(results) == len(nodeNames) || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(imagePullPollInterval):
+		}
+	}
+
+	for _, nodeName := range nodeNames {
+		result, ok := results[nodeName]
+
+		switch {
+		case !ok:
+			result = NodeImagePullResult{
+				NodeName: nodeName,
+				Duration: time.Since(start),
+				Err:      fmt.Errorf("no pull pod became ready before timeout: %w", ctx.Err()),
+			}
+		case result.Err != nil:
+			result.Duration = time.Since(start)
+			result.Err = fmt.Errorf("%w: %w", result.Err, ctx.Err())
+		}
+
+		report.Nodes = append(report.Nodes, result)
+	}
+
+	return report, nil
+}
+
+// validate checks that the puller has a client and at least one image.
+func (puller *ImagePrePuller) validate() error {
+	if puller.apiClient == nil {
+		return fmt.Errorf("apiClient cannot be nil")
+	}
+
+	if len(puller.images) == 0 || slices.Contains(puller.images, "") {
+		return fmt.Errorf("images cannot be empty")
+	}
+
+	if len(puller.pullSecrets) > 0 && puller.namespace == "" {
+		return fmt.Errorf("pull secrets require the namespace they are in to be set")
+	}
+
+	return nil
+}
+
+// createDaemonSet creates the namespace if needed and the DaemonSet, returning the namespace and a function to delete
+// everything that was created.
+func (puller *ImagePrePuller) createDaemonSet() (string, func(), error) {
+	namespaceName := puller.namespace
+
+	var tempNamespace *namespace.Builder
+
+	if namespaceName == "" {
+		namespaceName = puller.name
+
+		var err error
+
+		tempNamespace, err = namespace.NewBuilder(puller.apiClient, namespaceName).Create()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create image pull namespace %s: %w", namespaceName, err)
+		}
+	}
+
+	daemonSetBuilder := daemonset.NewBuilder(
+		puller.apiClient, puller.name, namespaceName, map[string]string{imagePrePullLabel: puller.name},
+		puller.newContainer("wait", puller.images[len(puller.images)-1], "trap 'exit 0' TERM; sleep infinity & wait"))
+
+	if len(puller.nodeSelector) > 0 {
+		daemonSetBuilder.WithNodeSelector(puller.nodeSelector)
+	}
+
+	daemonSetBuilder, err := daemonSetBuilder.WithOptions(puller.withPullPodSpec).Create()
+
+	cleanup := func() {
+		if daemonSetBuilder != nil {
+			err := daemonSetBuilder.Delete()
+			if err != nil {
+				klog.V(90).Infof("Failed to delete image pull DaemonSet %s: %v", puller.name, err)
+			}
+		}
+
+		if tempNamespace != nil {
+			err := tempNamespace.DeleteAndWait(imagePrePullCleanupTimeout)
+			if err != nil {
+				klog.V(90).Infof("Failed to delete image pull namespace %s: %v", namespaceName, err)
+			}
+		}
+	}
+
+	if err != nil {
+		cleanup()
+
+		return "", nil, fmt.Errorf("failed to create image pull DaemonSet %s: %w", puller.name, err)
+	}
+
+	return namespaceName, cleanup, nil
+}
+
+// withPullPodSpec adds the init containers pulling each image and lets the pods run on any node the selector matches
+// without extra privileges.
+func (puller *ImagePrePuller) withPullPodSpec(builder *daemonset.Builder) (*daemonset.Builder, error) {
+	podSpec := &builder.Definition.Spec.Template.Spec
+
+	for index, image := range puller.images {
+		podSpec.InitContainers = append(
+			podSpec.InitContainers, puller.newContainer(fmt.Sprintf("pull-%d", index), image, "exit 0"))
+	}
+
+	for _, secretName := range puller.pullSecrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+	}
+
+	podSpec.Tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	podSpec.SecurityContext = &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	podSpec.TerminationGracePeriodSeconds = ptr.To[int64](0)
+
+	return builder, nil
+}
+
+// newContainer returns an unprivileged container running shellCmd in image.
+func (puller *ImagePrePuller) newContainer(name, image, shellCmd string) corev1.Container {
+	return corev1.Container{
+		Name:            name,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/bin/sh", "-c", shellCmd},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+}
+
+// collectImagePullResults updates results with the nodes whose pull pods are ready and the current failure of nodes
+// whose pull pods are failing. Results of nodes where the images were already pulled are kept as they are.
+func collectImagePullResults(
+	podList []*pod.Builder,
+	nodeNames []string,
+	results map[string]NodeImagePullResult,
+	start time.Time) map[string]NodeImagePullResult {
+	for _, podBuilder := range podList {
+		podObject := podBuilder.Object
+		nodeName := podObject.Spec.NodeName
+
+		if !slices.Contains(nodeNames, nodeName) {
+			continue
+		}
+
+		if result, ok := results[nodeName]; ok && result.Err == nil {
+			continue
+		}
+
+		readyIndex := slices.IndexFunc(podObject.Status.Conditions, func(condition corev1.PodCondition) bool {
+			return condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue
+		})
+
+		if readyIndex >= 0 {
+			duration := podObject.Status.Conditions[readyIndex].LastTransitionTime.Sub(podObject.CreationTimestamp.Time)
+			if podObject.CreationTimestamp.IsZero() || duration < 0 {
+				duration = time.Since(start)
+			}
+
+			klog.V(90).Infof("Pulled images on node %s in %s", nodeName, duration)
+
+			results[nodeName] = NodeImagePullResult{NodeName: nodeName, Duration: duration}
+
+			continue
+		}
+
+		if err := imagePullPodError(podObject); err != nil {
+			klog.V(90).Infof("Image pull failing on node %s: %v", nodeName, err)
+
+			results[nodeName] = NodeImagePullResult{NodeName: nodeName, Err: err}
+		}
+	}
+
+	return results
+}
+
+// countPulled returns the number of nodes in results where the images were pulled.
+func countPulled(results map[string]NodeImagePullResult) int {
+	count := 0
+
+	for _, result := range results {
+		if result.Err == nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// imagePullPodError returns an error describing why a container of podObject is failing to pull or run its image, or
+// nil if none are.
+func imagePullPodError(podObject *corev1.Pod) error {
+	for _, status := range slices.Concat(podObject.Status.InitContainerStatuses, podObject.Status.ContainerStatuses) {
+		waiting := status.State.Waiting
+		if waiting != nil && slices.Contains(imagePullFailureReasons, waiting.Reason) {
+			return fmt.Errorf("image %s: %s: %s", status.Image, waiting.Reason, waiting.Message)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/daemonset"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	imagePullTestImage     = "quay.io/test/image:latest"
+	imagePullTestNamespace = "test-ns"
+	imagePullTestName      = "test-prepull"
+)
+
+func TestImagePrePullerPrePull(t *testing.T) {
+	testCases := []struct {
+		objects        []runtime.Object
+		expectedFailed map[string]string
+	}{
+		{
+			objects: []runtime.Object{
+				buildImagePullNode("node-1"), buildImagePullNode("node-2"),
+				buildImagePullPod("node-1", ""), buildImagePullPod("node-2", ""),
+			},
+			expectedFailed: map[string]string{},
+		},
+		{
+			objects: []runtime.Object{
+				buildImagePullNode("node-1"), buildImagePullNode("node-2"), buildImagePullNode("node-3"),
+				buildImagePullPod("node-1", ""), buildImagePullPod("node-2", "ImagePullBackOff"),
+			},
+			expectedFailed: map[string]string{
+				"node-2": "ImagePullBackOff",
+				"node-3": "no pull pod became ready",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		apiClient := clients.GetTestClients(clients.TestClientParams{K8sMockObjects: testCase.objects})
+
+		puller := NewImagePrePuller(apiClient, imagePullTestImage).
+			WithNamespace(imagePullTestNamespace).
+			WithTimeout(50 * time.Millisecond)
+		puller.name = imagePullTestName
+
+		report, err := puller.PrePull(t.Context())
+		assert.Nil(t, err)
+		assert.Len(t, report.Failed(), len(testCase.expectedFailed))
+
+		for _, result := range report.Nodes {
+			expectedMessage, failed := testCase.expectedFailed[result.NodeName]
+			if !failed {
+				assert.Nil(t, result.Err)
+
+				continue
+			}
+
+			assert.ErrorContains(t, result.Err, expectedMessage)
+		}
+
+		if len(testCase.expectedFailed) > 0 {
+			assert.ErrorIs(t, report.Err(), ErrImagePull)
+		} else {
+			assert.Nil(t, report.Err())
+		}
+
+		daemonSets, err := apiClient.DaemonSets(imagePullTestNamespace).List(t.Context(), metav1.ListOptions{})
+		assert.Nil(t, err)
+		assert.Empty(t, daemonSets.Items)
+	}
+}
+
+func TestImagePrePullerValidate(t *testing.T) {
+	apiClient := clients.GetTestClients(clients.TestClientParams{})
+
+	testCases := []struct {
+		puller        *ImagePrePuller
+		expectedError string
+	}{
+		{puller: NewImagePrePuller(nil, imagePullTestImage), expectedError: "apiClient cannot be nil"},
+		{puller: NewImagePrePuller(apiClient), expectedError: "images cannot be empty"},
+		{puller: NewImagePrePuller(apiClient, ""), expectedError: "images cannot be empty"},
+		{
+			puller:        NewImagePrePuller(apiClient, imagePullTestImage).WithPullSecrets("pull-secret"),
+			expectedError: "pull secrets require the namespace they are in to be set",
+		},
+		{puller: NewImagePrePuller(apiClient, imagePullTestImage), expectedError: "no nodes match node selector"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := testCase.puller.PrePull(t.Context())
+		assert.ErrorContains(t, err, testCase.expectedError)
+	}
+}
+
+func TestImagePrePullerPodSpec(t *testing.T) {
+	puller := NewImagePrePuller(clients.GetTestClients(clients.TestClientParams{}), "image-1", "image-2").
+		WithPullSecrets("pull-secret")
+
+	builder, err := puller.withPullPodSpec(&daemonset.Builder{Definition: &appsv1.DaemonSet{}})
+	assert.Nil(t, err)
+
+	podSpec := builder.Definition.Spec.Template.Spec
+	if assert.Len(t, podSpec.InitContainers, 2) {
+		assert.Equal(t, "image-1", podSpec.InitContainers[0].Image)
+		assert.Equal(t, "image-2", podSpec.InitContainers[1].Image)
+		assert.False(t, *podSpec.InitContainers[0].SecurityContext.AllowPrivilegeEscalation)
+	}
+
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "pull-secret"}}, podSpec.ImagePullSecrets)
+	assert.Equal(t, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}, podSpec.Tolerations)
+}
+
+func TestCollectImagePullResults(t *testing.T) {
+	start := time.Now()
+	readyPod := buildImagePullPod("node-1", "")
+	readyPod.CreationTimestamp = metav1.NewTime(start.Add(-time.Minute))
+	readyPod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(start.Add(-15 * time.Second))
+
+	podList := []*pod.Builder{
+		{Object: readyPod},
+		{Object: buildImagePullPod("node-2", "ErrImagePull")},
+		{Object: buildImagePullPod("other-node", "")},
+	}
+
+	results := collectImagePullResults(
+		podList, []string{"node-1", "node-2", "node-3"}, make(map[string]NodeImagePullResult), start)
+	assert.Len(t, results, 2)
+	assert.Equal(t, NodeImagePullResult{NodeName: "node-1", Duration: 45 * time.Second}, results["node-1"])
+	assert.ErrorContains(t, results["node-2"].Err, "image quay.io/test/image:latest: ErrImagePull: pull failed")
+	assert.Equal(t, 1, countPulled(results))
+}
+
+func buildImagePullNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func buildImagePullPod(nodeName, waitingReason string) *corev1.Pod {
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      imagePullTestName + "-" + nodeName,
+			Namespace: imagePullTestNamespace,
+			Labels:    map[string]string{imagePrePullLabel: imagePullTestName},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	if waitingReason != "" {
+		testPod.Status.Conditions[0].Status = corev1.ConditionFalse
+		testPod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "pull-0",
+			Image: imagePullTestImage,
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason, Message: "pull failed"}},
+		}}
+	}
+
+	return testPod
+}
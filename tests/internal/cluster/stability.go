@@ -0,0 +1,522 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clusteroperator"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/mco"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/nodes"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultStabilitySettleWindow is how long the cluster must stay stable by default before Wait returns.
+	defaultStabilitySettleWindow = time.Minute
+	// defaultStabilityPollInterval is how often the cluster is checked by default while waiting.
+	defaultStabilityPollInterval = 10 * time.Second
+	// crashLoopBackOffReason is the waiting reason of containers that keep crashing.
+	crashLoopBackOffReason = "CrashLoopBackOff"
+)
+
+// ErrClusterUnstable is wrapped by the StabilityError returned when the cluster does not become stable in time.
+var ErrClusterUnstable = errors.New("cluster did not become stable")
+
+// StabilityCheck is a part of the cluster checked by a StabilityGate.
+type StabilityCheck string
+
+const (
+	// StabilityCheckAPI checks that the API server is reachable.
+	StabilityCheckAPI StabilityCheck = "api"
+	// StabilityCheckNodes checks that all nodes are ready and schedulable.
+	StabilityCheckNodes StabilityCheck = "nodes"
+	// StabilityCheckMCPs checks that all MachineConfigPools are updated and not updating or degraded.
+	StabilityCheckMCPs StabilityCheck = "machineconfigpools"
+	// StabilityCheckClusterOperators checks that all ClusterOperators are available and not progressing or degraded.
+	StabilityCheckClusterOperators StabilityCheck = "clusteroperators"
+	// StabilityCheckCSRs checks that there are no CertificateSigningRequests waiting for approval.
+	StabilityCheckCSRs StabilityCheck = "csrs"
+	// StabilityCheckPods checks that no containers in the pods of the namespaces from WithPodNamespaces are crash
+	// looping or restarting.
+	StabilityCheckPods StabilityCheck = "pods"
+)
+
+// allStabilityChecks are the checks run by a StabilityGate unless WithChecks is used, in the order they are run.
+var allStabilityChecks = []StabilityCheck{
+	StabilityCheckAPI,
+	StabilityCheckNodes,
+	StabilityCheckMCPs,
+	StabilityCheckClusterOperators,
+	StabilityCheckCSRs,
+	StabilityCheckPods,
+}
+
+// UnstableResource is a single resource that failed a StabilityCheck.
+type UnstableResource struct {
+	// Check is the check the resource failed.
+	Check StabilityCheck
+	// Namespace is the namespace of the resource, empty for cluster scoped resources.
+	Namespace string
+	// Name is the name of the resource, empty when the check itself could not be run.
+	Name string
+	// Reason describes why the resource is not stable.
+	Reason string
+}
+
+// String returns the resource as check/namespace/name: reason.
+func (resource UnstableResource) String() string {
+	path := []string{string(resource.Check)}
+
+	if resource.Namespace != "" {
+		path = append(path, resource.Namespace)
+	}
+
+	if resource.Name != "" {
+		path = append(path, resource.Name)
+	}
+
+	return fmt.Sprintf("%s: %s", strings.Join(path, "/"), resource.Reason)
+}
+
+// StabilityReport describes the result of checking the stability of a cluster.
+type StabilityReport struct {
+	// CheckedAt is when the report was created.
+	CheckedAt time.Time
+	// StableFor is how long the cluster has been stable for as of CheckedAt. It is always zero for reports from
+	// StabilityGate.Check.
+	StableFor time.Duration
+	// Unstable lists every resource that was not stable, in the order the checks were run.
+	Unstable []UnstableResource
+}
+
+// IsStable returns whether no resources were found to be unstable.
+func (report StabilityReport) IsStable() bool {
+	return len(report.Unstable) == 0
+}
+
+// String returns each unstable resource on its own line.
+func (report StabilityReport) String() string {
+	if report.IsStable() {
+		return fmt.Sprintf("cluster stable for %s", report.StableFor)
+	}
+
+	lines := make([]string, 0, len(report.Unstable))
+
+	for _, resource := range report.Unstable {
+		lines = append(lines, resource.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// StabilityError is returned by StabilityGate.Wait when the cluster does not stay stable for the settle window before
+// the timeout. It contains the last report so callers can see exactly what was not stable.
+type StabilityError struct {
+	// Report is the last report before giving up.
+	Report StabilityReport
+	// SettleWindow is how long the cluster needed to stay stable.
+	SettleWindow time.Duration
+	// Err is the error from the context that ended the wait.
+	Err error
+}
+
+// Error returns the error with the unstable resources from the report.
+func (stabilityErr *StabilityError) Error() string {
+	if stabilityErr.Report.IsStable() {
+		return fmt.Sprintf("%v: stable for only %s of %s: %v",
+			ErrClusterUnstable, stabilityErr.Report.StableFor, stabilityErr.SettleWindow, stabilityErr.Err)
+	}
+
+	return fmt.Sprintf("%v: %v: %d unstable resources:\n%s",
+		ErrClusterUnstable, stabilityErr.Err, len(stabilityErr.Report.Unstable), stabilityErr.Report)
+}
+
+// Unwrap allows errors.Is to match both ErrClusterUnstable and the context error.
+func (stabilityErr *StabilityError) Unwrap() []error {
+	return []error{ErrClusterUnstable, stabilityErr.Err}
+}
+
+// StabilityGate checks that a cluster is stable, optionally waiting for it to stay stable for a settle window. It is
+// not safe for concurrent use.
+type StabilityGate struct {
+	apiClient     *clients.Settings
+	checks        []StabilityCheck
+	podNamespaces []string
+	settleWindow  time.Duration
+	pollInterval  time.Duration
+
+	// restartCounts holds the restart count of each container from the previous check while waiting, keyed by
+	// namespace/pod/container.
+	restartCounts map[string]int32
+}
+
+// NewStabilityGate returns a StabilityGate for the cluster of apiClient that runs all of the checks, waits for the
+// cluster to stay stable for one minute, and checks every 10 seconds. The pods check only runs for namespaces provided
+// using WithPodNamespaces.
+func NewStabilityGate(apiClient *clients.Settings) *StabilityGate {
+	return &StabilityGate{
+		apiClient:    apiClient,
+		checks:       allStabilityChecks,
+		settleWindow: defaultStabilitySettleWindow,
+		pollInterval: defaultStabilityPollInterval,
+	}
+}
+
+// WithChecks sets the checks to run, replacing the default of running all of them.
+func (gate *StabilityGate) WithChecks(checks ...StabilityCheck) *StabilityGate {
+	gate.checks = checks
+
+	return gate
+}
+
+// WithPodNamespaces sets the namespaces whose pods are checked for crash looping or restarting containers.
+func (gate *StabilityGate) WithPodNamespaces(namespaces ...string) *StabilityGate {
+	gate.podNamespaces = namespaces
+
+	return gate
+}
+
+// WithSettleWindow sets how long the cluster must stay stable before Wait returns. A settle window of zero makes Wait
+// return as soon as the cluster is stable.
+func (gate *StabilityGate) WithSettleWindow(settleWindow time.Duration) *StabilityGate {
+	gate.settleWindow = settleWindow
+
+	return gate
+}
+
+// WithPollInterval sets how often the cluster is checked while waiting.
+func (gate *StabilityGate) WithPollInterval(pollInterval time.Duration) *StabilityGate {
+	gate.pollInterval = pollInterval
+
+	return gate
+}
+
+// Check runs each of the checks once and returns a report of the resources that are not stable. Failing to list a
+// resource is reported as an unstable resource of that check rather than returned as an error.
+func (gate *StabilityGate) Check(ctx context.Context) StabilityReport {
+	report := StabilityReport{CheckedAt: time.Now()}
+
+	if gate.apiClient == nil {
+		report.Unstable = append(report.Unstable, UnstableResource{
+			Check: StabilityCheckAPI, Reason: "apiClient cannot be nil"})
+
+		return report
+	}
+
+	for _, check := range allStabilityChecks {
+		if !slices.Contains(gate.checks, check) {
+			continue
+		}
+
+		var unstable []UnstableResource
+
+		switch check {
+		case StabilityCheckAPI:
+			unstable = gate.checkAPI()
+		case StabilityCheckNodes:
+			unstable = gate.checkNodes()
+		case StabilityCheckMCPs:
+			unstable = gate.checkMCPs()
+		case StabilityCheckClusterOperators:
+			unstable = gate.checkClusterOperators()
+		case StabilityCheckCSRs:
+			unstable = gate.checkCSRs(ctx)
+		case StabilityCheckPods:
+			unstable = gate.checkPods()
+		}
+
+		report.Unstable = append(report.Unstable, unstable...)
+	}
+
+	return report
+}
+
+// Wait checks the cluster every poll interval until it has stayed stable for the settle window, returning the last
+// report. If the cluster is not stable for long enough before timeout or ctx is done, a *StabilityError is returned.
+// Containers restarting between checks also count as unstable while waiting.
+func (gate *StabilityGate) Wait(ctx context.Context, timeout time.Duration) (StabilityReport, error) {
+	klog.V(90).Infof("Waiting up to %s for cluster to be stable for %s with checks %v",
+		timeout, gate.settleWindow, gate.checks)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	gate.restartCounts = make(map[string]int32)
+	defer func() { gate.restartCounts = nil }()
+
+	var stableSince time.Time
+
+	for {
+		report := gate.Check(ctx)
+
+		switch {
+		case !report.IsStable():
+			klog.V(90).Infof("Cluster is not stable:\n%s", report)
+
+			stableSince = time.Time{}
+		case stableSince.IsZero():
+			stableSince = report.CheckedAt
+		default:
+			report.StableFor = report.CheckedAt.Sub(stableSince)
+		}
+
+		if report.IsStable() && report.StableFor >= gate.settleWindow {
+			klog.V(90).Infof("Cluster has been stable for %s", report.StableFor)
+
+			return report, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return report, &StabilityError{Report: report, SettleWindow: gate.settleWindow, Err: ctx.Err()}
+		case <-time.After(gate.pollInterval):
+		}
+	}
+}
+
+// checkAPI checks that the API server responds to a version request.
+func (gate *StabilityGate) checkAPI() []UnstableResource {
+	if gate.apiClient.K8sClient == nil {
+		return []UnstableResource{{Check: StabilityCheckAPI, Reason: "kubernetes client is not set"}}
+	}
+
+	_, err := gate.apiClient.K8sClient.Discovery().ServerVersion()
+	if err != nil {
+		return []UnstableResource{{Check: StabilityCheckAPI, Reason: fmt.Sprintf("API server unreachable: %v", err)}}
+	}
+
+	return nil
+}
+
+// checkNodes checks that every node is ready and not cordoned.
+func (gate *StabilityGate) checkNodes() []UnstableResource {
+	nodeList, err := nodes.List(gate.apiClient)
+	if err != nil {
+		return []UnstableResource{{Check: StabilityCheckNodes, Reason: fmt.Sprintf("failed to list nodes: %v", err)}}
+	}
+
+	var unstable []UnstableResource
+
+	for _, node := range nodeList {
+		if node.Object.Spec.Unschedulable {
+			unstable = append(unstable, UnstableResource{
+				Check: StabilityCheckNodes, Name: node.Object.Name, Reason: "node is unschedulable"})
+		}
+
+		readyIndex := slices.IndexFunc(node.Object.Status.Conditions, func(condition corev1.NodeCondition) bool {
+			return condition.Type == corev1.NodeReady
+		})
+
+		if readyIndex < 0 {
+			unstable = append(unstable, UnstableResource{
+				Check: StabilityCheckNodes, Name: node.Object.Name, Reason: "node has no Ready condition"})
+
+			continue
+		}
+
+		ready := node.Object.Status.Conditions[readyIndex]
+		if ready.Status != corev1.ConditionTrue {
+			unstable = append(unstable, UnstableResource{
+				Check:  StabilityCheckNodes,
+				Name:   node.Object.Name,
+				Reason: fmt.Sprintf("Ready=%s: %s", ready.Status, ready.Message),
+			})
+		}
+	}
+
+	return unstable
+}
+
+// checkMCPs checks that every MachineConfigPool is updated, not updating, and not degraded.
+func (gate *StabilityGate) checkMCPs() []UnstableResource {
+	mcpList, err := mco.ListMCP(gate.apiClient)
+	if err != nil {
+		return []UnstableResource{{
+			Check: StabilityCheckMCPs, Reason: fmt.Sprintf("failed to list MachineConfigPools: %v", err)}}
+	}
+
+	expectedConditions := map[mcv1.MachineConfigPoolConditionType]corev1.ConditionStatus{
+		mcv1.MachineConfigPoolUpdated:  corev1.ConditionTrue,
+		mcv1.MachineConfigPoolUpdating: corev1.ConditionFalse,
+		mcv1.MachineConfigPoolDegraded: corev1.ConditionFalse,
+	}
+
+	var unstable []UnstableResource
+
+	for _, mcp := range mcpList {
+		var reasons []string
+
+		for _, conditionType := range []mcv1.MachineConfigPoolConditionType{
+			mcv1.MachineConfigPoolUpdated, mcv1.MachineConfigPoolUpdating, mcv1.MachineConfigPoolDegraded} {
+			status := corev1.ConditionUnknown
+
+			for _, condition := range mcp.Object.Status.Conditions {
+				if condition.Type == conditionType {
+					status = condition.Status
+				}
+			}
+
+			if status != expectedConditions[conditionType] {
+				reasons = append(reasons, fmt.Sprintf("%s=%s", conditionType, status))
+			}
+		}
+
+		if mcp.Object.Status.DegradedMachineCount > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d degraded machines", mcp.Object.Status.DegradedMachineCount))
+		}
+
+		if len(reasons) > 0 {
+			unstable = append(unstable, UnstableResource{
+				Check: StabilityCheckMCPs, Name: mcp.Object.Name, Reason: strings.Join(reasons, ", ")})
+		}
+	}
+
+	return unstable
+}
+
+// checkClusterOperators checks that every ClusterOperator is available, not progressing, and not degraded.
+func (gate *StabilityGate) checkClusterOperators() []UnstableResource {
+	operatorList, err := clusteroperator.List(gate.apiClient)
+	if err != nil {
+		return []UnstableResource{{
+			Check: StabilityCheckClusterOperators, Reason: fmt.Sprintf("failed to list ClusterOperators: %v", err)}}
+	}
+
+	expectedConditions := map[configv1.ClusterStatusConditionType]configv1.ConditionStatus{
+		configv1.OperatorAvailable:   configv1.ConditionTrue,
+		configv1.OperatorProgressing: configv1.ConditionFalse,
+		configv1.OperatorDegraded:    configv1.ConditionFalse,
+	}
+
+	var unstable []UnstableResource
+
+	for _, operator := range operatorList {
+		var reasons []string
+
+		for _, conditionType := range []configv1.ClusterStatusConditionType{
+			configv1.OperatorAvailable, configv1.OperatorProgressing, configv1.OperatorDegraded} {
+			status := configv1.ConditionUnknown
+			message := ""
+
+			for _, condition := range operator.Object.Status.Conditions {
+				if condition.Type == conditionType {
+					status = condition.Status
+					message = condition.Message
+				}
+			}
+
+			if status != expectedConditions[conditionType] {
+				reasons = append(reasons, strings.TrimSuffix(fmt.Sprintf("%s=%s: %s", conditionType, status, message), ": "))
+			}
+		}
+
+		if len(reasons) > 0 {
+			unstable = append(unstable, UnstableResource{
+				Check: StabilityCheckClusterOperators, Name: operator.Object.Name, Reason: strings.Join(reasons, ", ")})
+		}
+	}
+
+	return unstable
+}
+
+// checkCSRs checks that no CertificateSigningRequest is waiting to be approved or denied.
+func (gate *StabilityGate) checkCSRs(ctx context.Context) []UnstableResource {
+	if gate.apiClient.K8sClient == nil {
+		return []UnstableResource{{Check: StabilityCheckCSRs, Reason: "kubernetes client is not set"}}
+	}
+
+	csrList, err := gate.apiClient.K8sClient.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []UnstableResource{{
+			Check: StabilityCheckCSRs, Reason: fmt.Sprintf("failed to list CertificateSigningRequests: %v", err)}}
+	}
+
+	var unstable []UnstableResource
+
+	for _, csr := range csrList.Items {
+		decided := slices.ContainsFunc(csr.Status.Conditions, isCSRDecided)
+
+		if !decided {
+			unstable = append(unstable, UnstableResource{
+				Check: StabilityCheckCSRs, Name: csr.Name, Reason: fmt.Sprintf("pending for %s", csr.Spec.Username)})
+		}
+	}
+
+	return unstable
+}
+
+// checkPods checks that no container in the pod namespaces is crash looping or, while waiting, has restarted since the
+// previous check.
+func (gate *StabilityGate) checkPods() []UnstableResource {
+	var unstable []UnstableResource
+
+	for _, namespace := range gate.podNamespaces {
+		podList, err := pod.List(gate.apiClient, namespace)
+		if err != nil {
+			unstable = append(unstable, UnstableResource{
+				Check: StabilityCheckPods, Namespace: namespace, Reason: fmt.Sprintf("failed to list pods: %v", err)})
+
+			continue
+		}
+
+		for _, podBuilder := range podList {
+			reasons := gate.checkContainerStatuses(podBuilder.Object)
+			if len(reasons) > 0 {
+				unstable = append(unstable, UnstableResource{
+					Check:     StabilityCheckPods,
+					Namespace: namespace,
+					Name:      podBuilder.Object.Name,
+					Reason:    strings.Join(reasons, ", "),
+				})
+			}
+		}
+	}
+
+	return unstable
+}
+
+// checkContainerStatuses returns the reasons the containers of podObject are not stable, updating the restart counts
+// if the gate is waiting.
+func (gate *StabilityGate) checkContainerStatuses(podObject *corev1.Pod) []string {
+	var reasons []string
+
+	for _, status := range slices.Concat(podObject.Status.InitContainerStatuses, podObject.Status.ContainerStatuses) {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopBackOffReason {
+			reasons = append(reasons, fmt.Sprintf("container %s is in %s", status.Name, crashLoopBackOffReason))
+
+			continue
+		}
+
+		if gate.restartCounts == nil {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", podObject.Namespace, podObject.Name, status.Name)
+
+		previousCount, ok := gate.restartCounts[key]
+		if ok && status.RestartCount > previousCount {
+			reasons = append(reasons, fmt.Sprintf("container %s restarted %d times since the last check",
+				status.Name, status.RestartCount-previousCount))
+		}
+
+		gate.restartCounts[key] = status.RestartCount
+	}
+
+	return reasons
+}
+
+// isCSRDecided returns whether condition marks a CertificateSigningRequest as approved or denied.
+func isCSRDecided(condition certificatesv1.CertificateSigningRequestCondition) bool {
+	return condition.Type == certificatesv1.CertificateApproved || condition.Type == certificatesv1.CertificateDenied
+}
@@ -0,0 +1,265 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	configv1fake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const stabilityTestNamespace = "test-ns"
+
+func TestStabilityGateCheck(t *testing.T) {
+	testCases := []struct {
+		objects          []runtime.Object
+		operators        []runtime.Object
+		csrs             []*certificatesv1.CertificateSigningRequest
+		expectedUnstable []UnstableResource
+	}{
+		{
+			objects: []runtime.Object{
+				buildStabilityNode("node-1", corev1.ConditionTrue, false),
+				buildStabilityMCP("master", false),
+				buildStabilityPod("pod-1", ""),
+			},
+			operators:        []runtime.Object{buildStabilityOperator("dns", true)},
+			csrs:             []*certificatesv1.CertificateSigningRequest{buildStabilityCSR("csr-1", true)},
+			expectedUnstable: nil,
+		},
+		{
+			objects: []runtime.Object{
+				buildStabilityNode("node-1", corev1.ConditionFalse, false),
+				buildStabilityNode("node-2", corev1.ConditionTrue, true),
+				buildStabilityMCP("worker", true),
+				buildStabilityPod("pod-1", crashLoopBackOffReason),
+			},
+			operators: []runtime.Object{buildStabilityOperator("dns", false)},
+			csrs:      []*certificatesv1.CertificateSigningRequest{buildStabilityCSR("csr-1", false)},
+			expectedUnstable: []UnstableResource{
+				{Check: StabilityCheckNodes, Name: "node-1", Reason: "Ready=False: kubelet stopped"},
+				{Check: StabilityCheckNodes, Name: "node-2", Reason: "node is unschedulable"},
+				{
+					Check:  StabilityCheckMCPs,
+					Name:   "worker",
+					Reason: "Updated=False, Updating=True, Degraded=True, 1 degraded machines",
+				},
+				{
+					Check:  StabilityCheckClusterOperators,
+					Name:   "dns",
+					Reason: "Available=False: dns is down, Progressing=True, Degraded=Unknown",
+				},
+				{Check: StabilityCheckCSRs, Name: "csr-1", Reason: "pending for system:node:node-1"},
+				{
+					Check:     StabilityCheckPods,
+					Namespace: stabilityTestNamespace,
+					Name:      "pod-1",
+					Reason:    "container test is in CrashLoopBackOff",
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		apiClient := buildStabilityTestClient(t, testCase.objects, testCase.operators, testCase.csrs)
+
+		report := NewStabilityGate(apiClient).WithPodNamespaces(stabilityTestNamespace).Check(t.Context())
+		assert.Equal(t, testCase.expectedUnstable, report.Unstable)
+		assert.Equal(t, len(testCase.expectedUnstable) == 0, report.IsStable())
+	}
+}
+
+func TestStabilityGateWithChecks(t *testing.T) {
+	apiClient := buildStabilityTestClient(t, []runtime.Object{
+		buildStabilityNode("node-1", corev1.ConditionFalse, false),
+		buildStabilityPod("pod-1", crashLoopBackOffReason),
+	}, nil, nil)
+
+	report := NewStabilityGate(apiClient).
+		WithChecks(StabilityCheckPods).
+		WithPodNamespaces(stabilityTestNamespace).
+		Check(t.Context())
+	assert.Len(t, report.Unstable, 1)
+	assert.Equal(t, StabilityCheckPods, report.Unstable[0].Check)
+
+	report = NewStabilityGate(nil).Check(t.Context())
+	assert.False(t, report.IsStable())
+}
+
+func TestStabilityGateWait(t *testing.T) {
+	apiClient := buildStabilityTestClient(t, []runtime.Object{
+		buildStabilityNode("node-1", corev1.ConditionTrue, false),
+	}, nil, nil)
+
+	gate := NewStabilityGate(apiClient).
+		WithChecks(StabilityCheckAPI, StabilityCheckNodes).
+		WithSettleWindow(30 * time.Millisecond).
+		WithPollInterval(10 * time.Millisecond)
+
+	report, err := gate.Wait(t.Context(), 5*time.Second)
+	assert.Nil(t, err)
+	assert.True(t, report.IsStable())
+	assert.GreaterOrEqual(t, report.StableFor, 30*time.Millisecond)
+
+	// The cluster never stays stable for the settle window before the timeout.
+	report, err = gate.WithSettleWindow(time.Hour).Wait(t.Context(), 50*time.Millisecond)
+	assert.ErrorIs(t, err, ErrClusterUnstable)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, report.IsStable())
+	assert.Less(t, report.StableFor, time.Hour)
+}
+
+func TestStabilityGateWaitTimeout(t *testing.T) {
+	apiClient := buildStabilityTestClient(t, []runtime.Object{
+		buildStabilityNode("node-1", corev1.ConditionFalse, false),
+	}, nil, nil)
+
+	_, err := NewStabilityGate(apiClient).
+		WithChecks(StabilityCheckNodes).
+		WithPollInterval(10*time.Millisecond).
+		Wait(t.Context(), 50*time.Millisecond)
+	assert.ErrorIs(t, err, ErrClusterUnstable)
+
+	var stabilityErr *StabilityError
+
+	assert.True(t, errors.As(err, &stabilityErr))
+	assert.Equal(t, []UnstableResource{
+		{Check: StabilityCheckNodes, Name: "node-1", Reason: "Ready=False: kubelet stopped"},
+	}, stabilityErr.Report.Unstable)
+	assert.Contains(t, err.Error(), "nodes/node-1: Ready=False: kubelet stopped")
+}
+
+func TestStabilityGateRestarts(t *testing.T) {
+	testPod := buildStabilityPod("pod-1", "")
+	apiClient := buildStabilityTestClient(t, []runtime.Object{testPod}, nil, nil)
+
+	gate := NewStabilityGate(apiClient).WithPodNamespaces(stabilityTestNamespace)
+	gate.restartCounts = make(map[string]int32)
+
+	assert.Empty(t, gate.checkPods())
+
+	testPod.Status.ContainerStatuses[0].RestartCount = 2
+	_, err := apiClient.Pods(stabilityTestNamespace).UpdateStatus(t.Context(), testPod, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []UnstableResource{{
+		Check:     StabilityCheckPods,
+		Namespace: stabilityTestNamespace,
+		Name:      "pod-1",
+		Reason:    "container test restarted 2 times since the last check",
+	}}, gate.checkPods())
+	assert.Empty(t, gate.checkPods())
+}
+
+func buildStabilityTestClient(
+	t *testing.T,
+	objects, operators []runtime.Object,
+	csrs []*certificatesv1.CertificateSigningRequest) *clients.Settings {
+	t.Helper()
+
+	apiClient := clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects:  objects,
+		SchemeAttachers: []clients.SchemeAttacher{mcv1.Install},
+	})
+	apiClient.ConfigV1Interface = configv1fake.NewClientset(operators...).ConfigV1()
+
+	for _, csr := range csrs {
+		_, err := apiClient.K8sClient.CertificatesV1().CertificateSigningRequests().Create(
+			t.Context(), csr, metav1.CreateOptions{})
+		assert.Nil(t, err)
+	}
+
+	return apiClient
+}
+
+func buildStabilityNode(name string, ready corev1.ConditionStatus, unschedulable bool) *corev1.Node {
+	condition := corev1.NodeCondition{Type: corev1.NodeReady, Status: ready}
+	if ready != corev1.ConditionTrue {
+		condition.Message = "kubelet stopped"
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{condition}},
+	}
+}
+
+func buildStabilityMCP(name string, degraded bool) *mcv1.MachineConfigPool {
+	updated, updating := corev1.ConditionTrue, corev1.ConditionFalse
+	degradedStatus, degradedCount := corev1.ConditionFalse, int32(0)
+
+	if degraded {
+		updated, updating = corev1.ConditionFalse, corev1.ConditionTrue
+		degradedStatus, degradedCount = corev1.ConditionTrue, 1
+	}
+
+	return &mcv1.MachineConfigPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: mcv1.MachineConfigPoolStatus{
+			DegradedMachineCount: degradedCount,
+			Conditions: []mcv1.MachineConfigPoolCondition{
+				{Type: mcv1.MachineConfigPoolUpdated, Status: updated},
+				{Type: mcv1.MachineConfigPoolUpdating, Status: updating},
+				{Type: mcv1.MachineConfigPoolDegraded, Status: degradedStatus},
+			},
+		},
+	}
+}
+
+func buildStabilityOperator(name string, available bool) *configv1.ClusterOperator {
+	conditions := []configv1.ClusterOperatorStatusCondition{
+		{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+		{Type: configv1.OperatorProgressing, Status: configv1.ConditionFalse},
+		{Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse},
+	}
+
+	if !available {
+		conditions = []configv1.ClusterOperatorStatusCondition{
+			{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse, Message: name + " is down"},
+			{Type: configv1.OperatorProgressing, Status: configv1.ConditionTrue},
+		}
+	}
+
+	return &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     configv1.ClusterOperatorStatus{Conditions: conditions},
+	}
+}
+
+func buildStabilityCSR(name string, approved bool) *certificatesv1.CertificateSigningRequest {
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       certificatesv1.CertificateSigningRequestSpec{Username: "system:node:node-1"},
+	}
+
+	if approved {
+		csr.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{
+			{Type: certificatesv1.CertificateApproved, Status: corev1.ConditionTrue},
+		}
+	}
+
+	return csr
+}
+
+func buildStabilityPod(name, waitingReason string) *corev1.Pod {
+	status := corev1.ContainerStatus{Name: "test", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+
+	if waitingReason != "" {
+		status.State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason}}
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: stabilityTestNamespace},
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+	}
+}
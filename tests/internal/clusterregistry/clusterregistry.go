@@ -0,0 +1,343 @@
+package clusterregistry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clusterversion"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/infrastructure"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
+	"k8s.io/klog/v2"
+)
+
+// Role is the part a cluster plays in a test suite.
+type Role string
+
+const (
+	// RoleHub is a cluster managing other clusters, such as through ACM or the assisted service.
+	RoleHub Role = "hub"
+	// RoleSpoke is a cluster managed by a hub.
+	RoleSpoke Role = "spoke"
+	// RoleSeed is a cluster an image based upgrade or install seed image is generated from.
+	RoleSeed Role = "seed"
+	// RoleTarget is a cluster an image based upgrade or install is performed on.
+	RoleTarget Role = "target"
+)
+
+var (
+	// ErrInvalidName is returned when registering a cluster without a name.
+	ErrInvalidName = errors.New("cluster name cannot be empty")
+	// ErrInvalidRole is returned when registering a cluster with a role other than the ones defined in this package.
+	ErrInvalidRole = errors.New("invalid cluster role")
+	// ErrDuplicateName is returned when registering a cluster with the name of an already registered cluster.
+	ErrDuplicateName = errors.New("cluster already registered")
+	// ErrNotFound is returned when getting a cluster that has not been registered.
+	ErrNotFound = errors.New("cluster not registered")
+	// ErrAPIClient is returned when the API client of a cluster cannot be created from its kubeconfig.
+	ErrAPIClient = errors.New("failed to create API client")
+)
+
+// defaultRegistry is the registry used by the package level functions. It is loaded by inittools.Setup from the
+// general config and domain inittools packages register their clusters to it.
+var defaultRegistry = New()
+
+// Cluster is a named cluster in a Registry. Its API client is only created when first needed and its version and
+// topology are cached after first being retrieved. It is safe for concurrent use.
+type Cluster struct {
+	// Name uniquely identifies the cluster in its registry.
+	Name string
+	// Role is the part the cluster plays in the suite.
+	Role Role
+	// KubeconfigPath is the path to the kubeconfig used to create the API client.
+	KubeconfigPath string
+
+	mutex     sync.Mutex
+	apiClient *clients.Settings
+	version   string
+	topology  configv1.TopologyMode
+}
+
+// GetAPIClient returns the API client for the cluster, creating it from the kubeconfig the first time it is called.
+// Failures to create the client are not cached so later calls will try again. This implements the
+// cluster.APIClientGetter interface.
+func (cluster *Cluster) GetAPIClient() (*clients.Settings, error) {
+	cluster.mutex.Lock()
+	defer cluster.mutex.Unlock()
+
+	return cluster.getAPIClient()
+}
+
+// Version returns the desired OCP version from the clusterversion of the cluster. The version is cached after it is
+// first retrieved successfully.
+func (cluster *Cluster) Version() (string, error) {
+	cluster.mutex.Lock()
+	defer cluster.mutex.Unlock()
+
+	if cluster.version != "" {
+		return cluster.version, nil
+	}
+
+	apiClient, err := cluster.getAPIClient()
+	if err != nil {
+		return "", err
+	}
+
+	klog.V(90).Infof("Getting OCP version of cluster %s", cluster.Name)
+
+	clusterVersion, err := clusterversion.Pull(apiClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to get clusterversion of cluster %s: %w", cluster.Name, err)
+	}
+
+	cluster.version = clusterVersion.Object.Status.Desired.Version
+
+	return cluster.version, nil
+}
+
+// Topology returns the control plane topology from the infrastructure of the cluster. The topology is cached after it
+// is first retrieved successfully.
+func (cluster *Cluster) Topology() (configv1.TopologyMode, error) {
+	cluster.mutex.Lock()
+	defer cluster.mutex.Unlock()
+
+	if cluster.topology != "" {
+		return cluster.topology, nil
+	}
+
+	apiClient, err := cluster.getAPIClient()
+	if err != nil {
+		return "", err
+	}
+
+	klog.V(90).Infof("Getting control plane topology of cluster %s", cluster.Name)
+
+	infraConfig, err := infrastructure.Pull(apiClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to get infrastructure of cluster %s: %w", cluster.Name, err)
+	}
+
+	cluster.topology = infraConfig.Object.Status.ControlPlaneTopology
+
+	return cluster.topology, nil
+}
+
+// IsSNO returns whether the cluster is a Single Node OpenShift cluster based on its cached topology.
+func (cluster *Cluster) IsSNO() (bool, error) {
+	topology, err := cluster.Topology()
+	if err != nil {
+		return false, err
+	}
+
+	return topology == configv1.SingleReplicaTopologyMode, nil
+}
+
+// getAPIClient returns the API client, creating it if necessary. The mutex must be held by the caller.
+func (cluster *Cluster) getAPIClient() (*clients.Settings, error) {
+	if cluster.apiClient != nil {
+		return cluster.apiClient, nil
+	}
+
+	klog.V(90).Infof("Creating API client for cluster %s from %s", cluster.Name, cluster.KubeconfigPath)
+
+	apiClient := clients.New(cluster.KubeconfigPath)
+	if apiClient == nil {
+		return nil, fmt.Errorf("%w for cluster %s from %s", ErrAPIClient, cluster.Name, cluster.KubeconfigPath)
+	}
+
+	cluster.apiClient = apiClient
+
+	return cluster.apiClient, nil
+}
+
+// Registry holds clusters by name in the order they were registered. It is safe for concurrent use.
+type Registry struct {
+	mutex    sync.RWMutex
+	clusters []*Cluster
+}
+
+// New returns an empty Registry.
+func New() *Registry {
+	return &Registry{}
+}
+
+// Register adds a cluster whose API client is created from the kubeconfig at kubeconfigPath when first needed.
+func (registry *Registry) Register(name string, role Role, kubeconfigPath string) (*Cluster, error) {
+	return registry.add(&Cluster{Name: name, Role: role, KubeconfigPath: kubeconfigPath})
+}
+
+// RegisterClient adds a cluster with an already created API client, such as the ones created by domain configs or a
+// fake client in unit tests.
+func (registry *Registry) RegisterClient(name string, role Role, apiClient *clients.Settings) (*Cluster, error) {
+	if apiClient == nil {
+		return nil, fmt.Errorf("%w for cluster %s: apiClient cannot be nil", ErrAPIClient, name)
+	}
+
+	return registry.add(&Cluster{
+		Name:           name,
+		Role:           role,
+		KubeconfigPath: apiClient.KubeconfigPath,
+		apiClient:      apiClient,
+	})
+}
+
+// Load registers every cluster in clusterConfigs, stopping at the first one that fails to register.
+func (registry *Registry) Load(clusterConfigs []config.ClusterConfig) error {
+	for _, clusterConfig := range clusterConfigs {
+		_, err := registry.Register(clusterConfig.Name, Role(clusterConfig.Role), clusterConfig.Kubeconfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the cluster registered with name or an error wrapping ErrNotFound if there is none.
+func (registry *Registry) Get(name string) (*Cluster, error) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	index := slices.IndexFunc(registry.clusters, func(cluster *Cluster) bool {
+		return cluster.Name == name
+	})
+
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	return registry.clusters[index], nil
+}
+
+// Clusters returns all of the registered clusters in the order they were registered.
+func (registry *Registry) Clusters() []*Cluster {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	return slices.Clone(registry.clusters)
+}
+
+// ByRole returns the registered clusters with role in the order they were registered.
+func (registry *Registry) ByRole(role Role) []*Cluster {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	var clusters []*Cluster
+
+	for _, cluster := range registry.clusters {
+		if cluster.Role == role {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return clusters
+}
+
+// Reset removes all of the registered clusters.
+func (registry *Registry) Reset() {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.clusters = nil
+}
+
+// add validates cluster and appends it to the registry.
+func (registry *Registry) add(cluster *Cluster) (*Cluster, error) {
+	if cluster.Name == "" {
+		return nil, ErrInvalidName
+	}
+
+	if !slices.Contains([]Role{RoleHub, RoleSpoke, RoleSeed, RoleTarget}, cluster.Role) {
+		return nil, fmt.Errorf("%w %q for cluster %s", ErrInvalidRole, cluster.Role, cluster.Name)
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	for _, registered := range registry.clusters {
+		if registered.Name == cluster.Name {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateName, cluster.Name)
+		}
+	}
+
+	klog.V(90).Infof("Registering %s cluster %s", cluster.Role, cluster.Name)
+
+	registry.clusters = append(registry.clusters, cluster)
+
+	return cluster, nil
+}
+
+// Default returns the registry used by the package level functions.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a cluster to the default registry. See Registry.Register.
+func Register(name string, role Role, kubeconfigPath string) (*Cluster, error) {
+	return defaultRegistry.Register(name, role, kubeconfigPath)
+}
+
+// RegisterClient adds a cluster with an already created API client to the default registry. See
+// Registry.RegisterClient.
+func RegisterClient(name string, role Role, apiClient *clients.Settings) (*Cluster, error) {
+	return defaultRegistry.RegisterClient(name, role, apiClient)
+}
+
+// RegisterClientIfSet adds a cluster with an already created API client to the default registry if apiClient is not
+// nil and no cluster with name is registered yet. This allows domain configs to register optional clusters, such as a
+// second spoke, without checking each client while letting the clusters from the general config take precedence.
+func RegisterClientIfSet(name string, role Role, apiClient *clients.Settings) error {
+	if apiClient == nil {
+		return nil
+	}
+
+	_, err := defaultRegistry.RegisterClient(name, role, apiClient)
+	if errors.Is(err, ErrDuplicateName) {
+		klog.V(90).Infof("Cluster %s is already registered, keeping the registered one", name)
+
+		return nil
+	}
+
+	return err
+}
+
+// Get returns the cluster registered with name in the default registry. See Registry.Get.
+func Get(name string) (*Cluster, error) {
+	return defaultRegistry.Get(name)
+}
+
+// Clusters returns all of the clusters in the default registry. See Registry.Clusters.
+func Clusters() []*Cluster {
+	return defaultRegistry.Clusters()
+}
+
+// ByRole returns the clusters in the default registry with role. See Registry.ByRole.
+func ByRole(role Role) []*Cluster {
+	return defaultRegistry.ByRole(role)
+}
+
+// ResolveKubeconfig returns the absolute path of kubeconfig with symlinks resolved, defaulting to the KUBECONFIG
+// environment variable like clients.New does, so the same cluster is identified regardless of how its kubeconfig was
+// specified. If the path cannot be resolved, such as when the file does not exist, the absolute path is returned.
+func ResolveKubeconfig(kubeconfig string) string {
+	if kubeconfig == "" {
+		kubeconfig = os.Getenv("KUBECONFIG")
+	}
+
+	absKubeconfig, err := filepath.Abs(kubeconfig)
+	if err != nil {
+		return kubeconfig
+	}
+
+	resolvedKubeconfig, err := filepath.EvalSymlinks(absKubeconfig)
+	if err != nil {
+		return absKubeconfig
+	}
+
+	return resolvedKubeconfig
+}
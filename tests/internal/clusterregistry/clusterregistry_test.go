@@ -0,0 +1,222 @@
+package clusterregistry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          Role
+		expectedError error
+	}{
+		{
+			name:          "hub",
+			role:          RoleHub,
+			expectedError: nil,
+		},
+		{
+			name:          "",
+			role:          RoleHub,
+			expectedError: ErrInvalidName,
+		},
+		{
+			name:          "hub",
+			role:          Role("manager"),
+			expectedError: ErrInvalidRole,
+		},
+		{
+			name:          "spoke1",
+			role:          RoleSpoke,
+			expectedError: ErrDuplicateName,
+		},
+	}
+
+	for _, testCase := range testCases {
+		registry := New()
+
+		_, err := registry.Register("spoke1", RoleSpoke, "/spoke1/kubeconfig")
+		assert.Nil(t, err)
+
+		cluster, err := registry.Register(testCase.name, testCase.role, "/test/kubeconfig")
+		if testCase.expectedError == nil {
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.name, cluster.Name)
+			assert.Equal(t, testCase.role, cluster.Role)
+			assert.Equal(t, "/test/kubeconfig", cluster.KubeconfigPath)
+			assert.Len(t, registry.Clusters(), 2)
+		} else {
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.Nil(t, cluster)
+			assert.Len(t, registry.Clusters(), 1)
+		}
+	}
+}
+
+func TestRegistryRegisterClient(t *testing.T) {
+	registry := New()
+
+	_, err := registry.RegisterClient("hub", RoleHub, nil)
+	assert.ErrorIs(t, err, ErrAPIClient)
+
+	testClient := clients.GetTestClients(clients.TestClientParams{})
+
+	cluster, err := registry.RegisterClient("hub", RoleHub, testClient)
+	assert.Nil(t, err)
+
+	apiClient, err := cluster.GetAPIClient()
+	assert.Nil(t, err)
+	assert.Equal(t, testClient, apiClient)
+}
+
+func TestRegistryLookup(t *testing.T) {
+	registry := New()
+
+	err := registry.Load([]config.ClusterConfig{
+		{Name: "hub", Role: "hub", Kubeconfig: "/hub/kubeconfig"},
+		{Name: "spoke1", Role: "spoke", Kubeconfig: "/spoke1/kubeconfig"},
+		{Name: "spoke2", Role: "spoke", Kubeconfig: "/spoke2/kubeconfig"},
+	})
+	assert.Nil(t, err)
+
+	cluster, err := registry.Get("spoke2")
+	assert.Nil(t, err)
+	assert.Equal(t, "/spoke2/kubeconfig", cluster.KubeconfigPath)
+
+	_, err = registry.Get("spoke3")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []string{"hub", "spoke1", "spoke2"}, clusterNames(registry.Clusters()))
+	assert.Equal(t, []string{"spoke1", "spoke2"}, clusterNames(registry.ByRole(RoleSpoke)))
+	assert.Empty(t, registry.ByRole(RoleSeed))
+
+	err = registry.Load([]config.ClusterConfig{{Name: "hub", Role: "hub", Kubeconfig: "/other/kubeconfig"}})
+	assert.ErrorIs(t, err, ErrDuplicateName)
+
+	registry.Reset()
+	assert.Empty(t, registry.Clusters())
+}
+
+func TestClusterVersionAndTopology(t *testing.T) {
+	testCases := []struct {
+		topology    configv1.TopologyMode
+		expectedSNO bool
+	}{
+		{
+			topology:    configv1.SingleReplicaTopologyMode,
+			expectedSNO: true,
+		},
+		{
+			topology:    configv1.HighlyAvailableTopologyMode,
+			expectedSNO: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testClient := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects: []runtime.Object{
+				&configv1.ClusterVersion{
+					ObjectMeta: metav1.ObjectMeta{Name: "version"},
+					Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.20.1"}},
+				},
+				&configv1.Infrastructure{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+					Status:     configv1.InfrastructureStatus{ControlPlaneTopology: testCase.topology},
+				},
+			},
+			SchemeAttachers: []clients.SchemeAttacher{configv1.Install},
+		})
+
+		cluster, err := New().RegisterClient("spoke", RoleSpoke, testClient)
+		assert.Nil(t, err)
+
+		version, err := cluster.Version()
+		assert.Nil(t, err)
+		assert.Equal(t, "4.20.1", version)
+
+		isSNO, err := cluster.IsSNO()
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedSNO, isSNO)
+
+		// The cached values are returned even once the cluster can no longer be reached.
+		cluster.apiClient = clients.GetTestClients(clients.TestClientParams{})
+
+		version, err = cluster.Version()
+		assert.Nil(t, err)
+		assert.Equal(t, "4.20.1", version)
+
+		topology, err := cluster.Topology()
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.topology, topology)
+	}
+}
+
+func TestRegisterClientIfSet(t *testing.T) {
+	defaultRegistry.Reset()
+	defer defaultRegistry.Reset()
+
+	_, err := Register("hub", RoleHub, "/hub/kubeconfig")
+	assert.Nil(t, err)
+
+	err = RegisterClientIfSet("spoke", RoleSpoke, nil)
+	assert.Nil(t, err)
+	assert.Len(t, Clusters(), 1)
+
+	err = RegisterClientIfSet("hub", RoleHub, clients.GetTestClients(clients.TestClientParams{}))
+	assert.Nil(t, err)
+
+	hub, err := Get("hub")
+	assert.Nil(t, err)
+	assert.Equal(t, "/hub/kubeconfig", hub.KubeconfigPath)
+
+	err = RegisterClientIfSet("spoke", Role("manager"), clients.GetTestClients(clients.TestClientParams{}))
+	assert.ErrorIs(t, err, ErrInvalidRole)
+}
+
+func clusterNames(clusters []*Cluster) []string {
+	var names []string
+
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+
+	return names
+}
+
+func TestResolveKubeconfig(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+
+	kubeconfig := filepath.Join(tempDir, "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, []byte("apiVersion: v1\n"), 0o600))
+
+	symlink := filepath.Join(tempDir, "kubeconfig-link")
+	assert.NoError(t, os.Symlink(kubeconfig, symlink))
+
+	t.Chdir(tempDir)
+	t.Setenv("KUBECONFIG", symlink)
+
+	testCases := []struct {
+		kubeconfig string
+		expected   string
+	}{
+		{kubeconfig: kubeconfig, expected: kubeconfig},
+		{kubeconfig: "kubeconfig", expected: kubeconfig},
+		{kubeconfig: "./kubeconfig-link", expected: kubeconfig},
+		{kubeconfig: "", expected: kubeconfig},
+		{kubeconfig: "missing", expected: filepath.Join(tempDir, "missing")},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, ResolveKubeconfig(testCase.kubeconfig), testCase.kubeconfig)
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClusterConfig describes a cluster registered by name with the cluster registry, in addition to the one from the
+// KUBECONFIG environment variable.
+type ClusterConfig struct {
+	// Name uniquely identifies the cluster, such as hub or spoke1.
+	Name string `yaml:"name"`
+	// Role is the part the cluster plays in the suite: hub, spoke, seed, or target.
+	Role string `yaml:"role"`
+	// Kubeconfig is the path to the kubeconfig of the cluster.
+	Kubeconfig string `yaml:"kubeconfig"`
+}
+
+// ClusterConfigs is a list of clusters that can also be decoded from an environment variable.
+type ClusterConfigs []ClusterConfig
+
+// Decode implements the envconfig.Decoder interface. The value is a comma separated list of name:role:kubeconfig
+// entries, for example hub:hub:/path/to/hub/kubeconfig,spoke1:spoke:/path/to/spoke1/kubeconfig.
+func (clusterConfigs *ClusterConfigs) Decode(value string) error {
+	var decoded ClusterConfigs
+
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.SplitN(entry, ":", 3)
+		if len(fields) != 3 || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+			return fmt.Errorf("invalid cluster %q: expected name:role:kubeconfig", entry)
+		}
+
+		decoded = append(decoded, ClusterConfig{Name: fields[0], Role: fields[1], Kubeconfig: fields[2]})
+	}
+
+	*clusterConfigs = decoded
+
+	return nil
+}
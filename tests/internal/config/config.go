@@ -26,6 +26,8 @@ type GeneralConfig struct {
 	DryRun                    bool   `yaml:"dry_run" envconfig:"ECO_DRY_RUN"`
 	SSHKeyPath                string `envconfig:"ECO_SSH_KEY_PATH"`
 	SSHUser                   string `yaml:"ssh_user" envconfig:"ECO_SSH_USER"`
+	SSHKnownHostsPath         string `yaml:"ssh_known_hosts_path" envconfig:"ECO_SSH_KNOWN_HOSTS_PATH"`
+	SSHJumpHost               string `yaml:"ssh_jump_host" envconfig:"ECO_SSH_JUMP_HOST"`
 	KubernetesRolePrefix      string `yaml:"kubernetes_role_prefix" envconfig:"ECO_KUBERNETES_ROLE_PREFIX"`
 	WorkerLabelEnvVar         string `yaml:"worker_label" envconfig:"ECO_WORKER_LABEL"`
 	WorkerLabel               string
@@ -39,6 +41,11 @@ type GeneralConfig struct {
 	SriovFecOperatorNamespace string `yaml:"sriov_fec_operator_namespace" envconfig:"ECO_SRIOV_FEC_OPERATOR_NAMESPACE"`
 	WorkerLabelMap            map[string]string
 	ControlPlaneLabelMap      map[string]string
+	Clusters                  ClusterConfigs `yaml:"clusters" envconfig:"ECO_CLUSTERS"`
+	KeepResourcesOnFailure    bool           `yaml:"keep_resources_on_failure" envconfig:"ECO_KEEP_RESOURCES_ON_FAILURE"`
+	BundleFailedTests         bool           `yaml:"bundle_failed_tests" envconfig:"ECO_BUNDLE_FAILED_TESTS"`
+	FailedTestSpecBudgetMB    int64          `yaml:"failed_test_spec_budget_mb" envconfig:"ECO_FAILED_TEST_SPEC_BUDGET_MB"`
+	FailedTestRunBudgetMB     int64          `yaml:"failed_test_run_budget_mb" envconfig:"ECO_FAILED_TEST_RUN_BUDGET_MB"`
 }
 
 // NewConfig returns instance of GeneralConfig config type.
@@ -132,7 +139,12 @@ func readFile(cfg *GeneralConfig, cfgFile string) error {
 }
 
 func readEnv(cfg *GeneralConfig) error {
-	err := envconfig.Process("", cfg)
+	err := ReadProfiles(cfg)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", cfg)
 	if err != nil {
 		return err
 	}
@@ -1 +1,322 @@
 package config
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type testConfig struct {
+	*GeneralConfig
+	Namespace string            `yaml:"namespace" envconfig:"ECO_TEST_CONFIG_NAMESPACE"`
+	Image     string            `yaml:"image" envconfig:"ECO_TEST_CONFIG_IMAGE"`
+	Password  string            `yaml:"password" envconfig:"ECO_TEST_CONFIG_PASSWORD"`
+	Labels    map[string]string `yaml:"labels"`
+	Derived   string
+}
+
+func writeTestProfile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.Nil(t, err)
+
+	return path
+}
+
+func TestProfileFiles(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected []string
+	}{
+		{
+			value:    "",
+			expected: nil,
+		},
+		{
+			value:    "/a.yaml",
+			expected: []string{"/a.yaml"},
+		},
+		{
+			value:    " /a.yaml, ,/b.yaml ",
+			expected: []string{"/a.yaml", "/b.yaml"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Setenv(ConfigFileEnvVar, testCase.value)
+
+		assert.Equal(t, testCase.expected, ProfileFiles())
+	}
+}
+
+func TestReadProfiles(t *testing.T) {
+	testCases := []struct {
+		profiles          []string
+		env               map[string]string
+		expectedNamespace string
+		expectedImage     string
+		expectedError     bool
+	}{
+		{
+			profiles:          nil,
+			expectedNamespace: "default-ns",
+			expectedImage:     "default-image",
+		},
+		{
+			profiles:          []string{"namespace: lab-ns\nunrelated_key: value\n"},
+			expectedNamespace: "lab-ns",
+			expectedImage:     "default-image",
+		},
+		{
+			profiles:          []string{"namespace: lab-ns\nimage: lab-image\n", "namespace: override-ns\n"},
+			expectedNamespace: "override-ns",
+			expectedImage:     "lab-image",
+		},
+		{
+			profiles:          []string{"namespace: lab-ns\n"},
+			env:               map[string]string{"ECO_TEST_CONFIG_NAMESPACE": "env-ns"},
+			expectedNamespace: "env-ns",
+			expectedImage:     "default-image",
+		},
+		{
+			profiles:      []string{"namespace: [\n"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var files []string
+
+		for i, profile := range testCase.profiles {
+			files = append(files, writeTestProfile(t, fmt.Sprintf("profile-%d.yaml", i), profile))
+		}
+
+		t.Setenv(ConfigFileEnvVar, strings.Join(files, ","))
+
+		for key, value := range testCase.env {
+			t.Setenv(key, value)
+		}
+
+		testConf := &testConfig{Namespace: "default-ns", Image: "default-image"}
+
+		err := ReadProfiles(testConf)
+		if testCase.expectedError {
+			assert.NotNil(t, err)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+
+		err = envconfig.Process("", testConf)
+		assert.Nil(t, err)
+
+		assert.Equal(t, testCase.expectedNamespace, testConf.Namespace)
+		assert.Equal(t, testCase.expectedImage, testConf.Image)
+
+		for key := range testCase.env {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+// TestConfigLoadersReadProfiles checks that every function under ./tests that reads a config from the environment also
+// applies the profiles first, so a new config type cannot silently ignore ECO_CONFIG_FILE.
+func TestConfigLoadersReadProfiles(t *testing.T) {
+	testsDir := filepath.Join("..", "..")
+	fileSet := token.NewFileSet()
+	loaders := 0
+
+	err := filepath.WalkDir(testsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+
+		file, err := parser.ParseFile(fileSet, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+
+			readProfiles, process := findConfigCalls(funcDecl.Body)
+			if !process.IsValid() {
+				continue
+			}
+
+			loaders++
+
+			assert.Truef(t, readProfiles.IsValid() && readProfiles < process,
+				"%s: %s reads the environment with envconfig.Process without calling ReadProfiles first",
+				fileSet.Position(process), funcDecl.Name.Name)
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+
+	// Guards against the walk silently finding nothing if the directory layout changes.
+	assert.Greater(t, loaders, 1)
+}
+
+// findConfigCalls returns the positions of the first call to ReadProfiles and the first call to envconfig.Process in
+// body. Positions are invalid if there is no such call.
+func findConfigCalls(body *ast.BlockStmt) (readProfiles, process token.Pos) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		switch function := call.Fun.(type) {
+		case *ast.Ident:
+			if function.Name == "ReadProfiles" && !readProfiles.IsValid() {
+				readProfiles = call.Pos()
+			}
+		case *ast.SelectorExpr:
+			pkg, ok := function.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+
+			switch {
+			case function.Sel.Name == "ReadProfiles" && !readProfiles.IsValid():
+				readProfiles = call.Pos()
+			case pkg.Name == "envconfig" && function.Sel.Name == "Process" && !process.IsValid():
+				process = call.Pos()
+			}
+		}
+
+		return true
+	})
+
+	return readProfiles, process
+}
+
+func TestReadProfilesMissingFile(t *testing.T) {
+	t.Setenv(ConfigFileEnvVar, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	err := ReadProfiles(&testConfig{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDumpConfigs(t *testing.T) {
+	t.Setenv(ConfigFileEnvVar, "")
+
+	testConf := &testConfig{
+		GeneralConfig: &GeneralConfig{ReportsDirAbsPath: "/tmp/reports", SSHKeyPath: "/root/.ssh/id_rsa"},
+		Namespace:     "lab-ns",
+		Password:      "hunter2",
+		Labels:        map[string]string{"b": "2", "a": "1"},
+		Derived:       "not dumped",
+	}
+
+	err := ReadProfiles(testConf)
+	assert.Nil(t, err)
+
+	dumpPath := filepath.Join(t.TempDir(), "dump.yaml")
+
+	err = DumpConfigs(dumpPath)
+	assert.Nil(t, err)
+
+	content, err := os.ReadFile(dumpPath)
+	assert.Nil(t, err)
+
+	var dump map[string]map[string]any
+
+	err = yaml.Unmarshal(content, &dump)
+	assert.Nil(t, err)
+
+	assert.Contains(t, dump, "config.testConfig")
+
+	testDump := dump["config.testConfig"]
+	assert.Equal(t, "lab-ns", testDump["namespace"])
+	assert.Equal(t, redactedValue, testDump["password"])
+	assert.Equal(t, "/tmp/reports", testDump["reports_dump_dir"])
+	assert.Equal(t, "/root/.ssh/id_rsa", testDump["SSHKeyPath"])
+	assert.Equal(t, map[any]any{"a": "1", "b": "2"}, testDump["labels"])
+	assert.NotContains(t, testDump, "Derived")
+	assert.NotContains(t, string(content), "hunter2")
+}
+
+func TestGetConfigDumpPath(t *testing.T) {
+	generalConfig := &GeneralConfig{ReportsDirAbsPath: "/tmp/reports"}
+
+	assert.Equal(t, "/tmp/reports/ptp_config.yaml", generalConfig.GetConfigDumpPath("/repo/tests/cnf/ran/ptp"))
+}
+
+func TestClusterConfigsDecode(t *testing.T) {
+	testCases := []struct {
+		value          string
+		expectedConfig ClusterConfigs
+		expectedError  bool
+	}{
+		{
+			value:          "",
+			expectedConfig: nil,
+			expectedError:  false,
+		},
+		{
+			value: "hub:hub:/hub/kubeconfig, spoke1:spoke:/spoke1/kubeconfig",
+			expectedConfig: ClusterConfigs{
+				{Name: "hub", Role: "hub", Kubeconfig: "/hub/kubeconfig"},
+				{Name: "spoke1", Role: "spoke", Kubeconfig: "/spoke1/kubeconfig"},
+			},
+			expectedError: false,
+		},
+		{
+			value:          "seed:seed:C:/seed/kubeconfig",
+			expectedConfig: ClusterConfigs{{Name: "seed", Role: "seed", Kubeconfig: "C:/seed/kubeconfig"}},
+			expectedError:  false,
+		},
+		{
+			value:          "hub:/hub/kubeconfig",
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+		{
+			value:          "hub::/hub/kubeconfig",
+			expectedConfig: nil,
+			expectedError:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var clusterConfigs ClusterConfigs
+
+		err := clusterConfigs.Decode(testCase.value)
+		if testCase.expectedError {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err)
+		}
+
+		assert.Equal(t, testCase.expectedConfig, clusterConfigs)
+	}
+
+	t.Setenv("ECO_CLUSTERS", "target:target:/target/kubeconfig")
+
+	var generalConfig GeneralConfig
+
+	err := envconfig.Process("", &generalConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, ClusterConfigs{{Name: "target", Role: "target", Kubeconfig: "/target/kubeconfig"}},
+		generalConfig.Clusters)
+}
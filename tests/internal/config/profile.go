@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ConfigFileEnvVar is the environment variable listing the profile files layered on top of the defaults of every
+	// config. Multiple files are separated by commas and later files take precedence over earlier ones.
+	ConfigFileEnvVar = "ECO_CONFIG_FILE"
+	// redactedValue replaces the value of sensitive fields when dumping configs.
+	redactedValue = "REDACTED"
+)
+
+var (
+	// sensitiveFieldPattern matches the names, yaml keys, and environment variables of fields whose values are
+	// redacted when dumping configs.
+	sensitiveFieldPattern = regexp.MustCompile(`(?i)pass|secret|token|credential|private|auth`)
+
+	// loadedConfigs holds every config that has had profiles applied, keyed by type name, so they can be dumped at
+	// the start of the suite.
+	loadedConfigs      = make(map[string]any)
+	loadedConfigsMutex sync.Mutex
+)
+
+// ProfileFiles returns the profile files listed in the ECO_CONFIG_FILE environment variable, in the order they should
+// be applied. It returns nil if the variable is not set.
+func ProfileFiles() []string {
+	var files []string
+
+	for file := range strings.SplitSeq(os.Getenv(ConfigFileEnvVar), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// ReadProfiles decodes every profile file from ProfileFiles into cfg, which must be a pointer to a config struct. It
+// should be called after reading the default.yaml for cfg and before processing the environment variables so the
+// profiles override the defaults while environment variables still take precedence. Keys in a profile that do not
+// belong to cfg are ignored, allowing a single profile to contain the settings for all suites. The config is also
+// recorded so it is included by DumpConfigs.
+func ReadProfiles(cfg any) error {
+	for _, file := range ProfileFiles() {
+		err := readProfile(cfg, file)
+		if err != nil {
+			return fmt.Errorf("failed to read profile %s from %s: %w", file, ConfigFileEnvVar, err)
+		}
+	}
+
+	loadedConfigsMutex.Lock()
+	defer loadedConfigsMutex.Unlock()
+
+	loadedConfigs[strings.TrimPrefix(fmt.Sprintf("%T", cfg), "*")] = cfg
+
+	return nil
+}
+
+// DumpConfigs writes the current values of every config passed to ReadProfiles to the file at path as yaml, keyed by
+// type name. Values of fields whose name, yaml key, or environment variable looks sensitive are redacted. Only fields
+// with a yaml or envconfig tag are included, along with embedded structs, so clients and other derived fields are
+// omitted.
+func DumpConfigs(path string) error {
+	loadedConfigsMutex.Lock()
+
+	names := slices.Sorted(maps.Keys(loadedConfigs))
+
+	var dump yaml.MapSlice
+
+	for _, name := range names {
+		dump = append(dump, yaml.MapItem{Key: name, Value: redactValue(reflect.ValueOf(loadedConfigs[name]))})
+	}
+
+	loadedConfigsMutex.Unlock()
+
+	content, err := yaml.Marshal(dump)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+// GetConfigDumpPath returns full path to the file where the effective configs are dumped for the suite in suitePath.
+func (cfg *GeneralConfig) GetConfigDumpPath(suitePath string) string {
+	return fmt.Sprintf("%s_config.yaml", filepath.Join(cfg.ReportsDirAbsPath, filepath.Base(suitePath)))
+}
+
+func readProfile(cfg any, file string) error {
+	openedFile, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = openedFile.Close()
+	}()
+
+	err = yaml.NewDecoder(openedFile).Decode(cfg)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// redactValue converts value into a form that can be marshaled to yaml with the sensitive fields of all structs
+// redacted. Nil pointers become nil and structs become ordered mappings.
+func redactValue(value reflect.Value) any {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+
+		return redactValue(value.Elem())
+	case reflect.Struct:
+		return redactStruct(value)
+	case reflect.Slice, reflect.Array:
+		var items []any
+
+		for i := range value.Len() {
+			items = append(items, redactValue(value.Index(i)))
+		}
+
+		return items
+	case reflect.Map:
+		keys := value.MapKeys()
+		slices.SortFunc(keys, func(keyA, keyB reflect.Value) int {
+			return strings.Compare(fmt.Sprint(keyA), fmt.Sprint(keyB))
+		})
+
+		var items yaml.MapSlice
+
+		for _, key := range keys {
+			items = append(items, yaml.MapItem{Key: fmt.Sprint(key), Value: redactValue(value.MapIndex(key))})
+		}
+
+		return items
+	default:
+		if !value.CanInterface() {
+			return nil
+		}
+
+		if duration, ok := value.Interface().(time.Duration); ok {
+			return duration.String()
+		}
+
+		return value.Interface()
+	}
+}
+
+// redactStruct converts the tagged and embedded fields of a struct into an ordered mapping, inlining the fields of
+// embedded structs and replacing the values of sensitive fields.
+func redactStruct(value reflect.Value) yaml.MapSlice {
+	var items yaml.MapSlice
+
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			if embedded, ok := redactValue(value.Field(i)).(yaml.MapSlice); ok {
+				items = append(items, embedded...)
+			}
+
+			continue
+		}
+
+		yamlKey, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		envVar := field.Tag.Get("envconfig")
+
+		if yamlKey == "-" || (yamlKey == "" && envVar == "") {
+			continue
+		}
+
+		key := yamlKey
+		if key == "" {
+			key = field.Name
+		}
+
+		if sensitiveFieldPattern.MatchString(field.Name+" "+yamlKey+" "+envVar) && !value.Field(i).IsZero() {
+			items = append(items, yaml.MapItem{Key: key, Value: redactedValue})
+
+			continue
+		}
+
+		items = append(items, yaml.MapItem{Key: key, Value: redactValue(value.Field(i))})
+	}
+
+	return items
+}
@@ -0,0 +1,160 @@
+// Package execlog records the commands executed in pods and on nodes during a spec so that the transcript can be saved
+// alongside the rest of the report when the spec fails. Transcripts are kept per Ginkgo process, so specs running in
+// parallel processes never share one.
+package execlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Entry is a single command executed during a spec.
+type Entry struct {
+	// Target describes where the command ran, for example pod ns/name/container or node worker-0.
+	Target   string
+	Command  string
+	Start    time.Time
+	Duration time.Duration
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	// Err is the error executing the command, if any. Non-zero exit codes are not errors.
+	Err error
+}
+
+// Transcript is a concurrency-safe record of the commands executed during a spec.
+type Transcript struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+// NewTranscript returns an empty transcript.
+func NewTranscript() *Transcript {
+	return &Transcript{}
+}
+
+// Record appends entry to the transcript.
+func (transcript *Transcript) Record(entry Entry) {
+	if transcript == nil {
+		return
+	}
+
+	transcript.mutex.Lock()
+	defer transcript.mutex.Unlock()
+
+	writeEntry(&transcript.buffer, entry)
+}
+
+// String returns the contents of the transcript.
+func (transcript *Transcript) String() string {
+	if transcript == nil {
+		return ""
+	}
+
+	transcript.mutex.Lock()
+	defer transcript.mutex.Unlock()
+
+	return transcript.buffer.String()
+}
+
+// WriteFile writes the transcript to path. Nothing is written if the transcript is empty.
+func (transcript *Transcript) WriteFile(path string) error {
+	content := transcript.String()
+	if content == "" {
+		return nil
+	}
+
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write exec transcript to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+type contextKey struct{}
+
+// current is the transcript of the spec currently running in this process. Ginkgo runs one spec at a time per
+// process, so this is never shared between specs running in parallel.
+var current atomic.Pointer[Transcript]
+
+// NewContext returns a copy of ctx that records commands executed with it to transcript instead of the transcript of
+// the current spec.
+func NewContext(ctx context.Context, transcript *Transcript) context.Context {
+	return context.WithValue(ctx, contextKey{}, transcript)
+}
+
+// FromContext returns the transcript attached to ctx, falling back to the transcript of the current spec. It returns
+// nil if neither exists.
+func FromContext(ctx context.Context) *Transcript {
+	if ctx != nil {
+		if transcript, ok := ctx.Value(contextKey{}).(*Transcript); ok {
+			return transcript
+		}
+	}
+
+	return current.Load()
+}
+
+// StartSpec replaces the transcript of the current spec with a new one and returns it. It is called before every spec
+// by the reporter package.
+func StartSpec() *Transcript {
+	transcript := NewTranscript()
+	current.Store(transcript)
+
+	return transcript
+}
+
+// Current returns the transcript of the current spec, or nil if StartSpec has not been called.
+func Current() *Transcript {
+	return current.Load()
+}
+
+// Record logs entry using the logger from ctx and appends it to the transcript from FromContext, if any.
+func Record(ctx context.Context, entry Entry) {
+	logger := klog.FromContext(ctx).V(90)
+	if logger.Enabled() {
+		logger.Info("Executed command", "target", entry.Target, "command", entry.Command,
+			"exitCode", entry.ExitCode, "duration", entry.Duration, "error", entry.Err)
+	}
+
+	FromContext(ctx).Record(entry)
+}
+
+// writeEntry writes entry to writer in the human readable transcript format.
+func writeEntry(writer io.Writer, entry Entry) {
+	_, _ = fmt.Fprintf(writer, "=== %s %s (%s)\n$ %s\n",
+		entry.Start.UTC().Format(time.RFC3339Nano), entry.Target, entry.Duration.Round(time.Millisecond), entry.Command)
+
+	if entry.Err != nil {
+		_, _ = fmt.Fprintf(writer, "error: %v\n", entry.Err)
+	} else {
+		_, _ = fmt.Fprintf(writer, "exit code: %d\n", entry.ExitCode)
+	}
+
+	writeStream(writer, "stdout", entry.Stdout)
+	writeStream(writer, "stderr", entry.Stderr)
+	_, _ = fmt.Fprintln(writer)
+}
+
+// writeStream writes the output of a command under a header, making sure it ends with a newline.
+func writeStream(writer io.Writer, name, output string) {
+	if output == "" {
+		return
+	}
+
+	_, _ = fmt.Fprintf(writer, "--- %s ---\n%s", name, output)
+
+	if !strings.HasSuffix(output, "\n") {
+		_, _ = fmt.Fprintln(writer)
+	}
+}
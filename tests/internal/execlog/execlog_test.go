@@ -0,0 +1,103 @@
+package execlog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscriptRecord(t *testing.T) {
+	start := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		entry    Entry
+		expected string
+	}{
+		{
+			entry: Entry{
+				Target:   "pod test-ns/test-pod/test-container",
+				Command:  "cat /etc/hostname",
+				Start:    start,
+				Duration: 1500 * time.Millisecond,
+				Stdout:   "node-1\n",
+			},
+			expected: "=== 2026-01-02T03:04:05Z pod test-ns/test-pod/test-container (1.5s)\n$ cat /etc/hostname\n" +
+				"exit code: 0\n--- stdout ---\nnode-1\n\n",
+		},
+		{
+			entry: Entry{
+				Target:   "node node-1 over ssh",
+				Command:  "false",
+				Start:    start,
+				ExitCode: 1,
+				Stdout:   "out",
+				Stderr:   "err",
+			},
+			expected: "=== 2026-01-02T03:04:05Z node node-1 over ssh (0s)\n$ false\n" +
+				"exit code: 1\n--- stdout ---\nout\n--- stderr ---\nerr\n\n",
+		},
+		{
+			entry:    Entry{Target: "node node-1 over ssh", Command: "true", Start: start, Err: errors.New("timed out")},
+			expected: "=== 2026-01-02T03:04:05Z node node-1 over ssh (0s)\n$ true\nerror: timed out\n\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		transcript := NewTranscript()
+		transcript.Record(testCase.entry)
+
+		assert.Equal(t, testCase.expected, transcript.String())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	t.Cleanup(func() { current.Store(nil) })
+
+	current.Store(nil)
+	assert.Nil(t, FromContext(context.Background()))
+
+	// Recording without any transcript must not panic.
+	Record(context.Background(), Entry{Command: "true"})
+
+	specTranscript := StartSpec()
+	assert.Same(t, specTranscript, Current())
+	assert.Same(t, specTranscript, FromContext(context.Background()))
+
+	contextTranscript := NewTranscript()
+	ctx := NewContext(context.Background(), contextTranscript)
+	assert.Same(t, contextTranscript, FromContext(ctx))
+
+	Record(ctx, Entry{Command: "in context"})
+	Record(context.Background(), Entry{Command: "in spec"})
+
+	assert.Contains(t, contextTranscript.String(), "$ in context\n")
+	assert.NotContains(t, contextTranscript.String(), "in spec")
+	assert.Contains(t, specTranscript.String(), "$ in spec\n")
+
+	// Starting the next spec must not carry over the commands of the previous one.
+	assert.Empty(t, StartSpec().String())
+}
+
+func TestTranscriptWriteFile(t *testing.T) {
+	transcriptPath := filepath.Join(t.TempDir(), "pod_exec_logs.log")
+
+	assert.Nil(t, NewTranscript().WriteFile(transcriptPath))
+	assert.NoFileExists(t, transcriptPath)
+
+	var nilTranscript *Transcript
+	assert.Nil(t, nilTranscript.WriteFile(transcriptPath))
+
+	transcript := NewTranscript()
+	transcript.Record(Entry{Target: "node node-1 over ssh", Command: "true"})
+
+	assert.Nil(t, transcript.WriteFile(transcriptPath))
+
+	content, err := os.ReadFile(transcriptPath)
+	assert.Nil(t, err)
+	assert.Equal(t, transcript.String(), string(content))
+}
@@ -0,0 +1,364 @@
+// Package failurebundle writes the artifacts collected for failed specs, such as cluster dumps, system reports, and
+// must-gathers. Every artifact is redacted before it is written and the total size written is limited by a budget per
+// spec and per run. Artifacts are either bundled into a single zstd compressed tarball per spec with a manifest or, for
+// compatibility, written as loose files.
+package failurebundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Extension is the file extension of bundles.
+	Extension = ".tar.zst"
+	// ManifestName is the name of the manifest at the root of every bundle.
+	ManifestName = "manifest.json"
+)
+
+// binaryExtensions are the extensions of artifacts that are never text, regardless of their content.
+var binaryExtensions = []string{".tar", ".gz", ".tgz", ".zst", ".zip"}
+
+var (
+	// ErrBudgetExceeded is returned when an artifact was truncated or dropped because a size budget was exhausted.
+	ErrBudgetExceeded = errors.New("failure bundle size budget exceeded")
+	// ErrClosed is returned when adding an artifact to a closed bundle.
+	ErrClosed = errors.New("failure bundle is closed")
+)
+
+// Writer is where the artifacts of a failed spec are written to.
+type Writer interface {
+	// Add redacts content and writes it as name, a slash separated path relative to the root of the writer. If a size
+	// budget does not allow all of content to be written, text is truncated and anything else, such as a tarball, is
+	// dropped, and an error wrapping ErrBudgetExceeded is returned.
+	Add(name string, content []byte) error
+}
+
+// Budget limits the total size of the artifacts written by the bundles sharing it. Sizes are counted after redaction
+// and before compression. It is safe for concurrent use.
+type Budget struct {
+	mutex sync.Mutex
+	limit int64
+	used  int64
+}
+
+// NewBudget returns a budget allowing limit bytes to be written. A limit of zero or less is unlimited.
+func NewBudget(limit int64) *Budget {
+	return &Budget{limit: limit}
+}
+
+// Used returns how many bytes have been written under the budget.
+func (budget *Budget) Used() int64 {
+	if budget == nil {
+		return 0
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	return budget.used
+}
+
+// reserve records that up to size bytes will be written and returns how many of them the budget allows. If partial is
+// false, either all of size is allowed or none of it is. A nil budget is unlimited.
+func (budget *Budget) reserve(size int64, partial bool) int64 {
+	if budget == nil {
+		return size
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	if budget.limit > 0 && size > budget.limit-budget.used {
+		size = 0
+		if partial {
+			size = max(budget.limit-budget.used, 0)
+		}
+	}
+
+	budget.used += size
+
+	return size
+}
+
+// ManifestFile describes a single artifact in a bundle.
+type ManifestFile struct {
+	Name string `json:"name"`
+	// Size is the number of bytes written, after redaction and truncation. Only text artifacts are truncated.
+	Size int64 `json:"size"`
+	// OriginalSize is the size of the artifact before redaction and truncation.
+	OriginalSize int64  `json:"originalSize"`
+	SHA256       string `json:"sha256"`
+	Redactions   int    `json:"redactions,omitempty"`
+	Truncated    bool   `json:"truncated,omitempty"`
+}
+
+// Manifest lists the artifacts in a bundle. It is written as ManifestName when the bundle is closed.
+type Manifest struct {
+	Spec      string         `json:"spec"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Files     []ManifestFile `json:"files"`
+	// Dropped lists the artifacts that were not written at all, either because a budget was exhausted or because they
+	// are not text, such as tarballs, and did not fit in what was left of a budget.
+	Dropped    []string `json:"dropped,omitempty"`
+	SpecBudget int64    `json:"specBudget,omitempty"`
+	Size       int64    `json:"size"`
+}
+
+// limiter redacts artifacts and applies the budgets for a single spec.
+type limiter struct {
+	specBudget int64
+	runBudget  *Budget
+	specUsed   int64
+}
+
+// prepare redacts content and truncates it to what the budgets allow, returning the bytes to write and their
+// manifest entry. Artifacts that are not text are never truncated, since a truncated tarball or binary cannot be read,
+// so they are either written in full or not at all. It must be called with the lock of the writer held.
+func (limiter *limiter) prepare(name string, content []byte) ([]byte, ManifestFile, error) {
+	redacted, redactions := Redact(name, content)
+	size := int64(len(redacted))
+	partial := isText(name, redacted)
+
+	if limiter.specBudget > 0 && size > limiter.specBudget-limiter.specUsed {
+		size = 0
+		if partial {
+			size = max(limiter.specBudget-limiter.specUsed, 0)
+		}
+	}
+
+	size = limiter.runBudget.reserve(size, partial)
+	limiter.specUsed += size
+
+	checksum := sha256.Sum256(redacted[:size])
+	file := ManifestFile{
+		Name:         name,
+		Size:         size,
+		OriginalSize: int64(len(content)),
+		SHA256:       hex.EncodeToString(checksum[:]),
+		Redactions:   redactions,
+		Truncated:    size > 0 && size < int64(len(redacted)),
+	}
+
+	if size < int64(len(redacted)) {
+		return redacted[:size], file,
+			fmt.Errorf("%w: wrote %d of %d bytes of %s", ErrBudgetExceeded, size, len(redacted), name)
+	}
+
+	return redacted, file, nil
+}
+
+// isText returns whether the artifact name with the given content is text, which may be truncated. Archives and
+// compressed files are detected by their extension and anything else by its content.
+func isText(name string, content []byte) bool {
+	for _, extension := range binaryExtensions {
+		if strings.HasSuffix(name, extension) {
+			return false
+		}
+	}
+
+	return utf8.Valid(content) && !bytes.ContainsRune(content, 0)
+}
+
+// Bundle writes the artifacts of a single spec to a zstd compressed tarball. It is safe for concurrent use.
+type Bundle struct {
+	mutex     sync.Mutex
+	path      string
+	file      *os.File
+	encoder   *zstd.Encoder
+	tarWriter *tar.Writer
+	limiter   limiter
+	manifest  Manifest
+	closed    bool
+}
+
+var _ Writer = (*Bundle)(nil)
+
+// Create creates the bundle for spec at bundlePath, creating its directory if needed. The artifacts of the bundle are
+// limited to specBudget bytes, if greater than zero, and count towards runBudget, which may be nil.
+func Create(bundlePath, spec string, specBudget int64, runBudget *Budget) (*Bundle, error) {
+	err := os.MkdirAll(filepath.Dir(bundlePath), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for bundle %s: %w", bundlePath, err)
+	}
+
+	file, err := os.Create(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle %s: %w", bundlePath, err)
+	}
+
+	encoder, err := zstd.NewWriter(file)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to create zstd encoder for bundle %s: %w", bundlePath, err)
+	}
+
+	return &Bundle{
+		path:      bundlePath,
+		file:      file,
+		encoder:   encoder,
+		tarWriter: tar.NewWriter(encoder),
+		limiter:   limiter{specBudget: specBudget, runBudget: runBudget},
+		manifest:  Manifest{Spec: spec, CreatedAt: time.Now().UTC(), Files: []ManifestFile{}, SpecBudget: specBudget},
+	}, nil
+}
+
+// Path returns the path of the bundle file.
+func (bundle *Bundle) Path() string {
+	return bundle.path
+}
+
+// Add redacts content and adds it to the bundle as name.
+func (bundle *Bundle) Add(name string, content []byte) error {
+	bundle.mutex.Lock()
+	defer bundle.mutex.Unlock()
+
+	if bundle.closed {
+		return ErrClosed
+	}
+
+	name = path.Clean(name)
+
+	data, file, budgetErr := bundle.limiter.prepare(name, content)
+	if file.Size == 0 && file.OriginalSize > 0 {
+		bundle.manifest.Dropped = append(bundle.manifest.Dropped, name)
+
+		return budgetErr
+	}
+
+	err := bundle.writeEntry(name, data)
+	if err != nil {
+		return err
+	}
+
+	bundle.manifest.Files = append(bundle.manifest.Files, file)
+	bundle.manifest.Size += file.Size
+
+	return budgetErr
+}
+
+// Manifest returns a copy of the manifest of the bundle so far.
+func (bundle *Bundle) Manifest() Manifest {
+	bundle.mutex.Lock()
+	defer bundle.mutex.Unlock()
+
+	manifest := bundle.manifest
+	manifest.Files = append([]ManifestFile{}, bundle.manifest.Files...)
+	manifest.Dropped = append([]string(nil), bundle.manifest.Dropped...)
+
+	return manifest
+}
+
+// Close writes the manifest and closes the bundle. Closing a bundle more than once has no effect.
+func (bundle *Bundle) Close() error {
+	bundle.mutex.Lock()
+	defer bundle.mutex.Unlock()
+
+	if bundle.closed {
+		return nil
+	}
+
+	bundle.closed = true
+
+	manifest, err := json.MarshalIndent(bundle.manifest, "", "  ")
+	if err != nil {
+		err = fmt.Errorf("failed to marshal manifest of bundle %s: %w", bundle.path, err)
+	} else {
+		err = bundle.writeEntry(ManifestName, manifest)
+	}
+
+	return errors.Join(err, bundle.tarWriter.Close(), bundle.encoder.Close(), bundle.file.Close())
+}
+
+// writeEntry writes a regular file to the tarball. It must be called with the lock held.
+func (bundle *Bundle) writeEntry(name string, data []byte) error {
+	err := bundle.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     0644,
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write header of %s to bundle %s: %w", name, bundle.path, err)
+	}
+
+	_, err = bundle.tarWriter.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s to bundle %s: %w", name, bundle.path, err)
+	}
+
+	return nil
+}
+
+// Directory writes artifacts as loose files under a root directory, applying the same redaction and budgets as
+// Bundle. It is safe for concurrent use.
+type Directory struct {
+	mutex   sync.Mutex
+	root    string
+	limiter limiter
+}
+
+var _ Writer = (*Directory)(nil)
+
+// NewDirectory returns a Directory writing to root with the same budgets as Create.
+func NewDirectory(root string, specBudget int64, runBudget *Budget) *Directory {
+	return &Directory{root: root, limiter: limiter{specBudget: specBudget, runBudget: runBudget}}
+}
+
+// Add redacts content and writes it to name under the root directory, creating any missing directories.
+func (directory *Directory) Add(name string, content []byte) error {
+	directory.mutex.Lock()
+	defer directory.mutex.Unlock()
+
+	data, file, budgetErr := directory.limiter.prepare(name, content)
+	if file.Size == 0 && file.OriginalSize > 0 {
+		return budgetErr
+	}
+
+	filePath := filepath.Join(directory.root, filepath.FromSlash(path.Clean(name)))
+
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+	}
+
+	err = os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+
+	return budgetErr
+}
+
+// WithPrefix returns a Writer that adds artifacts to writer with prefix joined to their names.
+//
+//nolint:ireturn // the prefixed writer wraps any Writer, so it is returned as one too.
+func WithPrefix(writer Writer, prefix string) Writer {
+	return prefixedWriter{writer: writer, prefix: prefix}
+}
+
+type prefixedWriter struct {
+	writer Writer
+	prefix string
+}
+
+// Add adds content to the wrapped writer as name under the prefix.
+func (writer prefixedWriter) Add(name string, content []byte) error {
+	return writer.writer.Add(path.Join(writer.prefix, name), content)
+}
@@ -0,0 +1,200 @@
+package failurebundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "reports", "failed_spec"+Extension)
+
+	bundle, err := Create(bundlePath, "failed spec", 0, nil)
+	assert.Nil(t, err)
+
+	writer := WithPrefix(bundle, "failed_suite/failed_spec")
+	assert.Nil(t, writer.Add("nodes.log", []byte("nodes")))
+	assert.Nil(t, writer.Add("system/node-1_uptime", []byte("Authorization: Bearer abc.def-ghi")))
+	assert.Nil(t, bundle.Close())
+	assert.Nil(t, bundle.Close())
+	assert.ErrorIs(t, bundle.Add("late.log", []byte("late")), ErrClosed)
+
+	files := readTestBundle(t, bundlePath)
+	assert.Equal(t, "nodes", files["failed_suite/failed_spec/nodes.log"])
+	assert.Equal(t, "Authorization: Bearer REDACTED", files["failed_suite/failed_spec/system/node-1_uptime"])
+
+	var manifest Manifest
+
+	assert.Nil(t, json.Unmarshal([]byte(files[ManifestName]), &manifest))
+	assert.Equal(t, "failed spec", manifest.Spec)
+	assert.Equal(t, int64(35), manifest.Size)
+
+	if assert.Len(t, manifest.Files, 2) {
+		assert.Equal(t, "failed_suite/failed_spec/nodes.log", manifest.Files[0].Name)
+		assert.Equal(t, int64(5), manifest.Files[0].Size)
+		assert.Equal(t, 1, manifest.Files[1].Redactions)
+		assert.Equal(t, int64(33), manifest.Files[1].OriginalSize)
+		assert.Equal(t, int64(30), manifest.Files[1].Size)
+	}
+}
+
+func TestBundleBudgets(t *testing.T) {
+	runBudget := NewBudget(15)
+
+	firstBundle, err := Create(filepath.Join(t.TempDir(), "first"+Extension), "first", 8, runBudget)
+	assert.Nil(t, err)
+
+	assert.Nil(t, firstBundle.Add("a.log", []byte("12345")))
+
+	// Only 3 bytes are left in the spec budget.
+	err = firstBundle.Add("b.log", []byte("12345"))
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	err = firstBundle.Add("c.log", []byte("12345"))
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	manifest := firstBundle.Manifest()
+	assert.Equal(t, int64(8), manifest.Size)
+	assert.Equal(t, []string{"c.log"}, manifest.Dropped)
+
+	if assert.Len(t, manifest.Files, 2) {
+		assert.True(t, manifest.Files[1].Truncated)
+		assert.Equal(t, int64(3), manifest.Files[1].Size)
+	}
+
+	assert.Nil(t, firstBundle.Close())
+
+	// The second bundle has its own spec budget but shares the 7 bytes left in the run budget.
+	secondBundle, err := Create(filepath.Join(t.TempDir(), "second"+Extension), "second", 8, runBudget)
+	assert.Nil(t, err)
+
+	err = secondBundle.Add("a.log", []byte("12345678"))
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, int64(7), secondBundle.Manifest().Size)
+	assert.Equal(t, int64(15), runBudget.Used())
+	assert.Nil(t, secondBundle.Close())
+
+	files := readTestBundle(t, secondBundle.Path())
+	assert.Equal(t, "1234567", files["a.log"])
+}
+
+func TestBundleBinaryBudgets(t *testing.T) {
+	smallTar := buildTestTar(t, map[string]string{"small.log": "small"})
+	largeTar := buildTestTar(t, map[string]string{"large.log": strings.Repeat("large", 1000)})
+
+	bundle, err := Create(filepath.Join(t.TempDir(), "binary"+Extension), "binary", int64(len(smallTar))+10, nil)
+	assert.Nil(t, err)
+
+	// Tarballs and binary artifacts that do not fit are dropped instead of being truncated into corrupt files.
+	err = bundle.Add("ptp-must-gather.tar", largeTar)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	assert.Nil(t, bundle.Add("must-gather.tar", smallTar))
+
+	err = bundle.Add("core.bin", []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	// Text is still truncated to what is left.
+	err = bundle.Add("nodes.log", []byte("12345678901"))
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	manifest := bundle.Manifest()
+	assert.Equal(t, []string{"ptp-must-gather.tar", "core.bin"}, manifest.Dropped)
+
+	if assert.Len(t, manifest.Files, 2) {
+		assert.Equal(t, "must-gather.tar", manifest.Files[0].Name)
+		assert.False(t, manifest.Files[0].Truncated)
+		assert.Equal(t, "nodes.log", manifest.Files[1].Name)
+		assert.True(t, manifest.Files[1].Truncated)
+		assert.Equal(t, int64(10), manifest.Files[1].Size)
+	}
+
+	assert.Nil(t, bundle.Close())
+
+	files := readTestBundle(t, bundle.Path())
+	assert.Equal(t, string(smallTar), files["must-gather.tar"])
+	assert.Equal(t, "1234567890", files["nodes.log"])
+}
+
+func TestDirectory(t *testing.T) {
+	root := t.TempDir()
+	directory := NewDirectory(root, 10, nil)
+
+	err := WithPrefix(directory, "failed_suite/spec").Add("system/token", []byte("sha256~abcdefghijklmnopqrstuvwxyz"))
+	assert.Nil(t, err)
+
+	err = directory.Add("too-big.log", []byte("12345678901"))
+	assert.True(t, errors.Is(err, ErrBudgetExceeded))
+
+	content, err := os.ReadFile(filepath.Join(root, "failed_suite", "spec", "system", "token"))
+	assert.Nil(t, err)
+	assert.Equal(t, "REDACTED", string(content))
+
+	content, err = os.ReadFile(filepath.Join(root, "too-big.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, "12", string(content))
+}
+
+// buildTestTar returns a tarball holding files, keyed by their name in the tarball.
+func buildTestTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	writer := tar.NewWriter(&buffer)
+
+	for name, content := range files {
+		assert.Nil(t, writer.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg, Name: name, Size: int64(len(content)), Mode: 0644}))
+
+		_, err := writer.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+// readTestBundle returns the contents of the files in the bundle at bundlePath by name.
+func readTestBundle(t *testing.T, bundlePath string) map[string]string {
+	t.Helper()
+
+	file, err := os.Open(bundlePath)
+	assert.Nil(t, err)
+
+	defer file.Close()
+
+	decoder, err := zstd.NewReader(file)
+	assert.Nil(t, err)
+
+	defer decoder.Close()
+
+	files := make(map[string]string)
+	reader := tar.NewReader(decoder)
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		assert.Nil(t, err)
+
+		content, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+
+		files[header.Name] = string(content)
+	}
+
+	return files
+}
@@ -0,0 +1,339 @@
+package failurebundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces the sensitive values found in artifacts.
+const redactedValue = "REDACTED"
+
+var (
+	// bearerToken matches bearer tokens in headers and logged requests.
+	bearerToken = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	// jsonWebToken matches JWTs, such as service account tokens, wherever they appear.
+	jsonWebToken = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)
+	// openShiftToken matches OpenShift OAuth access tokens.
+	openShiftToken = regexp.MustCompile(`sha256~[A-Za-z0-9_-]{20,}`)
+	// pullSecretAuth matches the credentials of registries in a pull secret, including when the pull secret is itself
+	// an escaped JSON string.
+	pullSecretAuth = regexp.MustCompile(`(\\*"(?:auth|password|identitytoken)\\*"\s*:\s*\\*")[^"\\]+`)
+	// yamlSecretKind matches the kind of a Secret serialized as YAML, including as an item of a list.
+	yamlSecretKind = regexp.MustCompile(`(?m)^[ \t]*(?:-[ \t]+)?kind:[ \t]*["']?Secret["']?[ \t]*$`)
+)
+
+// Redact returns content with the data of Secrets, the credentials of pull secrets, and bearer tokens replaced, along
+// with the number of values replaced. Tarballs, detected by a name ending in .tar, have each of their files redacted.
+// JSON objects written by json.MarshalIndent starting at the beginning of a line and YAML documents containing a
+// Secret are redacted structurally, so the data of any Secret they contain is removed regardless of how it is encoded.
+func Redact(name string, content []byte) ([]byte, int) {
+	if strings.HasSuffix(name, ".tar") {
+		return redactTar(content)
+	}
+
+	content, redactions := redactJSONObjects(content)
+
+	content, yamlRedactions := redactYAMLDocuments(content)
+	redactions += yamlRedactions
+
+	for _, pattern := range []*regexp.Regexp{bearerToken, jsonWebToken, openShiftToken, pullSecretAuth} {
+		content = pattern.ReplaceAllFunc(content, func(match []byte) []byte {
+			redactions++
+
+			submatches := pattern.FindSubmatch(match)
+			if len(submatches) > 1 {
+				return append(append([]byte{}, submatches[1]...), redactedValue...)
+			}
+
+			return []byte(redactedValue)
+		})
+	}
+
+	return content, redactions
+}
+
+// redactJSONObjects redacts the Secrets in every JSON object of content that starts with a line containing only { and
+// ends with a line containing only }, which is how the dumps write objects.
+func redactJSONObjects(content []byte) ([]byte, int) {
+	if !bytes.Contains(content, []byte(`"Secret`)) && !bytes.Contains(content, []byte(".dockerconfigjson")) &&
+		!bytes.Contains(content, []byte(".dockercfg")) {
+		return content, 0
+	}
+
+	var (
+		output     bytes.Buffer
+		redactions int
+	)
+
+	for len(content) > 0 {
+		start := findLine(content, "{")
+		if start < 0 {
+			break
+		}
+
+		end := findLine(content[start:], "}")
+		if end < 0 {
+			break
+		}
+
+		end += start + 1
+
+		output.Write(content[:start])
+
+		object, objectRedactions := redactJSON(content[start:end])
+		output.Write(object)
+
+		redactions += objectRedactions
+		content = content[end:]
+	}
+
+	output.Write(content)
+
+	return output.Bytes(), redactions
+}
+
+// findLine returns the index of the first line of content equal to line, or -1 if there is none.
+func findLine(content []byte, line string) int {
+	for offset := 0; offset < len(content); {
+		lineEnd := bytes.IndexByte(content[offset:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(content) - offset
+		}
+
+		if string(content[offset:offset+lineEnd]) == line {
+			return offset
+		}
+
+		offset += lineEnd + 1
+	}
+
+	return -1
+}
+
+// redactJSON redacts the Secrets in a single JSON value. The value is only reformatted if something was redacted.
+func redactJSON(content []byte) ([]byte, int) {
+	var value any
+
+	err := json.Unmarshal(content, &value)
+	if err != nil {
+		return content, 0
+	}
+
+	redactions := redactSecrets(value)
+	if redactions == 0 {
+		return content, 0
+	}
+
+	redacted, err := json.MarshalIndent(value, "", "    ")
+	if err != nil {
+		return content, 0
+	}
+
+	return redacted, redactions
+}
+
+// redactSecrets replaces the data of every Secret in value, as well as any docker config, and returns how many values
+// were replaced.
+func redactSecrets(value any) int {
+	redactions := 0
+
+	switch typed := value.(type) {
+	case map[string]any:
+		if typed["kind"] == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := typed[field].(map[string]any); ok {
+					for key := range data {
+						data[key] = redactedValue
+						redactions++
+					}
+				}
+			}
+		}
+
+		for key, child := range typed {
+			if key == ".dockerconfigjson" || key == ".dockercfg" {
+				if child != redactedValue {
+					typed[key] = redactedValue
+					redactions++
+				}
+
+				continue
+			}
+
+			redactions += redactSecrets(child)
+		}
+	case []any:
+		for _, child := range typed {
+			redactions += redactSecrets(child)
+		}
+	}
+
+	return redactions
+}
+
+// redactYAMLDocuments redacts the Secrets in every YAML document of content that contains one. Documents are separated
+// by lines containing only ---, which are kept as is.
+func redactYAMLDocuments(content []byte) ([]byte, int) {
+	if !yamlSecretKind.Match(content) {
+		return content, 0
+	}
+
+	var (
+		output     bytes.Buffer
+		document   []byte
+		redactions int
+	)
+
+	flush := func() {
+		redacted, documentRedactions := redactYAML(document)
+		output.Write(redacted)
+
+		redactions += documentRedactions
+		document = nil
+	}
+
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if string(bytes.TrimRight(line, " \t\r\n")) == "---" {
+			flush()
+			output.Write(line)
+
+			continue
+		}
+
+		document = append(document, line...)
+	}
+
+	flush()
+
+	return output.Bytes(), redactions
+}
+
+// redactYAML redacts the Secrets in a single YAML document. The document is only reformatted if something was redacted
+// and is returned unchanged if it is not valid YAML.
+func redactYAML(document []byte) ([]byte, int) {
+	if !yamlSecretKind.Match(document) {
+		return document, 0
+	}
+
+	var node yaml.Node
+
+	err := yaml.Unmarshal(document, &node)
+	if err != nil {
+		return document, 0
+	}
+
+	redactions := redactYAMLSecrets(&node)
+	if redactions == 0 {
+		return document, 0
+	}
+
+	var output bytes.Buffer
+
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(2)
+
+	if encoder.Encode(&node) != nil || encoder.Close() != nil {
+		return document, 0
+	}
+
+	if !bytes.HasSuffix(document, []byte("\n")) {
+		return bytes.TrimSuffix(output.Bytes(), []byte("\n")), redactions
+	}
+
+	return output.Bytes(), redactions
+}
+
+// redactYAMLSecrets replaces the values of the data and stringData of every Secret in node and returns how many values
+// were replaced.
+func redactYAMLSecrets(node *yaml.Node) int {
+	redactions := 0
+
+	if node.Kind == yaml.MappingNode {
+		if kind := yamlMappingValue(node, "kind"); kind != nil && kind.Kind == yaml.ScalarNode && kind.Value == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				data := yamlMappingValue(node, field)
+				if data == nil || data.Kind != yaml.MappingNode {
+					continue
+				}
+
+				for index := 1; index < len(data.Content); index += 2 {
+					data.Content[index] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: redactedValue}
+					redactions++
+				}
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		redactions += redactYAMLSecrets(child)
+	}
+
+	return redactions
+}
+
+// yamlMappingValue returns the value of key in the mapping node, or nil if it has none.
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key {
+			return node.Content[index+1]
+		}
+	}
+
+	return nil
+}
+
+// redactTar redacts every regular file in a tarball. The tarball is returned unchanged if it cannot be read.
+func redactTar(content []byte) ([]byte, int) {
+	var (
+		output     bytes.Buffer
+		redactions int
+	)
+
+	reader := tar.NewReader(bytes.NewReader(content))
+	writer := tar.NewWriter(&output)
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return content, 0
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return content, 0
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			var fileRedactions int
+
+			data, fileRedactions = Redact(header.Name, data)
+			redactions += fileRedactions
+			header.Size = int64(len(data))
+		}
+
+		if writer.WriteHeader(header) != nil {
+			return content, 0
+		}
+
+		if _, err := writer.Write(data); err != nil {
+			return content, 0
+		}
+	}
+
+	if writer.Close() != nil {
+		return content, 0
+	}
+
+	return output.Bytes(), redactions
+}
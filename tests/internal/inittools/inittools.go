@@ -1,48 +1,150 @@
 package inittools
 
 import (
+	"context"
+	"errors"
 	"flag"
-	"os"
+	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2" //nolint:depguard // necessary for registering the config dump node
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"k8s.io/klog/v2"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
-	// APIClient provides access to cluster.
+	// APIClient provides access to cluster. It is nil until Setup is called.
 	APIClient *clients.Settings
-	// GeneralConfig provides access to general configuration parameters.
+	// GeneralConfig provides access to general configuration parameters. It is nil until Setup is called.
 	GeneralConfig *config.GeneralConfig
+
+	// setupFuncs are the functions registered by the domain inittools packages, run in registration order by Setup.
+	setupFuncs      []namedSetupFunc
+	setupFuncsMutex sync.Mutex
+
+	// configDumpOnce makes sure RegisterConfigDump only registers its node once.
+	configDumpOnce sync.Once
+)
+
+var (
+	// ErrConfig is returned by Setup when the general config cannot be loaded.
+	ErrConfig = errors.New("failed to load general config")
+	// ErrAPIClient is returned by Setup when the API client cannot be created from the KUBECONFIG environment
+	// variable and dry run is not enabled.
+	ErrAPIClient = errors.New("failed to create API client, please check your KUBECONFIG env var")
+	// ErrSetupFunc is returned by Setup when one of the functions registered with RegisterSetup fails.
+	ErrSetupFunc = errors.New("failed to set up domain")
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// SetupFunc initializes a domain inittools package once APIClient and GeneralConfig are available.
+type SetupFunc func(ctx context.Context) error
+
+// namedSetupFunc is a SetupFunc with the name of the domain it initializes, used for errors.
+type namedSetupFunc struct {
+	name  string
+	setup SetupFunc
+}
+
+// Options configures Setup. The zero value loads the general config from default.yaml, profiles, and environment
+// variables and creates the API client from the KUBECONFIG environment variable.
+type Options struct {
+	// APIClient is used instead of creating a client from the KUBECONFIG environment variable when not nil, such as
+	// a fake client in unit tests.
+	APIClient *clients.Settings
+	// GeneralConfig is used instead of loading the general config when not nil.
+	GeneralConfig *config.GeneralConfig
+}
+
+// init only configures logging. Loading the config and creating the API client is deferred to Setup so that importing
+// this package, directly or through a helper package, has no other side effects.
 func init() {
 	klog.InitFlags(nil)
 	klog.EnableContextualLogging(true)
 	logf.SetLogger(logr.Discard())
 
 	_ = flag.Set("logtostderr", "true")
+}
 
-	// Skip loading config if running unit tests
-	if os.Getenv("UNIT_TEST") == "true" {
-		return
-	}
+// RegisterSetup registers a function to be run by Setup after GeneralConfig and APIClient are initialized. It is meant
+// to be called from the init function of domain inittools packages, which ensures the functions of packages are run
+// after those of the packages they depend on. The name identifies the domain in errors.
+func RegisterSetup(name string, setup SetupFunc) {
+	setupFuncsMutex.Lock()
+	defer setupFuncsMutex.Unlock()
+
+	setupFuncs = append(setupFuncs, namedSetupFunc{name: name, setup: setup})
+}
 
-	if GeneralConfig = config.NewConfig(); GeneralConfig == nil {
-		klog.Fatalf("error to load general config")
+// Setup loads GeneralConfig, creates APIClient, registers the clusters from GeneralConfig with the default cluster
+// registry, and then runs every function registered with RegisterSetup. It should be called by the suite bootstrap
+// before RunSpecs since the JUnit report path and the spec tree depend on the config. The returned error wraps
+// ErrConfig, ErrAPIClient, or ErrSetupFunc depending on the step that failed. If dry run is enabled, failing to create
+// the API client is not an error and APIClient is left nil.
+func Setup(ctx context.Context, opts Options) error {
+	GeneralConfig = opts.GeneralConfig
+	if GeneralConfig == nil {
+		GeneralConfig = config.NewConfig()
+		if GeneralConfig == nil {
+			return ErrConfig
+		}
 	}
 
 	_ = flag.Set("v", GeneralConfig.VerboseLevel)
 
-	if APIClient = clients.New(""); APIClient == nil {
-		if GeneralConfig.DryRun {
-			return
+	APIClient = opts.APIClient
+	if APIClient == nil {
+		APIClient = clients.New("")
+		if APIClient == nil && !GeneralConfig.DryRun {
+			return ErrAPIClient
 		}
+	}
+
+	clusterregistry.Default().Reset()
 
-		klog.Exitf("can not load ApiClient. Please check your KUBECONFIG env var")
+	err := clusterregistry.Default().Load(GeneralConfig.Clusters)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrConfig, err)
 	}
+
+	setupFuncsMutex.Lock()
+	registeredFuncs := setupFuncs
+	setupFuncsMutex.Unlock()
+
+	for _, registered := range registeredFuncs {
+		err = ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		err = registered.setup(ctx)
+		if err != nil {
+			return fmt.Errorf("%w %s: %w", ErrSetupFunc, registered.name, err)
+		}
+	}
+
+	return nil
+}
+
+// RegisterConfigDump registers a Ginkgo node that writes the effective configs, after applying profiles and environment
+// variables, to the reports dir at the start of the suite. Sensitive values are redacted. It should be called by the
+// suite bootstrap before RunSpecs and only registers the node once.
+func RegisterConfigDump() {
+	configDumpOnce.Do(func() {
+		ginkgo.ReportBeforeSuite(func(report ginkgo.Report) {
+			if GeneralConfig == nil {
+				return
+			}
+
+			dumpPath := GeneralConfig.GetConfigDumpPath(report.SuitePath)
+
+			err := config.DumpConfigs(dumpPath)
+			if err != nil {
+				klog.Errorf("Failed to dump effective configs to %s: %v", dumpPath, err)
+			}
+		})
+	})
 }
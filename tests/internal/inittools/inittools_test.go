@@ -1 +1,109 @@
 package inittools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	errTestSetup := errors.New("test setup failed")
+
+	testCases := []struct {
+		setups        map[string]error
+		expectedCalls []string
+		expectedError error
+	}{
+		{
+			setups:        nil,
+			expectedCalls: nil,
+			expectedError: nil,
+		},
+		{
+			setups:        map[string]error{"first": nil, "second": nil},
+			expectedCalls: []string{"first", "second"},
+			expectedError: nil,
+		},
+		{
+			setups:        map[string]error{"first": errTestSetup, "second": nil},
+			expectedCalls: []string{"first"},
+			expectedError: ErrSetupFunc,
+		},
+	}
+
+	for _, testCase := range testCases {
+		setupFuncs = nil
+
+		var calls []string
+
+		for _, name := range []string{"first", "second"} {
+			setupErr, ok := testCase.setups[name]
+			if !ok {
+				continue
+			}
+
+			RegisterSetup(name, func(context.Context) error {
+				assert.NotNil(t, APIClient)
+				assert.NotNil(t, GeneralConfig)
+
+				calls = append(calls, name)
+
+				return setupErr
+			})
+		}
+
+		testClient := clients.GetTestClients(clients.TestClientParams{})
+		testConfig := &config.GeneralConfig{VerboseLevel: "0"}
+
+		err := Setup(t.Context(), Options{APIClient: testClient, GeneralConfig: testConfig})
+		assert.Equal(t, testCase.expectedCalls, calls)
+		assert.Equal(t, testClient, APIClient)
+		assert.Equal(t, testConfig, GeneralConfig)
+
+		if testCase.expectedError == nil {
+			assert.Nil(t, err)
+		} else {
+			assert.ErrorIs(t, err, testCase.expectedError)
+			assert.ErrorIs(t, err, errTestSetup)
+		}
+	}
+
+	setupFuncs = nil
+}
+
+func TestSetupCanceled(t *testing.T) {
+	setupFuncs = nil
+
+	RegisterSetup("never", func(context.Context) error {
+		t.Error("setup should not be called after the context is canceled")
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := Setup(ctx, Options{
+		APIClient:     clients.GetTestClients(clients.TestClientParams{}),
+		GeneralConfig: &config.GeneralConfig{VerboseLevel: "0"},
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	setupFuncs = nil
+}
+
+func TestSetupDryRun(t *testing.T) {
+	setupFuncs = nil
+
+	t.Setenv("KUBECONFIG", "/nonexistent/kubeconfig")
+
+	err := Setup(t.Context(), Options{GeneralConfig: &config.GeneralConfig{VerboseLevel: "0", DryRun: true}})
+	assert.Nil(t, err)
+
+	err = Setup(t.Context(), Options{GeneralConfig: &config.GeneralConfig{VerboseLevel: "0"}})
+	assert.ErrorIs(t, err, ErrAPIClient)
+}
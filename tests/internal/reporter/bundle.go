@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/onsi/ginkgo/v2" //nolint:depguard // necessary for splitting the run budget between parallel processes
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	"k8s.io/klog/v2"
+)
+
+// ErrDumpDisabled is returned by SpecArtifacts when dumping failed specs is not enabled.
+var ErrDumpDisabled = errors.New("dumping failed tests is disabled")
+
+var (
+	// currentArtifacts is where the artifacts of the current failed spec are written. It is closed after the spec by
+	// closeSpecArtifacts.
+	currentArtifacts      *specArtifacts
+	currentArtifactsMutex sync.Mutex
+
+	// runBudget limits the size of the artifacts of all failed specs run by this process.
+	runBudget     *failurebundle.Budget
+	runBudgetOnce sync.Once
+)
+
+// specArtifacts is the writer for the artifacts of a single failed spec.
+type specArtifacts struct {
+	specText string
+	writer   failurebundle.Writer
+	bundle   *failurebundle.Bundle
+}
+
+// SpecArtifacts returns the writer for the artifacts of the failed spec in report. Names passed to the writer are
+// relative to the directory of the spec under the dump directory of testSuite, so nodes.log ends up at
+// failed_<suite>/<spec>/nodes.log. When ECO_BUNDLE_FAILED_TESTS is set, the artifacts of the spec from every call are
+// added to a single bundle in the dump directory of the first call, which is closed after the spec. Otherwise, they
+// are written as loose files. In both cases they are redacted and limited by the per spec and per run budgets.
+//
+//nolint:ireturn // the writer is either a bundle or a directory depending on the config.
+func SpecArtifacts(report types.SpecReport, testSuite string) (failurebundle.Writer, error) {
+	reporterCfg := getGeneralConfig()
+	if reporterCfg == nil {
+		return nil, ErrDumpDisabled
+	}
+
+	dumpDir := reporterCfg.GetDumpFailedTestReportLocation(testSuite)
+	if dumpDir == "" {
+		return nil, ErrDumpDisabled
+	}
+
+	specDirName := dumpDirName(report.FullText())
+	prefix := path.Join(filepath.Base(dumpDir), specDirName)
+
+	currentArtifactsMutex.Lock()
+	defer currentArtifactsMutex.Unlock()
+
+	if currentArtifacts != nil && currentArtifacts.specText == report.FullText() {
+		return failurebundle.WithPrefix(currentArtifacts.writer, prefix), nil
+	}
+
+	closeArtifacts(currentArtifacts)
+	currentArtifacts = nil
+
+	specBudget := reporterCfg.FailedTestSpecBudgetMB << 20
+	artifacts := &specArtifacts{specText: report.FullText()}
+
+	if reporterCfg.BundleFailedTests {
+		bundle, err := failurebundle.Create(
+			filepath.Join(dumpDir, specDirName+failurebundle.Extension), report.FullText(), specBudget, getRunBudget())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create failure bundle: %w", err)
+		}
+
+		artifacts.writer = bundle
+		artifacts.bundle = bundle
+	} else {
+		artifacts.writer = failurebundle.NewDirectory(reporterCfg.ReportsDirAbsPath, specBudget, getRunBudget())
+	}
+
+	currentArtifacts = artifacts
+
+	return failurebundle.WithPrefix(artifacts.writer, prefix), nil
+}
+
+// closeSpecArtifacts closes the bundle of the current failed spec, if any.
+func closeSpecArtifacts() {
+	currentArtifactsMutex.Lock()
+	defer currentArtifactsMutex.Unlock()
+
+	closeArtifacts(currentArtifacts)
+	currentArtifacts = nil
+}
+
+// closeArtifacts closes the bundle of artifacts if it has one. It must be called with currentArtifactsMutex held.
+func closeArtifacts(artifacts *specArtifacts) {
+	if artifacts == nil || artifacts.bundle == nil {
+		return
+	}
+
+	err := artifacts.bundle.Close()
+	if err != nil {
+		klog.Errorf("Failed to close failure bundle %s: %v", artifacts.bundle.Path(), err)
+
+		return
+	}
+
+	manifest := artifacts.bundle.Manifest()
+	klog.V(100).Infof("Wrote %d artifacts totaling %d bytes to failure bundle %s",
+		len(manifest.Files), manifest.Size, artifacts.bundle.Path())
+}
+
+// getRunBudget returns the budget shared by the failed specs of this process. The run budget from the config is split
+// evenly between parallel processes since they cannot share a budget.
+func getRunBudget() *failurebundle.Budget {
+	runBudgetOnce.Do(func() {
+		limit := getGeneralConfig().FailedTestRunBudgetMB << 20
+
+		suiteConfig, _ := ginkgo.GinkgoConfiguration()
+		if suiteConfig.ParallelTotal > 1 {
+			limit /= int64(suiteConfig.ParallelTotal)
+		}
+
+		runBudget = failurebundle.NewBudget(limit)
+	})
+
+	return runBudget
+}
@@ -0,0 +1,324 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift-kni/k8sreporter"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// dumpFileSeparator separates the sections of the dump files, matching k8sreporter.
+	dumpFileSeparator = "-----------------------------------\n"
+	// podExecLogsFileName is the name of the file the exec transcript of the spec is written to.
+	podExecLogsFileName = "pod_exec_logs.log"
+)
+
+// clusterDumper dumps the nodes, pod logs, events, pod specs, and CRs of a cluster in the same layout as
+// k8sreporter.KubernetesReporter, followed by a timeline of the Events and condition transitions during the spec.
+// Unlike k8sreporter, every request is made with a context carrying a discarding logger, so the verbose request
+// logging of client-go is suppressed without changing the global klog flags.
+type clusterDumper struct {
+	kubeClient     kubernetes.Interface
+	runtimeClient  runtimeclient.Client
+	namespaceToLog k8sreporter.NamespaceFilter
+	crs            []k8sreporter.CRData
+}
+
+// newClusterDumper returns a clusterDumper for the cluster at kubeconfig, defaulting to the KUBECONFIG environment
+// variable and then the in-cluster config like k8sreporter does. The scheme used for the CRs includes reporterSchemes.
+func newClusterDumper(
+	kubeconfig string, namespacesToDump map[string]string, cRDs []k8sreporter.CRData) (*clusterDumper, error) {
+	restConfig, err := loadRESTConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	crScheme := runtime.NewScheme()
+
+	err = clientgoscheme.AddToScheme(crScheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add client-go scheme: %w", err)
+	}
+
+	err = setReporterSchemes(crScheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reporter schemes: %w", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kube client: %w", err)
+	}
+
+	runtimeClient, err := runtimeclient.New(restConfig, runtimeclient.Options{Scheme: crScheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runtime client: %w", err)
+	}
+
+	return &clusterDumper{
+		kubeClient:     kubeClient,
+		runtimeClient:  runtimeClient,
+		namespaceToLog: namespaceFilter(namespacesToDump),
+		crs:            cRDs,
+	}, nil
+}
+
+// loadRESTConfig returns the rest config for kubeconfig, falling back to KUBECONFIG and then the in-cluster config.
+func loadRESTConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig == "" {
+		kubeconfig = os.Getenv("KUBECONFIG")
+	}
+
+	if kubeconfig == "" {
+		restConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+		}
+
+		return restConfig, nil
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %s: %w", kubeconfig, err)
+	}
+
+	return restConfig, nil
+}
+
+// namespaceFilter returns a filter matching the keys of namespacesToDump.
+func namespaceFilter(namespacesToDump map[string]string) k8sreporter.NamespaceFilter {
+	return func(namespace string) bool {
+		_, found := namespacesToDump[namespace]
+
+		return found
+	}
+}
+
+// dumpDirName returns the name of the directory a spec is dumped to, matching k8sreporter.
+func dumpDirName(specText string) string {
+	dirName := strings.ReplaceAll(specText, "/", "-")
+
+	return strings.ReplaceAll(dirName, " ", "_")
+}
+
+// Dump writes the state of the cluster to artifacts, including pod logs and events from duration before now. Failures
+// to dump individual sections are logged and do not stop the rest of the dump.
+func (dumper *clusterDumper) Dump(ctx context.Context, duration time.Duration, artifacts failurebundle.Writer) {
+	ctx = klog.NewContext(ctx, logr.Discard())
+	since := time.Now().Add(-duration).Add(-5 * time.Second)
+
+	pods, err := dumper.kubeClient.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.V(100).Infof("Failed to list pods for the dump: %v", err)
+	}
+
+	var podsToLog []corev1.Pod
+
+	if pods != nil {
+		for _, pod := range pods.Items {
+			if dumper.namespaceToLog(pod.Namespace) {
+				podsToLog = append(podsToLog, pod)
+			}
+		}
+	}
+
+	errs := []error{
+		dumper.dumpNodes(ctx, artifacts),
+		dumper.dumpLogs(ctx, since, artifacts, podsToLog),
+		dumper.dumpEvents(ctx, since, artifacts),
+		dumper.dumpPods(artifacts, podsToLog),
+	}
+
+	for _, cr := range dumper.crs {
+		errs = append(errs, dumper.dumpCR(ctx, cr, artifacts))
+	}
+
+	errs = append(errs, dumper.dumpTimeline(ctx, since, artifacts, podsToLog))
+
+	if err := errors.Join(errs...); err != nil {
+		klog.V(100).Infof("Failed to dump part of the cluster: %v", err)
+	}
+}
+
+func (dumper *clusterDumper) dumpNodes(ctx context.Context, artifacts failurebundle.Writer) error {
+	return writeDumpFile(artifacts, "nodes", func(writer io.Writer) error {
+		_, _ = fmt.Fprint(writer, dumpFileSeparator)
+
+		nodes, err := dumper.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list nodes: %w", err)
+		}
+
+		return writeJSON(writer, nodes)
+	})
+}
+
+func (dumper *clusterDumper) dumpLogs(
+	ctx context.Context, since time.Time, artifacts failurebundle.Writer, pods []corev1.Pod) error {
+	var errs []error
+
+	logStart := metav1.NewTime(since)
+
+	for _, pod := range pods {
+		containers := slices.Concat(pod.Spec.Containers, pod.Spec.InitContainers)
+
+		err := writeDumpFile(artifacts, pod.Namespace+"_"+pod.Name+"_pods_logs", func(writer io.Writer) error {
+			for _, container := range containers {
+				for _, previous := range []bool{false, true} {
+					logs, err := dumper.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+						Container: container.Name, SinceTime: &logStart, Previous: previous,
+					}).DoRaw(ctx)
+					if err != nil {
+						continue
+					}
+
+					kind := "logs"
+					if previous {
+						kind = "previous logs"
+					}
+
+					_, _ = fmt.Fprintf(writer, "%sDumping %s for pod %s-%s-%s\n%s\n",
+						dumpFileSeparator, kind, pod.Namespace, pod.Name, container.Name, logs)
+				}
+			}
+
+			return nil
+		})
+
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (dumper *clusterDumper) dumpEvents(ctx context.Context, since time.Time, artifacts failurebundle.Writer) error {
+	return writeDumpFile(artifacts, "events", func(writer io.Writer) error {
+		namespaces, err := dumper.kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list namespaces: %w", err)
+		}
+
+		var errs []error
+
+		for _, namespace := range namespaces.Items {
+			if !dumper.namespaceToLog(namespace.Name) {
+				continue
+			}
+
+			_, _ = fmt.Fprintf(writer, "%sDumping events for namespace %s\n", dumpFileSeparator, namespace.Name)
+
+			events, err := dumper.kubeClient.CoreV1().Events(namespace.Name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list events in namespace %s: %w", namespace.Name, err))
+
+				continue
+			}
+
+			for _, event := range events.Items {
+				if event.CreationTimestamp.Time.Before(since) {
+					continue
+				}
+
+				errs = append(errs, writeJSON(writer, event))
+			}
+		}
+
+		return errors.Join(errs...)
+	})
+}
+
+func (dumper *clusterDumper) dumpPods(artifacts failurebundle.Writer, pods []corev1.Pod) error {
+	var errs []error
+
+	for _, pod := range pods {
+		err := writeDumpFile(artifacts, pod.Namespace+"_"+pod.Name+"_pods_specs", func(writer io.Writer) error {
+			return writeJSON(writer, pod)
+		})
+
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (dumper *clusterDumper) dumpCR(ctx context.Context, cr k8sreporter.CRData, artifacts failurebundle.Writer) error {
+	fileName := objectKind(dumper.runtimeClient.Scheme(), cr.Cr)
+	if cr.Namespace != nil {
+		fileName = fmt.Sprintf("%s_%s", fileName, *cr.Namespace)
+	}
+
+	return writeDumpFile(artifacts, fileName, func(writer io.Writer) error {
+		var options []runtimeclient.ListOption
+		if cr.Namespace != nil {
+			options = append(options, runtimeclient.InNamespace(*cr.Namespace))
+		}
+
+		err := dumper.runtimeClient.List(ctx, cr.Cr, options...)
+		if err != nil {
+			// This is expected when dumping CRs of an operator that is not installed.
+			_, _ = fmt.Fprintf(writer, "Failed to fetch %T: %v\n", cr.Cr, err)
+
+			return nil
+		}
+
+		return writeJSON(writer, cr.Cr)
+	})
+}
+
+// writeDumpFile calls write with a buffer and adds its contents to artifacts as the dump file for kind.
+func writeDumpFile(artifacts failurebundle.Writer, kind string, write func(io.Writer) error) error {
+	var buffer bytes.Buffer
+
+	writeErr := write(&buffer)
+
+	return errors.Join(writeErr, artifacts.Add(kind+".log", buffer.Bytes()))
+}
+
+// writeJSON writes object to writer as indented JSON followed by a newline.
+func writeJSON(writer io.Writer, object any) error {
+	content, err := json.MarshalIndent(object, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", object, err)
+	}
+
+	_, err = fmt.Fprintln(writer, string(content))
+
+	return err
+}
+
+// objectKind returns the kind of list according to typer, or lostfound if it is unknown, matching k8sreporter.
+func objectKind(typer runtime.ObjectTyper, list runtimeclient.ObjectList) string {
+	gvks, _, err := typer.ObjectKinds(list)
+	if err != nil {
+		return "lostfound"
+	}
+
+	for _, gvk := range gvks {
+		if gvk.Kind != "" && gvk.Version != "" && gvk.Version != runtime.APIVersionInternal {
+			return gvk.Kind
+		}
+	}
+
+	return "lostfound"
+}
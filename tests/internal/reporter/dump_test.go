@@ -0,0 +1,114 @@
+package reporter
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift-kni/k8sreporter"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterDumperDump(t *testing.T) {
+	now := metav1.Now()
+	oldEvent := metav1.NewTime(now.Add(-time.Hour))
+	objects := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dumped-ns"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other-ns"}},
+		buildDumpTestPod("dumped-ns", "dumped-pod"),
+		buildDumpTestPod("other-ns", "other-pod"),
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "new-event", Namespace: "dumped-ns", CreationTimestamp: now},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "dumped-ns", Name: "dumped-pod"},
+			Reason:         "Started",
+		},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "old-event", Namespace: "dumped-ns", CreationTimestamp: oldEvent}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dumped-cm", Namespace: "dumped-ns"}},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "dumped-secret", Namespace: "dumped-ns"},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		},
+	}
+
+	crScheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(crScheme))
+
+	dumpedNamespace := "dumped-ns"
+	dumper := &clusterDumper{
+		kubeClient:     fake.NewClientset(objects...),
+		runtimeClient:  runtimefake.NewClientBuilder().WithScheme(crScheme).WithRuntimeObjects(objects...).Build(),
+		namespaceToLog: namespaceFilter(map[string]string{dumpedNamespace: ""}),
+		crs: []k8sreporter.CRData{
+			{Cr: &corev1.ConfigMapList{}, Namespace: &dumpedNamespace},
+			{Cr: &corev1.SecretList{}, Namespace: &dumpedNamespace},
+		},
+	}
+
+	dumpDir := t.TempDir()
+	dumper.Dump(t.Context(), time.Minute, failurebundle.NewDirectory(dumpDir, 0, nil))
+
+	entries, err := os.ReadDir(dumpDir)
+	assert.Nil(t, err)
+
+	var fileNames []string
+	for _, entry := range entries {
+		fileNames = append(fileNames, entry.Name())
+	}
+
+	assert.ElementsMatch(t, []string{
+		"nodes.log",
+		"events.log",
+		"dumped-ns_dumped-pod_pods_logs.log",
+		"dumped-ns_dumped-pod_pods_specs.log",
+		"ConfigMapList_dumped-ns.log",
+		"SecretList_dumped-ns.log",
+		"timeline.jsonl",
+		"timeline.txt",
+	}, fileNames)
+
+	assert.Contains(t, readDumpFile(t, dumpDir, "nodes.log"), `"name": "node-1"`)
+	assert.Contains(t, readDumpFile(t, dumpDir, "dumped-ns_dumped-pod_pods_logs.log"),
+		"Dumping logs for pod dumped-ns-dumped-pod-test-container\nfake logs\n")
+
+	events := readDumpFile(t, dumpDir, "events.log")
+	assert.Contains(t, events, "Dumping events for namespace dumped-ns\n")
+	assert.Contains(t, events, `"name": "new-event"`)
+	assert.NotContains(t, events, "old-event")
+	assert.NotContains(t, events, "other-ns")
+
+	assert.Contains(t, readDumpFile(t, dumpDir, "ConfigMapList_dumped-ns.log"), `"name": "dumped-cm"`)
+
+	secrets := readDumpFile(t, dumpDir, "SecretList_dumped-ns.log")
+	assert.Contains(t, secrets, `"password": "REDACTED"`)
+	assert.NotContains(t, secrets, base64.StdEncoding.EncodeToString([]byte("hunter2")))
+
+	assert.Contains(t, readDumpFile(t, dumpDir, "timeline.jsonl"), `"reason":"Started"`)
+	assert.Contains(t, readDumpFile(t, dumpDir, "timeline.txt"), "Pod dumped-ns/dumped-pod Started")
+}
+
+func buildDumpTestPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "test-container"}}},
+	}
+}
+
+func readDumpFile(t *testing.T, dumpDir, fileName string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(dumpDir, fileName))
+	assert.Nil(t, err)
+
+	return string(content)
+}
@@ -1,67 +1,84 @@
 package reporter
 
 import (
+	"context"
 	"errors"
-	"flag"
 	"fmt"
-	"io"
-	"os"
 	"path"
-	"strings"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/onsi/ginkgo/v2" //nolint:depguard // necessary for registering the dump nodes
 	"github.com/onsi/ginkgo/v2/types"
 	"github.com/openshift-kni/k8sreporter"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
-	"k8s.io/apimachinery/pkg/runtime"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/execlog"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"k8s.io/klog/v2"
 )
 
+// dumpTimeout is how long dumping a single cluster for a failed spec may take.
+const dumpTimeout = 10 * time.Minute
+
 var (
-	pathToPodExecLogs = "/tmp/pod_exec_logs.log"
-	// generalCfg holds the configuration for reporter operations.
+	// generalCfg holds the configuration for reporter operations. When nil, inittools.GeneralConfig is used.
 	generalCfg *config.GeneralConfig
+
+	// currentDump tracks the clusters dumped for the current failed spec so the registered clusters that were not
+	// dumped explicitly can be dumped once the spec is done.
+	currentDump      *specDump
+	currentDumpMutex sync.Mutex
+
+	// registerDumpsOnce makes sure RegisterDumps only registers its nodes once.
+	registerDumpsOnce sync.Once
 )
 
-// init initializes the reporter configuration.
-func init() {
-	// Skip loading config if running unit tests
-	if os.Getenv("UNIT_TEST") == "true" {
-		return
-	}
+// specDump is the state of dumping a single failed spec. The test suite, namespaces, and CRs are the ones passed to
+// ReportIfFailed and are reused for the registered clusters.
+type specDump struct {
+	specText    string
+	testSuite   string
+	nSpaces     map[string]string
+	cRDs        []k8sreporter.CRData
+	kubeconfigs []string
+}
 
-	generalCfg = config.NewConfig()
+// RegisterDumps registers the Ginkgo nodes of the reporter. It should be called by the suite bootstrap before RunSpecs
+// and only registers the nodes once. The nodes do the following:
+//
+//   - Before every spec, start a new exec transcript so the transcript dumped for a failed spec only contains the
+//     commands it executed.
+//   - After every failed spec, dump every registered cluster that was not already dumped for it. This runs after the
+//     JustAfterEach and AfterEach nodes of the spec, so explicit calls to ReportIfFailedOnCluster with more specific
+//     namespaces and CRs for a cluster take precedence.
+func RegisterDumps() {
+	registerDumpsOnce.Do(func() {
+		ginkgo.BeforeEach(func() {
+			execlog.StartSpec()
+		})
+
+		ginkgo.ReportAfterEach(func(report ginkgo.SpecReport) {
+			ReportIfFailedOnRegisteredClusters(report)
+			closeSpecArtifacts()
+		})
+	})
 }
 
-// SetGeneralConfig allows overriding the default configuration.
+// SetGeneralConfig allows overriding the default configuration, which is the one loaded by inittools.Setup.
 func SetGeneralConfig(cfg *config.GeneralConfig) {
 	generalCfg = cfg
 }
 
-func newReporter(
-	reportPath,
-	kubeconfig string,
-	namespacesToDump map[string]string,
-	apiScheme func(scheme *runtime.Scheme) error,
-	cRDs []k8sreporter.CRData) (*k8sreporter.KubernetesReporter, error) {
-	nsToDumpFilter := func(ns string) bool {
-		_, found := namespacesToDump[ns]
-
-		return found
-	}
-
-	if _, err := os.Stat(reportPath); os.IsNotExist(err) {
-		err := os.MkdirAll(reportPath, 0755)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	res, err := k8sreporter.New(kubeconfig, apiScheme, nsToDumpFilter, reportPath, cRDs...)
-	if err != nil {
-		return nil, err
+// getGeneralConfig returns the config set by SetGeneralConfig, or the one loaded by inittools.Setup otherwise. It
+// returns nil if neither is available.
+func getGeneralConfig() *config.GeneralConfig {
+	if generalCfg != nil {
+		return generalCfg
 	}
 
-	return res, nil
+	return inittools.GeneralConfig
 }
 
 // ReportIfFailed dumps requested cluster CRs if TC is failed to the given directory.
@@ -70,9 +87,50 @@ func ReportIfFailed(
 	testSuite string,
 	nSpaces map[string]string,
 	cRDs []k8sreporter.CRData) {
+	if types.SpecStateFailureStates.Is(report.State) {
+		currentDumpMutex.Lock()
+		currentDump = &specDump{specText: report.FullText(), testSuite: testSuite, nSpaces: nSpaces, cRDs: cRDs}
+		currentDumpMutex.Unlock()
+	}
+
 	ReportIfFailedOnCluster("", report, testSuite, nSpaces, cRDs)
 }
 
+// ReportIfFailedOnRegisteredClusters dumps the clusters in the default cluster registry that have not already been
+// dumped for the failed spec, using the namespaces and CRs passed to ReportIfFailed. Each cluster is dumped to a
+// directory prefixed with its name. It is called after each spec by the node registered with RegisterDumps, so suites
+// do not need to call it unless they want the clusters dumped earlier.
+func ReportIfFailedOnRegisteredClusters(report types.SpecReport) {
+	currentDumpMutex.Lock()
+	dump := currentDump
+	currentDump = nil
+	currentDumpMutex.Unlock()
+
+	if dump == nil || dump.specText != report.FullText() || !types.SpecStateFailureStates.Is(report.State) {
+		return
+	}
+
+	currentDir, currentFilename := path.Split(dump.testSuite)
+
+	for _, cluster := range clusterregistry.Clusters() {
+		kubeconfig := clusterregistry.ResolveKubeconfig(cluster.KubeconfigPath)
+		if slices.Contains(dump.kubeconfigs, kubeconfig) {
+			continue
+		}
+
+		dump.kubeconfigs = append(dump.kubeconfigs, kubeconfig)
+
+		klog.V(100).Infof("Dumping registered %s cluster %s for failed test: %s", cluster.Role, cluster.Name, dump.testSuite)
+
+		dumpCluster(
+			cluster.KubeconfigPath,
+			report,
+			fmt.Sprintf("%s%s_%s", currentDir, cluster.Name, currentFilename),
+			dump.nSpaces,
+			dump.cRDs)
+	}
+}
+
 // ReportIfFailedOnCluster dumps the requested cluster CRs on the cluster specified by kubeconfig if TC is failed to the
 // given directory.
 func ReportIfFailedOnCluster(
@@ -85,85 +143,68 @@ func ReportIfFailedOnCluster(
 		return
 	}
 
+	recordDumpedKubeconfig(report, kubeconfig)
+	dumpCluster(kubeconfig, report, testSuite, nSpaces, cRDs)
+}
+
+// dumpCluster dumps the requested cluster CRs on the cluster specified by kubeconfig to the directory for testSuite,
+// regardless of the state of the spec.
+func dumpCluster(
+	kubeconfig string,
+	report types.SpecReport,
+	testSuite string,
+	nSpaces map[string]string,
+	cRDs []k8sreporter.CRData) {
+	reporterCfg := getGeneralConfig()
+
 	// If no config is available, skip dumping
-	if generalCfg == nil {
+	if reporterCfg == nil {
 		klog.V(100).Infof("No reporter configuration available, skipping dump for test: %s", testSuite)
 
 		return
 	}
 
-	dumpDir := generalCfg.GetDumpFailedTestReportLocation(testSuite)
-
-	if dumpDir != "" {
-		reporter, err := newReporter(dumpDir, kubeconfig, nSpaces, setReporterSchemes, cRDs)
-		if err != nil {
-			klog.Fatalf("Failed to create log reporter due to %s", err)
-		}
-
-		tcReportFolderName := strings.ReplaceAll(report.FullText(), " ", "_")
-
-		// Workaround for the fact we are unable to pass a context to specify a logger for the client used by
-		// the reporter. Otherwise, we get megabytes of verbose logging.
-		_ = flag.Set("v", "0")
-
-		reporter.Dump(report.RunTime, tcReportFolderName)
-
-		_ = flag.Set("v", generalCfg.VerboseLevel)
-
-		_, podExecLogsFName := path.Split(pathToPodExecLogs)
-
-		err = moveFile(
-			pathToPodExecLogs, path.Join(generalCfg.ReportsDirAbsPath, tcReportFolderName, podExecLogsFName))
-		if err != nil {
-			klog.Fatalf("Failed to move pod exec logs %s to report folder: %s", pathToPodExecLogs, err)
-		}
+	artifacts, err := SpecArtifacts(report, testSuite)
+	if errors.Is(err, ErrDumpDisabled) {
+		return
 	}
 
-	err := removeFile(pathToPodExecLogs)
 	if err != nil {
-		klog.Fatal(err.Error())
-	}
-}
+		klog.Errorf("Failed to open artifacts for failed test %s: %v", report.FullText(), err)
 
-func moveFile(sourcePath, destPath string) error {
-	_, err := os.Stat(sourcePath)
-	if errors.Is(err, os.ErrNotExist) {
-		return nil
+		return
 	}
 
-	inputFile, err := os.Open(sourcePath)
+	dumper, err := newClusterDumper(kubeconfig, nSpaces, cRDs)
 	if err != nil {
-		return fmt.Errorf("couldn't open source file: %w", err)
+		klog.Fatalf("Failed to create log reporter due to %s", err)
 	}
 
-	defer func() {
-		_ = inputFile.Close()
-	}()
+	ctx, cancel := context.WithTimeout(context.Background(), dumpTimeout)
+	defer cancel()
 
-	outputFile, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("couldn't open dest file: %w", err)
-	}
+	dumper.Dump(ctx, report.RunTime, artifacts)
 
-	defer func() {
-		_ = outputFile.Close()
-	}()
+	transcript := execlog.Current().String()
+	if transcript == "" {
+		return
+	}
 
-	_, err = io.Copy(outputFile, inputFile)
+	err = artifacts.Add(podExecLogsFileName, []byte(transcript))
 	if err != nil {
-		return fmt.Errorf("writing to output file failed: %w", err)
+		klog.Errorf("Failed to write pod exec logs for failed test %s: %v", report.FullText(), err)
 	}
-
-	return nil
 }
 
-func removeFile(fPath string) error {
-	if _, err := os.Stat(fPath); err == nil {
-		err := os.Remove(fPath)
-		if err != nil {
-			return fmt.Errorf("failed to remove pod exec logs from %s: %w", fPath, err)
-		}
+// recordDumpedKubeconfig records that the cluster at kubeconfig was dumped for the current failed spec so it is not
+// dumped again as a registered cluster.
+func recordDumpedKubeconfig(report types.SpecReport, kubeconfig string) {
+	currentDumpMutex.Lock()
+	defer currentDumpMutex.Unlock()
+
+	if currentDump == nil || currentDump.specText != report.FullText() {
+		return
 	}
 
-	return nil
+	currentDump.kubeconfigs = append(currentDump.kubeconfigs, clusterregistry.ResolveKubeconfig(kubeconfig))
 }
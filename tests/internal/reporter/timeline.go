@@ -0,0 +1,342 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openshift-kni/k8sreporter"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// timelineJSONFileName is the name of the file the timeline is written to as one JSON object per line.
+	timelineJSONFileName = "timeline.jsonl"
+	// timelineTextFileName is the name of the file the human readable timeline is written to.
+	timelineTextFileName = "timeline.txt"
+)
+
+// TimelineSource is what a TimelineEntry was recorded from.
+type TimelineSource string
+
+const (
+	// TimelineSourceEvent is used for entries recorded from Kubernetes Events.
+	TimelineSourceEvent TimelineSource = "Event"
+	// TimelineSourceCondition is used for entries recorded from the last transition of a status condition.
+	TimelineSourceCondition TimelineSource = "Condition"
+)
+
+// TimelineEntry is a single Event or condition transition of an object in the timeline of a failed spec.
+type TimelineEntry struct {
+	Time      time.Time      `json:"time"`
+	Source    TimelineSource `json:"source"`
+	Kind      string         `json:"kind"`
+	Namespace string         `json:"namespace,omitempty"`
+	Name      string         `json:"name"`
+	// Type is the type of the Event, such as Warning, or the type of the condition, such as Ready.
+	Type string `json:"type"`
+	// Status is the status of the condition. It is empty for Events.
+	Status  string `json:"status,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Count is how many times the Event occurred. It is zero for conditions.
+	Count int32 `json:"count,omitempty"`
+}
+
+// String returns the entry as a single line of the text timeline.
+func (entry TimelineEntry) String() string {
+	object := entry.Kind + " " + entry.Name
+	if entry.Namespace != "" {
+		object = entry.Kind + " " + entry.Namespace + "/" + entry.Name
+	}
+
+	what := entry.Type
+	if entry.Source == TimelineSourceCondition {
+		what = entry.Type + "=" + entry.Status
+	}
+
+	line := fmt.Sprintf("%s %-9s %-24s %s", entry.Time.UTC().Format(time.RFC3339), entry.Source, what, object)
+
+	if entry.Reason != "" {
+		line += " " + entry.Reason
+	}
+
+	if entry.Message != "" {
+		line += ": " + strings.ReplaceAll(entry.Message, "\n", " ")
+	}
+
+	if entry.Count > 1 {
+		line += fmt.Sprintf(" (x%d)", entry.Count)
+	}
+
+	return line
+}
+
+// sortTimeline sorts entries chronologically, keeping the order entries were recorded in for equal times.
+func sortTimeline(entries []TimelineEntry) {
+	slices.SortStableFunc(entries, func(first, second TimelineEntry) int {
+		return first.Time.Compare(second.Time)
+	})
+}
+
+// writeTimeline writes entries as JSON lines to jsonWriter and as text to textWriter.
+func writeTimeline(entries []TimelineEntry, jsonWriter, textWriter io.Writer) error {
+	encoder := json.NewEncoder(jsonWriter)
+
+	for _, entry := range entries {
+		err := encoder.Encode(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode timeline entry: %w", err)
+		}
+
+		_, err = fmt.Fprintln(textWriter, entry.String())
+		if err != nil {
+			return fmt.Errorf("failed to write timeline entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dumpTimeline adds the Events in the dumped namespaces and the condition transitions of the nodes, the pods in
+// podsToLog, and the CRs of the dumper since the given time to artifacts as the timeline files.
+func (dumper *clusterDumper) dumpTimeline(
+	ctx context.Context, since time.Time, artifacts failurebundle.Writer, podsToLog []corev1.Pod) error {
+	entries, collectErr := dumper.collectTimeline(ctx, since, podsToLog)
+
+	sortTimeline(entries)
+
+	var jsonBuffer, textBuffer bytes.Buffer
+
+	err := writeTimeline(entries, &jsonBuffer, &textBuffer)
+	if err != nil {
+		return errors.Join(collectErr, err)
+	}
+
+	return errors.Join(collectErr,
+		artifacts.Add(timelineJSONFileName, jsonBuffer.Bytes()), artifacts.Add(timelineTextFileName, textBuffer.Bytes()))
+}
+
+// collectTimeline returns the timeline entries since the given time. Entries that could be collected are returned
+// along with the errors for the ones that could not.
+func (dumper *clusterDumper) collectTimeline(
+	ctx context.Context, since time.Time, podsToLog []corev1.Pod) ([]TimelineEntry, error) {
+	var (
+		entries []TimelineEntry
+		errs    []error
+	)
+
+	namespaces, err := dumper.kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list namespaces: %w", err))
+	} else {
+		for _, namespace := range namespaces.Items {
+			if !dumper.namespaceToLog(namespace.Name) {
+				continue
+			}
+
+			events, err := dumper.kubeClient.CoreV1().Events(namespace.Name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list events in namespace %s: %w", namespace.Name, err))
+
+				continue
+			}
+
+			entries = append(entries, eventTimelineEntries(events.Items, since)...)
+		}
+	}
+
+	nodes, err := dumper.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list nodes: %w", err))
+	} else {
+		for _, node := range nodes.Items {
+			for _, condition := range node.Status.Conditions {
+				entries = appendCondition(entries, since, "Node", "", node.Name, metav1.Condition{
+					Type:               string(condition.Type),
+					Status:             metav1.ConditionStatus(condition.Status),
+					LastTransitionTime: condition.LastTransitionTime,
+					Reason:             condition.Reason,
+					Message:            condition.Message,
+				})
+			}
+		}
+	}
+
+	for _, pod := range podsToLog {
+		for _, condition := range pod.Status.Conditions {
+			entries = appendCondition(entries, since, "Pod", pod.Namespace, pod.Name, metav1.Condition{
+				Type:               string(condition.Type),
+				Status:             metav1.ConditionStatus(condition.Status),
+				LastTransitionTime: condition.LastTransitionTime,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			})
+		}
+	}
+
+	for _, cr := range dumper.crs {
+		crEntries, err := dumper.crTimelineEntries(ctx, cr, since)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		entries = append(entries, crEntries...)
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// eventTimelineEntries returns the entries for the events last seen at or after since.
+func eventTimelineEntries(events []corev1.Event, since time.Time) []TimelineEntry {
+	var entries []TimelineEntry
+
+	for _, event := range events {
+		eventTime := eventLastSeen(event)
+		if eventTime.Before(since) {
+			continue
+		}
+
+		entries = append(entries, TimelineEntry{
+			Time:      eventTime,
+			Source:    TimelineSourceEvent,
+			Kind:      event.InvolvedObject.Kind,
+			Namespace: event.InvolvedObject.Namespace,
+			Name:      event.InvolvedObject.Name,
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     event.Count,
+		})
+	}
+
+	return entries
+}
+
+// eventLastSeen returns when event last occurred, using the first of the fields set by the different event APIs.
+func eventLastSeen(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// appendCondition appends an entry for condition to entries if it last transitioned at or after since.
+func appendCondition(
+	entries []TimelineEntry, since time.Time, kind, namespace, name string, condition metav1.Condition) []TimelineEntry {
+	if condition.LastTransitionTime.IsZero() || condition.LastTransitionTime.Time.Before(since) {
+		return entries
+	}
+
+	return append(entries, TimelineEntry{
+		Time:      condition.LastTransitionTime.Time,
+		Source:    TimelineSourceCondition,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Type:      condition.Type,
+		Status:    string(condition.Status),
+		Reason:    condition.Reason,
+		Message:   condition.Message,
+	})
+}
+
+// crTimelineEntries lists the objects of cr and returns the entries for the conditions in their status. Since CRs
+// are only known by their list type, the conditions are read from the unstructured form of the objects.
+func (dumper *clusterDumper) crTimelineEntries(
+	ctx context.Context, cr k8sreporter.CRData, since time.Time) ([]TimelineEntry, error) {
+	list, ok := cr.Cr.DeepCopyObject().(runtimeclient.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("failed to copy %T", cr.Cr)
+	}
+
+	var options []runtimeclient.ListOption
+	if cr.Namespace != nil {
+		options = append(options, runtimeclient.InNamespace(*cr.Namespace))
+	}
+
+	err := dumper.runtimeClient.List(ctx, list, options...)
+	if err != nil {
+		// As with the dump, this is expected when the CRD of an operator that is not installed is requested.
+		return nil, nil
+	}
+
+	unstructuredList, err := runtime.DefaultUnstructuredConverter.ToUnstructured(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T to unstructured: %w", list, err)
+	}
+
+	items, _, _ := unstructured.NestedSlice(unstructuredList, "items")
+	kind := strings.TrimSuffix(objectKind(dumper.runtimeClient.Scheme(), cr.Cr), "List")
+
+	var entries []TimelineEntry
+
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		namespace, _, _ := unstructured.NestedString(object, "metadata", "namespace")
+		name, _, _ := unstructured.NestedString(object, "metadata", "name")
+		conditions, _, _ := unstructured.NestedSlice(object, "status", "conditions")
+
+		for _, rawCondition := range conditions {
+			condition, ok := unstructuredCondition(rawCondition)
+			if ok {
+				entries = appendCondition(entries, since, kind, namespace, name, condition)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// unstructuredCondition converts a condition from the unstructured status of an object. Conditions without a type
+// or a parsable last transition time are ignored.
+func unstructuredCondition(rawCondition any) (metav1.Condition, bool) {
+	fields, ok := rawCondition.(map[string]any)
+	if !ok {
+		return metav1.Condition{}, false
+	}
+
+	conditionType, _, _ := unstructured.NestedString(fields, "type")
+	status, _, _ := unstructured.NestedString(fields, "status")
+	reason, _, _ := unstructured.NestedString(fields, "reason")
+	message, _, _ := unstructured.NestedString(fields, "message")
+	lastTransition, _, _ := unstructured.NestedString(fields, "lastTransitionTime")
+
+	transitionTime, err := time.Parse(time.RFC3339, lastTransition)
+	if conditionType == "" || err != nil {
+		return metav1.Condition{}, false
+	}
+
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionStatus(status),
+		LastTransitionTime: metav1.NewTime(transitionTime),
+		Reason:             reason,
+		Message:            message,
+	}, true
+}
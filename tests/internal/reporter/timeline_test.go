@@ -0,0 +1,149 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift-kni/k8sreporter"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	runtimefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var timelineTestStart = time.Date(2026, time.March, 4, 5, 6, 0, 0, time.UTC)
+
+func TestCollectTimeline(t *testing.T) {
+	at := func(seconds int) metav1.Time {
+		return metav1.NewTime(timelineTestStart.Add(time.Duration(seconds) * time.Second))
+	}
+
+	pod := buildDumpTestPod("dumped-ns", "dumped-pod")
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: at(1)},
+		{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: at(4), Reason: "ContainersNotReady"},
+		{Type: corev1.PodInitialized, Status: corev1.ConditionTrue, LastTransitionTime: at(-60)},
+	}
+
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dumped-ns"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other-ns"}},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, LastTransitionTime: at(3), Reason: "KubeletNotReady"},
+			}},
+		},
+		pod,
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "scheduled", Namespace: "dumped-ns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "dumped-ns", Name: "dumped-pod"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Scheduled",
+			LastTimestamp:  at(2),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "backoff", Namespace: "dumped-ns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "dumped-ns", Name: "dumped-pod"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          3,
+			LastTimestamp:  at(5),
+		},
+		&corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: "old", Namespace: "dumped-ns"},
+			LastTimestamp: at(-60),
+		},
+		&corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: "other", Namespace: "other-ns"},
+			LastTimestamp: at(2),
+		},
+	}
+
+	crScheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(crScheme))
+
+	dumpedNamespace := "dumped-ns"
+	dumper := &clusterDumper{
+		kubeClient:     fake.NewClientset(objects...),
+		runtimeClient:  runtimefake.NewClientBuilder().WithScheme(crScheme).WithRuntimeObjects(objects...).Build(),
+		namespaceToLog: namespaceFilter(map[string]string{dumpedNamespace: ""}),
+		// Pods are listed as a CR here to exercise reading conditions from the unstructured status.
+		crs: []k8sreporter.CRData{{Cr: &corev1.PodList{}, Namespace: &dumpedNamespace}},
+	}
+
+	entries, err := dumper.collectTimeline(t.Context(), timelineTestStart, nil)
+	assert.Nil(t, err)
+
+	sortTimeline(entries)
+
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, strings.Join(strings.Fields(entry.String())[1:], " "))
+	}
+
+	assert.Equal(t, []string{
+		"Condition PodScheduled=True Pod dumped-ns/dumped-pod",
+		"Event Normal Pod dumped-ns/dumped-pod Scheduled",
+		"Condition Ready=False Node node-1 KubeletNotReady",
+		"Condition Ready=False Pod dumped-ns/dumped-pod ContainersNotReady",
+		"Event Warning Pod dumped-ns/dumped-pod BackOff: Back-off restarting failed container (x3)",
+	}, lines)
+}
+
+func TestWriteTimeline(t *testing.T) {
+	entries := []TimelineEntry{{
+		Time:      timelineTestStart,
+		Source:    TimelineSourceCondition,
+		Kind:      "Pod",
+		Namespace: "test-ns",
+		Name:      "test-pod",
+		Type:      "Ready",
+		Status:    "False",
+		Message:   "multi\nline",
+	}}
+
+	var jsonBuffer, textBuffer bytes.Buffer
+
+	assert.Nil(t, writeTimeline(entries, &jsonBuffer, &textBuffer))
+	assert.Equal(t, fmt.Sprintf("2026-03-04T05:06:00Z Condition %-24s Pod test-ns/test-pod: multi line\n", "Ready=False"),
+		textBuffer.String())
+
+	var decoded TimelineEntry
+
+	assert.Nil(t, json.Unmarshal(jsonBuffer.Bytes(), &decoded))
+	assert.Equal(t, entries[0], decoded)
+}
+
+func TestEventLastSeen(t *testing.T) {
+	first := metav1.NewTime(timelineTestStart)
+	last := metav1.NewTime(timelineTestStart.Add(time.Minute))
+	series := metav1.NewMicroTime(timelineTestStart.Add(2 * time.Minute))
+
+	testCases := []struct {
+		event    corev1.Event
+		expected time.Time
+	}{
+		{event: corev1.Event{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: first}}, expected: first.Time},
+		{event: corev1.Event{FirstTimestamp: first, LastTimestamp: last}, expected: last.Time},
+		{event: corev1.Event{EventTime: series}, expected: series.Time},
+		{
+			event: corev1.Event{
+				EventTime: metav1.NewMicroTime(first.Time), Series: &corev1.EventSeries{LastObservedTime: series},
+			},
+			expected: series.Time,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, eventLastSeen(testCase.event))
+	}
+}
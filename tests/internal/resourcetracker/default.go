@@ -0,0 +1,250 @@
+package resourcetracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2" //nolint:depguard // necessary for deferring cleanup and the leak check
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// leakCheckTimeout is how long checking for leaked resources at the end of the suite may take.
+	leakCheckTimeout = 5 * time.Minute
+	// remainingEntryName is the name of the report entry listing the tracked resources that still existed after the
+	// cleanup of a spec.
+	remainingEntryName = "resourcetracker remaining resources"
+)
+
+var (
+	// defaultTracker is the tracker used by the package level functions. Each resource tracked with it is deleted when
+	// the Ginkgo node that tracked it goes out of scope.
+	defaultTracker = New(nil)
+	// leakCheckOnce makes sure RegisterLeakCheck only registers its node once.
+	leakCheckOnce sync.Once
+)
+
+// deferCleanup arranges for cleanupTracked to be called on resource when the current Ginkgo node goes out of scope. It
+// is a variable so that tests can run the cleanup without Ginkgo.
+var deferCleanup = func(resource trackedResource) {
+	ginkgo.DeferCleanup(func(ctx ginkgo.SpecContext) {
+		remaining, err := cleanupTracked(ctx, resource, ginkgo.CurrentSpecReport().Failed())
+		if err != nil {
+			klog.Errorf("Failed to clean up tracked resource %s: %v", resource, err)
+			ginkgo.AddReportEntry("Leaked resources", err.Error(), ginkgo.ReportEntryVisibilityFailureOrVerbose)
+		}
+
+		if len(remaining) > 0 {
+			ginkgo.AddReportEntry(remainingEntryName, toRemainingResources(remaining), ginkgo.ReportEntryVisibilityNever)
+		}
+	})
+}
+
+//nolint:gochecknoinits // registering the setup function must happen before inittools.Setup is called
+func init() {
+	inittools.RegisterSetup("resourcetracker", func(context.Context) error {
+		defaultTracker.mutex.Lock()
+		defer defaultTracker.mutex.Unlock()
+
+		defaultTracker.apiClient = inittools.APIClient
+		defaultTracker.keepOnFailure = inittools.GeneralConfig.KeepResourcesOnFailure
+
+		return nil
+	})
+}
+
+// Default returns the tracker used by the package level functions. It uses inittools.APIClient once inittools.Setup
+// has been called and keeps resources on failure if ECO_KEEP_RESOURCES_ON_FAILURE is set. Its Tracked method returns
+// the resources tracked by nodes that are still in scope.
+func Default() *Tracker {
+	return defaultTracker
+}
+
+// Track records the resource of builder with the default tracker. It must be called from a running Ginkgo node, since
+// the resource is deleted when that node goes out of scope, the same as with ginkgo.DeferCleanup: resources tracked in
+// a BeforeEach, It, or AfterEach are deleted after the spec, those tracked in a BeforeAll after the AfterAll of its
+// container, and those tracked in a BeforeSuite after the AfterSuite. Any cleanup the node registers itself runs first,
+// and resources tracked by the same node are deleted in the reverse of the order they were tracked, each waiting for
+// its finalizers.
+//
+// Tracking is opt-in: only resources passed to Track, TrackWithClient, or Created are deleted. Resources created by
+// eco-goinfra builders are not tracked on their own.
+func Track(builder any) error {
+	defaultTracker.mutex.Lock()
+	apiClient := defaultTracker.apiClient
+	defaultTracker.mutex.Unlock()
+
+	return TrackWithClient(apiClient, builder)
+}
+
+// TrackWithClient records the resource of builder with the default tracker, deleting it using apiClient. Like Track,
+// it must be called from a running Ginkgo node.
+func TrackWithClient(apiClient *clients.Settings, builder any) error {
+	resource, err := newTrackedResource(apiClient, builder)
+	if err != nil {
+		return err
+	}
+
+	if defaultTracker.add(resource) {
+		deferCleanup(resource)
+	}
+
+	return nil
+}
+
+// Created tracks the builder returned by a Create method with the default tracker if err is nil. It is meant to wrap
+// Create calls directly:
+//
+//	nsBuilder, err := resourcetracker.Created(namespace.NewBuilder(APIClient, nsName).Create())
+//
+// An error tracking the builder is returned if Create succeeded.
+func Created[B any](builder B, err error) (B, error) {
+	if err != nil {
+		return builder, err
+	}
+
+	return builder, Track(builder)
+}
+
+// RemainingResource is a tracked resource that still existed after its cleanup, either because it was kept on failure
+// or because deleting it failed. It is recorded in the report of the spec so that the leak check at the end of the
+// suite sees the resources of every parallel process, not only of the process running the check.
+type RemainingResource struct {
+	Resource
+	// Kubeconfig is the path to the kubeconfig of the cluster the resource is on.
+	Kubeconfig string
+}
+
+// cleanupTracked stops tracking resource with the default tracker and deletes it, unless specFailed is true and the
+// default tracker keeps resources on failure. It returns the resource if it still exists afterwards.
+func cleanupTracked(ctx context.Context, resource trackedResource, specFailed bool) ([]trackedResource, error) {
+	defaultTracker.remove(resource)
+
+	err := defaultTracker.delete(ctx, []trackedResource{resource}, specFailed)
+
+	remaining, existsErr := existingResources(ctx, []trackedResource{resource})
+
+	return remaining, errors.Join(err, existsErr)
+}
+
+// RegisterLeakCheck registers a Ginkgo node that logs any tracked resources still on the cluster at the end of the
+// suite. It should be called by the suite bootstrap before RunSpecs and only registers the node once. The node only
+// runs on the first parallel process, but the report it receives includes the specs of every process, so the remaining
+// resources recorded after each cleanup cover the whole suite.
+func RegisterLeakCheck() {
+	leakCheckOnce.Do(func() {
+		ginkgo.ReportAfterSuite("resourcetracker leak check", func(report ginkgo.Report) {
+			ctx, cancel := context.WithTimeout(context.Background(), leakCheckTimeout)
+			defer cancel()
+
+			leaked, err := leakedResources(ctx, remainingFromReport(report))
+			if err != nil {
+				klog.Errorf("Failed to check for leaked resources: %v", err)
+			}
+
+			for _, resource := range leaked {
+				klog.Warningf("Tracked resource %s still exists at the end of the suite on cluster %s",
+					resource.Resource, resource.Kubeconfig)
+			}
+		})
+	})
+}
+
+// toRemainingResources converts resources into the form recorded in spec reports.
+func toRemainingResources(resources []trackedResource) []RemainingResource {
+	remaining := make([]RemainingResource, 0, len(resources))
+
+	for _, resource := range resources {
+		remaining = append(remaining, RemainingResource{
+			Resource:   resource.Resource,
+			Kubeconfig: resource.apiClient.KubeconfigPath,
+		})
+	}
+
+	return remaining
+}
+
+// remainingFromReport returns the remaining resources recorded in every spec of report, without duplicates. Entries
+// recorded by the process reading the report hold the value itself, whereas entries from other processes have only
+// been received as JSON.
+func remainingFromReport(report ginkgo.Report) []RemainingResource {
+	var remaining []RemainingResource
+
+	for _, specReport := range report.SpecReports {
+		for _, entry := range specReport.ReportEntries {
+			if entry.Name != remainingEntryName {
+				continue
+			}
+
+			resources, ok := entry.GetRawValue().([]RemainingResource)
+			if !ok {
+				err := json.Unmarshal([]byte(entry.Value.AsJSON), &resources)
+				if err != nil {
+					klog.Errorf("Failed to read remaining resources of spec %q: %v", specReport.FullText(), err)
+
+					continue
+				}
+			}
+
+			for _, resource := range resources {
+				if !slices.Contains(remaining, resource) {
+					remaining = append(remaining, resource)
+				}
+			}
+		}
+	}
+
+	return remaining
+}
+
+// leakedResources returns the remaining resources that still exist. The default tracker's client is used for its own
+// cluster and a client is created for each other cluster.
+func leakedResources(ctx context.Context, remaining []RemainingResource) ([]RemainingResource, error) {
+	defaultTracker.mutex.Lock()
+	defaultClient := defaultTracker.apiClient
+	defaultTracker.mutex.Unlock()
+
+	apiClients := make(map[string]*clients.Settings)
+	if defaultClient != nil {
+		apiClients[defaultClient.KubeconfigPath] = defaultClient
+	}
+
+	var (
+		leaked []RemainingResource
+		errs   []error
+	)
+
+	for _, resource := range remaining {
+		apiClient, ok := apiClients[resource.Kubeconfig]
+		if !ok {
+			apiClient = clients.New(resource.Kubeconfig)
+			apiClients[resource.Kubeconfig] = apiClient
+		}
+
+		if apiClient == nil {
+			errs = append(errs, fmt.Errorf("%w for %s on cluster %s", ErrNoClient, resource.Resource, resource.Kubeconfig))
+
+			continue
+		}
+
+		exists, err := trackedResource{Resource: resource.Resource, apiClient: apiClient}.exists(ctx)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if exists {
+			leaked = append(leaked, resource)
+		}
+	}
+
+	return leaked, errors.Join(errs...)
+}
@@ -0,0 +1,559 @@
+package resourcetracker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// defaultDeleteTimeout is how long Cleanup waits by default for each group of resources to be removed.
+	defaultDeleteTimeout = 5 * time.Minute
+	// defaultPollInterval is how often Cleanup checks by default whether the deleted resources are gone.
+	defaultPollInterval = 2 * time.Second
+)
+
+var (
+	// ErrInvalidBuilder is returned when tracking a builder that has no Object or Definition to track.
+	ErrInvalidBuilder = errors.New("cannot track builder")
+	// ErrNoClient is returned when tracking a resource without an apiClient to delete it with.
+	ErrNoClient = errors.New("no apiClient to track resource with")
+	// ErrNotDeleted is returned by Cleanup when resources still exist after the delete timeout, usually because of
+	// finalizers.
+	ErrNotDeleted = errors.New("resources were not deleted in time")
+)
+
+// Resource identifies a tracked resource on the cluster.
+type Resource struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+}
+
+// String returns the resource as Kind/namespace/name, omitting the namespace for cluster scoped resources.
+func (resource Resource) String() string {
+	if resource.Namespace == "" {
+		return fmt.Sprintf("%s/%s", resource.GVK.Kind, resource.Name)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", resource.GVK.Kind, resource.Namespace, resource.Name)
+}
+
+// isNamespace returns whether the resource is a core Namespace.
+func (resource Resource) isNamespace() bool {
+	return resource.GVK.Group == "" && resource.GVK.Kind == "Namespace"
+}
+
+// deletionRank is the position of a resource in the deletion order. All resources of a lower rank are deleted and gone
+// before any resource of a higher rank is deleted.
+type deletionRank int
+
+const (
+	// rankNamespaced is the rank of namespaced resources not covered by another rank, including custom resources.
+	rankNamespaced deletionRank = iota
+	// rankClusterScoped is the rank of cluster scoped resources not covered by another rank, such as MachineConfigs.
+	rankClusterScoped
+	// rankOperator is the rank of the OLM resources installing operators. They are deleted after the custom
+	// resources, so that the operator is still running to remove their finalizers.
+	rankOperator
+	// rankCRD is the rank of CustomResourceDefinitions, which are deleted once no custom resources are left.
+	rankCRD
+	// rankNamespace is the rank of namespaces, which are deleted once everything in them is gone.
+	rankNamespace
+)
+
+// olmGroup is the API group of the OLM resources, such as Subscriptions and ClusterServiceVersions.
+const olmGroup = "operators.coreos.com"
+
+// rank returns the deletion rank of the resource.
+func (resource Resource) rank() deletionRank {
+	switch {
+	case resource.isNamespace():
+		return rankNamespace
+	case resource.GVK.Group == "apiextensions.k8s.io" && resource.GVK.Kind == "CustomResourceDefinition":
+		return rankCRD
+	case resource.GVK.Group == olmGroup:
+		return rankOperator
+	case resource.Namespace == "":
+		return rankClusterScoped
+	default:
+		return rankNamespaced
+	}
+}
+
+// trackedResource is a Resource along with the client it was created with, so resources on other clusters are deleted
+// from the right cluster.
+type trackedResource struct {
+	Resource
+
+	apiClient *clients.Settings
+}
+
+// newObject returns an empty unstructured object identifying the resource, usable with the runtime client regardless
+// of the Go type it was created from.
+func (resource trackedResource) newObject() *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(resource.GVK)
+	object.SetNamespace(resource.Namespace)
+	object.SetName(resource.Name)
+
+	return object
+}
+
+// Tracker records the resources created during a spec so they can be deleted when the spec ends, even if it aborted
+// before its own cleanup. It is safe for concurrent use.
+type Tracker struct {
+	mutex         sync.Mutex
+	apiClient     *clients.Settings
+	keepOnFailure bool
+	deleteTimeout time.Duration
+	pollInterval  time.Duration
+
+	// resources are the resources tracked since the last Cleanup, in the order they were tracked.
+	resources []trackedResource
+	// history is every resource ever tracked, used to check for leaks at the end of the suite.
+	history []trackedResource
+}
+
+// New returns a Tracker that uses apiClient for resources tracked with Track. The apiClient may be nil if all resources
+// are tracked using TrackWithClient.
+func New(apiClient *clients.Settings) *Tracker {
+	return &Tracker{
+		apiClient:     apiClient,
+		deleteTimeout: defaultDeleteTimeout,
+		pollInterval:  defaultPollInterval,
+	}
+}
+
+// WithKeepOnFailure sets whether Cleanup leaves the resources of failed specs in place for debugging.
+func (tracker *Tracker) WithKeepOnFailure(keepOnFailure bool) *Tracker {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.keepOnFailure = keepOnFailure
+
+	return tracker
+}
+
+// WithDeleteTimeout sets how long Cleanup waits for each group of deleted resources to be removed, including the time
+// for their finalizers to run.
+func (tracker *Tracker) WithDeleteTimeout(deleteTimeout time.Duration) *Tracker {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.deleteTimeout = deleteTimeout
+
+	return tracker
+}
+
+// WithPollInterval sets how often Cleanup checks whether deleted resources are gone.
+func (tracker *Tracker) WithPollInterval(pollInterval time.Duration) *Tracker {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.pollInterval = pollInterval
+
+	return tracker
+}
+
+// Track records the resource of an eco-goinfra builder so it is deleted by the next Cleanup. The builder must be a
+// pointer to a struct with an Object or Definition field, which all eco-goinfra builders have. Tracking a resource that
+// is already tracked has no effect.
+func (tracker *Tracker) Track(builder any) error {
+	tracker.mutex.Lock()
+	apiClient := tracker.apiClient
+	tracker.mutex.Unlock()
+
+	return tracker.TrackWithClient(apiClient, builder)
+}
+
+// TrackWithClient is like Track but deletes the resource using apiClient instead of the client of the tracker, such as
+// for resources created on a spoke cluster.
+func (tracker *Tracker) TrackWithClient(apiClient *clients.Settings, builder any) error {
+	resource, err := newTrackedResource(apiClient, builder)
+	if err != nil {
+		return err
+	}
+
+	tracker.add(resource)
+
+	return nil
+}
+
+// newTrackedResource returns the resource of builder, to be deleted using apiClient.
+func newTrackedResource(apiClient *clients.Settings, builder any) (trackedResource, error) {
+	if apiClient == nil {
+		return trackedResource{}, ErrNoClient
+	}
+
+	object, err := objectFromBuilder(builder)
+	if err != nil {
+		return trackedResource{}, err
+	}
+
+	gvk, err := apiutil.GVKForObject(object, apiClient.Client.Scheme())
+	if err != nil {
+		return trackedResource{}, fmt.Errorf("%w: failed to get GVK of %T: %w", ErrInvalidBuilder, builder, err)
+	}
+
+	return trackedResource{
+		Resource:  Resource{GVK: gvk, Namespace: object.GetNamespace(), Name: object.GetName()},
+		apiClient: apiClient,
+	}, nil
+}
+
+// add records resource so it is deleted by the next Cleanup. It returns false if the resource was already tracked.
+func (tracker *Tracker) add(resource trackedResource) bool {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if !slices.ContainsFunc(tracker.history, resource.sameResource) {
+		tracker.history = append(tracker.history, resource)
+	}
+
+	if slices.ContainsFunc(tracker.resources, resource.sameResource) {
+		return false
+	}
+
+	klog.V(90).Infof("Tracking %s for deletion", resource)
+
+	tracker.resources = append(tracker.resources, resource)
+
+	return true
+}
+
+// remove stops tracking resource without deleting it. It is still checked by Leaked.
+func (tracker *Tracker) remove(resource trackedResource) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.resources = slices.DeleteFunc(tracker.resources, resource.sameResource)
+}
+
+// Tracked returns the resources that will be deleted by the next Cleanup, in the order they were tracked.
+func (tracker *Tracker) Tracked() []Resource {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	return toResources(tracker.resources)
+}
+
+// Cleanup deletes the resources tracked since the last Cleanup in reverse dependency order and waits for each group to
+// be removed, including any finalizers, before deleting the next. Resources are deleted in the following order:
+//
+//  1. Namespaced resources, including custom resources.
+//  2. Cluster scoped resources.
+//  3. The OLM resources of operators, such as Subscriptions and ClusterServiceVersions.
+//  4. CustomResourceDefinitions.
+//  5. Namespaces.
+//
+// Within each step, resources owned by another tracked resource, according to their ownerReferences on the cluster,
+// are deleted before their owner. Otherwise, resources are deleted in the reverse of the order they were tracked. If
+// specFailed is true and the tracker keeps resources on failure, nothing is deleted. The tracked resources are cleared
+// either way.
+func (tracker *Tracker) Cleanup(ctx context.Context, specFailed bool) error {
+	_, err := tracker.cleanup(ctx, specFailed)
+
+	return err
+}
+
+// cleanup is Cleanup but also returns the resources that were tracked since the last cleanup, whether or not they were
+// deleted.
+func (tracker *Tracker) cleanup(ctx context.Context, specFailed bool) ([]trackedResource, error) {
+	tracker.mutex.Lock()
+	resources := tracker.resources
+	tracker.resources = nil
+	tracker.mutex.Unlock()
+
+	return resources, tracker.delete(ctx, resources, specFailed)
+}
+
+// delete deletes resources in the order described by Cleanup, unless specFailed is true and the tracker keeps resources
+// on failure.
+func (tracker *Tracker) delete(ctx context.Context, resources []trackedResource, specFailed bool) error {
+	tracker.mutex.Lock()
+	keepOnFailure := tracker.keepOnFailure
+	deleteTimeout := tracker.deleteTimeout
+	pollInterval := tracker.pollInterval
+	tracker.mutex.Unlock()
+
+	if len(resources) == 0 {
+		return nil
+	}
+
+	if specFailed && keepOnFailure {
+		klog.Infof("Spec failed, keeping %d resources for debugging: %v", len(resources), toResources(resources))
+
+		return nil
+	}
+
+	var errs []error
+
+	for _, group := range deletionGroups(ctx, resources) {
+		for _, resource := range group {
+			klog.V(90).Infof("Deleting tracked resource %s", resource)
+
+			err := resource.apiClient.Client.Delete(
+				ctx, resource.newObject(), runtimeclient.PropagationPolicy(metav1.DeletePropagationForeground))
+			if err != nil && !k8serrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete %s: %w", resource, err))
+			}
+		}
+
+		err := waitForDeletion(ctx, group, deleteTimeout, pollInterval)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Leaked returns every resource ever tracked by the tracker that still exists on the cluster, such as resources kept
+// on failure or that failed to be deleted. Errors checking whether a resource exists are returned after checking the
+// rest.
+func (tracker *Tracker) Leaked(ctx context.Context) ([]Resource, error) {
+	tracker.mutex.Lock()
+	history := slices.Clone(tracker.history)
+	tracker.mutex.Unlock()
+
+	existing, err := existingResources(ctx, history)
+
+	return toResources(existing), err
+}
+
+// existingResources returns the resources that still exist on the cluster. Errors checking whether a resource exists
+// are returned after checking the rest.
+func existingResources(ctx context.Context, resources []trackedResource) ([]trackedResource, error) {
+	var (
+		existing []trackedResource
+		errs     []error
+	)
+
+	for _, resource := range resources {
+		exists, err := resource.exists(ctx)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if exists {
+			existing = append(existing, resource)
+		}
+	}
+
+	return existing, errors.Join(errs...)
+}
+
+// sameResource returns whether other identifies the same resource on the same cluster.
+func (resource trackedResource) sameResource(other trackedResource) bool {
+	return resource.Resource == other.Resource && resource.apiClient == other.apiClient
+}
+
+// exists returns whether the resource still exists on the cluster.
+func (resource trackedResource) exists(ctx context.Context) (bool, error) {
+	err := resource.apiClient.Client.Get(ctx, runtimeclient.ObjectKey{
+		Namespace: resource.Namespace, Name: resource.Name}, resource.newObject())
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w", resource, err)
+	}
+
+	return true, nil
+}
+
+// waitForDeletion waits for all of the resources to be gone from the cluster. On timeout, the returned error lists the
+// remaining resources along with their finalizers.
+func waitForDeletion(
+	ctx context.Context, resources []trackedResource, timeout, pollInterval time.Duration) error {
+	remaining := resources
+
+	err := wait.PollUntilContextTimeout(ctx, pollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var stillExists []trackedResource
+
+		for _, resource := range remaining {
+			exists, err := resource.exists(ctx)
+			if err != nil || exists {
+				stillExists = append(stillExists, resource)
+			}
+		}
+
+		remaining = stillExists
+
+		return len(remaining) == 0, nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	var details []string
+
+	for _, resource := range remaining {
+		object := resource.newObject()
+
+		getErr := resource.apiClient.Client.Get(ctx, runtimeclient.ObjectKeyFromObject(object), object)
+		if getErr != nil {
+			details = append(details, fmt.Sprintf("%s (%v)", resource, getErr))
+
+			continue
+		}
+
+		details = append(details, fmt.Sprintf("%s (finalizers: %v)", resource, object.GetFinalizers()))
+	}
+
+	return fmt.Errorf("%w: %s: %w", ErrNotDeleted, strings.Join(details, ", "), err)
+}
+
+// deletionGroups splits resources into the groups that must be deleted one after the other. Groups are ordered by the
+// rank of their resources and then by ownership, so that within a rank, resources owned by other tracked resources are
+// in an earlier group than their owners. Each group is in the reverse of the order the resources were tracked so that
+// resources are deleted before the resources they were created from.
+func deletionGroups(ctx context.Context, resources []trackedResource) [][]trackedResource {
+	heights := ownershipHeights(ctx, resources)
+
+	type groupKey struct {
+		rank   deletionRank
+		height int
+	}
+
+	groups := make(map[groupKey][]trackedResource)
+
+	for index, resource := range slices.Backward(resources) {
+		key := groupKey{rank: resource.rank(), height: heights[index]}
+		groups[key] = append(groups[key], resource)
+	}
+
+	keys := slices.SortedFunc(maps.Keys(groups), func(first, second groupKey) int {
+		if first.rank != second.rank {
+			return cmp.Compare(first.rank, second.rank)
+		}
+
+		return cmp.Compare(first.height, second.height)
+	})
+
+	result := make([][]trackedResource, 0, len(keys))
+
+	for _, key := range keys {
+		result = append(result, groups[key])
+	}
+
+	return result
+}
+
+// ownershipHeights returns, for each resource, the length of the longest chain of tracked resources it owns, either
+// directly or through other tracked resources. Resources that own no tracked resources have a height of zero. Owners
+// are read from the ownerReferences of the resources on the cluster, and resources that cannot be read are treated as
+// having no owners.
+func ownershipHeights(ctx context.Context, resources []trackedResource) []int {
+	owners := make([][]int, len(resources))
+
+	for childIndex, child := range resources {
+		object := child.newObject()
+
+		err := child.apiClient.Client.Get(ctx, runtimeclient.ObjectKeyFromObject(object), object)
+		if err != nil {
+			continue
+		}
+
+		for _, reference := range object.GetOwnerReferences() {
+			for ownerIndex, owner := range resources {
+				if ownerIndex != childIndex && child.ownedBy(owner, reference) {
+					owners[childIndex] = append(owners[childIndex], ownerIndex)
+				}
+			}
+		}
+	}
+
+	heights := make([]int, len(resources))
+
+	// Each pass raises owners above their children, so after len(resources) passes every chain without a cycle has
+	// been fully propagated. Ownership cycles, which Kubernetes does not allow, cannot make this loop forever.
+	for range resources {
+		changed := false
+
+		for childIndex, ownerIndices := range owners {
+			for _, ownerIndex := range ownerIndices {
+				if heights[ownerIndex] <= heights[childIndex] {
+					heights[ownerIndex] = heights[childIndex] + 1
+					changed = true
+				}
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return heights
+}
+
+// ownedBy returns whether reference, an owner reference of the resource, refers to owner. Owners must be on the same
+// cluster and either cluster scoped or in the same namespace as the resource.
+func (resource trackedResource) ownedBy(owner trackedResource, reference metav1.OwnerReference) bool {
+	if resource.apiClient != owner.apiClient || reference.Kind != owner.GVK.Kind || reference.Name != owner.Name {
+		return false
+	}
+
+	if owner.Namespace != "" && owner.Namespace != resource.Namespace {
+		return false
+	}
+
+	groupVersion, err := schema.ParseGroupVersion(reference.APIVersion)
+
+	return err == nil && groupVersion.Group == owner.GVK.Group
+}
+
+// objectFromBuilder returns the Object field of builder, as last pulled from the cluster, falling back to the
+// Definition field if the Object is nil.
+func objectFromBuilder(builder any) (runtimeclient.Object, error) {
+	value := reflect.ValueOf(builder)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w %T: builder must be a non-nil pointer to a struct", ErrInvalidBuilder, builder)
+	}
+
+	for _, fieldName := range []string{"Object", "Definition"} {
+		field := value.Elem().FieldByName(fieldName)
+		if !field.IsValid() || field.Kind() != reflect.Pointer || field.IsNil() {
+			continue
+		}
+
+		if object, ok := field.Interface().(runtimeclient.Object); ok && object.GetName() != "" {
+			return object, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %T: builder has no Object or Definition with a name", ErrInvalidBuilder, builder)
+}
+
+// toResources returns the Resource of each tracked resource.
+func toResources(resources []trackedResource) []Resource {
+	result := make([]Resource, 0, len(resources))
+
+	for _, resource := range resources {
+		result = append(result, resource.Resource)
+	}
+
+	return result
+}
@@ -0,0 +1,325 @@
+package resourcetracker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/configmap"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/namespace"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const testNamespace = "test-ns"
+
+// testBuilder mimics the exported fields of an eco-goinfra builder.
+type testBuilder struct {
+	Definition *rbacv1.ClusterRole
+	Object     *rbacv1.ClusterRole
+}
+
+func TestTrack(t *testing.T) {
+	apiClient := buildTestClient(nil, nil)
+
+	testCases := []struct {
+		builder       any
+		expectedError error
+	}{
+		{builder: namespace.NewBuilder(apiClient, testNamespace), expectedError: nil},
+		{builder: configmap.NewBuilder(apiClient, "test-cm", testNamespace), expectedError: nil},
+		{builder: &testBuilder{Object: buildClusterRole("test-role")}, expectedError: nil},
+		{builder: namespace.NewBuilder(apiClient, testNamespace), expectedError: nil},
+		{builder: nil, expectedError: ErrInvalidBuilder},
+		{builder: testBuilder{}, expectedError: ErrInvalidBuilder},
+		{builder: &testBuilder{}, expectedError: ErrInvalidBuilder},
+	}
+
+	tracker := New(apiClient)
+
+	for _, testCase := range testCases {
+		assert.ErrorIs(t, tracker.Track(testCase.builder), testCase.expectedError)
+	}
+
+	tracked := tracker.Tracked()
+	if assert.Len(t, tracked, 3) {
+		assert.Equal(t, "Namespace/test-ns", tracked[0].String())
+		assert.Equal(t, "ConfigMap/test-ns/test-cm", tracked[1].String())
+		assert.Equal(t, "ClusterRole/test-role", tracked[2].String())
+	}
+
+	assert.ErrorIs(t, New(nil).Track(namespace.NewBuilder(apiClient, testNamespace)), ErrNoClient)
+}
+
+func TestCleanup(t *testing.T) {
+	var deleted []string
+
+	apiClient := buildTestClient([]runtime.Object{
+		buildNamespace(testNamespace),
+		buildConfigMap("test-cm"),
+		buildClusterRole("test-role"),
+	}, &interceptor.Funcs{
+		Delete: func(
+			ctx context.Context, client runtimeclient.WithWatch, obj runtimeclient.Object, opts ...runtimeclient.DeleteOption,
+		) error {
+			deleted = append(deleted, obj.GetName())
+
+			return client.Delete(ctx, obj, opts...)
+		},
+	})
+
+	tracker := New(apiClient).WithPollInterval(10 * time.Millisecond)
+
+	// Track the namespace first so that deleting in reverse order alone would delete it before the ConfigMap.
+	assert.Nil(t, tracker.Track(namespace.NewBuilder(apiClient, testNamespace)))
+	assert.Nil(t, tracker.Track(&testBuilder{Definition: buildClusterRole("test-role")}))
+	assert.Nil(t, tracker.Track(configmap.NewBuilder(apiClient, "test-cm", testNamespace)))
+	assert.Nil(t, tracker.Track(configmap.NewBuilder(apiClient, "already-deleted", testNamespace)))
+
+	assert.Nil(t, tracker.Cleanup(t.Context(), false))
+	assert.Equal(t, []string{"already-deleted", "test-cm", "test-role", testNamespace}, deleted)
+	assert.Empty(t, tracker.Tracked())
+
+	leaked, err := tracker.Leaked(t.Context())
+	assert.Nil(t, err)
+	assert.Empty(t, leaked)
+}
+
+func TestCleanupKeepOnFailure(t *testing.T) {
+	apiClient := buildTestClient([]runtime.Object{buildNamespace(testNamespace)}, nil)
+
+	tracker := New(apiClient).WithKeepOnFailure(true)
+	assert.Nil(t, tracker.Track(namespace.NewBuilder(apiClient, testNamespace)))
+
+	assert.Nil(t, tracker.Cleanup(t.Context(), true))
+	assert.Empty(t, tracker.Tracked())
+
+	leaked, err := tracker.Leaked(t.Context())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Namespace/test-ns"}, resourceStrings(leaked))
+}
+
+func TestCleanupFinalizers(t *testing.T) {
+	finalizedRole := buildClusterRole("finalized-role")
+	finalizedRole.Finalizers = []string{"test.io/finalizer"}
+
+	apiClient := buildTestClient([]runtime.Object{finalizedRole}, nil)
+
+	tracker := New(apiClient).WithDeleteTimeout(50 * time.Millisecond).WithPollInterval(10 * time.Millisecond)
+	assert.Nil(t, tracker.Track(&testBuilder{Object: finalizedRole}))
+
+	err := tracker.Cleanup(t.Context(), false)
+	assert.ErrorIs(t, err, ErrNotDeleted)
+	assert.Contains(t, err.Error(), "ClusterRole/finalized-role (finalizers: [test.io/finalizer])")
+
+	leaked, err := tracker.Leaked(t.Context())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ClusterRole/finalized-role"}, resourceStrings(leaked))
+}
+
+func TestCreated(t *testing.T) {
+	apiClient := buildTestClient(nil, nil)
+	cleanups := useTestDefaultTracker(t, apiClient)
+
+	nsBuilder, err := Created(namespace.NewBuilder(apiClient, testNamespace), nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, nsBuilder)
+
+	_, err = Created(configmap.NewBuilder(apiClient, "test-cm", testNamespace), context.Canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = Created(namespace.NewBuilder(apiClient, testNamespace), nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"Namespace/test-ns"}, resourceStrings(Default().Tracked()))
+	assert.Equal(t, []string{"Namespace/test-ns"}, resourceStrings(toResources(*cleanups)))
+}
+
+func TestCleanupTracked(t *testing.T) {
+	apiClient := buildTestClient([]runtime.Object{
+		buildNamespace(testNamespace),
+		buildConfigMap("test-cm"),
+	}, nil)
+	cleanups := useTestDefaultTracker(t, apiClient)
+
+	// The namespace is tracked by an outer node, such as a BeforeAll, and the ConfigMap by a spec. Cleaning up after
+	// the spec must leave the namespace in place.
+	assert.Nil(t, Track(namespace.NewBuilder(apiClient, testNamespace)))
+	assert.Nil(t, Track(configmap.NewBuilder(apiClient, "test-cm", testNamespace)))
+
+	if !assert.Len(t, *cleanups, 2) {
+		return
+	}
+
+	remaining, err := cleanupTracked(t.Context(), (*cleanups)[1], false)
+	assert.Nil(t, err)
+	assert.Empty(t, remaining)
+	assert.Equal(t, []string{"Namespace/test-ns"}, resourceStrings(Default().Tracked()))
+
+	leaked, err := Default().Leaked(t.Context())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Namespace/test-ns"}, resourceStrings(leaked))
+
+	defaultTracker.WithKeepOnFailure(true)
+
+	remaining, err = cleanupTracked(t.Context(), (*cleanups)[0], true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Namespace/test-ns"}, resourceStrings(toResources(remaining)))
+	assert.Empty(t, Default().Tracked())
+}
+
+func TestDeletionGroups(t *testing.T) {
+	ownerMap := buildConfigMap("owner-cm")
+	childMap := buildConfigMap("child-cm")
+	childMap.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner-cm"}}
+
+	apiClient := buildTestClient([]runtime.Object{
+		buildNamespace(testNamespace), ownerMap, childMap, buildClusterRole("test-role"),
+	}, nil)
+	tracker := New(apiClient)
+
+	// Track resources in creation order, so that deleting in reverse order alone would delete the owner, the operator,
+	// and the CRD before the resources depending on them.
+	assert.Nil(t, tracker.Track(namespace.NewBuilder(apiClient, testNamespace)))
+	assert.Nil(t, tracker.Track(&unstructuredBuilder{Definition: buildUnstructured(
+		"apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.test.io")}))
+	assert.Nil(t, tracker.Track(&unstructuredBuilder{Definition: buildUnstructured(
+		"operators.coreos.com/v1alpha1", "Subscription", testNamespace, "widget-operator")}))
+	assert.Nil(t, tracker.Track(&testBuilder{Object: buildClusterRole("test-role")}))
+	assert.Nil(t, tracker.Track(configmap.NewBuilder(apiClient, "owner-cm", testNamespace)))
+	assert.Nil(t, tracker.Track(configmap.NewBuilder(apiClient, "child-cm", testNamespace)))
+	assert.Nil(t, tracker.Track(&unstructuredBuilder{Definition: buildUnstructured(
+		"test.io/v1", "Widget", testNamespace, "test-widget")}))
+
+	tracker.mutex.Lock()
+	resources := tracker.resources
+	tracker.mutex.Unlock()
+
+	var groups [][]string
+
+	for _, group := range deletionGroups(t.Context(), resources) {
+		groups = append(groups, resourceStrings(toResources(group)))
+	}
+
+	assert.Equal(t, [][]string{
+		{"Widget/test-ns/test-widget", "ConfigMap/test-ns/child-cm"},
+		{"ConfigMap/test-ns/owner-cm"},
+		{"ClusterRole/test-role"},
+		{"Subscription/test-ns/widget-operator"},
+		{"CustomResourceDefinition/widgets.test.io"},
+		{"Namespace/test-ns"},
+	}, groups)
+}
+
+func TestRemainingFromReport(t *testing.T) {
+	apiClient := buildTestClient([]runtime.Object{buildNamespace(testNamespace)}, nil)
+	useTestDefaultTracker(t, apiClient)
+
+	namespaceResource := RemainingResource{
+		Resource: Resource{GVK: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, Name: testNamespace},
+	}
+	deletedResource := RemainingResource{
+		Resource: Resource{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Namespace: testNamespace,
+			Name: "deleted-cm"},
+	}
+
+	// Entries from the local process hold the value, whereas entries from other processes only hold its JSON.
+	otherProcessJSON, err := json.Marshal([]RemainingResource{namespaceResource, deletedResource})
+	assert.Nil(t, err)
+
+	report := types.Report{SpecReports: types.SpecReports{
+		{ReportEntries: types.ReportEntries{{
+			Name:  remainingEntryName,
+			Value: types.WrapEntryValue([]RemainingResource{namespaceResource}),
+		}}},
+		{ReportEntries: types.ReportEntries{{
+			Name:  remainingEntryName,
+			Value: types.ReportEntryValue{AsJSON: string(otherProcessJSON)},
+		}}},
+		{ReportEntries: types.ReportEntries{{Name: "unrelated", Value: types.WrapEntryValue("value")}}},
+	}}
+
+	remaining := remainingFromReport(report)
+	assert.Equal(t, []RemainingResource{namespaceResource, deletedResource}, remaining)
+
+	leaked, err := leakedResources(t.Context(), remaining)
+	assert.Nil(t, err)
+	assert.Equal(t, []RemainingResource{namespaceResource}, leaked)
+}
+
+// useTestDefaultTracker replaces the default tracker with one using apiClient for the duration of the test. The
+// returned slice collects the resources whose cleanup would have been deferred to the end of the Ginkgo node.
+func useTestDefaultTracker(t *testing.T, apiClient *clients.Settings) *[]trackedResource {
+	t.Helper()
+
+	var cleanups []trackedResource
+
+	originalTracker, originalDeferCleanup := defaultTracker, deferCleanup
+	defaultTracker = New(apiClient).WithPollInterval(10 * time.Millisecond)
+	deferCleanup = func(resource trackedResource) { cleanups = append(cleanups, resource) }
+
+	t.Cleanup(func() { defaultTracker, deferCleanup = originalTracker, originalDeferCleanup })
+
+	return &cleanups
+}
+
+func buildTestClient(objects []runtime.Object, interceptorFuncs *interceptor.Funcs) *clients.Settings {
+	testParams := clients.TestClientParams{
+		K8sMockObjects:  objects,
+		SchemeAttachers: []clients.SchemeAttacher{corev1.AddToScheme, rbacv1.AddToScheme},
+	}
+
+	if interceptorFuncs != nil {
+		testParams.InterceptorFuncs = *interceptorFuncs
+	}
+
+	return clients.GetTestClients(testParams)
+}
+
+func buildNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func buildConfigMap(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}}
+}
+
+func buildClusterRole(name string) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+// unstructuredBuilder mimics an eco-goinfra builder for a resource without a Go type in the scheme.
+type unstructuredBuilder struct {
+	Definition *unstructured.Unstructured
+}
+
+func buildUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+
+	return object
+}
+
+func resourceStrings(resources []Resource) []string {
+	var result []string
+
+	for _, resource := range resources {
+		result = append(result, resource.String())
+	}
+
+	return result
+}
@@ -2,19 +2,31 @@ package systemreporter
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	re "regexp"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/onsi/ginkgo/v2/types"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/nodes"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
-	"golang.org/x/crypto/ssh"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 	"k8s.io/klog/v2"
 )
 
+const (
+	// gatherWorkers is the maximum number of commands run at the same time when gathering system information.
+	gatherWorkers = 8
+	// gatherCommandTimeout is how long each command may run when gathering system information.
+	gatherCommandTimeout = 2 * time.Minute
+)
+
 var (
 	// Matches option hypens, spaces, and special characters.
 	specialChars = re.MustCompile(`-?\s-?|[/|'"\.\[\]]`)
@@ -28,6 +40,7 @@ var (
 
 // ReportIfFailedFromClient dumps the requested command output
 // from nodes pulled from specified apiClient if test case fails.
+// Output from the nodes of every other registered cluster is dumped to a subdirectory named after the cluster.
 func ReportIfFailedFromClient(
 	report types.SpecReport, testSuite string, commands []string, apiClient *clients.Settings) {
 	if types.SpecStateFailureStates.Is(report.State) {
@@ -37,137 +50,203 @@ func ReportIfFailedFromClient(
 			return
 		}
 
-		dumpDir := GeneralConfig.GetDumpFailedTestReportLocation(testSuite)
-
-		tcReportFolderName := strings.ReplaceAll(report.FullText(), " ", "_")
-
-		systemFolder := fmt.Sprintf("%s/%s/system", dumpDir, tcReportFolderName)
-
-		err := os.MkdirAll(systemFolder, 0755)
+		artifacts, err := specSystemArtifacts(report, testSuite)
 		if err != nil {
-			klog.Errorf("failed creating dir for system info: %s", err)
-
 			return
 		}
 
-		GatherInfoThroughKubeClient(commands, systemFolder, apiClient)
+		gatherInfoThroughKubeClient(commands, artifacts, apiClient)
+		gatherInfoFromRegisteredClusters(commands, artifacts, apiClient.KubeconfigPath)
 	}
 }
 
 // ReportIfFailedFromNodeList dumps the requested command output from specified nodes through SSH if test case fails.
 func ReportIfFailedFromNodeList(report types.SpecReport, testSuite string, commands []string, nodes []string) {
 	if types.SpecStateFailureStates.Is(report.State) {
-		dumpDir := GeneralConfig.GetDumpFailedTestReportLocation(testSuite)
-
-		tcReportFolderName := strings.ReplaceAll(report.FullText(), " ", "_")
-
-		systemFolder := fmt.Sprintf("%s/%s/system", dumpDir, tcReportFolderName)
-
-		err := os.MkdirAll(systemFolder, 0755)
+		artifacts, err := specSystemArtifacts(report, testSuite)
 		if err != nil {
-			klog.Errorf("failed to create directory for storing system info %s", err)
-
 			return
 		}
 
-		GatherInfoThroughSSH(commands, systemFolder, GeneralConfig.SSHKeyPath, nodes)
+		gatherInfoThroughSSH(commands, artifacts, GeneralConfig.SSHKeyPath, nodes)
 	}
 }
 
+// specSystemArtifacts returns the writer for the system directory of the artifacts of the failed spec in report. Errors
+// other than dumping being disabled are logged.
+//
+//nolint:ireturn // the writer returned by the reporter is an interface.
+func specSystemArtifacts(report types.SpecReport, testSuite string) (failurebundle.Writer, error) {
+	artifacts, err := reporter.SpecArtifacts(report, testSuite)
+	if err != nil {
+		if !errors.Is(err, reporter.ErrDumpDisabled) {
+			klog.Errorf("failed to open artifacts for system info: %s", err)
+		}
+
+		return nil, err
+	}
+
+	return failurebundle.WithPrefix(artifacts, "system"), nil
+}
+
 // GatherInfoThroughSSH gathers command output from specified nodes
-// and writes output to specified directory.
+// and writes output to specified directory. Host keys are verified against the known_hosts file from
+// ECO_SSH_KNOWN_HOSTS_PATH and nodes are reached through ECO_SSH_JUMP_HOST if it is set.
 func GatherInfoThroughSSH(commands []string, outputdir string, sshKeyPath string, nodes []string) {
+	gatherInfoThroughSSH(commands, failurebundle.NewDirectory(outputdir, 0, nil), sshKeyPath, nodes)
+}
+
+// GatherInfoThroughKubeClient gathers command output from nodes accessible from APIClient
+// and writes output to specified directory.
+func GatherInfoThroughKubeClient(commands []string, outputdir string, apiClient *clients.Settings) {
+	gatherInfoThroughKubeClient(commands, failurebundle.NewDirectory(outputdir, 0, nil), apiClient)
+}
+
+// gatherInfoThroughSSH gathers command output from the specified nodes through SSH and writes it to artifacts.
+func gatherInfoThroughSSH(commands []string, artifacts failurebundle.Writer, sshKeyPath string, nodes []string) {
 	if sshKeyPath == "" {
 		klog.Errorf("cannot gather system information without providing ssh key path")
 
 		return
 	}
 
-	privateKey, err := os.ReadFile(sshKeyPath)
+	executor, err := newSSHExecutor(sshKeyPath)
 	if err != nil {
-		klog.Errorf("failed to read private ssh key from system: %s", err)
+		klog.Errorf("failed to create ssh executor: %s", err)
 
 		return
 	}
 
-	signer, err := ssh.ParsePrivateKey(privateKey)
-	if err != nil {
-		klog.Errorf("failed to parse private ssh key: %s", err)
+	defer executor.Close()
+
+	gatherInfo(context.TODO(), executor, commands, nodes, artifacts)
+}
+
+// gatherInfoThroughKubeClient gathers command output from the nodes of apiClient and writes it to artifacts.
+func gatherInfoThroughKubeClient(commands []string, artifacts failurebundle.Writer, apiClient *clients.Settings) {
+	if apiClient == nil {
+		klog.Errorf("cannot gather system information from nil APIClient")
 
 		return
 	}
 
-	config := ssh.ClientConfig{
-		User:            GeneralConfig.SSHUser,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
+	nodeList, err := nodes.List(apiClient)
+	if err != nil {
+		klog.Errorf("failed to list nodes to gather system information from: %s", err)
+
+		return
 	}
 
-	for _, node := range nodes {
-		client, err := ssh.Dial("tcp", fmt.Sprintf("%s:22", node), &config)
-		if err != nil {
-			klog.Errorf("failed to establish SSH connection to %s: %s", node, err)
+	var nodeNames []string
 
-			continue
-		}
+	for _, node := range nodeList {
+		nodeNames = append(nodeNames, node.Object.Name)
+	}
 
-		defer client.Close()
+	gatherInfo(context.TODO(), cluster.NewMCDExecutor(apiClient), commands, nodeNames, artifacts)
+}
 
-		for _, command := range commands {
-			var output bytes.Buffer
+// newSSHExecutor returns an SSHExecutor for the ssh user, known_hosts file, and jump host from GeneralConfig. Host keys
+// are verified against ~/.ssh/known_hosts if no known_hosts file is configured.
+func newSSHExecutor(sshKeyPath string) (*cluster.SSHExecutor, error) {
+	executor, err := cluster.NewSSHExecutor(GeneralConfig.SSHUser, sshKeyPath)
+	if err != nil {
+		return nil, err
+	}
 
-			var stderr bytes.Buffer
+	if GeneralConfig.SSHKnownHostsPath != "" {
+		executor.WithKnownHosts(GeneralConfig.SSHKnownHostsPath)
+	}
 
-			session, err := client.NewSession()
-			if err != nil {
-				klog.Errorf("failed to create SSH session on %s: %s", node, err)
+	if GeneralConfig.SSHJumpHost != "" {
+		executor.WithJumpHost(GeneralConfig.SSHJumpHost)
+	}
 
-				break
-			}
+	return executor, nil
+}
 
-			defer session.Close()
+// gatherJob is a single command to run on a single node.
+type gatherJob struct {
+	nodeName string
+	command  string
+}
+
+// gatherInfo runs every command on every node using executor, at most gatherWorkers at a time and each limited to
+// gatherCommandTimeout. The result of each is written to its own file in artifacts, including the exit status, even if
+// the command failed.
+func gatherInfo(ctx context.Context,
+	executor cluster.NodeExecutor, commands []string, nodeNames []string, artifacts failurebundle.Writer) {
+	jobs := make(chan gatherJob)
 
-			session.Stdout = &output
-			session.Stderr = &stderr
+	var waitGroup sync.WaitGroup
 
-			err = session.Run(command)
-			if err == nil {
-				err = os.WriteFile(outputdir+"/"+node+"_"+fileNameFromCommand(command), output.Bytes(), 0650)
+	for range min(gatherWorkers, len(commands)*len(nodeNames)) {
+		waitGroup.Go(func() {
+			for job := range jobs {
+				result, err := executor.ExecOnNode(
+					ctx, job.nodeName, job.command, cluster.WithExecTimeout(gatherCommandTimeout))
 				if err != nil {
-					klog.Errorf("error writing to file: %s", err)
+					klog.Errorf("error executing command '%s' on %s: %s", job.command, job.nodeName, err)
 				}
-			} else {
-				klog.Errorf("error executing command '%s' on %s: %s", command, node, stderr.String())
+
+				writeGatherResult(artifacts, job, result, err)
 			}
+		})
+	}
+
+	for _, nodeName := range nodeNames {
+		for _, command := range commands {
+			jobs <- gatherJob{nodeName: nodeName, command: command}
 		}
 	}
+
+	close(jobs)
+	waitGroup.Wait()
 }
 
-// GatherInfoThroughKubeClient gathers command output from nodes accessible from APIClient
-// and writes output to specified directory.
-func GatherInfoThroughKubeClient(commands []string, outputdir string, apiClient *clients.Settings) {
-	if apiClient == nil {
-		klog.Errorf("cannot gather system information from nil APIClient")
+// writeGatherResult writes the output of job to <node>_<command> in artifacts, preceded by a header with the command
+// and its exit status, or the error if it could not be run.
+func writeGatherResult(artifacts failurebundle.Writer, job gatherJob, result cluster.ExecResult, execErr error) {
+	var output bytes.Buffer
 
-		return
+	fmt.Fprintf(&output, "# node: %s\n# command: %s\n", job.nodeName, job.command)
+
+	if execErr != nil {
+		fmt.Fprintf(&output, "# error: %s\n", execErr)
+	} else {
+		fmt.Fprintf(&output, "# exit status: %d\n", result.ExitCode)
 	}
 
-	for _, command := range commands {
-		output, err := cluster.ExecCmdWithStdout(apiClient, command)
-		if err != nil {
-			klog.Errorf("error occurred while executing command: %s", err)
+	output.WriteString(result.Stdout)
+
+	if result.Stderr != "" {
+		fmt.Fprintf(&output, "\n# stderr:\n%s", result.Stderr)
+	}
+
+	err := artifacts.Add(job.nodeName+"_"+fileNameFromCommand(job.command), output.Bytes())
+	if err != nil {
+		klog.Errorf("error writing to file: %s", err)
+	}
+}
 
+// gatherInfoFromRegisteredClusters gathers command output from the nodes of each registered cluster not using the
+// kubeconfig at gatheredKubeconfig and writes output to a subdirectory of artifacts named after the cluster.
+func gatherInfoFromRegisteredClusters(commands []string, artifacts failurebundle.Writer, gatheredKubeconfig string) {
+	gatheredKubeconfig = clusterregistry.ResolveKubeconfig(gatheredKubeconfig)
+
+	for _, registered := range clusterregistry.Clusters() {
+		if clusterregistry.ResolveKubeconfig(registered.KubeconfigPath) == gatheredKubeconfig {
 			continue
 		}
 
-		for node, results := range output {
-			err = os.WriteFile(outputdir+"/"+node+"_"+fileNameFromCommand(command), []byte(results), 0650)
-			if err != nil {
-				klog.Errorf("error writing to file: %s", err)
-			}
+		apiClient, err := registered.GetAPIClient()
+		if err != nil {
+			klog.Errorf("failed to get API client of registered cluster %s: %s", registered.Name, err)
+
+			continue
 		}
+
+		gatherInfoThroughKubeClient(commands, failurebundle.WithPrefix(artifacts, registered.Name), apiClient)
 	}
 }
 
@@ -0,0 +1,86 @@
+package systemreporter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/failurebundle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatherInfo(t *testing.T) {
+	errTestExec := errors.New("connection refused")
+
+	executor := cluster.NewFakeExecutor().
+		WithResponse("node-1", "uname -r", cluster.ExecResult{Stdout: "5.14.0\n"}, nil).
+		WithResponse("node-1", "cat /missing", cluster.ExecResult{Stderr: "No such file", ExitCode: 1}, nil).
+		WithResponse("node-2", "", cluster.ExecResult{}, errTestExec)
+
+	outputDir := t.TempDir()
+
+	gatherInfo(t.Context(), executor, []string{"uname -r", "cat /missing"}, []string{"node-1", "node-2"},
+		failurebundle.NewDirectory(outputDir, 0, nil))
+
+	testCases := []struct {
+		fileName        string
+		expectedContent string
+	}{
+		{
+			fileName:        "node-1_uname_r",
+			expectedContent: "# node: node-1\n# command: uname -r\n# exit status: 0\n5.14.0\n",
+		},
+		{
+			fileName:        "node-1_cat_missing",
+			expectedContent: "# node: node-1\n# command: cat /missing\n# exit status: 1\n\n# stderr:\nNo such file",
+		},
+		{
+			fileName:        "node-2_uname_r",
+			expectedContent: "# node: node-2\n# command: uname -r\n# error: connection refused\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		content, err := os.ReadFile(filepath.Join(outputDir, testCase.fileName))
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedContent, string(content))
+	}
+
+	assert.Len(t, executor.Calls(), 4)
+}
+
+func TestGatherInfoBounded(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	handler := func(ctx context.Context, _, _ string) (cluster.ExecResult, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		return cluster.ExecResult{}, ctx.Err()
+	}
+
+	executor := cluster.NewFakeExecutor().WithHandler("", "", handler)
+
+	nodeNames := []string{"node-1", "node-2", "node-3", "node-4", "node-5"}
+	commands := []string{"uptime", "hostname", "uname -r"}
+
+	gatherInfo(t.Context(), executor, commands, nodeNames, failurebundle.NewDirectory(t.TempDir(), 0, nil))
+
+	assert.Len(t, executor.Calls(), len(nodeNames)*len(commands))
+	assert.LessOrEqual(t, maxRunning.Load(), int32(gatherWorkers))
+	assert.Greater(t, maxRunning.Load(), int32(1))
+}
@@ -2,8 +2,11 @@ package mgmtconfig
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedinstall/internal/ibiconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedinstall/mgmt/internal/mgmtparams"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/internal/seedimage"
@@ -12,6 +15,11 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	// PathToDefaultIbiMgmtParamsFile path to config file with default mgmt parameters.
+	PathToDefaultIbiMgmtParamsFile = "./default.yaml"
+)
+
 // ClusterHostNetworkInterface is one spoke host NIC entry under network.interfaces (map key = Linux iface name).
 type ClusterHostNetworkInterface struct {
 	MACAddress string `yaml:"mac_address"`
@@ -68,19 +76,19 @@ type Cluster struct {
 type MGMTConfig struct {
 	*ibiconfig.IBIConfig
 	Cluster                  *Cluster
-	ClusterInfoPath          string `envconfig:"ECO_LCA_IBI_MGMT_CLUSTER_INFO"`
-	SeedImage                string `envconfig:"ECO_LCA_IBI_MGMT_SEED_IMAGE" default:"quay.io/ocp-edge-qe/ib-seedimage-public:ci"`
+	ClusterInfoPath          string `yaml:"ibi_mgmt_cluster_info" envconfig:"ECO_LCA_IBI_MGMT_CLUSTER_INFO"`
+	SeedImage                string `yaml:"ibi_mgmt_seed_image" envconfig:"ECO_LCA_IBI_MGMT_SEED_IMAGE"`
 	SeedClusterInfo          *seedimage.SeedImageContent
-	SSHKeyPath               string `envconfig:"ECO_LCA_IBI_MGMT_SSHKEY_PATH"`
+	SSHKeyPath               string `yaml:"ibi_mgmt_sshkey_path" envconfig:"ECO_LCA_IBI_MGMT_SSHKEY_PATH"`
 	PublicSSHKey             string
-	StaticNetworking         bool   `envconfig:"ECO_LCA_IBI_MGMT_STATIC_NETWORK" default:"false"`
-	ExtraManifests           bool   `envconfig:"ECO_LCA_IBI_EXTRA_MANIFESTS" default:"true"`
-	CABundle                 bool   `envconfig:"ECO_LCA_IBI_CA_BUNDLE" default:"true"`
-	SiteConfig               bool   `envconfig:"ECO_LCA_IBI_SITECONFIG" default:"true"`
-	ExtraPartName            string `envconfig:"ECO_LCA_IBI_MGMT_EXTRA_PARTITION_NAME" default:""`
-	ExtraPartSizeMib         string `envconfig:"ECO_LCA_IBI_MGMT_EXTRA_PARTITION_SIZE" default:"50000"`
-	ReinstallGenerationLabel string `envconfig:"ECO_LCA_IBI_REINSTALL_GENERATION" default:"generate1"`
-	AdditionalNTPSources     string `envconfig:"ECO_LCA_IBI_ADDITIONAL_NTP_SOURCES" default:""`
+	StaticNetworking         bool   `yaml:"ibi_mgmt_static_network" envconfig:"ECO_LCA_IBI_MGMT_STATIC_NETWORK"`
+	ExtraManifests           bool   `yaml:"ibi_extra_manifests" envconfig:"ECO_LCA_IBI_EXTRA_MANIFESTS"`
+	CABundle                 bool   `yaml:"ibi_ca_bundle" envconfig:"ECO_LCA_IBI_CA_BUNDLE"`
+	SiteConfig               bool   `yaml:"ibi_siteconfig" envconfig:"ECO_LCA_IBI_SITECONFIG"`
+	ExtraPartName            string `yaml:"ibi_mgmt_extra_partition_name" envconfig:"ECO_LCA_IBI_MGMT_EXTRA_PARTITION_NAME"`
+	ExtraPartSizeMib         string `yaml:"ibi_mgmt_extra_partition_size" envconfig:"ECO_LCA_IBI_MGMT_EXTRA_PARTITION_SIZE"`
+	ReinstallGenerationLabel string `yaml:"ibi_reinstall_generation" envconfig:"ECO_LCA_IBI_REINSTALL_GENERATION"`
+	AdditionalNTPSources     string `yaml:"ibi_additional_ntp_sources" envconfig:"ECO_LCA_IBI_ADDITIONAL_NTP_SOURCES"`
 }
 
 // ReinstallConfig is used to collect info for performing resinstall test.
@@ -99,7 +107,25 @@ func NewMGMTConfig() *MGMTConfig {
 
 	mgmtConfig.IBIConfig = ibiconfig.NewIBIConfig()
 
-	err := envconfig.Process("eco_lca_ibi_mgmt_", &mgmtConfig)
+	_, filename, _, _ := runtime.Caller(0)
+	baseDir := filepath.Dir(filename)
+	configFile := filepath.Join(baseDir, PathToDefaultIbiMgmtParamsFile)
+
+	err := readFile(&mgmtConfig, configFile)
+	if err != nil {
+		klog.V(mgmtparams.MGMTLogLevel).Infof("Error reading config file %s", configFile)
+
+		return nil
+	}
+
+	err = config.ReadProfiles(&mgmtConfig)
+	if err != nil {
+		klog.V(mgmtparams.MGMTLogLevel).Infof("Error reading profiles: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("eco_lca_ibi_mgmt_", &mgmtConfig)
 	if err != nil {
 		return nil
 	}
@@ -127,3 +153,20 @@ func NewMGMTConfig() *MGMTConfig {
 
 	return &mgmtConfig
 }
+
+func readFile(mgmtConfig *MGMTConfig, configFile string) error {
+	openedConfigFile, err := os.Open(configFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = openedConfigFile.Close()
+	}()
+
+	decoder := yaml.NewDecoder(openedConfigFile)
+
+	err = decoder.Decode(mgmtConfig)
+
+	return err
+}
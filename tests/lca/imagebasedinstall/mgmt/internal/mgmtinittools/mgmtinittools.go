@@ -1,6 +1,9 @@
 package mgmtinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedinstall/mgmt/internal/mgmtconfig"
@@ -13,7 +16,19 @@ var (
 	MGMTConfig *mgmtconfig.MGMTConfig
 )
 
+// init registers setup so the IBI management config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ibi-mgmt", setup)
+}
+
+// setup loads the IBI management config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	MGMTConfig = mgmtconfig.NewMGMTConfig()
+	if MGMTConfig == nil {
+		return errors.New("failed to load IBI management config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
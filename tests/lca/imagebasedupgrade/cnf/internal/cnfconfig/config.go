@@ -6,6 +6,7 @@ import (
 	"runtime"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedupgrade/cnf/internal/cnfparams"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedupgrade/internal/ibuconfig"
 	"gopkg.in/yaml.v2"
@@ -64,11 +65,6 @@ func NewCNFConfig() *CNFConfig {
 		return nil
 	}
 
-	err = envconfig.Process("eco_lca_ibu_cnf_", &cnfConfig)
-	if err != nil {
-		return nil
-	}
-
 	return &cnfConfig
 }
 
@@ -90,7 +86,17 @@ func readFile(cnfConfig *CNFConfig, configFile string) error {
 }
 
 func readEnv(cnfConfig *CNFConfig) error {
-	err := envconfig.Process("", cnfConfig)
+	err := config.ReadProfiles(cnfConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", cnfConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("eco_lca_ibu_cnf_", cnfConfig)
 
 	return err
 }
@@ -1,7 +1,12 @@
 package cnfinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedupgrade/cnf/internal/cnfconfig"
 )
 
@@ -14,10 +19,17 @@ var (
 	CNFConfig *cnfconfig.CNFConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the IBU CNF config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ibu-cnf", setup)
+}
+
+// setup loads the IBU CNF config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	CNFConfig = cnfconfig.NewCNFConfig()
+	if CNFConfig == nil {
+		return errors.New("failed to load IBU CNF config")
+	}
 
 	if CNFConfig.TargetHubKubeConfig != "" {
 		TargetHubAPIClient = clients.New(CNFConfig.TargetHubKubeConfig)
@@ -26,4 +38,8 @@ func init() {
 	if CNFConfig.TargetSNOKubeConfig != "" {
 		TargetSNOAPIClient = clients.New(CNFConfig.TargetSNOKubeConfig)
 	}
+
+	return errors.Join(
+		clusterregistry.RegisterClientIfSet("target-hub", clusterregistry.RoleHub, TargetHubAPIClient),
+		clusterregistry.RegisterClientIfSet("target-sno", clusterregistry.RoleTarget, TargetSNOAPIClient))
 }
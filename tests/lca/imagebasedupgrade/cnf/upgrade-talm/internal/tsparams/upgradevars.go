@@ -35,12 +35,6 @@ var (
 	// Labels represents the range of labels that can be used for test cases selection.
 	Labels = append(cnfparams.Labels, LabelSuite)
 
-	// ReporterHubNamespacesToDump tells to the reporter which namespaces on the hub to collect pod logs from.
-	ReporterHubNamespacesToDump = map[string]string{
-		OCPOperatorsNamespace:          "",
-		CNFConfig.AcmOperatorNamespace: "",
-	}
-
 	// ReporterHubCRsToDump is the CRs the reporter should dump on the hub.
 	ReporterHubCRsToDump = []k8sreporter.CRData{
 		{Cr: &corev1.NamespaceList{}},
@@ -73,3 +67,11 @@ var (
 	// ClusterLabelSelector is the cluster label passed to IBGUs.
 	ClusterLabelSelector = map[string]string{"common": "true"}
 )
+
+// ReporterHubNamespacesToDump tells to the reporter which namespaces on the hub to collect pod logs from.
+func ReporterHubNamespacesToDump() map[string]string {
+	return map[string]string{
+		OCPOperatorsNamespace:          "",
+		CNFConfig.AcmOperatorNamespace: "",
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedupgrade/cnf/internal/cnfinittools"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/reportxml"
@@ -19,6 +20,14 @@ import (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestUpgrade(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	_, reporterConfig := GinkgoConfiguration()
 	reporterConfig.JUnitReport = CNFConfig.GetJunitReportPath(currentFile)
 
@@ -68,7 +77,7 @@ var _ = JustAfterEach(func() {
 			CNFConfig.TargetHubKubeConfig,
 			report,
 			hubReportPath,
-			tsparams.ReporterHubNamespacesToDump,
+			tsparams.ReporterHubNamespacesToDump(),
 			tsparams.ReporterHubCRsToDump)
 	}
 })
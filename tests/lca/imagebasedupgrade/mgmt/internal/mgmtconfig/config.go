@@ -1,27 +1,38 @@
 package mgmtconfig
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
+
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedupgrade/internal/ibuconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/imagebasedupgrade/mgmt/internal/mgmtparams"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/internal/seedimage"
+	"gopkg.in/yaml.v2"
 	"k8s.io/klog/v2"
 )
 
+const (
+	// PathToDefaultIbuMgmtParamsFile path to config file with default mgmt parameters.
+	PathToDefaultIbuMgmtParamsFile = "./default.yaml"
+)
+
 // MGMTConfig type contains mgmt configuration.
 //
 //nolint:lll
 type MGMTConfig struct {
 	*ibuconfig.IBUConfig
-	SeedImage            string `envconfig:"ECO_LCA_IBU_MGMT_SEED_IMAGE" default:"quay.io/ocp-edge-qe/ib-seedimage-public:ci"`
+	SeedImage            string `yaml:"ibu_mgmt_seed_image" envconfig:"ECO_LCA_IBU_MGMT_SEED_IMAGE"`
 	SeedClusterInfo      *seedimage.SeedImageContent
-	IBUWorkloadImage     string `envconfig:"ECO_LCA_IBU_MGMT_WORKLOAD_IMAGE" default:"registry.redhat.io/openshift4/ose-hello-openshift-rhel8@sha256:10dca31348f07e1bfb56ee93c324525cceefe27cb7076b23e42ac181e4d1863e"`
-	IdlePostUpgrade      bool   `envconfig:"ECO_LCA_IBU_MGMT_IDLE_POST_UPGRADE" default:"false"`
-	RollbackAfterUpgrade bool   `envconfig:"ECO_LCA_IBU_MGMT_ROLLBACK_AFTER_UPGRADE" default:"false"`
-	ExtraManifests       bool   `envconfig:"ECO_LCA_IBU_MGMT_EXTRA_MANIFESTS" default:"true"`
-	AdditionalNTPSources string `envconfig:"ECO_LCA_IBU_MGMT_ADDITIONAL_NTP_SOURCES" default:""`
-	StateTransitions     bool   `envconfig:"ECO_LCA_IBU_MGMT_STATE_TRANSITIONS" default:"false"`
-	SecondUpgrade        bool   `envconfig:"ECO_LCA_IBU_MGMT_SECOND_UPGRADE" default:"false"`
+	IBUWorkloadImage     string `yaml:"ibu_mgmt_workload_image" envconfig:"ECO_LCA_IBU_MGMT_WORKLOAD_IMAGE"`
+	IdlePostUpgrade      bool   `yaml:"ibu_mgmt_idle_post_upgrade" envconfig:"ECO_LCA_IBU_MGMT_IDLE_POST_UPGRADE"`
+	RollbackAfterUpgrade bool   `yaml:"ibu_mgmt_rollback_after_upgrade" envconfig:"ECO_LCA_IBU_MGMT_ROLLBACK_AFTER_UPGRADE"`
+	ExtraManifests       bool   `yaml:"ibu_mgmt_extra_manifests" envconfig:"ECO_LCA_IBU_MGMT_EXTRA_MANIFESTS"`
+	AdditionalNTPSources string `yaml:"ibu_mgmt_additional_ntp_sources" envconfig:"ECO_LCA_IBU_MGMT_ADDITIONAL_NTP_SOURCES"`
+	StateTransitions     bool   `yaml:"ibu_mgmt_state_transitions" envconfig:"ECO_LCA_IBU_MGMT_STATE_TRANSITIONS"`
+	SecondUpgrade        bool   `yaml:"ibu_mgmt_second_upgrade" envconfig:"ECO_LCA_IBU_MGMT_SECOND_UPGRADE"`
 }
 
 // NewMGMTConfig returns instance of MGMTConfig type.
@@ -32,10 +43,45 @@ func NewMGMTConfig() *MGMTConfig {
 
 	mgmtConfig.IBUConfig = ibuconfig.NewIBUConfig()
 
-	err := envconfig.Process("eco_lca_ibu_mgmt_", &mgmtConfig)
+	_, filename, _, _ := runtime.Caller(0)
+	baseDir := filepath.Dir(filename)
+	configFile := filepath.Join(baseDir, PathToDefaultIbuMgmtParamsFile)
+
+	err := readFile(&mgmtConfig, configFile)
+	if err != nil {
+		klog.V(mgmtparams.MGMTLogLevel).Infof("Error reading config file %s", configFile)
+
+		return nil
+	}
+
+	err = config.ReadProfiles(&mgmtConfig)
+	if err != nil {
+		klog.V(mgmtparams.MGMTLogLevel).Infof("Error reading profiles: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("eco_lca_ibu_mgmt_", &mgmtConfig)
 	if err != nil {
 		return nil
 	}
 
 	return &mgmtConfig
 }
+
+func readFile(mgmtConfig *MGMTConfig, configFile string) error {
+	openedConfigFile, err := os.Open(configFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = openedConfigFile.Close()
+	}()
+
+	decoder := yaml.NewDecoder(openedConfigFile)
+
+	err = decoder.Decode(mgmtConfig)
+
+	return err
+}
@@ -2,22 +2,25 @@ package ipcconfig
 
 import (
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/internal/lcaconfig"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/ipchange/internal/ipcparams"
 	"k8s.io/klog/v2"
 )
 
 // IPCConfig type contains ipchange configuration.
+//
+//nolint:lll
 type IPCConfig struct {
 	*lcaconfig.LCAConfig
-	ExpectedStage              string `envconfig:"ECO_LCA_IPC_EXPECTED_STAGE"`
-	ExpectedIPv4Address        string `envconfig:"ECO_LCA_IPC_EXPECTED_IPV4_ADDRESS"`
-	ExpectedIPv4Gateway        string `envconfig:"ECO_LCA_IPC_EXPECTED_IPV4_GATEWAY"`
-	ExpectedIPv4MachineNetwork string `envconfig:"ECO_LCA_IPC_EXPECTED_IPV4_MACHINE_NETWORK"`
-	ExpectedIPv6Address        string `envconfig:"ECO_LCA_IPC_EXPECTED_IPV6_ADDRESS"`
-	ExpectedIPv6Gateway        string `envconfig:"ECO_LCA_IPC_EXPECTED_IPV6_GATEWAY"`
-	ExpectedIPv6MachineNetwork string `envconfig:"ECO_LCA_IPC_EXPECTED_IPV6_MACHINE_NETWORK"`
-	ExpectedDNSServers         string `envconfig:"ECO_LCA_IPC_EXPECTED_DNS_SERVERS"`
+	ExpectedStage              string `yaml:"ipc_expected_stage" envconfig:"ECO_LCA_IPC_EXPECTED_STAGE"`
+	ExpectedIPv4Address        string `yaml:"ipc_expected_ipv4_address" envconfig:"ECO_LCA_IPC_EXPECTED_IPV4_ADDRESS"`
+	ExpectedIPv4Gateway        string `yaml:"ipc_expected_ipv4_gateway" envconfig:"ECO_LCA_IPC_EXPECTED_IPV4_GATEWAY"`
+	ExpectedIPv4MachineNetwork string `yaml:"ipc_expected_ipv4_machine_network" envconfig:"ECO_LCA_IPC_EXPECTED_IPV4_MACHINE_NETWORK"`
+	ExpectedIPv6Address        string `yaml:"ipc_expected_ipv6_address" envconfig:"ECO_LCA_IPC_EXPECTED_IPV6_ADDRESS"`
+	ExpectedIPv6Gateway        string `yaml:"ipc_expected_ipv6_gateway" envconfig:"ECO_LCA_IPC_EXPECTED_IPV6_GATEWAY"`
+	ExpectedIPv6MachineNetwork string `yaml:"ipc_expected_ipv6_machine_network" envconfig:"ECO_LCA_IPC_EXPECTED_IPV6_MACHINE_NETWORK"`
+	ExpectedDNSServers         string `yaml:"ipc_expected_dns_servers" envconfig:"ECO_LCA_IPC_EXPECTED_DNS_SERVERS"`
 }
 
 // NewIPCConfig returns instance of IPCConfig type.
@@ -33,7 +36,14 @@ func NewIPCConfig() *IPCConfig {
 		return nil
 	}
 
-	err := envconfig.Process("", &ipcConfig)
+	err := config.ReadProfiles(&ipcConfig)
+	if err != nil {
+		klog.V(ipcparams.IPCLogLevel).Infof("Error reading profiles: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("", &ipcConfig)
 	if err != nil {
 		klog.V(ipcparams.IPCLogLevel).Infof("Error reading environment variables: %v", err)
 
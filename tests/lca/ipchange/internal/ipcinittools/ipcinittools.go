@@ -1,6 +1,9 @@
 package ipcinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/ipchange/internal/ipcconfig"
@@ -13,11 +16,21 @@ var (
 	IPCConfig *ipcconfig.IPCConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the IP change config is loaded by inittools.Setup rather than on import.
 //
 //nolint:gochecknoinits // Package initialization pattern used throughout eco-gotests
 func init() {
+	inittools.RegisterSetup("ipchange", setup)
+}
+
+// setup loads the IP change config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	IPCConfig = ipcconfig.NewIPCConfig()
+	if IPCConfig == nil {
+		return errors.New("failed to load IP change config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/reportxml"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/reporter"
 	. "github.com/rh-ecosystem-edge/eco-gotests/tests/lca/ipchange/internal/ipcinittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/ipchange/internal/tsparams"
@@ -19,6 +20,14 @@ import (
 var _, currentFile, _, _ = runtime.Caller(0)
 
 func TestIPChange(t *testing.T) {
+	err := inittools.Setup(t.Context(), inittools.Options{})
+	if err != nil {
+		t.Fatalf("failed to set up suite: %v", err)
+	}
+
+	inittools.RegisterConfigDump()
+	reporter.RegisterDumps()
+
 	if IPCConfig == nil {
 		t.Skip("IPCConfig is nil; check envconfig inputs")
 	}
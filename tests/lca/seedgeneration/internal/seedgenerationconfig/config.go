@@ -10,7 +10,7 @@ import (
 // SeedGenerationConfig contains configuration for seed generation tests.
 type SeedGenerationConfig struct {
 	*config.GeneralConfig
-	TargetSNOKubeConfig string `envconfig:"ECO_LCA_IBU_CNF_KUBECONFIG_TARGET_SNO"`
+	TargetSNOKubeConfig string `yaml:"target_sno_kubeconfig" envconfig:"ECO_LCA_IBU_CNF_KUBECONFIG_TARGET_SNO"`
 	IbguSeedImage       string `yaml:"ibgu_seed_image" envconfig:"ECO_LCA_IBGU_SEED_IMAGE"`
 }
 
@@ -27,7 +27,14 @@ func NewSeedGenerationConfig() *SeedGenerationConfig {
 		return nil
 	}
 
-	err := envconfig.Process("", &seedConfig)
+	err := config.ReadProfiles(&seedConfig)
+	if err != nil {
+		klog.V(90).Infof("Error reading profiles: %v", err)
+
+		return nil
+	}
+
+	err = envconfig.Process("", &seedConfig)
 	if err != nil {
 		klog.V(90).Infof("Error reading environment variables: %v", err)
 
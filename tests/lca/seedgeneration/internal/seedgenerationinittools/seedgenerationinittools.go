@@ -1,7 +1,11 @@
 package seedgenerationinittools
 
 import (
+	"context"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/clusterregistry"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/lca/seedgeneration/internal/seedgenerationconfig"
 )
 
@@ -12,18 +16,24 @@ var (
 	SeedGenerationConfig *seedgenerationconfig.SeedGenerationConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the seed generation config is loaded by inittools.Setup rather than on import.
 //
 //nolint:gochecknoinits // Package initialization pattern used throughout eco-gotests
 func init() {
+	inittools.RegisterSetup("seedgeneration", setup)
+}
+
+// setup loads the seed generation config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	SeedGenerationConfig = seedgenerationconfig.NewSeedGenerationConfig()
 	if SeedGenerationConfig == nil {
 		SeedGenerationConfig = &seedgenerationconfig.SeedGenerationConfig{}
 		TargetSNOAPIClient = nil
 
-		return
+		return nil
 	}
 
 	TargetSNOAPIClient = SeedGenerationConfig.GetTargetSNOAPIClient()
+
+	return clusterregistry.RegisterClientIfSet("seed", clusterregistry.RoleSeed, TargetSNOAPIClient)
 }
@@ -77,7 +77,12 @@ func readFile(ocpConfig *OcpConfig, cfgFile string) error {
 }
 
 func readEnv(ocpConfig *OcpConfig) error {
-	err := envconfig.Process("", ocpConfig)
+	err := config.ReadProfiles(ocpConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", ocpConfig)
 	if err != nil {
 		return err
 	}
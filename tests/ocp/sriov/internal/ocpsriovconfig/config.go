@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/ocp/internal/ocpconfig"
 	"gopkg.in/yaml.v2"
 )
@@ -283,7 +284,12 @@ func readFile(sriovOcpConfig *SriovOcpConfig, cfgFile string) error {
 }
 
 func readEnv(sriovOcpConfig *SriovOcpConfig) error {
-	err := envconfig.Process("", sriovOcpConfig)
+	err := config.ReadProfiles(sriovOcpConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", sriovOcpConfig)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,9 @@
 package ocpsriovinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	sriovconfig "github.com/rh-ecosystem-edge/eco-gotests/tests/ocp/sriov/internal/ocpsriovconfig"
@@ -13,9 +16,19 @@ var (
 	SriovOcpConfig *sriovconfig.SriovOcpConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the OCP SR-IOV config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ocp-sriov", setup)
+}
+
+// setup loads the OCP SR-IOV config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	SriovOcpConfig = sriovconfig.NewSriovOcpConfig()
+	if SriovOcpConfig == nil {
+		return errors.New("failed to load OCP SR-IOV config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
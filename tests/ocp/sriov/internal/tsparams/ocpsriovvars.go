@@ -47,9 +47,12 @@ var (
 		{Cr: &sriovv1.SriovNetworkNodeStateList{}},
 		{Cr: &sriovv1.SriovOperatorConfigList{}},
 	}
+)
 
-	// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
-	ReporterNamespacesToDump = map[string]string{
+// ReporterNamespacesToDump tells to the reporter what namespaces to dump.
+func ReporterNamespacesToDump() map[string]string {
+	return map[string]string{
 		SriovOcpConfig.OcpSriovOperatorNamespace: SriovOcpConfig.OcpSriovOperatorNamespace,
-		TestNamespaceName:                        "other"}
-)
+		TestNamespaceName:                        "other",
+	}
+}
@@ -71,7 +71,12 @@ func readFile(rhwaConfig *RHWAConfig, cfgFile string) error {
 }
 
 func readEnv(rhwaConfig *RHWAConfig) error {
-	err := envconfig.Process("", rhwaConfig)
+	err := config.ReadProfiles(rhwaConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", rhwaConfig)
 	if err != nil {
 		return err
 	}
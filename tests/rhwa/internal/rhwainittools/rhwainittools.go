@@ -1,6 +1,9 @@
 package rhwainittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/rhwa/internal/rhwaconfig"
@@ -13,9 +16,19 @@ var (
 	RHWAConfig *rhwaconfig.RHWAConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the RHWA config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("rhwa", setup)
+}
+
+// setup loads the RHWA config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	RHWAConfig = rhwaconfig.NewRHWAConfig()
+	if RHWAConfig == nil {
+		return errors.New("failed to load RHWA config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
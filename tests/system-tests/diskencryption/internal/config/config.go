@@ -9,6 +9,7 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
+	generalconfig "github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/diskencryption/tsparams"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
 	"gopkg.in/yaml.v2"
@@ -95,7 +96,12 @@ func readFile(diskEncrptionConfig *DiskEncrptionConfig, cfgFile string) error {
 }
 
 func readEnv(diskEncrptionConfig *DiskEncrptionConfig) error {
-	err := envconfig.Process("", diskEncrptionConfig)
+	err := generalconfig.ReadProfiles(diskEncrptionConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", diskEncrptionConfig)
 	if err != nil {
 		return err
 	}
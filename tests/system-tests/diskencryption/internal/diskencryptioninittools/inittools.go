@@ -1,6 +1,9 @@
 package diskencryptioninittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/nodes"
@@ -21,13 +24,22 @@ var (
 	DiskEncryptionTestConfig *config.DiskEncrptionConfig
 )
 
-// init loads all variables automatically when this package is imported.
-// Once package is imported a user has full access to all vars within init function.
-// It is recommended to import this package using dot import.
+// init registers setup so the disk encryption config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("diskencryption", setup)
+}
+
+// setup loads the disk encryption config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	DiskEncryptionTestConfig = config.NewDiskEncryptionConfig()
+	if DiskEncryptionTestConfig == nil {
+		return errors.New("failed to load disk encryption config")
+	}
+
 	APIClient = inittools.APIClient
 	BMCClient = DiskEncryptionTestConfig.Spoke1BMC
+
+	return nil
 }
 
 // GetNodeNames returns a string slice with all of the cluster node names.
@@ -1,64 +1,32 @@
 package ptp
 
 import (
-	"fmt"
-	"sort"
+	"context"
 	"strings"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/cluster"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 )
 
-// ShellQuoteForNsenter escapes single quotes for use inside sh -c '...' on the node.
-func ShellQuoteForNsenter(shellCmd string) string {
-	return strings.ReplaceAll(shellCmd, `'`, `'\''`)
-}
-
 // ShellQuoteArg wraps s in single quotes for safe embedding in POSIX shell words.
 // An apostrophe in s is expanded using the usual shell pattern: close quote, backslash-escaped quote, reopen.
 func ShellQuoteArg(s string) string {
-	return `'` + strings.ReplaceAll(s, `'`, `'\''`) + `'`
+	return cluster.ShellQuote(s)
 }
 
-// ExecCmdOnNodeHost runs hostShellCmd in the host mount namespace via the machine-config-daemon pod.
-// It reuses cluster.ExecCmdWithStdout and expects exactly one node to match metadata.name == nodeName.
+// ExecCmdOnNodeHost runs hostShellCmd in the host mount namespace via the machine-config-daemon pod on nodeName.
+// The output includes stderr and a non-zero exit code is returned as an error.
 func ExecCmdOnNodeHost(apiClient *clients.Settings, nodeName, hostShellCmd string) (string, error) {
-	escaped := ShellQuoteForNsenter(hostShellCmd)
-
-	outMap, err := cluster.ExecCmdWithStdout(
-		apiClient,
-		escaped,
-		metav1.ListOptions{
-			FieldSelector: fields.OneTermEqualSelector("metadata.name", nodeName).String(),
-		},
-	)
+	result, err := cluster.NewMCDExecutor(apiClient).ExecOnNode(
+		context.TODO(), nodeName, hostShellCmd, cluster.WithCombinedOutput(true))
 	if err != nil {
 		return "", err
 	}
 
-	if len(outMap) != 1 {
-		keys := make([]string, 0, len(outMap))
-		for k := range outMap {
-			keys = append(keys, k)
-		}
-
-		sort.Strings(keys)
-
-		return "", fmt.Errorf(
-			"ExecCmdOnNodeHost: expected exactly 1 stdout entry for node %s, got %d hostnames: %v",
-			nodeName, len(outMap), keys,
-		)
-	}
-
-	var combined string
-
-	for _, v := range outMap {
-		combined = v
-
-		break
+	err = result.Err()
+	if err != nil {
+		return "", err
 	}
 
-	return combined, nil
+	return strings.ReplaceAll(result.Stdout, "\r", ""), nil
 }
@@ -77,7 +77,12 @@ func readFile(systemtestsConfig *SystemTestsConfig, cfgFile string) error {
 }
 
 func readEnv(systemtestsConfig *SystemTestsConfig) error {
-	err := envconfig.Process("", systemtestsConfig)
+	err := config.ReadProfiles(systemtestsConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", systemtestsConfig)
 	if err != nil {
 		return err
 	}
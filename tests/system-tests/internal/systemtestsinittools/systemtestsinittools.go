@@ -1,6 +1,9 @@
 package systemtestsinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
@@ -13,9 +16,19 @@ var (
 	SystemTestsTestConfig *systemtestsconfig.SystemTestsConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the system tests config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("system-tests", setup)
+}
+
+// setup loads the system tests config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	SystemTestsTestConfig = systemtestsconfig.NewSystemTestsConfig()
+	if SystemTestsTestConfig == nil {
+		return errors.New("failed to load system tests config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
 	"gopkg.in/yaml.v2"
 )
@@ -89,7 +90,12 @@ func readFile(ipsecConfig *IpsecConfig, cfgFile string) error {
 }
 
 func readEnv(ipsecConfig *IpsecConfig) error {
-	err := envconfig.Process("", ipsecConfig)
+	err := config.ReadProfiles(ipsecConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", ipsecConfig)
 	if err != nil {
 		return err
 	}
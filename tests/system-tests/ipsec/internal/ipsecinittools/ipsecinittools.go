@@ -1,6 +1,9 @@
 package ipsecinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/nodes"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
@@ -16,12 +19,21 @@ var (
 	IpsecTestConfig *ipsecconfig.IpsecConfig
 )
 
-// init loads all variables automatically when this package is imported.
-// Once package is imported a user has full access to all vars within init function.
-// It is recommended to import this package using dot import.
+// init registers setup so the IPSec config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ipsec", setup)
+}
+
+// setup loads the IPSec config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	IpsecTestConfig = ipsecconfig.NewIpsecConfig()
+	if IpsecTestConfig == nil {
+		return errors.New("failed to load IPSec config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
 
 // GetNodeNames returns a string slice with all of the cluster node names.
@@ -211,7 +211,7 @@ func VerifySuccessfulAlarmRetrieval(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	VerifyOcloudCRsExist(provisioningRequest)
 
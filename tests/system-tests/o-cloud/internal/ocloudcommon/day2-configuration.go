@@ -41,7 +41,7 @@ func VerifySuccessfulOperatorUpgrade(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	provisioningRequest2 := VerifyProvisionSnoCluster(
 		OCloudConfig.TemplateName,
@@ -49,7 +49,7 @@ func VerifySuccessfulOperatorUpgrade(ctx SpecContext) {
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest1)
 	nsname1 := provisioningRequest1.Object.Status.Extensions.ClusterDetails.Name
@@ -148,7 +148,7 @@ func VerifyFailedOperatorUpgradeAllSnos(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	provisioningRequest2 := VerifyProvisionSnoCluster(
 		OCloudConfig.TemplateName,
@@ -156,7 +156,7 @@ func VerifyFailedOperatorUpgradeAllSnos(ctx SpecContext) {
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest1)
 	nsname1 := provisioningRequest1.Object.Status.Extensions.ClusterDetails.Name
@@ -277,7 +277,7 @@ func VerifyFailedOperatorUpgradeSubsetSnos(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	provisioningRequest2 := VerifyProvisionSnoCluster(
 		OCloudConfig.TemplateName,
@@ -285,7 +285,7 @@ func VerifyFailedOperatorUpgradeSubsetSnos(ctx SpecContext) {
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest1)
 	nsname1 := provisioningRequest1.Object.Status.Extensions.ClusterDetails.Name
@@ -27,7 +27,7 @@ func VerifySuccessfulSnoProvisioning(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	VerifyOcloudCRsExist(provisioningRequest)
 
@@ -57,7 +57,7 @@ func VerifyFailedSnoProvisioning(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	VerifyOcloudCRsExist(provisioningRequest)
 
@@ -82,14 +82,14 @@ func VerifySimultaneousSnoProvisioningSameClusterTemplate(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 	provisioningRequest2 := VerifyProvisionSnoCluster(
 		OCloudConfig.TemplateName,
 		OCloudConfig.TemplateVersionAISuccess,
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest1)
 	nsname1 := provisioningRequest1.Object.Status.Extensions.ClusterDetails.Name
@@ -179,7 +179,7 @@ func VerifySimultaneousSnoProvisioningDifferentClusterTemplates(ctx SpecContext)
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	provisioningRequest2 := VerifyProvisionSnoCluster(
 		OCloudConfig.TemplateName,
@@ -187,7 +187,7 @@ func VerifySimultaneousSnoProvisioningDifferentClusterTemplates(ctx SpecContext)
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest1)
 	nsname1 := provisioningRequest1.Object.Status.Extensions.ClusterDetails.Name
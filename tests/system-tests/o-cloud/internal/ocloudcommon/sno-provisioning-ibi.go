@@ -73,7 +73,7 @@ func VerifySuccessfulIbiSnoProvisioning(ctx SpecContext) {
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest)
 
@@ -116,7 +116,7 @@ func VerifyFailedIbiSnoProvisioning(ctx SpecContext) {
 		OCloudConfig.NodeClusterName2,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters2)
+		ocloudparams.ClusterInstanceParameters2())
 
 	VerifyOcloudCRsExist(provisioningRequest)
 
@@ -177,7 +177,7 @@ func generateBaseImage(ctx SpecContext) {
 		OCloudConfig.NodeClusterName1,
 		OCloudConfig.OCloudSiteID,
 		ocloudparams.PolicyTemplateParameters,
-		ocloudparams.ClusterInstanceParameters1)
+		ocloudparams.ClusterInstanceParameters1())
 
 	VerifyOcloudCRsExist(provisioningRequest)
 	nsname := provisioningRequest.Object.Status.Extensions.ClusterDetails.Name
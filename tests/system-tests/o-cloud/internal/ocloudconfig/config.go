@@ -9,6 +9,7 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
 	"gopkg.in/yaml.v2"
 )
@@ -203,7 +204,12 @@ func readFile(ocloudConfig *OCloudConfig, cfgFile string) error {
 }
 
 func readEnv(ocloudConfig *OCloudConfig) error {
-	err := envconfig.Process("", ocloudConfig)
+	err := config.ReadProfiles(ocloudConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", ocloudConfig)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,9 @@
 package ocloudinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
@@ -16,9 +19,19 @@ var (
 	BMCClient *bmc.BMC
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the O-Cloud config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("o-cloud", setup)
+}
+
+// setup loads the O-Cloud config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	OCloudConfig = ocloudconfig.NewOCloudConfig()
+	if OCloudConfig == nil {
+		return errors.New("failed to load O-Cloud config")
+	}
+
 	HubAPIClient = inittools.APIClient
+
+	return nil
 }
@@ -31,8 +31,52 @@ var (
 	// PolicyTemplateParameters defines the policy template parameters.
 	PolicyTemplateParameters = map[string]any{}
 
-	// ClusterInstanceParameters1 is the map with the cluster instance parameters for the first cluster.
-	ClusterInstanceParameters1 = map[string]any{
+	//nolint:lll
+	// SkopeoRedhatOperatorsUpgrade command to create a tag for the redhat-operators upgrade.
+	SkopeoRedhatOperatorsUpgrade = "skopeo copy --authfile %s --tls-verify=false docker://%s/olm/redhat-operators:v4.20-new docker://%s/olm/redhat-operators:v4.20-day2"
+	//nolint:lll
+	// SkopeoRedhatOperatorsDowngrade command to create a tag for the redhat-operators downgrade.
+	SkopeoRedhatOperatorsDowngrade = "skopeo copy --authfile %s --tls-verify=false docker://%s/olm/redhat-operators:v4.20-old docker://%s/olm/redhat-operators:v4.20-day2"
+	//nolint:lll
+	// SnoKubeconfigCreate command to get the SNO kubeconfig file.
+	SnoKubeconfigCreate = "oc -n %s get secret %s-admin-kubeconfig -o json | jq -r .data.kubeconfig | base64 -d > tmp/%s/auth/kubeconfig"
+	//nolint:lll
+	// CreateImageBasedInstallationConfig command to create the image based installation configuration template.
+	CreateImageBasedInstallationConfig = "openshift-install image-based create image-config-template --dir tmp/ibi-iso-workdir"
+	// CreateIsoImage command to create the ISO image.
+	CreateIsoImage = "openshift-install image-based create image --dir tmp/ibi-iso-workdir"
+	//nolint:lll
+	// CheckIbiCompleted command to check that the image based installation has finished.
+	CheckIbiCompleted = "journalctl -u install-rhcos-and-restore-seed.service | grep 'Finished SNO Image-based Installation.'"
+
+	// SpokeSSHUser ssh user of the spoke cluster.
+	SpokeSSHUser = "core"
+	// SpokeSSHPasskeyPath path to the ssh key of the spoke cluster.
+	SpokeSSHPasskeyPath = "/opt/id_rsa"
+	// SeedGeneratorName name of the seedgenerator CR.
+	SeedGeneratorName = "seedimage"
+	// RegistryCertPath path to the registry certificate.
+	RegistryCertPath = "/opt/registry.crt"
+	// IbiConfigTemplate template for the image based installation configuration.
+	IbiConfigTemplate = "/opt/ibi-config.yaml.tmpl"
+	// IbiConfigTemplateYaml path to the YAML file with the image based installation configuration.
+	IbiConfigTemplateYaml = "tmp/ibi-iso-workdir/image-based-installation-config.yaml"
+	// IbiBasedImageSourcePath path to the base image.
+	IbiBasedImageSourcePath = "tmp/ibi-iso-workdir/rhcos-ibi.iso"
+
+	// PtpCPURequest is cpu request for the PTP container.
+	PtpCPURequest = "50m"
+	// PtpMemoryRequest is cpu request for the PTP container.
+	PtpMemoryRequest = "100Mi"
+	// PtpCPULimit is cpu limit for the PTP container.
+	PtpCPULimit = "1m"
+	// PtpMemoryLimit is cpu limit for the PTP container.
+	PtpMemoryLimit = "1Mi"
+)
+
+// ClusterInstanceParameters1 is the map with the cluster instance parameters for the first cluster.
+func ClusterInstanceParameters1() map[string]any {
+	return map[string]any{
 		"clusterName": OCloudConfig.ClusterName1,
 		"nodes": []map[string]any{
 			{
@@ -70,9 +114,11 @@ var (
 			},
 		},
 	}
+}
 
-	// ClusterInstanceParameters2 is the map with the cluster instance parameters for the second cluster.
-	ClusterInstanceParameters2 = map[string]any{
+// ClusterInstanceParameters2 is the map with the cluster instance parameters for the second cluster.
+func ClusterInstanceParameters2() map[string]any {
+	return map[string]any{
 		"clusterName": OCloudConfig.ClusterName2,
 		"nodes": []map[string]any{
 			{
@@ -110,46 +156,4 @@ var (
 			},
 		},
 	}
-
-	//nolint:lll
-	// SkopeoRedhatOperatorsUpgrade command to create a tag for the redhat-operators upgrade.
-	SkopeoRedhatOperatorsUpgrade = "skopeo copy --authfile %s --tls-verify=false docker://%s/olm/redhat-operators:v4.20-new docker://%s/olm/redhat-operators:v4.20-day2"
-	//nolint:lll
-	// SkopeoRedhatOperatorsDowngrade command to create a tag for the redhat-operators downgrade.
-	SkopeoRedhatOperatorsDowngrade = "skopeo copy --authfile %s --tls-verify=false docker://%s/olm/redhat-operators:v4.20-old docker://%s/olm/redhat-operators:v4.20-day2"
-	//nolint:lll
-	// SnoKubeconfigCreate command to get the SNO kubeconfig file.
-	SnoKubeconfigCreate = "oc -n %s get secret %s-admin-kubeconfig -o json | jq -r .data.kubeconfig | base64 -d > tmp/%s/auth/kubeconfig"
-	//nolint:lll
-	// CreateImageBasedInstallationConfig command to create the image based installation configuration template.
-	CreateImageBasedInstallationConfig = "openshift-install image-based create image-config-template --dir tmp/ibi-iso-workdir"
-	// CreateIsoImage command to create the ISO image.
-	CreateIsoImage = "openshift-install image-based create image --dir tmp/ibi-iso-workdir"
-	//nolint:lll
-	// CheckIbiCompleted command to check that the image based installation has finished.
-	CheckIbiCompleted = "journalctl -u install-rhcos-and-restore-seed.service | grep 'Finished SNO Image-based Installation.'"
-
-	// SpokeSSHUser ssh user of the spoke cluster.
-	SpokeSSHUser = "core"
-	// SpokeSSHPasskeyPath path to the ssh key of the spoke cluster.
-	SpokeSSHPasskeyPath = "/opt/id_rsa"
-	// SeedGeneratorName name of the seedgenerator CR.
-	SeedGeneratorName = "seedimage"
-	// RegistryCertPath path to the registry certificate.
-	RegistryCertPath = "/opt/registry.crt"
-	// IbiConfigTemplate template for the image based installation configuration.
-	IbiConfigTemplate = "/opt/ibi-config.yaml.tmpl"
-	// IbiConfigTemplateYaml path to the YAML file with the image based installation configuration.
-	IbiConfigTemplateYaml = "tmp/ibi-iso-workdir/image-based-installation-config.yaml"
-	// IbiBasedImageSourcePath path to the base image.
-	IbiBasedImageSourcePath = "tmp/ibi-iso-workdir/rhcos-ibi.iso"
-
-	// PtpCPURequest is cpu request for the PTP container.
-	PtpCPURequest = "50m"
-	// PtpMemoryRequest is cpu request for the PTP container.
-	PtpMemoryRequest = "100Mi"
-	// PtpCPULimit is cpu limit for the PTP container.
-	PtpCPULimit = "1m"
-	// PtpMemoryLimit is cpu limit for the PTP container.
-	PtpMemoryLimit = "1Mi"
-)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
 	"gopkg.in/yaml.v2"
 )
@@ -160,7 +161,12 @@ func readFile(randuConfig *RanDuConfig, cfgFile string) error {
 }
 
 func readEnv(randuConfig *RanDuConfig) error {
-	err := envconfig.Process("", randuConfig)
+	err := config.ReadProfiles(randuConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", randuConfig)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,9 @@
 package randuinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/ran-du/internal/randuconfig"
@@ -13,9 +16,19 @@ var (
 	RanDuTestConfig *randuconfig.RanDuConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the RAN DU config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("ran-du", setup)
+}
+
+// setup loads the RAN DU config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	RanDuTestConfig = randuconfig.NewRanDuConfig()
+	if RanDuTestConfig == nil {
+		return errors.New("failed to load RAN DU config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
@@ -40,12 +40,9 @@ const (
 )
 
 var (
-	egressIPPodLabelsMap = map[string]string{
-		strings.Split(RDSCoreConfig.EgressIPPodLabel, "=")[0]: strings.Split(RDSCoreConfig.EgressIPPodLabel, "=")[1]}
-
-	egressIPPodSelector = metav1.ListOptions{
-		LabelSelector: RDSCoreConfig.EgressIPPodLabel,
-	}
+	// egressIPPodLabelsMap and egressIPPodSelector are set by setup from RDSCoreConfig.EgressIPPodLabel.
+	egressIPPodLabelsMap   map[string]string
+	egressIPPodSelector    metav1.ListOptions
 	nonEgressIPPodSelector = metav1.ListOptions{
 		LabelSelector: nonEgressIPPodLabel,
 	}
@@ -37,14 +37,11 @@ tcpdump -vvv -e -nn -i %s | egrep -i 'Host:'
 )
 
 var (
-	bgpOneAppURLIPv4 = fmt.Sprintf("http://%s:%s",
-		RDSCoreConfig.MetalLBLoadBalancerOneIPv4, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
-	bgpOneAppURLIPv6 = fmt.Sprintf("http://[%s]:%s",
-		RDSCoreConfig.MetalLBLoadBalancerOneIPv6, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
-	bgpTwoAppURLIPv4 = fmt.Sprintf("http://%s:%s",
-		RDSCoreConfig.MetalLBLoadBalancerTwoIPv4, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
-	bgpTwoAppURLIPv6 = fmt.Sprintf("http://[%s]:%s",
-		RDSCoreConfig.MetalLBLoadBalancerTwoIPv6, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
+	// The URLs of the BGP apps are set by setup from the MetalLB load balancer IPs in RDSCoreConfig.
+	bgpOneAppURLIPv4      string
+	bgpOneAppURLIPv6      string
+	bgpTwoAppURLIPv4      string
+	bgpTwoAppURLIPv6      string
 	stDeploymentLabelList = metav1.ListOptions{
 		LabelSelector: "rds-core=supporttools-deploy",
 	}
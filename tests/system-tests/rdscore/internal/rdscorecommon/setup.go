@@ -0,0 +1,52 @@
+package rdscorecommon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
+	. "github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/rdscore/internal/rdscoreinittools"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// init registers setup so the package vars derived from RDSCoreConfig are set once it is loaded by inittools.Setup.
+//
+//nolint:gochecknoinits // RDSCoreConfig is not available until inittools.Setup is called
+func init() {
+	inittools.RegisterSetup("rdscorecommon", setup)
+}
+
+// setup sets the package vars that are derived from RDSCoreConfig.
+func setup(context.Context) error {
+	egressIPLabelKey, egressIPLabelValue, _ := strings.Cut(RDSCoreConfig.EgressIPPodLabel, "=")
+	egressIPPodLabelsMap = map[string]string{egressIPLabelKey: egressIPLabelValue}
+	egressIPPodSelector = metav1.ListOptions{LabelSelector: RDSCoreConfig.EgressIPPodLabel}
+
+	bgpOneAppURLIPv4 = fmt.Sprintf("http://%s:%s",
+		RDSCoreConfig.MetalLBLoadBalancerOneIPv4, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
+	bgpOneAppURLIPv6 = fmt.Sprintf("http://[%s]:%s",
+		RDSCoreConfig.MetalLBLoadBalancerOneIPv6, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
+	bgpTwoAppURLIPv4 = fmt.Sprintf("http://%s:%s",
+		RDSCoreConfig.MetalLBLoadBalancerTwoIPv4, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
+	bgpTwoAppURLIPv6 = fmt.Sprintf("http://[%s]:%s",
+		RDSCoreConfig.MetalLBLoadBalancerTwoIPv6, RDSCoreConfig.MetalLBTrafficSegregationTargetPort)
+
+	deploymentNamespace = RDSCoreConfig.RootlessDPDKNamespace
+	dpdkVlanID = RDSCoreConfig.RootlessDPDKVlanID
+	dummyVlanID = RDSCoreConfig.RootlessDPDKDummyVlanID
+	dpdkDeploymentSAName = RDSCoreConfig.RootlessDPDKDeploymentSA
+	firstInterfaceBasedOnTapOne = fmt.Sprintf("%s.%s", tapOneInterfaceName, dpdkVlanID)
+	secondInterfaceBasedOnTapOne = fmt.Sprintf("%s.%s", tapOneInterfaceName, dummyVlanID)
+	dpdkNetworkOne = RDSCoreConfig.RootlessDPDKNetworkOne
+	dpdkNetworkTwo = RDSCoreConfig.RootlessDPDKNetworkTwo
+	dpdkPolicyTwo = RDSCoreConfig.RootlessDPDKPolicyTwo
+	dpdkClientDeploymentName = RDSCoreConfig.RootlessDPDKClientDeploymentName
+	dpdkClientVlanMac = RDSCoreConfig.RootlessDPDKClientVlanMac
+	dpdkClientMacVlanMac = RDSCoreConfig.RootlessDPDKClientMacVlanMac
+	dpdkClientIPVlanMac = RDSCoreConfig.RootlessDPDKClientIPVlanMac
+	dpdkClientIPVlanIP = RDSCoreConfig.RootlessDPDKClientIPVlanIPv4
+	dpdkClientIPVlanIPDummy = RDSCoreConfig.RootlessDPDKClientIPVlanIPv4Dummy
+
+	return nil
+}
@@ -61,21 +61,22 @@ const (
 )
 
 var (
-	deploymentNamespace          = RDSCoreConfig.RootlessDPDKNamespace
-	dpdkVlanID                   = RDSCoreConfig.RootlessDPDKVlanID
-	dummyVlanID                  = RDSCoreConfig.RootlessDPDKDummyVlanID
-	dpdkDeploymentSAName         = RDSCoreConfig.RootlessDPDKDeploymentSA
-	firstInterfaceBasedOnTapOne  = fmt.Sprintf("%s.%s", tapOneInterfaceName, dpdkVlanID)
-	secondInterfaceBasedOnTapOne = fmt.Sprintf("%s.%s", tapOneInterfaceName, dummyVlanID)
-	dpdkNetworkOne               = RDSCoreConfig.RootlessDPDKNetworkOne
-	dpdkNetworkTwo               = RDSCoreConfig.RootlessDPDKNetworkTwo
-	dpdkPolicyTwo                = RDSCoreConfig.RootlessDPDKPolicyTwo
-	dpdkClientDeploymentName     = RDSCoreConfig.RootlessDPDKClientDeploymentName
-	dpdkClientVlanMac            = RDSCoreConfig.RootlessDPDKClientVlanMac
-	dpdkClientMacVlanMac         = RDSCoreConfig.RootlessDPDKClientMacVlanMac
-	dpdkClientIPVlanMac          = RDSCoreConfig.RootlessDPDKClientIPVlanMac
-	dpdkClientIPVlanIP           = RDSCoreConfig.RootlessDPDKClientIPVlanIPv4
-	dpdkClientIPVlanIPDummy      = RDSCoreConfig.RootlessDPDKClientIPVlanIPv4Dummy
+	// The rootless DPDK settings are set by setup from RDSCoreConfig.
+	deploymentNamespace          string
+	dpdkVlanID                   string
+	dummyVlanID                  string
+	dpdkDeploymentSAName         string
+	firstInterfaceBasedOnTapOne  string
+	secondInterfaceBasedOnTapOne string
+	dpdkNetworkOne               string
+	dpdkNetworkTwo               string
+	dpdkPolicyTwo                string
+	dpdkClientDeploymentName     string
+	dpdkClientVlanMac            string
+	dpdkClientMacVlanMac         string
+	dpdkClientIPVlanMac          string
+	dpdkClientIPVlanIP           string
+	dpdkClientIPVlanIPDummy      string
 
 	rootUser         = int64(0)
 	waitTimeout      = 3 * time.Minute
@@ -654,7 +654,12 @@ func readFile(rdsConfig *CoreConfig, cfgFile string) error {
 }
 
 func readEnv(rdsConfig *CoreConfig) error {
-	err := envconfig.Process("", rdsConfig)
+	err := config.ReadProfiles(rdsConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", rdsConfig)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,9 @@
 package rdscoreinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/rdscore/internal/rdscoreconfig"
@@ -13,9 +16,19 @@ var (
 	RDSCoreConfig *rdscoreconfig.CoreConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the RDS Core config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("rdscore", setup)
+}
+
+// setup loads the RDS Core config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	RDSCoreConfig = rdscoreconfig.NewCoreConfig()
+	if RDSCoreConfig == nil {
+		return errors.New("failed to load RDS Core config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
 	"gopkg.in/yaml.v2"
 )
@@ -143,7 +144,12 @@ func readFile(spkConfig *SPKConfig, cfgFile string) error {
 }
 
 func readEnv(spkConfig *SPKConfig) error {
-	err := envconfig.Process("", spkConfig)
+	err := config.ReadProfiles(spkConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", spkConfig)
 	if err != nil {
 		return err
 	}
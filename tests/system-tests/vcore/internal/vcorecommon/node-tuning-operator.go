@@ -34,7 +34,8 @@ import (
 )
 
 var (
-	workerLabelList = []string{VCoreConfig.VCorePpMCPName, VCoreConfig.VCoreCpMCPName}
+	// workerLabelList is set by setup from the MCP names in VCoreConfig.
+	workerLabelList []string
 )
 
 // VerifyNTOSuite container that contains tests for Node Tuning Operator verification.
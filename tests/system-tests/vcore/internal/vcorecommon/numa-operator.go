@@ -27,9 +27,10 @@ import (
 )
 
 var (
-	workerLabel           = VCoreConfig.WorkerLabel
-	workerLabelMap        = VCoreConfig.WorkerLabelMap
-	workerLabelListOption = VCoreConfig.WorkerLabelListOption
+	// The worker labels are set by setup from VCoreConfig.
+	workerLabel           string
+	workerLabelMap        map[string]string
+	workerLabelListOption metav1.ListOptions
 )
 
 // VerifyNROPSuite container that contains tests for Numa Resources Operator verification.
@@ -0,0 +1,26 @@
+package vcorecommon
+
+import (
+	"context"
+
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
+	. "github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/vcore/internal/vcoreinittools"
+)
+
+// init registers setup so the package vars derived from VCoreConfig are set once it is loaded by inittools.Setup.
+//
+//nolint:gochecknoinits // VCoreConfig is not available until inittools.Setup is called
+func init() {
+	inittools.RegisterSetup("vcorecommon", setup)
+}
+
+// setup sets the package vars that are derived from VCoreConfig.
+func setup(context.Context) error {
+	workerLabelList = []string{VCoreConfig.VCorePpMCPName, VCoreConfig.VCoreCpMCPName}
+
+	workerLabel = VCoreConfig.WorkerLabel
+	workerLabelMap = VCoreConfig.WorkerLabelMap
+	workerLabelListOption = VCoreConfig.WorkerLabelListOption
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/config"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/internal/systemtestsconfig"
 	"gopkg.in/yaml.v2"
 )
@@ -100,7 +101,12 @@ func readFile(vcoreConfig *VCoreConfig, cfgFile string) error {
 }
 
 func readEnv(vcoreConfig *VCoreConfig) error {
-	err := envconfig.Process("", vcoreConfig)
+	err := config.ReadProfiles(vcoreConfig)
+	if err != nil {
+		return err
+	}
+
+	err = envconfig.Process("", vcoreConfig)
 	if err != nil {
 		return err
 	}
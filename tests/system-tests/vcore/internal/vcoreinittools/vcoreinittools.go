@@ -1,6 +1,9 @@
 package vcoreinittools
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/internal/inittools"
 	"github.com/rh-ecosystem-edge/eco-gotests/tests/system-tests/vcore/internal/vcoreconfig"
@@ -13,9 +16,19 @@ var (
 	VCoreConfig *vcoreconfig.VCoreConfig
 )
 
-// init loads all variables automatically when this package is imported. Once package is imported a user has full
-// access to all vars within init function. It is recommended to import this package using dot import.
+// init registers setup so the vCore config is loaded by inittools.Setup rather than on import.
 func init() {
+	inittools.RegisterSetup("vcore", setup)
+}
+
+// setup loads the vCore config and sets the API clients once the general config is loaded.
+func setup(context.Context) error {
 	VCoreConfig = vcoreconfig.NewVCoreConfig()
+	if VCoreConfig == nil {
+		return errors.New("failed to load vCore config")
+	}
+
 	APIClient = inittools.APIClient
+
+	return nil
 }